@@ -6,26 +6,56 @@ import (
 	"strings"
 
 	"github.com/NASHEDIxCODER/gospyder/internal/app"
+	"github.com/NASHEDIxCODER/gospyder/internal/intel"
+	"github.com/NASHEDIxCODER/gospyder/internal/logger"
 	targetparser "github.com/NASHEDIxCODER/gospyder/internal/target"
+	"github.com/NASHEDIxCODER/gospyder/pkg/enum"
+	"github.com/NASHEDIxCODER/gospyder/pkg/origin"
 )
 
 type GlobalOptions struct {
-	Threads *int
-	Timeout *int
-	Verbose *bool
-	Output  *string
+	Threads    *int
+	Timeout    *int
+	Verbose    *bool
+	Output     *string
+	NoProgress *bool
+	LogLevel   *string
+	LogFormat  *string
+	LogFile    *string
+	LogModules *string
+	Metrics    *string
+	OTLP       *string
+	Record     *string
+	Replay     *string
+	Resolvers  *string
+	ASNDB      *string
+	IPRanges   *string
+	OwnASN     *string
 }
 
 func addGlobalFlags(fs *flag.FlagSet) *GlobalOptions {
 	return &GlobalOptions{
-		Threads: fs.Int("t", 0, "number of threads"),
-		Timeout: fs.Int("timeout", 0, "timeout in seconds"),
-		Verbose: fs.Bool("v", false, "verbose mode"),
-		Output:  fs.String("o", "", "output file"),
+		Threads:    fs.Int("t", 0, "number of threads"),
+		Timeout:    fs.Int("timeout", 0, "timeout in seconds"),
+		Verbose:    fs.Bool("v", false, "verbose mode"),
+		Output:     fs.String("o", "", "output file"),
+		NoProgress: fs.Bool("no-progress", false, "disable the live progress display"),
+		LogLevel:   fs.String("log-level", "", "log level: debug, info, warn, error"),
+		LogFormat:  fs.String("log-format", "", "log format: console, text, json"),
+		LogFile:    fs.String("log-file", "", "also write logs to this file"),
+		LogModules: fs.String("log-module", "", "per-module log levels, e.g. enum=debug,ports=warn"),
+		Metrics:    fs.String("metrics-addr", "", "serve Prometheus metrics on this address, e.g. :9090"),
+		OTLP:       fs.String("otlp-endpoint", "", "export trace spans to this OTLP/HTTP collector"),
+		Record:     fs.String("record", "", "record HTTP, DNS and TCP interactions to this fixture file"),
+		Replay:     fs.String("replay", "", "answer HTTP, DNS and TCP interactions from this fixture file"),
+		Resolvers:  fs.String("r", "", "file with DNS resolvers, one ip, ip:port or tcp://, tls://, https:// URL per line"),
+		ASNDB:      fs.String("asn-db", "", "comma-separated MMDB files with ASN, organization or country data"),
+		IPRanges:   fs.String("ip-ranges", "", "comma-separated cloud/CDN range files; provider=path names a plain CIDR list"),
+		OwnASN:     fs.String("own-asn", "", "comma-separated ASNs of the client, e.g. AS64500"),
 	}
 }
 
-func applyGlobalFlags(opts *GlobalOptions, flags map[string]interface{}) {
+func applyGlobalFlags(opts *GlobalOptions, flags map[string]interface{}) error {
 	ctx := app.Global()
 	if *opts.Threads > 0 {
 		ctx.Config.Threads = *opts.Threads
@@ -41,6 +71,77 @@ func applyGlobalFlags(opts *GlobalOptions, flags map[string]interface{}) {
 	if *opts.Output != "" {
 		flags["output"] = *opts.Output
 	}
+	if *opts.NoProgress {
+		ctx.Config.Progress.Enabled = false
+	}
+	if *opts.Resolvers != "" {
+		ctx.Config.DNS.ResolversFile = *opts.Resolvers
+	}
+	if *opts.LogLevel != "" {
+		ctx.Config.Log.Level = *opts.LogLevel
+	}
+	if *opts.LogFormat != "" {
+		ctx.Config.Log.Format = *opts.LogFormat
+	}
+	if *opts.LogFile != "" {
+		ctx.Config.Log.File = *opts.LogFile
+	}
+	if *opts.LogModules != "" {
+		levels, err := logger.ParseModuleLevels(*opts.LogModules)
+		if err != nil {
+			return err
+		}
+		ctx.Config.Log.Modules = levels
+	}
+	if err := ctx.ConfigureLogger(); err != nil {
+		return err
+	}
+	if *opts.Metrics != "" || *opts.OTLP != "" {
+		if *opts.Metrics != "" {
+			ctx.Config.Telemetry.MetricsAddr = *opts.Metrics
+		}
+		if *opts.OTLP != "" {
+			ctx.Config.Telemetry.OTLPEndpoint = *opts.OTLP
+		}
+		if err := ctx.ConfigureTelemetry(); err != nil {
+			return err
+		}
+	}
+	if *opts.ASNDB != "" || *opts.IPRanges != "" || *opts.OwnASN != "" {
+		if *opts.ASNDB != "" {
+			ctx.Config.Intel.ASNDatabases = splitComma(*opts.ASNDB)
+		}
+		if *opts.IPRanges != "" {
+			ctx.Config.Intel.Ranges = splitComma(*opts.IPRanges)
+		}
+		if *opts.OwnASN != "" {
+			asns, err := intel.ParseASNs(*opts.OwnASN)
+			if err != nil {
+				return err
+			}
+			ctx.Config.Intel.OwnASNs = asns
+		}
+		if err := ctx.ConfigureIntel(); err != nil {
+			return err
+		}
+	}
+	if *opts.Record != "" || *opts.Replay != "" {
+		ctx.Config.Replay.Record = *opts.Record
+		ctx.Config.Replay.Replay = *opts.Replay
+		return ctx.ConfigureReplay()
+	}
+	return nil
+}
+
+// splitComma splits a comma-separated flag value, dropping empty items.
+func splitComma(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
 
 // HandleEnum handles subdomain enumeration command
@@ -51,7 +152,47 @@ func HandleEnum(args []string) error {
 
 	fs := flag.NewFlagSet("enum", flag.ContinueOnError)
 	wordlist := fs.String("w", "wordlists/subdomains.txt", "subdomain wordlist")
-	mode := fs.String("mode", "active", "enum mode: active, passive, both")
+	mode := fs.String("mode", "active", "enum mode: active, passive, both, permute")
+	depth := fs.Int("depth", enum.DefaultRecursionDepth, "brute-force found subdomains this many labels below the target; 0 disables recursion")
+	permute := fs.Bool("permute", false, "also resolve alterations of the names found, in any mode")
+	permuteWords := fs.String("permute-words", "", "permutation wordlist (default: built-in words)")
+	permutePatterns := fs.String("permute-patterns", "", "permutation pattern file, e.g. {{word}}-{{sub}} per line (default: built-in patterns)")
+	permuteMax := fs.Int("permute-max", enum.DefaultMaxPermutations, "maximum permutation candidates to resolve")
+	sourceList := fs.String("sources", "", "passive sources, e.g. crtsh,wayback,certstream (default: crtsh,wayback,commoncrawl,passivedns)")
+	importFile := fs.String("import", "", "import subdomains from a file (lines, CSV or JSON); - reads stdin")
+	workspace := fs.Bool("workspace", true, "save results to workspace")
+	globalOpts := addGlobalFlags(fs)
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	flags := map[string]interface{}{
+		"target":           args[0],
+		"wordlist":         *wordlist,
+		"mode":             *mode,
+		"depth":            *depth,
+		"sources":          *sourceList,
+		"import":           *importFile,
+		"permute":          *permute,
+		"permute-words":    *permuteWords,
+		"permute-patterns": *permutePatterns,
+		"permute-max":      *permuteMax,
+		"workspace":        *workspace,
+	}
+	if err := applyGlobalFlags(globalOpts, flags); err != nil {
+		return err
+	}
+
+	return ExecuteModule("enum", flags)
+}
+
+// HandleDNS handles DNS record collection command
+func HandleDNS(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: gospyder dns <domain> [options]")
+	}
+
+	fs := flag.NewFlagSet("dns", flag.ContinueOnError)
 	workspace := fs.Bool("workspace", true, "save results to workspace")
 	globalOpts := addGlobalFlags(fs)
 	if err := fs.Parse(args[1:]); err != nil {
@@ -60,46 +201,193 @@ func HandleEnum(args []string) error {
 
 	flags := map[string]interface{}{
 		"target":    args[0],
-		"wordlist":  *wordlist,
-		"mode":      *mode,
 		"workspace": *workspace,
 	}
-	applyGlobalFlags(globalOpts, flags)
+	if err := applyGlobalFlags(globalOpts, flags); err != nil {
+		return err
+	}
 
-	return ExecuteModule("enum", flags)
+	return ExecuteModule("dns", flags)
+}
+
+// HandleMail handles email security posture command
+func HandleMail(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: gospyder mail <domain> [options]")
+	}
+
+	fs := flag.NewFlagSet("mail", flag.ContinueOnError)
+	selectors := fs.String("selectors", "", "comma-separated DKIM selectors to try besides the common ones")
+	workspace := fs.Bool("workspace", true, "save results to workspace")
+	globalOpts := addGlobalFlags(fs)
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	flags := map[string]interface{}{
+		"target":    args[0],
+		"selectors": *selectors,
+		"workspace": *workspace,
+	}
+	if err := applyGlobalFlags(globalOpts, flags); err != nil {
+		return err
+	}
+
+	return ExecuteModule("mail", flags)
+}
+
+// HandlePTR handles reverse DNS sweep command
+func HandlePTR(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: gospyder ptr <domain|ip|cidr> [options]")
+	}
+
+	fs := flag.NewFlagSet("ptr", flag.ContinueOnError)
+	ips := fs.String("ips", "", "comma-separated IPs or CIDRs to sweep")
+	list := fs.String("l", "", "file of IPs or CIDRs to sweep, one per line")
+	apex := fs.String("apex", "", "comma-separated extra domains whose names are kept")
+	sweep := fs.Int("sweep", 0, "also sweep the /N block around each IPv4 address, e.g. 24 (0 disables)")
+	workspace := fs.Bool("workspace", true, "save results to workspace")
+	globalOpts := addGlobalFlags(fs)
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	flags := map[string]interface{}{
+		"target":    args[0],
+		"ips":       *ips,
+		"list":      *list,
+		"apex":      *apex,
+		"sweep":     *sweep,
+		"workspace": *workspace,
+	}
+	if err := applyGlobalFlags(globalOpts, flags); err != nil {
+		return err
+	}
+
+	return ExecuteModule("ptr", flags)
+}
+
+// HandleTakeover handles subdomain takeover detection command
+func HandleTakeover(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: gospyder takeover <domain> [options]")
+	}
+
+	fs := flag.NewFlagSet("takeover", flag.ContinueOnError)
+	list := fs.String("l", "", "file of subdomains to check, one per line")
+	fingerprints := fs.String("fingerprints", "", "JSON file of extra takeover fingerprints")
+	workspace := fs.Bool("workspace", true, "save results to workspace")
+	globalOpts := addGlobalFlags(fs)
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	flags := map[string]interface{}{
+		"target":       args[0],
+		"list":         *list,
+		"fingerprints": *fingerprints,
+		"workspace":    *workspace,
+	}
+	if err := applyGlobalFlags(globalOpts, flags); err != nil {
+		return err
+	}
+
+	return ExecuteModule("takeover", flags)
 }
 
 // HandlePorts handles port scanning command
 func HandlePorts(args []string) error {
 	if len(args) < 1 {
-		return fmt.Errorf("usage: gospyder ports <domain> [options]")
+		return fmt.Errorf("usage: gospyder ports <host|cidr[,...]> [options]")
 	}
 
 	cfg := app.Global().Config
 	fs := flag.NewFlagSet("ports", flag.ContinueOnError)
-	portsList := fs.String("ports-list", "", "ports to scan, e.g. 80,443,8000-8010")
+	portsList := fs.String("ports-list", "", "ports, ranges and sets (web, db, mail, all) to scan, e.g. 80,443,8000-8010; - for all")
+	fs.StringVar(portsList, "p", "", "shorthand for -ports-list")
+	topPorts := fs.Int("top-ports", 0, "scan the N most frequently open ports")
+	excludePorts := fs.String("exclude-ports", "", "ports, ranges and sets never to scan")
+	sequential := fs.Bool("sequential", !cfg.Scanner.RandomizePorts, "scan ports in order instead of randomly")
+	deadAfter := fs.Int("dead-after", cfg.Scanner.DeadHostPorts, "skip a host once this many ports went unanswered and none answered (0: never)")
 	retry := fs.Int("retry", cfg.Retries, "retry attempts for failed connections")
+	udp := fs.Bool("sU", false, "scan UDP ports")
+	tcp := fs.Bool("sT", false, "scan TCP ports too when -sU is set")
+	udpRate := fs.Int("udp-rate", cfg.Scanner.UDPRate, "UDP probes per second")
+	syn := fs.Bool("syn", false, "SYN scan TCP ports through a raw socket (Linux, needs CAP_NET_RAW)")
+	synRate := fs.Int("syn-rate", cfg.Scanner.SYNRate, "SYN packets per second")
+	probes := fs.String("probes", cfg.Scanner.ServiceProbes, "extra service probes in nmap-service-probes syntax")
+	intensity := fs.Int("intensity", cfg.Scanner.ProbeIntensity, "highest rarity of service probes sent to any port (0-9)")
 	workspace := fs.Bool("workspace", true, "save results to workspace")
 	globalOpts := addGlobalFlags(fs)
-	if err := fs.Parse(args[1:]); err != nil {
+	if err := fs.Parse(allPortsFlag(args[1:])); err != nil {
 		return err
 	}
-	remaining := fs.Args()
-	if len(remaining) < 1 {
-		return fmt.Errorf("usage: gospyder ports <domain> [options]")
-	}
-	target := remaining[0]
 
 	flags := map[string]interface{}{
-		"target":     target,
-		"retry":      *retry,
-		"ports-list": *portsList,
-		"workspace":  *workspace,
+		"target":        args[0],
+		"retry":         *retry,
+		"ports-list":    *portsList,
+		"top-ports":     *topPorts,
+		"exclude-ports": *excludePorts,
+		"sequential":    *sequential,
+		"dead-after":    *deadAfter,
+		"udp":           *udp,
+		"tcp":           *tcp,
+		"udp-rate":      *udpRate,
+		"syn":           *syn,
+		"syn-rate":      *synRate,
+		"probes":        *probes,
+		"intensity":     *intensity,
+		"workspace":     *workspace,
+	}
+	if err := applyGlobalFlags(globalOpts, flags); err != nil {
+		return err
 	}
-	applyGlobalFlags(globalOpts, flags)
 	return ExecuteModule("ports", flags)
 }
 
+// allPortsFlag rewrites nmap's "-p-", which the flag package would read
+// as a flag named "p-", to "-p=-".
+func allPortsFlag(args []string) []string {
+	out := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "-p-" || arg == "--p-" {
+			arg = "-p=-"
+		}
+		out[i] = arg
+	}
+	return out
+}
+
+// HandleTLS handles TLS inspection command
+func HandleTLS(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: gospyder tls <host[:port]> [options]")
+	}
+
+	cfg := app.Global().Config
+	fs := flag.NewFlagSet("tls", flag.ContinueOnError)
+	ports := fs.String("ports", "", "ports, ranges and sets to inspect (default: from config, usually 443,8443)")
+	ciphers := fs.Bool("ciphers", cfg.TLS.Ciphers, "enumerate the cipher suites of every protocol version")
+	workspace := fs.Bool("workspace", true, "save results to workspace")
+	globalOpts := addGlobalFlags(fs)
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	flags := map[string]interface{}{
+		"target":    args[0],
+		"tls-ports": *ports,
+		"ciphers":   *ciphers,
+		"workspace": *workspace,
+	}
+	if err := applyGlobalFlags(globalOpts, flags); err != nil {
+		return err
+	}
+	return ExecuteModule("tls", flags)
+}
+
 // HandleFuzz handles directory fuzzing command
 func HandleFuzz(args []string) error {
 	if len(args) < 1 {
@@ -119,7 +407,9 @@ func HandleFuzz(args []string) error {
 		"wordlist":  *wordlist,
 		"workspace": *workspace,
 	}
-	applyGlobalFlags(globalOpts, flags)
+	if err := applyGlobalFlags(globalOpts, flags); err != nil {
+		return err
+	}
 
 	return ExecuteModule("fuzz", flags)
 }
@@ -141,11 +431,43 @@ func HandleWAF(args []string) error {
 		"target":    args[0],
 		"workspace": *workspace,
 	}
-	applyGlobalFlags(globalOpts, flags)
+	if err := applyGlobalFlags(globalOpts, flags); err != nil {
+		return err
+	}
 
 	return ExecuteModule("waf", flags)
 }
 
+// HandleOrigin handles origin IP discovery command
+func HandleOrigin(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: gospyder origin <domain> [options]")
+	}
+
+	fs := flag.NewFlagSet("origin", flag.ContinueOnError)
+	ips := fs.String("ips", "", "comma-separated IPs or CIDRs to check for the domain's certificate")
+	history := fs.String("history", "", "file of past DNS records, one \"[time] name ttl A ip\" per line")
+	threshold := fs.Float64("threshold", origin.DefaultThreshold, "response similarity (0-1) that marks a candidate as the origin")
+	workspace := fs.Bool("workspace", true, "save results to workspace")
+	globalOpts := addGlobalFlags(fs)
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	flags := map[string]interface{}{
+		"target":    args[0],
+		"ips":       *ips,
+		"history":   *history,
+		"threshold": *threshold,
+		"workspace": *workspace,
+	}
+	if err := applyGlobalFlags(globalOpts, flags); err != nil {
+		return err
+	}
+
+	return ExecuteModule("origin", flags)
+}
+
 // HandleHTTP handles HTTP probe command.
 func HandleHTTP(args []string) error {
 	if len(args) < 1 {
@@ -163,7 +485,9 @@ func HandleHTTP(args []string) error {
 		"target":    args[0],
 		"workspace": *workspace,
 	}
-	applyGlobalFlags(globalOpts, flags)
+	if err := applyGlobalFlags(globalOpts, flags); err != nil {
+		return err
+	}
 
 	return ExecuteModule("http", flags)
 }
@@ -185,7 +509,9 @@ func HandleLive(args []string) error {
 		"target":    args[0],
 		"workspace": *workspace,
 	}
-	applyGlobalFlags(globalOpts, flags)
+	if err := applyGlobalFlags(globalOpts, flags); err != nil {
+		return err
+	}
 
 	return ExecuteModule("live", flags)
 }
@@ -207,7 +533,9 @@ func HandleTech(args []string) error {
 		"target":    args[0],
 		"workspace": *workspace,
 	}
-	applyGlobalFlags(globalOpts, flags)
+	if err := applyGlobalFlags(globalOpts, flags); err != nil {
+		return err
+	}
 
 	return ExecuteModule("tech", flags)
 }
@@ -231,7 +559,9 @@ func HandleCrawl(args []string) error {
 		"depth":     *depth,
 		"workspace": *workspace,
 	}
-	applyGlobalFlags(globalOpts, flags)
+	if err := applyGlobalFlags(globalOpts, flags); err != nil {
+		return err
+	}
 
 	return ExecuteModule("crawl", flags)
 }
@@ -253,7 +583,9 @@ func HandleJS(args []string) error {
 		"target":    args[0],
 		"workspace": *workspace,
 	}
-	applyGlobalFlags(globalOpts, flags)
+	if err := applyGlobalFlags(globalOpts, flags); err != nil {
+		return err
+	}
 
 	return ExecuteModule("js", flags)
 }
@@ -267,15 +599,18 @@ func HandleRecon(args []string) error {
 	fs := flag.NewFlagSet("recon", flag.ContinueOnError)
 	enumWordlist := fs.String("w", "wordlists/subdomains.txt", "subdomain wordlist")
 	fuzzWordlist := fs.String("fuzz-wordlist", "wordlists/paths.txt", "path wordlist")
-	portsList := fs.String("ports-list", "", "ports to scan")
+	portsList := fs.String("ports-list", "", "ports, ranges and sets to scan; - for all")
+	fs.StringVar(portsList, "p", "", "shorthand for -ports-list")
+	topPorts := fs.Int("top-ports", 0, "scan the N most frequently open ports")
+	excludePorts := fs.String("exclude-ports", "", "ports, ranges and sets never to scan")
 	workspace := fs.Bool("workspace", true, "save results to workspace")
 	globalOpts := addGlobalFlags(fs)
-	if err := fs.Parse(args[1:]); err != nil {
+	if err := fs.Parse(allPortsFlag(args[1:])); err != nil {
 		return err
 	}
 
 	// Execute multiple modules in sequence
-	modules := []string{"enum", "ports", "fuzz", "waf", "http", "live", "tech", "js"}
+	modules := []string{"enum", "ptr", "dns", "mail", "takeover", "ports", "tls", "fuzz", "waf", "origin", "http", "live", "tech", "js"}
 	parsed, err := targetparser.Normalize(args[0])
 	if err != nil {
 		return fmt.Errorf("invalid target: %w", err)
@@ -287,10 +622,14 @@ func HandleRecon(args []string) error {
 		"wordlist":      *enumWordlist,
 		"fuzz-wordlist": *fuzzWordlist,
 		"ports-list":    *portsList,
+		"top-ports":     *topPorts,
+		"exclude-ports": *excludePorts,
 		"mode":          "active",
 		"workspace":     *workspace,
 	}
-	applyGlobalFlags(globalOpts, flags)
+	if err := applyGlobalFlags(globalOpts, flags); err != nil {
+		return err
+	}
 
 	ctx := app.Global()
 	ctx.Logger.Debug("Starting full reconnaissance for %s", args[0])
@@ -331,15 +670,22 @@ Usage:
 
 Commands:
   enum                 Subdomain enumeration
-  ports                Port scanning
+  dns                  DNS record collection (A, AAAA, CNAME, MX, NS, TXT, SOA)
+  mail                 Email security posture (SPF, DMARC, DKIM, MTA-STS, BIMI)
+  ptr                  Reverse DNS sweep of IPs and CIDRs for sibling hostnames
+  takeover             Subdomain takeover detection (dangling CNAMEs)
+  ports                TCP and UDP (-sU) port scanning
+  tls                  TLS certificates, protocols, cipher suites, JARM and JA3S
   fuzz                 Directory fuzzing
   waf                  WAF detection
+  origin               Origin IP discovery behind CDNs and WAFs
   http                 HTTP probe
   live                 Live host detection
   tech                 Technology fingerprinting
   crawl                Web crawling (URLs, parameters, APIs, JS files)
   js                   JavaScript analysis (endpoints, secrets, domains)
   recon                Full reconnaissance (all modules)
+  watch                Follow CertStream, appending new subdomains until Ctrl+C
   list                 List all available modules
   help [module]        Show help for specific module
 
@@ -348,13 +694,44 @@ Global Options:
   -timeout <seconds>   Timeout in seconds (default: 10)
   -v                   Enable verbose output
   -o <file>            Save report to file
+  -no-progress         Disable the live progress display on stderr
+  -r <file>            DNS resolvers to use, one ip, ip:port or tcp://, tls://,
+                       https:// URL per line
+  -log-level <level>   Log level: debug, info, warn, error (default: info)
+  -log-format <fmt>    Log format: console, text, json (default: console)
+  -log-file <file>     Also write logs to a file
+  -log-module <spec>   Per-module log levels, e.g. enum=debug,ports=warn
+  -metrics-addr <addr> Serve Prometheus metrics at http://<addr>/metrics
+  -otlp-endpoint <url> Export trace spans to an OTLP/HTTP collector
+  -record <file>       Record HTTP, DNS and TCP interactions to a fixture file
+  -replay <file>       Replay a recorded fixture instead of touching the network
+  -asn-db <files>      MMDB files with ASN, organization or country data
+  -ip-ranges <files>   Cloud/CDN range files; provider=path names a CIDR list
+  -own-asn <asns>      The client's own ASNs, e.g. AS64500
 
 Examples:
   gospyder enum example.com
+  gospyder enum example.com -mode passive -sources crtsh,wayback
+  gospyder enum example.com -permute -permute-words words.txt
+  gospyder enum example.com -depth 2 -timeout 600
+  gospyder dns example.com
+  gospyder mail example.com -selectors mx2024
+  gospyder ptr example.com -ips 192.0.2.0/24
+  gospyder takeover example.com -l subdomains.txt
   gospyder ports example.com
+  gospyder ports example.com -sU -sT -ports-list 53,161,443
+  gospyder ports example.com -top-ports 1000 -exclude-ports 22
+  gospyder ports 192.0.2.10 -p- -t 500
+  gospyder ports 192.0.2.0/24,2001:db8::/120 -top-ports 100
+  gospyder ports 192.0.2.0/24 -p- -syn -syn-rate 5000
+  gospyder ports example.com -intensity 9 -probes nmap-service-probes
+  gospyder tls example.com
+  gospyder tls example.com:8443 -ciphers=false
   gospyder fuzz https://example.com
+  gospyder origin example.com -ips 203.0.113.0/24
   gospyder js https://example.com
   gospyder recon example.com
+  gospyder watch example.com -url ws://localhost:8080/
   gospyder help
 
 For more information, visit: https://github.com/NASHEDIxCODER/gospyder
@@ -10,8 +10,12 @@ import (
 	"time"
 
 	"github.com/NASHEDIxCODER/gospyder/internal/app"
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
 	"github.com/NASHEDIxCODER/gospyder/internal/registry"
+	"github.com/NASHEDIxCODER/gospyder/internal/telemetry"
 	"github.com/NASHEDIxCODER/gospyder/internal/workspace"
+	"github.com/NASHEDIxCODER/gospyder/pkg/enum"
+	"github.com/NASHEDIxCODER/gospyder/pkg/origin"
 )
 
 // Handler base structure for all command handlers
@@ -28,7 +32,9 @@ func NewHandler(moduleNames ...string) *Handler {
 
 // ExecuteModule executes a single module with given flags
 func ExecuteModule(moduleName string, flags map[string]interface{}) error {
+	stopProgress := startProgress()
 	result, err := RunModule(moduleName, flags)
+	stopProgress()
 	if err != nil {
 		return err
 	}
@@ -63,35 +69,120 @@ func RunModule(moduleName string, flags map[string]interface{}) (*registry.Resul
 
 	opts := registry.Options{
 		Config:     ctx.Config,
-		Logger:     ctx.Logger,
+		Logger:     ctx.Logger.ForModule(moduleName),
 		Formatter:  ctx.Formatter,
 		Workspace:  ctx.Workspace,
-		HTTPClient: ctx.HTTPClient,
+		HTTPClient: ctx.Telemetry.InstrumentClient(ctx.Replay.Client(ctx.HTTPClient), moduleName),
 		Flags:      flags,
-		Errors:     ctx.Errors,
+		Errors:     errors.NewCollector(),
+		Telemetry:  ctx.Telemetry,
+		Replay:     ctx.Replay,
+		Intel:      ctx.Intel,
+	}
+	// Save after every module: a failing command exits without Cleanup, and
+	// recon keeps what earlier modules captured.
+	defer saveReplay(ctx)
+	if task := ctx.Progress.Track(moduleName, progressUnit(moduleName)); task != nil {
+		opts.Progress = task
+		defer task.Finish()
 	}
 
-	ctx.Logger.Info("Starting module: %s", moduleName)
+	target, _ := flags["target"].(string)
+	appCtx, span := ctx.Telemetry.StartSpan(appCtx, "module.run",
+		telemetry.String("module", moduleName),
+		telemetry.String("target", target),
+	)
+	defer span.End()
+	if traceID := span.TraceID(); traceID != "" {
+		opts.Logger = opts.Logger.With("trace_id", traceID)
+	}
+
+	opts.Logger.Info("Starting module: %s", moduleName)
 	start := time.Now()
 
 	result, err := module.Run(appCtx, opts)
+	for _, moduleErr := range opts.Errors.Errors() {
+		ctx.Errors.Add(moduleErr)
+	}
 	if err != nil {
-		ctx.Logger.Error("Module %s failed: %v", moduleName, err)
+		ctx.Errors.Add(err)
+		span.RecordError(err)
+		ctx.Telemetry.ObserveModule(moduleName, "error", time.Since(start), 0)
+		opts.Logger.Error("Module %s failed: %v", moduleName, err)
 		return nil, err
 	}
 
 	duration := time.Since(start)
 	if result != nil {
+		// Enrichment stage: annotate every finding carrying addresses.
+		for _, finding := range result.Findings {
+			ctx.Intel.Annotate(finding.Metadata)
+		}
 		result.Duration = duration.Seconds()
+		result.AddErrors(opts.Errors.Errors()...)
+		if len(result.Errors) > 0 {
+			opts.Logger.Warn("Module %s finished with %d error(s): %s", moduleName, len(result.Errors), formatErrorCounts(result.ErrorCounts))
+		}
+		span.SetAttributes(
+			telemetry.String("status", result.Status),
+			telemetry.Int("findings", len(result.Findings)),
+		)
+		ctx.Telemetry.ObserveModule(moduleName, result.Status, duration, len(result.Findings))
 	}
-	ctx.Logger.Info("Module %s completed in %.2fs", moduleName, duration.Seconds())
+	opts.Logger.Info("Module %s completed in %.2fs", moduleName, duration.Seconds())
 
 	return result, nil
 }
 
+func saveReplay(ctx *app.AppContext) {
+	if err := ctx.SaveReplay(); err != nil {
+		ctx.Logger.Warn("Replay: %v", err)
+	}
+}
+
+// startProgress begins the live progress display when enabled and routes log
+// output through it so log lines do not tear the status block. The returned
+// function stops the display and must be called before printing results.
+func startProgress() func() {
+	ctx := app.Global()
+	if !ctx.Config.Progress.Enabled || ctx.Progress == nil {
+		return func() {}
+	}
+	ctx.Progress.Start()
+	ctx.Logger.SetOutput(ctx.Progress)
+	return func() {
+		ctx.Progress.Stop()
+		ctx.Logger.SetOutput(os.Stderr)
+	}
+}
+
+// progressUnit names the work item each module counts, for rate display.
+func progressUnit(module string) string {
+	switch module {
+	case "enum":
+		return "lookups"
+	case "dns", "takeover":
+		return "names"
+	case "ptr", "origin":
+		return "addresses"
+	case "mail":
+		return "checks"
+	case "ports", "tls":
+		return "ports"
+	case "crawl":
+		return "pages"
+	case "js":
+		return "files"
+	default:
+		return "req"
+	}
+}
+
 // ExecuteModules executes multiple modules
 func ExecuteModules(moduleNames []string, flags map[string]interface{}) error {
 	results := make([]*registry.Result, 0, len(moduleNames))
+	stopProgress := startProgress()
+	defer stopProgress()
 	priorResults := map[string]*registry.Result{}
 
 	for _, moduleName := range moduleNames {
@@ -105,7 +196,7 @@ func ExecuteModules(moduleNames []string, flags map[string]interface{}) error {
 		// Route correct target format per module
 		switch moduleName {
 
-		case "enum", "ports":
+		case "enum", "ptr", "dns", "mail", "takeover", "ports", "tls", "origin":
 			if host, ok := flags["host"]; ok {
 				moduleFlags["target"] = host
 			}
@@ -139,6 +230,7 @@ func ExecuteModules(moduleNames []string, flags map[string]interface{}) error {
 		}
 	}
 
+	stopProgress()
 	ctx := app.Global()
 
 	formatted, err := ctx.Formatter.Format(results)
@@ -172,6 +264,12 @@ func saveModuleResult(result *registry.Result, flags map[string]interface{}, for
 	if err != nil {
 		return "", err
 	}
+	if err := appendDNSHistory(ws, result); err != nil {
+		return "", err
+	}
+	if err := saveTLSNames(ws, result); err != nil {
+		return "", err
+	}
 	return ws.Path, nil
 }
 
@@ -194,6 +292,12 @@ func saveReconResults(results []*registry.Result, flags map[string]interface{},
 		if _, err := ws.SaveResult(result.Module, workspaceFileName(result.Module), []byte(content)); err != nil {
 			return "", err
 		}
+		if err := appendDNSHistory(ws, result); err != nil {
+			return "", err
+		}
+		if err := saveTLSNames(ws, result); err != nil {
+			return "", err
+		}
 	}
 
 	if _, err := ws.SaveResult("recon", "recon-summary.txt", []byte(summary)); err != nil {
@@ -202,6 +306,50 @@ func saveReconResults(results []*registry.Result, flags map[string]interface{},
 	return ws.Path, nil
 }
 
+// appendDNSHistory keeps the A and AAAA records of dns results across
+// runs, so origin can try addresses the domain pointed to before a CDN.
+func appendDNSHistory(ws *workspace.Workspace, result *registry.Result) error {
+	if result.Module != "dns" {
+		return nil
+	}
+	seen := result.Timestamp.UTC().Format(time.RFC3339)
+	var lines []string
+	for _, f := range result.Findings {
+		if kind, _ := f.Metadata["type"].(string); kind == "A" || kind == "AAAA" {
+			lines = append(lines, seen+" "+f.Value)
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	_, err := ws.AppendResult(result.Module, origin.HistoryFile, lines...)
+	return err
+}
+
+// saveTLSNames merges the certificate names of tls results into the
+// workspace's seed file, so later enum runs resolve them.
+func saveTLSNames(ws *workspace.Workspace, result *registry.Result) error {
+	names, _ := result.Metadata["san_names"].([]string)
+	if result.Module != "tls" || len(names) == 0 {
+		return nil
+	}
+	path := filepath.Join(ws.Path, enum.SeedFile)
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	seen := map[string]bool{}
+	var merged []string
+	for _, name := range append(strings.Fields(string(existing)), names...) {
+		if !seen[name] {
+			seen[name] = true
+			merged = append(merged, name)
+		}
+	}
+	sort.Strings(merged)
+	return os.WriteFile(path, []byte(strings.Join(merged, "\n")+"\n"), 0644)
+}
+
 func workspaceEnabled(flags map[string]interface{}) bool {
 	ctx := app.Global()
 	enabled := ctx.Config.Workspace.Enabled
@@ -225,12 +373,22 @@ func workspaceFileName(module string) string {
 	switch module {
 	case "enum":
 		return "subdomains.txt"
+	case "dns":
+		return "dns-records.txt"
+	case "ptr":
+		return "ptr-hostnames.txt"
+	case "mail":
+		return "mail-security.txt"
 	case "ports":
 		return "ports.txt"
+	case "tls":
+		return "tls.txt"
 	case "fuzz":
 		return "fuzz.txt"
 	case "waf":
 		return "waf.txt"
+	case "origin":
+		return "origin-ips.txt"
 	case "http":
 		return "http-probe.txt"
 	case "live":
@@ -254,6 +412,37 @@ func moduleWorkspaceContent(result *registry.Result, formatted string) string {
 	case "enum":
 		b.WriteString("Subdomains:\n")
 		writeFindingLines(&b, result, func(f registry.Finding) string { return f.Value }, "No subdomains found")
+	case "dns":
+		b.WriteString("DNS Records:\n")
+		writeFindingLines(&b, result, func(f registry.Finding) string {
+			if f.Description != "" {
+				return f.Value + " [" + f.Description + "]"
+			}
+			return f.Value
+		}, "No DNS records found")
+	case "ptr":
+		b.WriteString("Hostnames from PTR Records:\n")
+		writeFindingLines(&b, result, func(f registry.Finding) string {
+			return f.Value + " [" + f.Description + "]"
+		}, "No in-scope PTR names found")
+	case "mail":
+		b.WriteString("Mail Security:\n")
+		writeFindingLines(&b, result, func(f registry.Finding) string {
+			line := fmt.Sprintf("[%s] %s %s: %s", f.Severity, f.Type, f.Value, f.Description)
+			for _, evidence := range f.Evidence {
+				line += "\n  - " + evidence
+			}
+			return line
+		}, "No mail records found")
+	case "takeover":
+		b.WriteString("Takeover Candidates:\n")
+		writeFindingLines(&b, result, func(f registry.Finding) string {
+			line := fmt.Sprintf("[%s] %s: %s", f.Severity, f.Value, f.Description)
+			for _, evidence := range f.Evidence {
+				line += "\n  - " + evidence
+			}
+			return line
+		}, "No takeover candidates found")
 	case "ports":
 		b.WriteString("Open Ports:\n")
 		writeFindingLines(&b, result, func(f registry.Finding) string {
@@ -269,6 +458,15 @@ func moduleWorkspaceContent(result *registry.Result, formatted string) string {
 			}
 			return line
 		}, "No open ports found")
+	case "tls":
+		b.WriteString("TLS Services:\n")
+		writeFindingLines(&b, result, func(f registry.Finding) string {
+			line := fmt.Sprintf("[%s] %s %s: %s", f.Severity, f.Type, f.Value, f.Description)
+			for _, evidence := range f.Evidence {
+				line += "\n  - " + evidence
+			}
+			return line
+		}, "No TLS services found")
 	case "fuzz":
 		b.WriteString("Directory Findings:\n")
 		writeFindingLines(&b, result, func(f registry.Finding) string {
@@ -282,7 +480,6 @@ func moduleWorkspaceContent(result *registry.Result, formatted string) string {
 	case "waf":
 		if len(result.Findings) == 0 {
 			b.WriteString("No WAF detected\n")
-			return b.String()
 		}
 		for _, finding := range result.Findings {
 			fmt.Fprintf(&b, "WAF Detected: %s\n", finding.Value)
@@ -297,6 +494,19 @@ func moduleWorkspaceContent(result *registry.Result, formatted string) string {
 				}
 			}
 		}
+	case "origin":
+		b.WriteString("Origin Candidates:\n")
+		if reason, _ := result.Metadata["skipped"].(string); reason != "" {
+			fmt.Fprintf(&b, "Skipped: %s\n", reason)
+			break
+		}
+		writeFindingLines(&b, result, func(f registry.Finding) string {
+			line := fmt.Sprintf("[%s] %s %s: %s", f.Severity, f.Type, f.Value, f.Description)
+			for _, evidence := range f.Evidence {
+				line += "\n  - " + evidence
+			}
+			return line
+		}, "No origin candidates found")
 	case "http":
 		b.WriteString("HTTP Probe Results:\n")
 		writeFindingLines(&b, result, httpProbeLine, "No HTTP responses received")
@@ -310,11 +520,14 @@ func moduleWorkspaceContent(result *registry.Result, formatted string) string {
 		writeCrawlContent(&b, result)
 	default:
 		if formatted != "" {
+			// The formatter already renders the errors section.
 			b.WriteString(formatted)
+			return b.String()
 		} else {
 			writeFindingLines(&b, result, func(f registry.Finding) string { return f.Value }, "No findings")
 		}
 	}
+	writeErrorSection(&b, result)
 	return b.String()
 }
 
@@ -329,6 +542,9 @@ func writeReportHeader(b *strings.Builder, result *registry.Result) {
 		fmt.Fprintf(b, "Duration: %.2fs\n", result.Duration)
 	}
 	fmt.Fprintf(b, "Findings: %d\n", len(result.Findings))
+	if len(result.Errors) > 0 {
+		fmt.Fprintf(b, "Errors: %d (%s)\n", len(result.Errors), formatErrorCounts(result.ErrorCounts))
+	}
 	if len(result.Metadata) > 0 {
 		b.WriteString("Metadata:\n")
 		for _, key := range sortedMetadataKeys(result.Metadata) {
@@ -338,6 +554,31 @@ func writeReportHeader(b *strings.Builder, result *registry.Result) {
 	b.WriteString("\n")
 }
 
+// writeErrorSection lists module failures with their counts by type.
+func writeErrorSection(b *strings.Builder, result *registry.Result) {
+	if len(result.Errors) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\nErrors (%s):\n", formatErrorCounts(result.ErrorCounts))
+	for _, msg := range result.Errors {
+		fmt.Fprintf(b, "- %s\n", msg)
+	}
+}
+
+// formatErrorCounts renders counts by type as "network=2, timeout=1".
+func formatErrorCounts(counts map[string]int) string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%d", key, counts[key]))
+	}
+	return strings.Join(parts, ", ")
+}
+
 func writeFindingLines(b *strings.Builder, result *registry.Result, line func(registry.Finding) string, empty string) {
 	if len(result.Findings) == 0 {
 		fmt.Fprintf(b, "%s\n", empty)
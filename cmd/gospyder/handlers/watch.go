@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/app"
+	"github.com/NASHEDIxCODER/gospyder/internal/workspace"
+	"github.com/NASHEDIxCODER/gospyder/pkg/models"
+	"github.com/NASHEDIxCODER/gospyder/pkg/sources"
+)
+
+// watchFile is the workspace file the watch command appends to.
+const watchFile = "certstream.txt"
+
+// HandleWatch follows CertStream for a domain until interrupted, appending
+// each new subdomain to the workspace as it arrives. It is not bound by
+// the module timeout.
+func HandleWatch(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: gospyder watch <domain> [options]")
+	}
+
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	url := fs.String("url", "", "CertStream websocket URL (default: "+sources.CertStreamURL+")")
+	replayFile := fs.String("stream-replay", "", "replay recorded CertStream messages from this file instead of connecting")
+	recordFile := fs.String("stream-record", "", "append received CertStream messages to this file")
+	saveWorkspace := fs.Bool("workspace", true, "append results to the workspace")
+	globalOpts := addGlobalFlags(fs)
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	target := strings.ToLower(strings.TrimSuffix(args[0], "."))
+	flags := map[string]interface{}{"target": target, "workspace": *saveWorkspace}
+	if err := applyGlobalFlags(globalOpts, flags); err != nil {
+		return err
+	}
+
+	ctx := app.Global()
+	client := &sources.CertStreamClient{
+		URL:    *url,
+		Replay: *replayFile,
+		OnDisconnect: func(err error, retry time.Duration) {
+			ctx.Logger.Warn("CertStream disconnected: %v; reconnecting in %s", err, retry)
+		},
+	}
+	if client.URL == "" {
+		client.URL = ctx.Config.Sources.Settings["certstream"].BaseURL
+	}
+	if *recordFile != "" {
+		f, err := os.OpenFile(*recordFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("open stream record file: %w", err)
+		}
+		defer f.Close()
+		client.Record = f
+	}
+
+	var ws *workspace.Workspace
+	if workspaceEnabled(flags) {
+		ws = workspaceForTarget(target)
+		fmt.Printf("Appending to:\n%s%s\n\n", displayWorkspacePath(ws.Path), watchFile)
+	}
+
+	runCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx.Logger.Info("Watching CertStream for %s; press Ctrl+C to stop", target)
+	found, err := watchCertStream(runCtx, client, target, ws)
+	ctx.Logger.Info("Stopped watching after %d new subdomain(s)", found)
+	return err
+}
+
+// watchCertStream prints and appends names from client that ws does not
+// already hold, until ctx ends or a replay is exhausted. It returns how
+// many new names were found.
+func watchCertStream(ctx context.Context, client *sources.CertStreamClient, target string, ws *workspace.Workspace) (int, error) {
+	seen := map[string]bool{}
+	if ws != nil {
+		if data, err := os.ReadFile(filepath.Join(ws.Path, watchFile)); err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				seen[strings.TrimSpace(line)] = true
+			}
+		}
+	}
+
+	domains := make(chan models.Domain, 100)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- client.Watch(ctx, target, domains)
+		close(domains)
+	}()
+
+	found := 0
+	for d := range domains {
+		if seen[d.Name] {
+			continue
+		}
+		seen[d.Name] = true
+		found++
+		fmt.Println(d.Name)
+		if ws != nil {
+			if _, err := ws.AppendResult("certstream", watchFile, d.Name); err != nil {
+				return found, err
+			}
+		}
+	}
+	if err := <-errc; err != nil && ctx.Err() == nil {
+		return found, err
+	}
+	return found, nil
+}
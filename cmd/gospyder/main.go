@@ -12,8 +12,14 @@ import (
 	"github.com/NASHEDIxCODER/gospyder/internal/registry"
 	jsModule "github.com/NASHEDIxCODER/gospyder/pkg/js"
 	enumModule "github.com/NASHEDIxCODER/gospyder/pkg/enum"
+	dnsModule "github.com/NASHEDIxCODER/gospyder/pkg/dns"
 	scannerModule "github.com/NASHEDIxCODER/gospyder/pkg/scanner"
 	crawlModule "github.com/NASHEDIxCODER/gospyder/pkg/crawl"
+	takeoverModule "github.com/NASHEDIxCODER/gospyder/pkg/takeover"
+	ptrModule "github.com/NASHEDIxCODER/gospyder/pkg/ptr"
+	mailModule "github.com/NASHEDIxCODER/gospyder/pkg/mail"
+	originModule "github.com/NASHEDIxCODER/gospyder/pkg/origin"
+	tlsModule "github.com/NASHEDIxCODER/gospyder/pkg/tlsscan"
 )
 
 const (
@@ -87,12 +93,24 @@ func main() {
 	switch command {
 	case "enum":
 		execErr = handlers.HandleEnum(args)
+	case "dns":
+		execErr = handlers.HandleDNS(args)
+	case "ptr":
+		execErr = handlers.HandlePTR(args)
+	case "mail":
+		execErr = handlers.HandleMail(args)
+	case "takeover":
+		execErr = handlers.HandleTakeover(args)
 	case "ports":
 		execErr = handlers.HandlePorts(args)
+	case "tls":
+		execErr = handlers.HandleTLS(args)
 	case "fuzz":
 		execErr = handlers.HandleFuzz(args)
 	case "waf":
 		execErr = handlers.HandleWAF(args)
+	case "origin":
+		execErr = handlers.HandleOrigin(args)
 	case "http":
 		execErr = handlers.HandleHTTP(args)
 	case "live":
@@ -105,6 +123,8 @@ func main() {
 		execErr = handlers.HandleJS(args)
 	case "recon":
 		execErr = handlers.HandleRecon(args)
+	case "watch":
+		execErr = handlers.HandleWatch(args)
 	case "list":
 		execErr = handlers.HandleList()
 	case "help":
@@ -135,9 +155,15 @@ func registerModules(appCtx *app.AppContext) {
 		module interface{ Name() string }
 	}{
 		{"enum", enumModule.NewModule()},
+		{"dns", dnsModule.NewModule()},
+		{"ptr", ptrModule.NewModule()},
+		{"mail", mailModule.NewModule()},
+		{"takeover", takeoverModule.NewModule()},
 		{"ports", scannerModule.NewPortScanModule()},
+		{"tls", tlsModule.NewModule()},
 		{"fuzz", scannerModule.NewFuzzerModule()},
 		{"waf", scannerModule.NewWAFModule()},
+		{"origin", originModule.NewModule()},
 		{"http", scannerModule.NewHTTPProbeModule()},
 		{"live", scannerModule.NewLiveHostModule()},
 		{"tech", scannerModule.NewTechModule()},
@@ -1,14 +1,21 @@
 package app
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/NASHEDIxCODER/gospyder/internal/config"
 	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/internal/intel"
 	"github.com/NASHEDIxCODER/gospyder/internal/logger"
 	"github.com/NASHEDIxCODER/gospyder/internal/output"
+	"github.com/NASHEDIxCODER/gospyder/internal/progress"
 	"github.com/NASHEDIxCODER/gospyder/internal/registry"
+	"github.com/NASHEDIxCODER/gospyder/internal/replay"
+	"github.com/NASHEDIxCODER/gospyder/internal/telemetry"
 	"github.com/NASHEDIxCODER/gospyder/internal/workspace"
 )
 
@@ -26,6 +33,10 @@ type AppContext struct {
 	Registry   *registry.Registry
 	HTTPClient *http.Client
 	Errors     *errors.Collector
+	Progress   *progress.Display
+	Telemetry  *telemetry.Telemetry // nil when metrics and tracing are disabled
+	Replay     *replay.Session      // nil unless recording or replaying
+	Intel      *intel.Database      // nil unless IP data files are configured
 }
 
 // Initialize creates and stores global application context
@@ -34,6 +45,9 @@ func Initialize(cfg *config.Config) error {
 	defer contextMutex.Unlock()
 
 	logger := logger.New(cfg.Verbose)
+	if err := logger.Configure(logOptions(cfg.Log)); err != nil {
+		return err
+	}
 	formatter := output.New(cfg.Output.Format, cfg.Output.Colors, cfg.Output.Pretty)
 	errCollector := errors.NewCollector()
 	reg := registry.New()
@@ -43,6 +57,21 @@ func Initialize(cfg *config.Config) error {
 		Timeout: cfg.HTTP.Timeout,
 	}
 
+	tel, err := telemetry.New(telemetryConfig(cfg.Telemetry, logger))
+	if err != nil {
+		return err
+	}
+
+	rec, err := replaySession(cfg.Replay)
+	if err != nil {
+		return err
+	}
+
+	ipData, err := intel.Open(cfg.Intel)
+	if err != nil {
+		return err
+	}
+
 	globalContext = &AppContext{
 		Config:     cfg,
 		Logger:     logger,
@@ -51,12 +80,113 @@ func Initialize(cfg *config.Config) error {
 		Registry:   reg,
 		HTTPClient: httpClient,
 		Errors:     errCollector,
+		Progress:   progress.NewStderr(cfg.Progress.Interval),
+		Telemetry:  tel,
+		Replay:     rec,
+		Intel:      ipData,
 	}
 
 	logger.Debug("Application context initialized")
 	return nil
 }
 
+// ConfigureLogger reapplies the logging section of the configuration, e.g.
+// after CLI flags changed it.
+func (c *AppContext) ConfigureLogger() error {
+	return c.Logger.Configure(logOptions(c.Config.Log))
+}
+
+func logOptions(cfg config.LogConfig) logger.Options {
+	return logger.Options{
+		Level:   cfg.Level,
+		Format:  cfg.Format,
+		File:    cfg.File,
+		Modules: cfg.Modules,
+	}
+}
+
+// ConfigureTelemetry restarts metrics and tracing from the telemetry section
+// of the configuration, e.g. after CLI flags changed it.
+func (c *AppContext) ConfigureTelemetry() error {
+	shutdownTelemetry(c.Telemetry)
+	tel, err := telemetry.New(telemetryConfig(c.Config.Telemetry, c.Logger))
+	if err != nil {
+		c.Telemetry = nil
+		return err
+	}
+	c.Telemetry = tel
+	if addr := tel.MetricsAddr(); addr != "" {
+		c.Logger.Info("Serving metrics on http://%s/metrics", addr)
+	}
+	return nil
+}
+
+func telemetryConfig(cfg config.TelemetryConfig, log *logger.Logger) telemetry.Config {
+	return telemetry.Config{
+		MetricsAddr:  cfg.MetricsAddr,
+		OTLPEndpoint: cfg.OTLPEndpoint,
+		ServiceName:  cfg.ServiceName,
+		RunID:        log.RunID(),
+		OnError: func(err error) {
+			log.Warn("Telemetry: %v", err)
+		},
+	}
+}
+
+func shutdownTelemetry(tel *telemetry.Telemetry) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	tel.Shutdown(ctx)
+}
+
+// ConfigureReplay opens the record or replay session named in the replay
+// section of the configuration, e.g. after CLI flags changed it.
+func (c *AppContext) ConfigureReplay() error {
+	rec, err := replaySession(c.Config.Replay)
+	if err != nil {
+		return err
+	}
+	c.Replay = rec
+	switch rec.Mode() {
+	case replay.ModeRecord:
+		c.Logger.Info("Recording network interactions to %s", rec.Path())
+	case replay.ModeReplay:
+		c.Logger.Info("Replaying network interactions from %s", rec.Path())
+	}
+	return nil
+}
+
+// ConfigureIntel loads the IP data files named in the intel section of the
+// configuration, e.g. after CLI flags changed it.
+func (c *AppContext) ConfigureIntel() error {
+	db, err := intel.Open(c.Config.Intel)
+	if err != nil {
+		return err
+	}
+	c.Intel = db
+	if db != nil {
+		c.Logger.Info("Loaded IP intelligence: %s", db.Summary())
+	}
+	return nil
+}
+
+// SaveReplay writes the recording, if any, to its fixture file.
+func (c *AppContext) SaveReplay() error {
+	return c.Replay.Save()
+}
+
+func replaySession(cfg config.ReplayConfig) (*replay.Session, error) {
+	switch {
+	case cfg.Record != "" && cfg.Replay != "":
+		return nil, fmt.Errorf("cannot record and replay in the same run")
+	case cfg.Record != "":
+		return replay.NewRecorder(cfg.Record), nil
+	case cfg.Replay != "":
+		return replay.Load(cfg.Replay)
+	}
+	return nil, nil
+}
+
 // Global returns the global application context
 // Panics if context not initialized
 func Global() *AppContext {
@@ -76,11 +206,17 @@ func Cleanup() {
 	defer contextMutex.Unlock()
 
 	if globalContext != nil {
+		globalContext.Progress.Stop()
+		shutdownTelemetry(globalContext.Telemetry)
+		if err := globalContext.SaveReplay(); err != nil && globalContext.Logger != nil {
+			globalContext.Logger.Warn("Replay: %v", err)
+		}
 		if globalContext.HTTPClient != nil {
 			globalContext.HTTPClient.CloseIdleConnections()
 		}
 		if globalContext.Logger != nil {
 			globalContext.Logger.Debug("Application cleanup complete")
+			globalContext.Logger.Close()
 		}
 	}
 }
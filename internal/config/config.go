@@ -14,11 +14,25 @@ type Config struct {
 	HTTP HTTPConfig
 
 	// Module settings
+	DNS     DNSConfig
+	Sources SourcesConfig
 	Scanner ScannerConfig
+	TLS     TLSConfig
 	Crawler CrawlerConfig
 
 	// Output settings
-	Output OutputConfig
+	Output   OutputConfig
+	Progress ProgressConfig
+	Log      LogConfig
+
+	// Observability settings
+	Telemetry TelemetryConfig
+
+	// Record/replay settings
+	Replay ReplayConfig
+
+	// IP enrichment settings
+	Intel IntelConfig
 
 	// Workspace settings
 	Workspace WorkspaceConfig
@@ -31,10 +45,41 @@ type HTTPConfig struct {
 	MaxRedirects   int
 }
 
+type DNSConfig struct {
+	Resolvers     []string // "ip", "ip:port" or udp/tcp/tls/https URLs; empty uses public resolvers
+	ResolversFile string   // file with one resolver per line; overrides Resolvers
+}
+
+type SourcesConfig struct {
+	Enabled  []string                // passive sources to run; empty runs the default set
+	Import   string                  // subdomain list (lines, CSV or JSON) for the file source; "-" reads stdin
+	Settings map[string]SourceConfig // per-source settings keyed by source name
+}
+
+type SourceConfig struct {
+	BaseURL   string        // service URL; empty uses the public service
+	APIKey    string        // empty falls back to $GOSPYDER_<NAME>_KEY
+	RateLimit time.Duration // minimum delay between requests; zero disables
+	Replay    string        // certstream: recorded messages to replay instead of connecting
+}
+
 type ScannerConfig struct {
-	DefaultPorts []int
-	PathWordlist string
-	PortTimeout  time.Duration
+	DefaultPorts    []int
+	DefaultUDPPorts []int
+	RandomizePorts  bool   // scan ports in random order rather than as listed
+	DeadHostPorts   int    // unanswered ports after which a silent host is skipped; 0 never
+	UDPRate         int    // UDP probes per second; 0 is unlimited
+	SYNRate         int    // SYN scan packets per second; 0 is unlimited
+	ServiceProbes   string // extra probes in nmap-service-probes syntax
+	ProbeIntensity  int    // highest probe rarity sent to unlisted ports, 0-9
+	PathWordlist    string
+	PortTimeout     time.Duration
+}
+
+type TLSConfig struct {
+	Ports         []int         // ports inspected when no ports scan ran first
+	Ciphers       bool          // enumerate cipher suites, not only versions
+	ExpiryWarning time.Duration // report certificates expiring sooner than this
 }
 
 type CrawlerConfig struct {
@@ -48,6 +93,35 @@ type OutputConfig struct {
 	Pretty bool
 }
 
+type ProgressConfig struct {
+	Enabled  bool
+	Interval time.Duration // status line interval when stderr is not a TTY
+}
+
+type LogConfig struct {
+	Level   string            // debug, info, warn, error
+	Format  string            // console, text, json
+	File    string            // optional log file receiving a copy of every record
+	Modules map[string]string // per-module level overrides
+}
+
+type TelemetryConfig struct {
+	MetricsAddr  string // Prometheus /metrics listen address; empty disables
+	OTLPEndpoint string // OTLP/HTTP trace collector URL; empty disables
+	ServiceName  string
+}
+
+type ReplayConfig struct {
+	Record string // fixture file to record network interactions into
+	Replay string // fixture file to answer network interactions from
+}
+
+type IntelConfig struct {
+	ASNDatabases []string // MMDB files with ASN, organization or country data
+	Ranges       []string // cloud/CDN range files; "provider=path" names a plain CIDR list
+	OwnASNs      []uint32 // the client's own ASNs, flagged as owned
+}
+
 type WorkspaceConfig struct {
 	Enabled bool
 	Path    string
@@ -67,9 +141,20 @@ func DefaultConfig() *Config {
 			MaxRedirects:   10,
 		},
 		Scanner: ScannerConfig{
-			DefaultPorts: []int{22, 80, 443, 8080, 8443, 3000, 5000, 9000},
-			PathWordlist: "wordlists/paths.txt",
-			PortTimeout:  3 * time.Second,
+			DefaultPorts:    []int{22, 80, 443, 8080, 8443, 3000, 5000, 9000},
+			DefaultUDPPorts: []int{53, 69, 123, 161, 500, 1900, 5353, 11211},
+			RandomizePorts:  true,
+			DeadHostPorts:   50,
+			UDPRate:         100,
+			SYNRate:         1000,
+			ProbeIntensity:  7,
+			PathWordlist:    "wordlists/paths.txt",
+			PortTimeout:     3 * time.Second,
+		},
+		TLS: TLSConfig{
+			Ports:         []int{443, 8443},
+			Ciphers:       true,
+			ExpiryWarning: 30 * 24 * time.Hour,
 		},
 		Crawler: CrawlerConfig{
 			MaxDepth:    3,
@@ -80,6 +165,18 @@ func DefaultConfig() *Config {
 			Colors: true,
 			Pretty: true,
 		},
+		Progress: ProgressConfig{
+			Enabled:  true,
+			Interval: 10 * time.Second,
+		},
+		Log: LogConfig{
+			Level:   "info",
+			Format:  "console",
+			Modules: map[string]string{},
+		},
+		Telemetry: TelemetryConfig{
+			ServiceName: "gospyder",
+		},
 		Workspace: WorkspaceConfig{
 			Enabled: true,
 			Path:    "./reports",
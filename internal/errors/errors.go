@@ -1,7 +1,13 @@
 package errors
 
 import (
+	"context"
+	stderrors "errors"
 	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"sort"
 	"sync"
 )
 
@@ -18,6 +24,26 @@ const (
 	ErrorTypeUnknown
 )
 
+// String returns the lower-case name used in reports and result metadata.
+func (t ErrorType) String() string {
+	switch t {
+	case ErrorTypeNetwork:
+		return "network"
+	case ErrorTypeTimeout:
+		return "timeout"
+	case ErrorTypeValidation:
+		return "validation"
+	case ErrorTypeConfig:
+		return "config"
+	case ErrorTypeIO:
+		return "io"
+	case ErrorTypeNotFound:
+		return "not_found"
+	default:
+		return "unknown"
+	}
+}
+
 // CustomError represents an application error
 type CustomError struct {
 	Type    ErrorType
@@ -33,6 +59,44 @@ func (e *CustomError) Error() string {
 	return e.Message
 }
 
+// Unwrap returns the underlying cause
+func (e *CustomError) Unwrap() error {
+	return e.Cause
+}
+
+// TypeOf classifies err. CustomErrors keep their type; other errors are
+// mapped from their cause (DNS not-found, timeouts, file system, network).
+func TypeOf(err error) ErrorType {
+	var custom *CustomError
+	if stderrors.As(err, &custom) {
+		return custom.Type
+	}
+	var dnsErr *net.DNSError
+	if stderrors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return ErrorTypeNotFound
+	}
+	if stderrors.Is(err, context.DeadlineExceeded) || stderrors.Is(err, os.ErrDeadlineExceeded) {
+		return ErrorTypeTimeout
+	}
+	var netErr net.Error
+	if stderrors.As(err, &netErr) && netErr.Timeout() {
+		return ErrorTypeTimeout
+	}
+	var pathErr *fs.PathError
+	if stderrors.As(err, &pathErr) {
+		return ErrorTypeIO
+	}
+	if netErr != nil {
+		return ErrorTypeNetwork
+	}
+	return ErrorTypeUnknown
+}
+
+// Wrap creates an error whose type is derived from cause
+func Wrap(msg string, cause error) *CustomError {
+	return &CustomError{Type: TypeOf(cause), Message: msg, Cause: cause}
+}
+
 // NewNetworkError creates a network error
 func NewNetworkError(msg string, cause error) *CustomError {
 	return &CustomError{Type: ErrorTypeNetwork, Message: msg, Cause: cause}
@@ -76,9 +140,9 @@ func NewCollector() *Collector {
 	}
 }
 
-// Add adds an error to the collection
+// Add adds an error to the collection. Adding to a nil collector is a no-op.
 func (c *Collector) Add(err error) {
-	if err != nil {
+	if c != nil && err != nil {
 		c.mu.Lock()
 		defer c.mu.Unlock()
 		c.errors = append(c.errors, err)
@@ -87,6 +151,9 @@ func (c *Collector) Add(err error) {
 
 // HasErrors checks if there are any errors
 func (c *Collector) HasErrors() bool {
+	if c == nil {
+		return false
+	}
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	return len(c.errors) > 0
@@ -94,6 +161,9 @@ func (c *Collector) HasErrors() bool {
 
 // Errors returns all collected errors
 func (c *Collector) Errors() []error {
+	if c == nil {
+		return nil
+	}
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -105,6 +175,9 @@ func (c *Collector) Errors() []error {
 
 // Count returns the number of collected errors
 func (c *Collector) Count() int {
+	if c == nil {
+		return 0
+	}
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	return len(c.errors)
@@ -116,3 +189,81 @@ func (c *Collector) Clear() {
 	defer c.mu.Unlock()
 	c.errors = make([]error, 0)
 }
+
+// CountByType returns the number of collected errors per type name
+func (c *Collector) CountByType() map[string]int {
+	return CountByType(c.Errors())
+}
+
+// CountByType groups errs by their type name
+func CountByType(errs []error) map[string]int {
+	counts := map[string]int{}
+	for _, err := range errs {
+		counts[TypeOf(err).String()]++
+	}
+	return counts
+}
+
+// Tally counts repeated failures of one kind of operation by error type, so
+// that thousands of failed lookups are reported as one error per type.
+type Tally struct {
+	what    string
+	mu      sync.Mutex
+	counts  map[ErrorType]int
+	samples map[ErrorType]error
+}
+
+// NewTally creates a tally for the named operation, e.g. "DNS lookups"
+func NewTally(what string) *Tally {
+	return &Tally{
+		what:    what,
+		counts:  map[ErrorType]int{},
+		samples: map[ErrorType]error{},
+	}
+}
+
+// Add records a failure. Nil errors and nil tallies are ignored.
+func (t *Tally) Add(err error) {
+	if t == nil || err == nil {
+		return
+	}
+	errType := TypeOf(err)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.counts[errType]++
+	if _, ok := t.samples[errType]; !ok {
+		t.samples[errType] = err
+	}
+}
+
+// Errors returns one summary error per type, with the first failure of that
+// type as the cause
+func (t *Tally) Errors() []error {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	types := make([]ErrorType, 0, len(t.counts))
+	for errType := range t.counts {
+		types = append(types, errType)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+
+	errs := make([]error, 0, len(types))
+	for _, errType := range types {
+		errs = append(errs, &CustomError{
+			Type:    errType,
+			Message: fmt.Sprintf("%s: %d failed", t.what, t.counts[errType]),
+			Cause:   t.samples[errType],
+		})
+	}
+	return errs
+}
+
+// FlushTo adds the summary errors to c
+func (t *Tally) FlushTo(c *Collector) {
+	for _, err := range t.Errors() {
+		c.Add(err)
+	}
+}
@@ -0,0 +1,62 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestTypeOfClassifiesCauses(t *testing.T) {
+	tests := []struct {
+		err  error
+		want ErrorType
+	}{
+		{NewValidationError("bad port"), ErrorTypeValidation},
+		{fmt.Errorf("lookup: %w", &net.DNSError{Err: "no such host", IsNotFound: true}), ErrorTypeNotFound},
+		{fmt.Errorf("lookup: %w", &net.DNSError{Err: "i/o timeout", IsTimeout: true}), ErrorTypeTimeout},
+		{context.DeadlineExceeded, ErrorTypeTimeout},
+		{&os.PathError{Op: "open", Path: "words.txt", Err: os.ErrNotExist}, ErrorTypeIO},
+		{&net.OpError{Op: "dial", Net: "tcp", Err: fmt.Errorf("connection refused")}, ErrorTypeNetwork},
+		{fmt.Errorf("something else"), ErrorTypeUnknown},
+	}
+	for _, tt := range tests {
+		if got := TypeOf(tt.err); got != tt.want {
+			t.Errorf("TypeOf(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestTallySummarizesByType(t *testing.T) {
+	tally := NewTally("DNS lookups")
+	for i := 0; i < 3; i++ {
+		tally.Add(context.DeadlineExceeded)
+	}
+	tally.Add(&net.OpError{Op: "read", Net: "udp", Err: fmt.Errorf("connection refused")})
+	tally.Add(nil)
+
+	collector := NewCollector()
+	tally.FlushTo(collector)
+
+	errs := collector.Errors()
+	if len(errs) != 2 {
+		t.Fatalf("FlushTo() added %d errors, want 2: %v", len(errs), errs)
+	}
+	if !strings.HasPrefix(errs[0].Error(), "DNS lookups: 1 failed") || TypeOf(errs[0]) != ErrorTypeNetwork {
+		t.Fatalf("first summary = %v (%s), want network summary", errs[0], TypeOf(errs[0]))
+	}
+	if !strings.HasPrefix(errs[1].Error(), "DNS lookups: 3 failed") || TypeOf(errs[1]) != ErrorTypeTimeout {
+		t.Fatalf("second summary = %v (%s), want timeout summary", errs[1], TypeOf(errs[1]))
+	}
+	if counts := collector.CountByType(); counts["timeout"] != 1 || counts["network"] != 1 {
+		t.Fatalf("CountByType() = %v", counts)
+	}
+
+	var nilCollector *Collector
+	nilCollector.Add(context.Canceled)
+	if nilCollector.HasErrors() {
+		t.Fatal("nil collector reports errors")
+	}
+}
@@ -0,0 +1,175 @@
+// Package intel maps IP addresses to their ASN, organization, country and
+// cloud or CDN provider using offline data files.
+package intel
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/config"
+)
+
+// Info is what the data files know about one address.
+type Info struct {
+	IP       string `json:"ip"`
+	ASN      uint32 `json:"asn,omitempty"`
+	Org      string `json:"org,omitempty"`
+	Country  string `json:"country,omitempty"`
+	Provider string `json:"provider,omitempty"`
+	Service  string `json:"service,omitempty"`
+	Region   string `json:"region,omitempty"`
+	CDN      bool   `json:"cdn,omitempty"`
+	// Owned reports that the ASN is one of the configured own ASNs.
+	Owned bool `json:"owned,omitempty"`
+}
+
+// Database answers address lookups from MMDB files and cloud range
+// files. A nil *Database knows nothing, so callers need not check.
+type Database struct {
+	mmdbs  []*MMDB
+	ranges *Ranges
+	own    map[uint32]bool
+}
+
+// Open loads the files named in cfg. It returns nil when none are
+// configured.
+func Open(cfg config.IntelConfig) (*Database, error) {
+	if len(cfg.ASNDatabases) == 0 && len(cfg.Ranges) == 0 {
+		return nil, nil
+	}
+	db := &Database{ranges: NewRanges(), own: map[uint32]bool{}}
+	for _, path := range cfg.ASNDatabases {
+		mmdb, err := OpenMMDB(path)
+		if err != nil {
+			return nil, err
+		}
+		db.mmdbs = append(db.mmdbs, mmdb)
+	}
+	for _, spec := range cfg.Ranges {
+		if err := db.ranges.Load(spec); err != nil {
+			return nil, err
+		}
+	}
+	for _, asn := range cfg.OwnASNs {
+		db.own[asn] = true
+	}
+	return db, nil
+}
+
+// Summary describes what was loaded, for logging.
+func (d *Database) Summary() string {
+	if d == nil {
+		return "no IP data loaded"
+	}
+	return fmt.Sprintf("%d MMDB database(s), %d cloud/CDN prefixes", len(d.mmdbs), d.ranges.Len())
+}
+
+// Lookup returns what is known about ip. ok is false when ip is invalid
+// or no data file covers it.
+func (d *Database) Lookup(ip string) (info Info, ok bool) {
+	if d == nil {
+		return Info{}, false
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return Info{}, false
+	}
+	info.IP = addr.Unmap().String()
+	for _, mmdb := range d.mmdbs {
+		// A broken record in one database should not hide the others.
+		record, err := mmdb.Lookup(addr)
+		if err == nil && record != nil {
+			mergeRecord(&info, record)
+		}
+	}
+	if owner, found := d.ranges.Lookup(addr); found {
+		info.Provider, info.Service, info.Region, info.CDN = owner.Provider, owner.Service, owner.Region, owner.CDN
+	}
+	info.Owned = info.ASN != 0 && d.own[info.ASN]
+	return info, info != Info{IP: info.IP}
+}
+
+// Annotate adds an "intel" entry to finding metadata that holds addresses
+// under "ip" (a string) or "ips" (a []string): one Info for "ip", a slice
+// for "ips". Addresses no data file covers are left out.
+func (d *Database) Annotate(metadata map[string]interface{}) {
+	if d == nil || metadata == nil {
+		return
+	}
+	if ip, _ := metadata["ip"].(string); ip != "" {
+		if info, ok := d.Lookup(ip); ok {
+			metadata["intel"] = info
+		}
+		return
+	}
+	ips, _ := metadata["ips"].([]string)
+	var infos []Info
+	for _, ip := range ips {
+		if info, ok := d.Lookup(ip); ok {
+			infos = append(infos, info)
+		}
+	}
+	if len(infos) > 0 {
+		metadata["intel"] = infos
+	}
+}
+
+// mergeRecord fills the empty fields of info from an MMDB record. It reads
+// the GeoLite2 and DB-IP layouts (autonomous_system_number, country.iso_code)
+// and the IPinfo one (asn "AS13335", as_name, country).
+func mergeRecord(info *Info, record map[string]interface{}) {
+	if info.ASN == 0 {
+		switch asn := record["autonomous_system_number"].(type) {
+		case uint64:
+			info.ASN = uint32(asn)
+		default:
+			if s, _ := record["asn"].(string); s != "" {
+				n, _ := strconv.ParseUint(strings.TrimPrefix(strings.ToUpper(s), "AS"), 10, 32)
+				info.ASN = uint32(n)
+			}
+		}
+	}
+	if info.Org == "" {
+		for _, key := range []string{"autonomous_system_organization", "as_name", "organization", "name"} {
+			if org, _ := record[key].(string); org != "" {
+				info.Org = org
+				break
+			}
+		}
+	}
+	if info.Country == "" {
+		for _, key := range []string{"country", "registered_country"} {
+			switch country := record[key].(type) {
+			case string:
+				info.Country = country
+			case map[string]interface{}:
+				info.Country, _ = country["iso_code"].(string)
+			}
+			if info.Country != "" {
+				break
+			}
+		}
+		if code, _ := record["country_code"].(string); info.Country == "" {
+			info.Country = code
+		}
+	}
+}
+
+// ParseASNs parses a comma-separated ASN list such as "AS13335,15169".
+func ParseASNs(list string) ([]uint32, error) {
+	var asns []uint32
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(item)), "AS")
+		if item == "" {
+			continue
+		}
+		n, err := strconv.ParseUint(item, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ASN %q", item)
+		}
+		asns = append(asns, uint32(n))
+	}
+	return asns, nil
+}
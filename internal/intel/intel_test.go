@@ -0,0 +1,181 @@
+package intel
+
+import (
+	"encoding/binary"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/config"
+)
+
+func TestDatabaseCombinesMMDBAndRanges(t *testing.T) {
+	dir := t.TempDir()
+	asnDB := filepath.Join(dir, "asn.mmdb")
+	writeMMDB(t, asnDB, map[string]map[string]interface{}{
+		"192.0.2.0/24": {"autonomous_system_number": uint32(64500), "autonomous_system_organization": "Example Corp"},
+		"198.51.100.0/24": {"autonomous_system_number": uint32(13335), "autonomous_system_organization": "CLOUDFLARENET",
+			"country": map[string]interface{}{"iso_code": "US"}},
+		"2001:db8::/32": {"asn": "AS64501", "as_name": "Example v6", "country": "DE"},
+	})
+	aws := filepath.Join(dir, "ip-ranges.json")
+	writeFile(t, aws, `{"syncToken":"1","createDate":"2024-01-01-00-00-00","prefixes":[
+		{"ip_prefix":"203.0.113.0/24","region":"us-east-1","service":"AMAZON"},
+		{"ip_prefix":"203.0.113.0/24","region":"us-east-1","service":"EC2"},
+		{"ip_prefix":"203.0.113.128/25","region":"GLOBAL","service":"CLOUDFRONT"}],
+		"ipv6_prefixes":[{"ipv6_prefix":"2001:db8:aa::/48","region":"eu-west-1","service":"EC2"}]}`)
+	cloudflare := filepath.Join(dir, "ips-v4")
+	writeFile(t, cloudflare, "# Cloudflare\n198.51.100.0/24\n")
+
+	db, err := Open(config.IntelConfig{
+		ASNDatabases: []string{asnDB},
+		Ranges:       []string{aws, "Cloudflare=" + cloudflare},
+		OwnASNs:      []uint32{64500},
+	})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	tests := []struct {
+		ip   string
+		want Info
+	}{
+		{"192.0.2.7", Info{IP: "192.0.2.7", ASN: 64500, Org: "Example Corp", Owned: true}},
+		{"198.51.100.1", Info{IP: "198.51.100.1", ASN: 13335, Org: "CLOUDFLARENET", Country: "US", Provider: "Cloudflare", CDN: true}},
+		{"203.0.113.5", Info{IP: "203.0.113.5", Provider: "AWS", Service: "EC2", Region: "us-east-1"}},
+		{"203.0.113.200", Info{IP: "203.0.113.200", Provider: "AWS", Service: "CLOUDFRONT", Region: "GLOBAL", CDN: true}},
+		{"2001:db8:aa::1", Info{IP: "2001:db8:aa::1", ASN: 64501, Org: "Example v6", Country: "DE", Provider: "AWS", Service: "EC2", Region: "eu-west-1"}},
+	}
+	for _, tt := range tests {
+		got, ok := db.Lookup(tt.ip)
+		if !ok || got != tt.want {
+			t.Errorf("Lookup(%s) = %+v, %v; want %+v", tt.ip, got, ok, tt.want)
+		}
+	}
+	if _, ok := db.Lookup("10.0.0.1"); ok {
+		t.Error("Lookup(10.0.0.1) found data for an uncovered address")
+	}
+
+	metadata := map[string]interface{}{"ips": []string{"10.0.0.1", "198.51.100.1"}}
+	db.Annotate(metadata)
+	if infos, _ := metadata["intel"].([]Info); len(infos) != 1 || infos[0].Provider != "Cloudflare" {
+		t.Errorf("Annotate() intel = %#v", metadata["intel"])
+	}
+}
+
+func TestOpenWithoutFilesReturnsNil(t *testing.T) {
+	db, err := Open(config.IntelConfig{OwnASNs: []uint32{1}})
+	if db != nil || err != nil {
+		t.Fatalf("Open() = %v, %v; want nil", db, err)
+	}
+	if _, ok := db.Lookup("192.0.2.1"); ok {
+		t.Fatal("nil database found data")
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeMMDB writes an IPv6 MMDB with 24-bit records, storing IPv4
+// prefixes under ::/96 as real databases do.
+func writeMMDB(t *testing.T, path string, records map[string]map[string]interface{}) {
+	t.Helper()
+	// Records of the tree: -1 is empty, values <= -2 index data, others
+	// are nodes.
+	nodes := [][2]int{{-1, -1}}
+	var data []byte
+	cidrs := make([]string, 0, len(records))
+	for cidr := range records {
+		cidrs = append(cidrs, cidr)
+	}
+	sort.Strings(cidrs)
+	var offsets []int
+	for i, cidr := range cidrs {
+		prefix := netip.MustParsePrefix(cidr)
+		key, bits := prefix.Addr().As16(), prefix.Bits()
+		if prefix.Addr().Is4() {
+			key = [16]byte{}
+			v4 := prefix.Addr().As4()
+			copy(key[12:], v4[:])
+			bits += 96
+		}
+		offsets = append(offsets, len(data))
+		data = append(data, encodeMMDB(records[cidr])...)
+
+		node := 0
+		for b := 0; b < bits; b++ {
+			bit := int(key[b/8]>>(7-b%8)) & 1
+			if b == bits-1 {
+				nodes[node][bit] = -2 - i
+				break
+			}
+			if nodes[node][bit] < 0 {
+				nodes = append(nodes, [2]int{-1, -1})
+				nodes[node][bit] = len(nodes) - 1
+			}
+			node = nodes[node][bit]
+		}
+	}
+
+	count := len(nodes)
+	var out []byte
+	for _, n := range nodes {
+		for _, r := range n {
+			v := count
+			if r >= 0 {
+				v = r
+			} else if r <= -2 {
+				v = count + 16 + offsets[-2-r]
+			}
+			out = append(out, byte(v>>16), byte(v>>8), byte(v))
+		}
+	}
+	out = append(out, make([]byte, 16)...)
+	out = append(out, data...)
+	out = append(out, metadataMarker...)
+	out = append(out, encodeMMDB(map[string]interface{}{
+		"node_count":    uint32(count),
+		"record_size":   uint32(24),
+		"ip_version":    uint32(6),
+		"database_type": "Test-ASN",
+	})...)
+	if err := os.WriteFile(path, out, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// encodeMMDB encodes maps, strings and uint32s in the MMDB data format.
+func encodeMMDB(v interface{}) []byte {
+	switch v := v.(type) {
+	case string:
+		if len(v) >= 29 {
+			return append([]byte{2<<5 | 29, byte(len(v) - 29)}, v...)
+		}
+		return append([]byte{2<<5 | byte(len(v))}, v...)
+	case uint32:
+		b := binary.BigEndian.AppendUint32(nil, v)
+		for len(b) > 0 && b[0] == 0 {
+			b = b[1:]
+		}
+		return append([]byte{6<<5 | byte(len(b))}, b...)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		out := []byte{7<<5 | byte(len(v))}
+		for _, k := range keys {
+			out = append(out, encodeMMDB(k)...)
+			out = append(out, encodeMMDB(v[k])...)
+		}
+		return out
+	}
+	panic("unsupported MMDB test value")
+}
@@ -0,0 +1,309 @@
+package intel
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"net/netip"
+	"os"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+)
+
+// metadataMarker starts the metadata section at the end of an MMDB file.
+var metadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+// MMDB is a MaxMind DB file, the format of GeoLite2, DB-IP and IPinfo
+// databases, held in memory.
+type MMDB struct {
+	buf        []byte
+	data       []byte // data section, the base of data pointers
+	nodeCount  uint
+	recordSize uint
+	ipVersion  uint
+	// Type is the database_type from the metadata, e.g. GeoLite2-ASN.
+	Type string
+}
+
+// OpenMMDB reads and validates an MMDB file.
+func OpenMMDB(path string) (*MMDB, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.NewIOError("read MMDB "+path, err)
+	}
+	db, err := parseMMDB(buf)
+	if err != nil {
+		return nil, errors.NewConfigError("parse MMDB "+path, err)
+	}
+	return db, nil
+}
+
+func parseMMDB(buf []byte) (*MMDB, error) {
+	at := bytes.LastIndex(buf, metadataMarker)
+	if at < 0 {
+		return nil, fmt.Errorf("no metadata section")
+	}
+	meta := buf[at+len(metadataMarker):]
+	value, _, err := (&decoder{buf: meta}).decode(0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("metadata: %w", err)
+	}
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("metadata is not a map")
+	}
+	db := &MMDB{buf: buf}
+	db.nodeCount = uint(asUint(fields["node_count"]))
+	db.recordSize = uint(asUint(fields["record_size"]))
+	db.ipVersion = uint(asUint(fields["ip_version"]))
+	db.Type, _ = fields["database_type"].(string)
+	switch db.recordSize {
+	case 24, 28, 32:
+	default:
+		return nil, fmt.Errorf("unsupported record size %d", db.recordSize)
+	}
+	if db.ipVersion != 4 && db.ipVersion != 6 {
+		return nil, fmt.Errorf("unsupported IP version %d", db.ipVersion)
+	}
+	treeSize := db.nodeCount * db.recordSize / 4
+	if treeSize+16 > uint(at) {
+		return nil, fmt.Errorf("search tree of %d nodes exceeds the file", db.nodeCount)
+	}
+	db.data = buf[treeSize+16 : at]
+	return db, nil
+}
+
+// Lookup returns the record for addr, or nil when the database has none.
+func (db *MMDB) Lookup(addr netip.Addr) (map[string]interface{}, error) {
+	addr = addr.Unmap()
+	var key []byte
+	switch {
+	case db.ipVersion == 6:
+		// IPv4 addresses live under ::/96 in IPv6 databases.
+		a := addr.As16()
+		if addr.Is4() {
+			a = [16]byte{}
+			v4 := addr.As4()
+			copy(a[12:], v4[:])
+		}
+		key = a[:]
+	case addr.Is4():
+		a := addr.As4()
+		key = a[:]
+	default:
+		return nil, nil
+	}
+
+	node := uint(0)
+	for i := 0; i < len(key)*8 && node < db.nodeCount; i++ {
+		bit := uint(key[i/8]>>(7-i%8)) & 1
+		node = db.record(node, bit)
+	}
+	if node == db.nodeCount {
+		return nil, nil
+	}
+	if node < db.nodeCount {
+		return nil, fmt.Errorf("search tree deeper than the address")
+	}
+	offset := node - db.nodeCount - 16
+	value, _, err := (&decoder{buf: db.data}).decode(offset, 0)
+	if err != nil {
+		return nil, err
+	}
+	record, _ := value.(map[string]interface{})
+	return record, nil
+}
+
+// record returns the left (bit 0) or right (bit 1) record of node.
+func (db *MMDB) record(node, bit uint) uint {
+	switch db.recordSize {
+	case 24:
+		b := db.buf[node*6+bit*3:]
+		return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+	case 28:
+		b := db.buf[node*7:]
+		if bit == 0 {
+			return uint(b[3]&0xf0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+		}
+		return uint(b[3]&0x0f)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6])
+	default:
+		return uint(binary.BigEndian.Uint32(db.buf[node*8+bit*4:]))
+	}
+}
+
+// maxDepth bounds nesting, so a corrupt file cannot recurse forever.
+const maxDepth = 32
+
+// decoder reads the MMDB data section format.
+type decoder struct {
+	buf []byte
+}
+
+// decode returns the value at offset and the offset following it.
+func (d *decoder) decode(offset uint, depth int) (interface{}, uint, error) {
+	if depth > maxDepth {
+		return nil, 0, fmt.Errorf("data nested too deeply")
+	}
+	kind, size, offset, err := d.control(offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	if kind == 1 {
+		// A pointer's target is decoded in place; reading continues after
+		// the pointer itself.
+		value, _, err := d.decode(size, depth+1)
+		return value, offset, err
+	}
+	if kind == 7 {
+		m := make(map[string]interface{}, size)
+		for i := uint(0); i < size; i++ {
+			var key, value interface{}
+			if key, offset, err = d.decode(offset, depth+1); err != nil {
+				return nil, 0, err
+			}
+			if value, offset, err = d.decode(offset, depth+1); err != nil {
+				return nil, 0, err
+			}
+			name, ok := key.(string)
+			if !ok {
+				return nil, 0, fmt.Errorf("map key is %T, not a string", key)
+			}
+			m[name] = value
+		}
+		return m, offset, nil
+	}
+	if kind == 11 {
+		a := make([]interface{}, 0, size)
+		for i := uint(0); i < size; i++ {
+			var value interface{}
+			if value, offset, err = d.decode(offset, depth+1); err != nil {
+				return nil, 0, err
+			}
+			a = append(a, value)
+		}
+		return a, offset, nil
+	}
+	if kind == 14 {
+		return size != 0, offset, nil
+	}
+
+	end := offset + size
+	if end > uint(len(d.buf)) {
+		return nil, 0, fmt.Errorf("value at %d runs past the data section", offset)
+	}
+	b := d.buf[offset:end]
+	switch kind {
+	case 2:
+		return string(b), end, nil
+	case 3:
+		if size != 8 {
+			return nil, 0, fmt.Errorf("double of %d bytes", size)
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), end, nil
+	case 4:
+		return append([]byte(nil), b...), end, nil
+	case 5, 6, 9:
+		if size > 8 {
+			return nil, 0, fmt.Errorf("integer of %d bytes", size)
+		}
+		var n uint64
+		for _, c := range b {
+			n = n<<8 | uint64(c)
+		}
+		return n, end, nil
+	case 8:
+		var n uint32
+		for _, c := range b {
+			n = n<<8 | uint32(c)
+		}
+		return int64(int32(n)), end, nil
+	case 10:
+		return new(big.Int).SetBytes(b), end, nil
+	case 15:
+		if size != 4 {
+			return nil, 0, fmt.Errorf("float of %d bytes", size)
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), end, nil
+	}
+	return nil, 0, fmt.Errorf("unsupported data type %d", kind)
+}
+
+// control reads a control byte and its size bytes, returning the type, the
+// size (or a pointer's target) and the offset of the payload.
+func (d *decoder) control(offset uint) (kind, size, next uint, err error) {
+	read := func(n uint) ([]byte, error) {
+		if offset+n > uint(len(d.buf)) {
+			return nil, fmt.Errorf("truncated data at %d", offset)
+		}
+		b := d.buf[offset : offset+n]
+		offset += n
+		return b, nil
+	}
+	b, err := read(1)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	ctrl := b[0]
+	kind = uint(ctrl >> 5)
+
+	if kind == 1 {
+		n := uint(ctrl>>3)&3 + 1
+		p, err := read(n)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		var v uint
+		for _, c := range p {
+			v = v<<8 | uint(c)
+		}
+		switch n {
+		case 1:
+			v |= uint(ctrl&7) << 8
+		case 2:
+			v = v | uint(ctrl&7)<<16 + 2048
+		case 3:
+			v = v | uint(ctrl&7)<<24 + 526336
+		}
+		return 1, v, offset, nil
+	}
+
+	if kind == 0 {
+		ext, err := read(1)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		kind = 7 + uint(ext[0])
+	}
+	size = uint(ctrl & 0x1f)
+	if size >= 29 {
+		n := size - 28
+		s, err := read(n)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		var v uint
+		for _, c := range s {
+			v = v<<8 | uint(c)
+		}
+		size = []uint{0, 29, 285, 65821}[n] + v
+	}
+	return kind, size, offset, nil
+}
+
+func asUint(v interface{}) uint64 {
+	switch n := v.(type) {
+	case uint64:
+		return n
+	case int64:
+		if n > 0 {
+			return uint64(n)
+		}
+	case float64:
+		if n > 0 {
+			return uint64(n)
+		}
+	}
+	return 0
+}
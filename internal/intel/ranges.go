@@ -0,0 +1,267 @@
+package intel
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+)
+
+// cdnProviders are providers whose ranges front other sites. Keys are
+// lowercase.
+var cdnProviders = map[string]bool{
+	"cloudflare": true, "fastly": true, "akamai": true, "cloudfront": true,
+	"imperva": true, "incapsula": true, "sucuri": true, "stackpath": true,
+	"bunnycdn": true, "edgecast": true, "limelight": true, "cdn77": true,
+}
+
+// Range describes the owner of a published IP range.
+type Range struct {
+	Provider string
+	Service  string
+	Region   string
+	CDN      bool
+}
+
+// Ranges maps addresses to the cloud and CDN ranges containing them,
+// preferring the most specific prefix.
+type Ranges struct {
+	prefixes map[netip.Prefix]Range
+	// bits4 and bits6 are the prefix lengths present per family, longest
+	// first.
+	bits4, bits6 []int
+}
+
+// NewRanges creates an empty range set.
+func NewRanges() *Ranges {
+	return &Ranges{prefixes: map[netip.Prefix]Range{}}
+}
+
+// Len returns the number of distinct prefixes.
+func (r *Ranges) Len() int {
+	return len(r.prefixes)
+}
+
+// Add records prefix as belonging to owner. Where a prefix is listed
+// twice, a specific service replaces a catch-all one such as AWS's
+// AMAZON, and CDN ranges replace others; otherwise the first wins.
+func (r *Ranges) Add(prefix netip.Prefix, owner Range) {
+	prefix = prefix.Masked()
+	if old, ok := r.prefixes[prefix]; ok {
+		if old.Service != "AMAZON" && (old.CDN || !owner.CDN) {
+			return
+		}
+	}
+	r.prefixes[prefix] = owner
+	bits := &r.bits6
+	if prefix.Addr().Is4() {
+		bits = &r.bits4
+	}
+	i := sort.Search(len(*bits), func(i int) bool { return (*bits)[i] <= prefix.Bits() })
+	if i == len(*bits) || (*bits)[i] != prefix.Bits() {
+		*bits = append((*bits)[:i], append([]int{prefix.Bits()}, (*bits)[i:]...)...)
+	}
+}
+
+// Lookup returns the owner of the most specific range containing addr.
+func (r *Ranges) Lookup(addr netip.Addr) (Range, bool) {
+	addr = addr.Unmap()
+	bits := r.bits6
+	if addr.Is4() {
+		bits = r.bits4
+	}
+	for _, n := range bits {
+		prefix, err := addr.Prefix(n)
+		if err != nil {
+			continue
+		}
+		if owner, ok := r.prefixes[prefix]; ok {
+			return owner, true
+		}
+	}
+	return Range{}, false
+}
+
+// rangeEntry covers the prefix objects of the AWS and Google range files.
+type rangeEntry struct {
+	IPPrefix   string `json:"ip_prefix"`
+	IPv6Prefix string `json:"ipv6_prefix"`
+	GoogleIPv4 string `json:"ipv4Prefix"`
+	GoogleIPv6 string `json:"ipv6Prefix"`
+	Service    string `json:"service"`
+	Region     string `json:"region"`
+	Scope      string `json:"scope"`
+}
+
+// rangeFile covers the published formats: AWS ip-ranges.json, Google
+// cloud.json and goog.json, Azure Service Tags, Oracle public_ip_ranges
+// and the Fastly and Cloudflare API lists.
+type rangeFile struct {
+	CreateDate   string       `json:"createDate"`
+	Prefixes     []rangeEntry `json:"prefixes"`
+	IPv6Prefixes []rangeEntry `json:"ipv6_prefixes"`
+	Values       []struct {
+		Name       string `json:"name"`
+		Properties struct {
+			Region          string   `json:"region"`
+			SystemService   string   `json:"systemService"`
+			AddressPrefixes []string `json:"addressPrefixes"`
+		} `json:"properties"`
+	} `json:"values"`
+	Regions []struct {
+		Region string `json:"region"`
+		CIDRs  []struct {
+			CIDR string   `json:"cidr"`
+			Tags []string `json:"tags"`
+		} `json:"cidrs"`
+	} `json:"regions"`
+	Addresses     []string `json:"addresses"`
+	IPv6Addresses []string `json:"ipv6_addresses"`
+	Result        struct {
+		IPv4CIDRs []string `json:"ipv4_cidrs"`
+		IPv6CIDRs []string `json:"ipv6_cidrs"`
+	} `json:"result"`
+}
+
+// Load adds the ranges in a file. spec is a path, or provider=path to
+// name the provider of a plain CIDR list; a plain list without one is
+// named after its file. JSON files are recognized by their layout.
+func (r *Ranges) Load(spec string) error {
+	provider, path, named := strings.Cut(spec, "=")
+	if !named {
+		provider, path = "", spec
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return errors.NewIOError("read IP ranges "+path, err)
+	}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var file rangeFile
+		if err := json.Unmarshal(trimmed, &file); err != nil {
+			return errors.NewConfigError("parse IP ranges "+path, err)
+		}
+		return r.addFile(path, provider, &file)
+	}
+
+	if provider == "" {
+		provider = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	owner := Range{Provider: provider, CDN: cdnProviders[strings.ToLower(provider)]}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		prefix, err := parsePrefix(text)
+		if err != nil {
+			return errors.NewConfigError(fmt.Sprintf("%s:%d", path, line), err)
+		}
+		r.Add(prefix, owner)
+	}
+	return nil
+}
+
+func (r *Ranges) addFile(path, provider string, file *rangeFile) error {
+	named := func(fallback string) string {
+		if provider != "" {
+			return provider
+		}
+		return fallback
+	}
+	var bad error
+	add := func(cidr string, owner Range) {
+		if cidr == "" || bad != nil {
+			return
+		}
+		prefix, err := parsePrefix(cidr)
+		if err != nil {
+			bad = err
+			return
+		}
+		r.Add(prefix, owner)
+	}
+
+	switch {
+	case file.CreateDate != "" || hasAWSPrefixes(file):
+		for _, p := range append(append([]rangeEntry{}, file.Prefixes...), file.IPv6Prefixes...) {
+			owner := Range{Provider: named("AWS"), Service: p.Service, Region: p.Region, CDN: p.Service == "CLOUDFRONT"}
+			add(p.IPPrefix, owner)
+			add(p.IPv6Prefix, owner)
+		}
+	case len(file.Prefixes) > 0:
+		for _, p := range file.Prefixes {
+			// cloud.json scopes customer ranges by region; goog.json
+			// lists all of Google's address space without one.
+			owner := Range{Provider: named("Google"), Service: p.Service}
+			if p.Scope != "" {
+				owner.Provider, owner.Region = named("Google Cloud"), p.Scope
+			}
+			add(p.GoogleIPv4, owner)
+			add(p.GoogleIPv6, owner)
+		}
+	case len(file.Values) > 0:
+		for _, v := range file.Values {
+			service := v.Properties.SystemService
+			if service == "" {
+				service = v.Name
+			}
+			owner := Range{Provider: named("Azure"), Service: service, Region: v.Properties.Region,
+				CDN: strings.HasPrefix(v.Name, "AzureFrontDoor")}
+			for _, cidr := range v.Properties.AddressPrefixes {
+				add(cidr, owner)
+			}
+		}
+	case len(file.Regions) > 0:
+		for _, region := range file.Regions {
+			for _, c := range region.CIDRs {
+				add(c.CIDR, Range{Provider: named("Oracle Cloud"), Service: strings.Join(c.Tags, ","), Region: region.Region})
+			}
+		}
+	case len(file.Addresses) > 0 || len(file.IPv6Addresses) > 0:
+		owner := Range{Provider: named("Fastly"), CDN: true}
+		for _, cidr := range append(append([]string{}, file.Addresses...), file.IPv6Addresses...) {
+			add(cidr, owner)
+		}
+	case len(file.Result.IPv4CIDRs) > 0 || len(file.Result.IPv6CIDRs) > 0:
+		owner := Range{Provider: named("Cloudflare"), CDN: true}
+		for _, cidr := range append(append([]string{}, file.Result.IPv4CIDRs...), file.Result.IPv6CIDRs...) {
+			add(cidr, owner)
+		}
+	default:
+		return errors.NewConfigError("parse IP ranges "+path, fmt.Errorf("unrecognized range file layout"))
+	}
+
+	if bad != nil {
+		return errors.NewConfigError("parse IP ranges "+path, bad)
+	}
+	return nil
+}
+
+func hasAWSPrefixes(file *rangeFile) bool {
+	return (len(file.Prefixes) > 0 && file.Prefixes[0].IPPrefix != "") ||
+		(len(file.IPv6Prefixes) > 0 && file.IPv6Prefixes[0].IPv6Prefix != "")
+}
+
+// parsePrefix parses a CIDR, or a bare address as a single-host prefix.
+func parsePrefix(s string) (netip.Prefix, error) {
+	if !strings.Contains(s, "/") {
+		addr, err := netip.ParseAddr(s)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		return netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()), nil
+	}
+	prefix, err := netip.ParsePrefix(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()), nil
+}
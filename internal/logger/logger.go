@@ -1,10 +1,16 @@
 package logger
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type Level int
@@ -17,11 +23,87 @@ const (
 	LevelFatal
 )
 
-// Logger provides structured logging
+// String returns the upper-case level name used in console output.
+func (lv Level) String() string {
+	switch lv {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	default:
+		return "FATAL"
+	}
+}
+
+func (lv Level) slogLevel() slog.Level {
+	switch lv {
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelInfo:
+		return slog.LevelInfo
+	case LevelWarn:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
+
+// ParseLevel converts a level name (debug, info, warn, error) to a Level.
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info", "":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	default:
+		return LevelInfo, fmt.Errorf("unknown log level %q", name)
+	}
+}
+
+// Output formats accepted by Configure.
+const (
+	FormatConsole = "console" // [timestamp] [LEVEL] msg key=value
+	FormatText    = "text"    // slog key=value
+	FormatJSON    = "json"    // slog JSON, one object per line
+)
+
+// Options configures log format, destinations and level overrides.
+type Options struct {
+	Level   string            // global level; overridden to debug by verbosity
+	Format  string            // console, text or json
+	File    string            // optional path that receives a copy of every record
+	Modules map[string]string // per-module level overrides, e.g. enum=debug
+}
+
+// Logger provides structured logging on top of log/slog. Messages keep the
+// printf style used across the code base; key/value fields are attached with
+// With and the module name with ForModule. A nil *Logger discards everything.
 type Logger struct {
+	shared *state
+	module string
+	attrs  []slog.Attr
+}
+
+// state is shared by a logger and every child derived from it, so that
+// reconfiguring the root logger affects module loggers already handed out.
+type state struct {
+	mu        sync.Mutex
 	level     Level
-	out       io.Writer
 	verbosity bool
+	modules   map[string]Level
+	format    string
+	out       io.Writer
+	file      *os.File
+	handler   slog.Handler
+	runID     string
 }
 
 // New creates a new logger
@@ -31,59 +113,370 @@ func New(verbosity bool) *Logger {
 		level = LevelDebug
 	}
 
-	return &Logger{
+	s := &state{
 		level:     level,
-		out:       os.Stderr,
 		verbosity: verbosity,
+		modules:   map[string]Level{},
+		format:    FormatConsole,
+		out:       os.Stderr,
+		runID:     uuid.NewString(),
 	}
+	s.rebuild()
+	return &Logger{shared: s}
+}
+
+// NewWriter creates a logger writing records in the given format to out.
+// It is intended for tests that need to inspect log output.
+func NewWriter(out io.Writer, format string, verbosity bool) *Logger {
+	l := New(verbosity)
+	l.shared.out = out
+	l.shared.format = format
+	l.shared.rebuild()
+	return l
+}
+
+// Configure applies format, file and level settings. It may be called again
+// after CLI flags are parsed; previously opened log files are closed.
+func (l *Logger) Configure(opts Options) error {
+	if l == nil {
+		return nil
+	}
+	level, err := ParseLevel(opts.Level)
+	if err != nil {
+		return err
+	}
+	modules := make(map[string]Level, len(opts.Modules))
+	for module, name := range opts.Modules {
+		lv, err := ParseLevel(name)
+		if err != nil {
+			return fmt.Errorf("module %s: %w", module, err)
+		}
+		modules[module] = lv
+	}
+	format := opts.Format
+	switch format {
+	case "":
+		format = FormatConsole
+	case FormatConsole, FormatText, FormatJSON:
+	default:
+		return fmt.Errorf("unknown log format %q", opts.Format)
+	}
+
+	var file *os.File
+	if opts.File != "" {
+		file, err = os.OpenFile(opts.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("open log file: %w", err)
+		}
+	}
+
+	s := l.shared
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file != nil {
+		s.file.Close()
+	}
+	s.file = file
+	s.format = format
+	s.modules = modules
+	if !s.verbosity {
+		s.level = level
+	}
+	s.rebuild()
+	return nil
+}
+
+// Close flushes and closes the log file, if any.
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+	s := l.shared
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	s.rebuild()
+	return err
 }
 
 // SetVerbosity updates the logging level dynamically
 func (l *Logger) SetVerbosity(verbosity bool) {
-	l.verbosity = verbosity
+	if l == nil {
+		return
+	}
+	s := l.shared
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.verbosity = verbosity
 	if verbosity {
-		l.level = LevelDebug
+		s.level = LevelDebug
 	} else {
-		l.level = LevelInfo
+		s.level = LevelInfo
+	}
+}
+
+// SetOutput redirects console log output, e.g. through the progress display.
+func (l *Logger) SetOutput(out io.Writer) {
+	if l == nil {
+		return
 	}
+	s := l.shared
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.out = out
+	s.rebuild()
+}
+
+// RunID returns the identifier attached to every record of this run.
+func (l *Logger) RunID() string {
+	if l == nil {
+		return ""
+	}
+	return l.shared.runID
+}
+
+// ForModule returns a child logger that tags records with the module name
+// and honours that module's level override.
+func (l *Logger) ForModule(module string) *Logger {
+	if l == nil {
+		return nil
+	}
+	return &Logger{shared: l.shared, module: module, attrs: l.attrs}
+}
+
+// With returns a child logger that adds the given key/value pairs to every
+// record, following the slog argument convention.
+func (l *Logger) With(args ...interface{}) *Logger {
+	if l == nil {
+		return nil
+	}
+	r := slog.NewRecord(time.Time{}, slog.LevelInfo, "", 0)
+	r.Add(args...)
+	attrs := append([]slog.Attr(nil), l.attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	return &Logger{shared: l.shared, module: l.module, attrs: attrs}
+}
+
+// Enabled reports whether records at level would be emitted.
+func (l *Logger) Enabled(level Level) bool {
+	if l == nil {
+		return false
+	}
+	s := l.shared
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return level >= s.levelFor(l.module)
 }
 
 // Debug logs debug level messages
 func (l *Logger) Debug(msg string, args ...interface{}) {
-	if l.level <= LevelDebug {
-		l.log("DEBUG", msg, args...)
-	}
+	l.log(LevelDebug, msg, args...)
 }
 
 // Info logs info level messages
 func (l *Logger) Info(msg string, args ...interface{}) {
-	if l.level <= LevelInfo {
-		l.log("INFO", msg, args...)
-	}
+	l.log(LevelInfo, msg, args...)
 }
 
 // Warn logs warning level messages
 func (l *Logger) Warn(msg string, args ...interface{}) {
-	if l.level <= LevelWarn {
-		l.log("WARN", msg, args...)
-	}
+	l.log(LevelWarn, msg, args...)
 }
 
 // Error logs error level messages
 func (l *Logger) Error(msg string, args ...interface{}) {
-	if l.level <= LevelError {
-		l.log("ERROR", msg, args...)
-	}
+	l.log(LevelError, msg, args...)
 }
 
 // Fatal logs fatal level messages and exits
 func (l *Logger) Fatal(msg string, args ...interface{}) {
-	l.log("FATAL", msg, args...)
+	l.log(LevelFatal, msg, args...)
+	l.Close()
 	os.Exit(1)
 }
 
-func (l *Logger) log(level, msg string, args ...interface{}) {
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	formatted := fmt.Sprintf(msg, args...)
-	fmt.Fprintf(l.out, "[%s] [%s] %s\n", timestamp, level, formatted)
+func (l *Logger) log(level Level, msg string, args ...interface{}) {
+	if l == nil {
+		return
+	}
+	s := l.shared
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if level < LevelFatal && level < s.levelFor(l.module) {
+		return
+	}
+
+	if len(args) > 0 {
+		msg = fmt.Sprintf(msg, args...)
+	}
+	r := slog.NewRecord(time.Now(), level.slogLevel(), msg, 0)
+	if level == LevelFatal {
+		r.AddAttrs(slog.Bool("fatal", true))
+	}
+	if l.module != "" {
+		r.AddAttrs(slog.String("module", l.module))
+	}
+	r.AddAttrs(l.attrs...)
+	_ = s.handler.Handle(context.Background(), r)
+}
+
+// levelFor returns the effective level for module. Callers must hold s.mu.
+func (s *state) levelFor(module string) Level {
+	if lv, ok := s.modules[module]; ok && module != "" {
+		return lv
+	}
+	return s.level
+}
+
+// rebuild recreates the output handler chain. Callers must hold s.mu (or
+// own s exclusively).
+func (s *state) rebuild() {
+	runID := []slog.Attr{slog.String("run_id", s.runID)}
+	handlers := []slog.Handler{newFormatHandler(s.out, s.format, runID)}
+	if s.file != nil {
+		format := s.format
+		if format == FormatConsole {
+			format = FormatText
+		}
+		handlers = append(handlers, newFormatHandler(s.file, format, runID))
+	}
+	if len(handlers) == 1 {
+		s.handler = handlers[0]
+		return
+	}
+	s.handler = multiHandler(handlers)
+}
+
+func newFormatHandler(out io.Writer, format string, runID []slog.Attr) slog.Handler {
+	opts := &slog.HandlerOptions{Level: slog.LevelDebug}
+	switch format {
+	case FormatJSON:
+		return slog.NewJSONHandler(out, opts).WithAttrs(runID)
+	case FormatText:
+		return slog.NewTextHandler(out, opts).WithAttrs(runID)
+	default:
+		// The run ID is noise on an interactive console; it is kept in
+		// machine-readable formats only.
+		return &consoleHandler{out: out}
+	}
+}
+
+// consoleHandler renders the human-oriented "[timestamp] [LEVEL] msg" format
+// followed by any key/value fields.
+type consoleHandler struct {
+	out   io.Writer
+	attrs []slog.Attr
+}
+
+func (h *consoleHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *consoleHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	level := "FATAL"
+	switch {
+	case r.Level < slog.LevelInfo:
+		level = "DEBUG"
+	case r.Level < slog.LevelWarn:
+		level = "INFO"
+	case r.Level < slog.LevelError:
+		level = "WARN"
+	default:
+		level = "ERROR"
+	}
+
+	fields := append([]slog.Attr(nil), h.attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == "fatal" {
+			level = "FATAL"
+			return true
+		}
+		fields = append(fields, a)
+		return true
+	})
+
+	fmt.Fprintf(&b, "[%s] [%s] %s", r.Time.Format("2006-01-02 15:04:05"), level, r.Message)
+	for _, a := range fields {
+		fmt.Fprintf(&b, " %s=%s", a.Key, quoteIfNeeded(a.Value.String()))
+	}
+	b.WriteByte('\n')
+	_, err := io.WriteString(h.out, b.String())
+	return err
+}
+
+func (h *consoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &consoleHandler{out: h.out, attrs: append(append([]slog.Attr(nil), h.attrs...), attrs...)}
+}
+
+func (h *consoleHandler) WithGroup(string) slog.Handler { return h }
+
+func quoteIfNeeded(value string) string {
+	if value == "" || strings.ContainsAny(value, " \t\"=") {
+		return fmt.Sprintf("%q", value)
+	}
+	return value
+}
+
+// multiHandler fans a record out to several handlers.
+type multiHandler []slog.Handler
+
+func (m multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range m {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m multiHandler) Handle(ctx context.Context, r slog.Record) error {
+	var firstErr error
+	for _, h := range m {
+		if err := h.Handle(ctx, r.Clone()); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (m multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := make(multiHandler, len(m))
+	for i, h := range m {
+		out[i] = h.WithAttrs(attrs)
+	}
+	return out
+}
+
+func (m multiHandler) WithGroup(name string) slog.Handler {
+	out := make(multiHandler, len(m))
+	for i, h := range m {
+		out[i] = h.WithGroup(name)
+	}
+	return out
+}
+
+// ParseModuleLevels parses "enum=debug,ports=warn" into a module level map.
+func ParseModuleLevels(spec string) (map[string]string, error) {
+	levels := map[string]string{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		module, level, ok := strings.Cut(part, "=")
+		if !ok || strings.TrimSpace(module) == "" {
+			return nil, fmt.Errorf("invalid module level %q, want module=level", part)
+		}
+		if _, err := ParseLevel(level); err != nil {
+			return nil, err
+		}
+		levels[strings.TrimSpace(module)] = strings.TrimSpace(level)
+	}
+	return levels, nil
 }
@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestJSONOutputCarriesRunIDAndModule(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWriter(&buf, FormatJSON, false)
+
+	log.ForModule("enum").With("source", "brute").Info("Found: %s", "api.example.com")
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("output is not JSON: %v (%q)", err, buf.String())
+	}
+	want := map[string]string{
+		"msg":    "Found: api.example.com",
+		"level":  "INFO",
+		"module": "enum",
+		"source": "brute",
+		"run_id": log.RunID(),
+	}
+	for key, value := range want {
+		if record[key] != value {
+			t.Fatalf("record[%q] = %v, want %q", key, record[key], value)
+		}
+	}
+}
+
+func TestModuleLevelOverrides(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWriter(&buf, FormatConsole, false)
+	if err := log.Configure(Options{Level: "warn", Format: FormatConsole, Modules: map[string]string{"enum": "debug"}}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	log.Info("global info")
+	log.ForModule("ports").Info("ports info")
+	log.ForModule("enum").Debug("enum debug")
+
+	got := buf.String()
+	if strings.Contains(got, "global info") || strings.Contains(got, "ports info") {
+		t.Fatalf("output = %q, want info records suppressed at warn", got)
+	}
+	if !strings.Contains(got, "[DEBUG] enum debug module=enum") {
+		t.Fatalf("output = %q, want enum debug record", got)
+	}
+	if strings.Contains(got, "run_id") {
+		t.Fatalf("console output = %q, should not include run_id", got)
+	}
+}
+
+func TestLogFileReceivesTextRecords(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWriter(&buf, FormatConsole, false)
+	path := filepath.Join(t.TempDir(), "gospyder.log")
+	if err := log.Configure(Options{Level: "info", File: path}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	log.Warn("resolver %s timed out", "1.1.1.1")
+	if err := log.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	got := string(data)
+	if !strings.Contains(got, `msg="resolver 1.1.1.1 timed out"`) || !strings.Contains(got, "run_id="+log.RunID()) {
+		t.Fatalf("log file = %q, want text record with run_id", got)
+	}
+	if !strings.Contains(buf.String(), "[WARN] resolver 1.1.1.1 timed out") {
+		t.Fatalf("console = %q, want warn record", buf.String())
+	}
+}
+
+func TestParseModuleLevels(t *testing.T) {
+	levels, err := ParseModuleLevels("enum=debug, ports=warn")
+	if err != nil {
+		t.Fatalf("ParseModuleLevels() error = %v", err)
+	}
+	if levels["enum"] != "debug" || levels["ports"] != "warn" {
+		t.Fatalf("ParseModuleLevels() = %v", levels)
+	}
+	if _, err := ParseModuleLevels("enum"); err == nil {
+		t.Fatal("ParseModuleLevels(\"enum\") error = nil, want error")
+	}
+	if _, err := ParseModuleLevels("enum=loud"); err == nil {
+		t.Fatal("ParseModuleLevels(\"enum=loud\") error = nil, want error")
+	}
+}
+
+func TestNilLoggerIsSafe(t *testing.T) {
+	var log *Logger
+	log.ForModule("enum").With("k", "v").Info("ignored")
+	if log.Enabled(LevelError) {
+		t.Fatal("nil logger reports enabled")
+	}
+}
@@ -83,26 +83,59 @@ func (f *Formatter) formatRegistryResult(result *registry.Result) string {
 		return ""
 	}
 
+	var out string
 	switch result.Module {
 	case "enum":
-		return f.formatEnum(result)
+		out = f.formatEnum(result)
 	case "ports":
-		return f.formatPorts(result)
+		out = f.formatPorts(result)
 	case "fuzz":
-		return f.formatFuzz(result)
+		out = f.formatFuzz(result)
 	case "waf":
-		return f.formatWAF(result)
+		out = f.formatWAF(result)
 	case "http":
-		return f.formatHTTP(result)
+		out = f.formatHTTP(result)
 	case "live":
-		return f.formatLive(result)
+		out = f.formatLive(result)
 	case "tech":
-		return f.formatTech(result)
+		out = f.formatTech(result)
 	case "js":
-		return f.formatJS(result)
+		out = f.formatJS(result)
 	default:
-		return f.formatGeneric(result)
+		out = f.formatGeneric(result)
 	}
+	return out + f.formatErrors(result)
+}
+
+// formatErrors renders the module's failures with counts by type.
+func (f *Formatter) formatErrors(result *registry.Result) string {
+	if len(result.Errors) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	tag := "[ERROR]"
+	if f.colors {
+		tag = ColorRed + tag + ColorReset
+	}
+	fmt.Fprintf(&b, "\n%s %s %s (%s)\n", tag, result.Module, result.Status, errorCounts(result.ErrorCounts))
+	for _, msg := range result.Errors {
+		fmt.Fprintf(&b, "  - %s\n", msg)
+	}
+	return b.String()
+}
+
+// errorCounts renders counts by type as "network=2, timeout=1".
+func errorCounts(counts map[string]int) string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%d", key, counts[key]))
+	}
+	return strings.Join(parts, ", ")
 }
 
 func (f *Formatter) formatBanner(title string) string {
@@ -479,12 +512,14 @@ func (f *Formatter) formatReconResults(results []*registry.Result) string {
 	var b strings.Builder
 	summary := map[string]int{}
 	waf := "None"
+	errorTotal := 0
 
 	for _, result := range results {
 		if result == nil {
 			continue
 		}
 
+		errorTotal += len(result.Errors)
 		formatted := f.formatRegistryResult(result)
 		if formatted != "" {
 			b.WriteString(formatted)
@@ -551,6 +586,9 @@ func (f *Formatter) formatReconResults(results []*registry.Result) string {
 	printRow("Paths", summary["Paths"])
 	printRow("Technologies", summary["Technologies"])
 	printRow("WAF", waf)
+	if errorTotal > 0 {
+		printRow("Errors", errorTotal)
+	}
 
 	b.WriteString(fmt.Sprintf("%s╚════════════════════════════════════╝%s\n", cyan, reset))
 
@@ -68,3 +68,23 @@ func TestFormatReconSummary(t *testing.T) {
 		}
 	}
 }
+
+func TestFormatErrorsSection(t *testing.T) {
+	formatter := New("txt", false, true)
+	out, err := formatter.Format(&registry.Result{
+		Module:      "enum",
+		Target:      "example.com",
+		Status:      "partial",
+		Findings:    []registry.Finding{{Value: "www.example.com"}},
+		Errors:      []string{"certstream unavailable: dial tcp: connection refused", "DNS lookups: 4 failed"},
+		ErrorCounts: map[string]int{"network": 1, "timeout": 1},
+	})
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	for _, want := range []string{"[ERROR] enum partial (network=1, timeout=1)", "- certstream unavailable", "- DNS lookups: 4 failed"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("Format() missing %q in:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Display renders live per-module progress on a terminal, or periodic plain
+// status lines when the output is not a TTY.
+type Display struct {
+	out      io.Writer
+	tty      bool
+	interval time.Duration
+
+	mu      sync.Mutex
+	tasks   []*Task
+	drawn   int // lines drawn by the last TTY render
+	running bool
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// Task tracks the counters of a single running module.
+type Task struct {
+	name  string
+	unit  string
+	start time.Time
+
+	total    atomic.Int64
+	done     atomic.Int64
+	errors   atomic.Int64
+	findings atomic.Int64
+	finished atomic.Int64 // unix nanos, zero while running
+}
+
+// New creates a display writing to out. TTY mode redraws a status block in
+// place; otherwise a status line per running module is printed every interval.
+func New(out io.Writer, tty bool, interval time.Duration) *Display {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	return &Display{
+		out:      out,
+		tty:      tty,
+		interval: interval,
+	}
+}
+
+// NewStderr creates a display on os.Stderr, detecting whether it is a TTY.
+func NewStderr(interval time.Duration) *Display {
+	return New(os.Stderr, IsTerminal(os.Stderr), interval)
+}
+
+// IsTerminal reports whether f refers to a character device such as a TTY.
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// Start begins rendering in the background. It is a no-op if already running.
+func (d *Display) Start() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.running {
+		return
+	}
+	d.running = true
+	d.stop = make(chan struct{})
+	d.done = make(chan struct{})
+	go d.loop(d.stop, d.done)
+}
+
+// Stop renders a final frame and stops the background renderer.
+func (d *Display) Stop() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	if !d.running {
+		d.mu.Unlock()
+		return
+	}
+	d.running = false
+	stop, done := d.stop, d.done
+	d.mu.Unlock()
+
+	close(stop)
+	<-done
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.tty {
+		d.renderTTY()
+		// Keep the final frame on screen instead of overwriting it.
+		d.drawn = 0
+	}
+	d.tasks = nil
+}
+
+// Track registers a module and returns its task. It returns nil when the
+// display is not running.
+func (d *Display) Track(name, unit string) *Task {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.running {
+		return nil
+	}
+	task := &Task{name: name, unit: unit, start: time.Now()}
+	d.tasks = append(d.tasks, task)
+	return task
+}
+
+// Write lets log output share the terminal with the status block: the block
+// is cleared, p is written, and the block is redrawn.
+func (d *Display) Write(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.tty || !d.running {
+		return d.out.Write(p)
+	}
+	d.clearTTY()
+	n, err := d.out.Write(p)
+	d.drawTTY()
+	return n, err
+}
+
+func (d *Display) loop(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	interval := d.interval
+	if d.tty {
+		interval = 200 * time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			d.mu.Lock()
+			if d.tty {
+				d.renderTTY()
+			} else {
+				d.renderPlain()
+			}
+			d.mu.Unlock()
+		}
+	}
+}
+
+// renderTTY redraws the status block in place. Callers must hold d.mu.
+func (d *Display) renderTTY() {
+	d.clearTTY()
+	d.drawTTY()
+}
+
+func (d *Display) clearTTY() {
+	for i := 0; i < d.drawn; i++ {
+		fmt.Fprint(d.out, "\033[1A\033[2K")
+	}
+	d.drawn = 0
+}
+
+func (d *Display) drawTTY() {
+	now := time.Now()
+	for _, task := range d.tasks {
+		fmt.Fprintf(d.out, "\033[2K%s\n", task.line(now))
+		d.drawn++
+	}
+}
+
+// renderPlain prints one status line per unfinished task. Callers must hold d.mu.
+func (d *Display) renderPlain() {
+	now := time.Now()
+	for _, task := range d.tasks {
+		if task.finished.Load() != 0 {
+			continue
+		}
+		fmt.Fprintf(d.out, "[progress] %s\n", task.line(now))
+	}
+}
+
+// AddTotal grows the number of work items the module expects to process.
+func (t *Task) AddTotal(n int64) {
+	t.total.Add(n)
+}
+
+// Increment marks n work items as processed.
+func (t *Task) Increment(n int64) {
+	t.done.Add(n)
+}
+
+// AddError records a failed work item.
+func (t *Task) AddError() {
+	t.errors.Add(1)
+}
+
+// AddFinding records a new finding.
+func (t *Task) AddFinding() {
+	t.findings.Add(1)
+}
+
+// Finish marks the task as complete so its rate and duration freeze.
+func (t *Task) Finish() {
+	t.finished.CompareAndSwap(0, time.Now().UnixNano())
+}
+
+// Snapshot is a point-in-time view of a task's counters.
+type Snapshot struct {
+	Name     string
+	Unit     string
+	Total    int64
+	Done     int64
+	Errors   int64
+	Findings int64
+	Elapsed  time.Duration
+	Rate     float64 // items per second
+	ETA      time.Duration
+	Finished bool
+}
+
+// Snapshot returns the task counters as of now.
+func (t *Task) Snapshot(now time.Time) Snapshot {
+	s := Snapshot{
+		Name:     t.name,
+		Unit:     t.unit,
+		Total:    t.total.Load(),
+		Done:     t.done.Load(),
+		Errors:   t.errors.Load(),
+		Findings: t.findings.Load(),
+	}
+	if finished := t.finished.Load(); finished != 0 {
+		now = time.Unix(0, finished)
+		s.Finished = true
+	}
+	s.Elapsed = now.Sub(t.start)
+	if s.Elapsed > 0 {
+		s.Rate = float64(s.Done) / s.Elapsed.Seconds()
+	}
+	if !s.Finished && s.Rate > 0 && s.Total > s.Done {
+		s.ETA = time.Duration(float64(s.Total-s.Done) / s.Rate * float64(time.Second))
+	}
+	return s
+}
+
+func (t *Task) line(now time.Time) string {
+	s := t.Snapshot(now)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-8s", s.Name)
+	if s.Total > 0 {
+		fmt.Fprintf(&b, " %d/%d (%5.1f%%)", s.Done, s.Total, float64(s.Done)*100/float64(s.Total))
+	} else {
+		fmt.Fprintf(&b, " %d", s.Done)
+	}
+	fmt.Fprintf(&b, " %.1f %s/s errors=%d findings=%d", s.Rate, s.Unit, s.Errors, s.Findings)
+	switch {
+	case s.Finished:
+		fmt.Fprintf(&b, " done in %s", s.Elapsed.Round(100*time.Millisecond))
+	case s.ETA > 0:
+		fmt.Fprintf(&b, " eta=%s", s.ETA.Round(time.Second))
+	default:
+		fmt.Fprintf(&b, " elapsed=%s", s.Elapsed.Round(time.Second))
+	}
+	return b.String()
+}
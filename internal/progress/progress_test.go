@@ -0,0 +1,114 @@
+package progress
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestTaskSnapshotRateAndETA(t *testing.T) {
+	task := &Task{name: "enum", unit: "lookups", start: time.Now().Add(-10 * time.Second)}
+	task.AddTotal(100)
+	task.Increment(50)
+	task.AddError()
+	task.AddFinding()
+	task.AddFinding()
+
+	snap := task.Snapshot(task.start.Add(10 * time.Second))
+	if snap.Done != 50 || snap.Total != 100 {
+		t.Fatalf("Snapshot() done/total = %d/%d, want 50/100", snap.Done, snap.Total)
+	}
+	if snap.Rate != 5 {
+		t.Fatalf("Snapshot() rate = %v, want 5", snap.Rate)
+	}
+	if snap.ETA != 10*time.Second {
+		t.Fatalf("Snapshot() ETA = %s, want 10s", snap.ETA)
+	}
+	if snap.Errors != 1 || snap.Findings != 2 {
+		t.Fatalf("Snapshot() errors/findings = %d/%d, want 1/2", snap.Errors, snap.Findings)
+	}
+
+	line := task.line(task.start.Add(10 * time.Second))
+	for _, want := range []string{"enum", "50/100", "lookups/s", "errors=1", "findings=2", "eta=10s"} {
+		if !strings.Contains(line, want) {
+			t.Fatalf("line() = %q, missing %q", line, want)
+		}
+	}
+}
+
+func TestPlainDisplayPrintsStatusLines(t *testing.T) {
+	out := &syncBuffer{}
+	display := New(out, false, 20*time.Millisecond)
+	display.Start()
+
+	task := display.Track("ports", "ports")
+	if task == nil {
+		t.Fatal("Track() = nil on running display")
+	}
+	task.AddTotal(10)
+	task.Increment(3)
+
+	time.Sleep(80 * time.Millisecond)
+	task.Finish()
+	display.Stop()
+
+	got := out.String()
+	if !strings.Contains(got, "[progress] ports") || !strings.Contains(got, "3/10") {
+		t.Fatalf("plain output = %q, want status line for ports", got)
+	}
+	if strings.Contains(got, "\033[") {
+		t.Fatalf("plain output contains escape sequences: %q", got)
+	}
+}
+
+func TestTrackRequiresRunningDisplay(t *testing.T) {
+	display := New(&syncBuffer{}, false, time.Second)
+	if task := display.Track("enum", "lookups"); task != nil {
+		t.Fatal("Track() on stopped display returned a task")
+	}
+
+	var nilDisplay *Display
+	if task := nilDisplay.Track("enum", "lookups"); task != nil {
+		t.Fatal("Track() on nil display returned a task")
+	}
+}
+
+func TestTTYWriteRedrawsBlock(t *testing.T) {
+	out := &syncBuffer{}
+	display := New(out, true, time.Second)
+	display.Start()
+	task := display.Track("fuzz", "req")
+	task.AddTotal(4)
+
+	if _, err := display.Write([]byte("log line\n")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	display.Stop()
+
+	got := out.String()
+	if !strings.Contains(got, "log line\n") {
+		t.Fatalf("tty output = %q, want log line passed through", got)
+	}
+	if !strings.Contains(got, "fuzz") || !strings.Contains(got, "0/4") {
+		t.Fatalf("tty output = %q, want fuzz status block", got)
+	}
+}
@@ -7,7 +7,10 @@ import (
 
 	"github.com/NASHEDIxCODER/gospyder/internal/config"
 	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/internal/intel"
 	"github.com/NASHEDIxCODER/gospyder/internal/logger"
+	"github.com/NASHEDIxCODER/gospyder/internal/replay"
+	"github.com/NASHEDIxCODER/gospyder/internal/telemetry"
 	"github.com/NASHEDIxCODER/gospyder/internal/workspace"
 )
 
@@ -38,8 +41,55 @@ type Options struct {
 
 	// Error collection
 	Errors *errors.Collector
+
+	// Progress receives live work counters for the CLI status display.
+	// It may be nil; modules should use ProgressReporter instead.
+	Progress Progress
+
+	// Telemetry records metrics and trace spans. A nil value is a no-op.
+	Telemetry *telemetry.Telemetry
+
+	// Replay records or replays HTTP, DNS and TCP interactions. A nil value
+	// leaves network access untouched.
+	Replay *replay.Session
+
+	// Intel maps addresses to ASN and cloud/CDN provider. A nil value knows
+	// nothing.
+	Intel *intel.Database
 }
 
+// ProgressReporter returns the configured progress sink, or a no-op one
+// when the caller did not request progress reporting.
+func (o Options) ProgressReporter() Progress {
+	if o.Progress == nil {
+		return NopProgress
+	}
+	return o.Progress
+}
+
+// Progress receives work counters from a running module so the CLI can
+// render live status. Implementations must be safe for concurrent use.
+type Progress interface {
+	// AddTotal grows the number of work items the module expects to process.
+	AddTotal(n int64)
+	// Increment marks n work items as processed.
+	Increment(n int64)
+	// AddError records a failed work item.
+	AddError()
+	// AddFinding records a new finding.
+	AddFinding()
+}
+
+// NopProgress discards all progress updates.
+var NopProgress Progress = nopProgress{}
+
+type nopProgress struct{}
+
+func (nopProgress) AddTotal(int64)  {}
+func (nopProgress) Increment(int64) {}
+func (nopProgress) AddError()       {}
+func (nopProgress) AddFinding()     {}
+
 // Formatter is the minimal output formatter contract modules can use without
 // coupling the registry package to a concrete output implementation.
 type Formatter interface {
@@ -55,7 +105,36 @@ type Result struct {
 	Findings  []Finding              `json:"findings,omitempty"`
 	Metadata  map[string]interface{} `json:"metadata,omitempty"`
 	Errors    []string               `json:"errors,omitempty"`
-	Duration  float64                `json:"duration_seconds,omitempty"`
+	// ErrorCounts groups Errors by type name (network, timeout, io, ...).
+	ErrorCounts map[string]int `json:"error_counts,omitempty"`
+	Duration    float64        `json:"duration_seconds,omitempty"`
+}
+
+// AddErrors records module failures on the result and downgrades a
+// successful status to "partial" when findings were still produced, or to
+// "error" when there are none.
+func (r *Result) AddErrors(errs ...error) {
+	if r == nil || len(errs) == 0 {
+		return
+	}
+	if r.ErrorCounts == nil {
+		r.ErrorCounts = map[string]int{}
+	}
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		r.Errors = append(r.Errors, err.Error())
+		r.ErrorCounts[errors.TypeOf(err).String()]++
+	}
+	if len(r.Errors) == 0 || r.Status == "error" {
+		return
+	}
+	if len(r.Findings) > 0 {
+		r.Status = "partial"
+	} else {
+		r.Status = "error"
+	}
 }
 
 // Finding is a single user-facing discovery produced by a module.
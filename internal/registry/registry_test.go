@@ -2,8 +2,12 @@ package registry
 
 import (
 	"context"
+	"fmt"
+	"os"
 	"testing"
 	"time"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
 )
 
 type testModule struct{}
@@ -72,3 +76,33 @@ func TestRegistryUnknownModule(t *testing.T) {
 		t.Fatal("Run() missing error = nil")
 	}
 }
+
+func TestResultAddErrorsSetsStatusAndCounts(t *testing.T) {
+	partial := &Result{Status: "success", Findings: []Finding{{Value: "www.example.com"}}}
+	partial.AddErrors(
+		errors.NewTimeoutError("DNS lookups: 3 failed"),
+		errors.Wrap("open wordlist", &os.PathError{Op: "open", Path: "missing.txt", Err: os.ErrNotExist}),
+		errors.NewTimeoutError("CertStream read timed out"),
+	)
+	if partial.Status != "partial" {
+		t.Fatalf("Status = %q, want partial", partial.Status)
+	}
+	if partial.ErrorCounts["timeout"] != 2 || partial.ErrorCounts["io"] != 1 {
+		t.Fatalf("ErrorCounts = %v, want timeout=2 io=1", partial.ErrorCounts)
+	}
+	if len(partial.Errors) != 3 {
+		t.Fatalf("Errors = %v, want 3 messages", partial.Errors)
+	}
+
+	failed := &Result{Status: "success"}
+	failed.AddErrors(fmt.Errorf("boom"))
+	if failed.Status != "error" || failed.ErrorCounts["unknown"] != 1 {
+		t.Fatalf("failed result = %+v, want error status with unknown count", failed)
+	}
+
+	clean := &Result{Status: "success"}
+	clean.AddErrors()
+	if clean.Status != "success" || clean.ErrorCounts != nil {
+		t.Fatalf("clean result = %+v, want unchanged", clean)
+	}
+}
@@ -0,0 +1,138 @@
+package replay
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+)
+
+// maxRecordedBody caps response bodies stored in a fixture. Callers
+// still get the whole body while recording.
+const maxRecordedBody = 10 * 1024 * 1024
+
+// uuidPattern matches random identifiers (e.g. the fuzzer's wildcard
+// baseline path or DNS wildcard probe labels) so they match across record
+// and replay.
+var uuidPattern = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+
+func httpKey(method, url string, body []byte) string {
+	return method + " " + uuidPattern.ReplaceAllString(url, "{uuid}") + " " + string(body)
+}
+
+// Transport wraps base for recording or replaces it for replay. It returns
+// base unchanged for a nil session; a nil base means http.DefaultTransport.
+func (s *Session) Transport(base http.RoundTripper) http.RoundTripper {
+	if s == nil {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{session: s, base: base}
+}
+
+// Client returns a shallow copy of client using Transport, or client itself
+// for a nil session.
+func (s *Session) Client(client *http.Client) *http.Client {
+	if s == nil || client == nil {
+		return client
+	}
+	clone := *client
+	clone.Transport = s.Transport(client.Transport)
+	return &clone
+}
+
+type transport struct {
+	session *Session
+	base    http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	if t.session.mode == ModeReplay {
+		return t.session.replayHTTP(req, reqBody)
+	}
+	return t.session.recordHTTP(t.base, req, reqBody)
+}
+
+func (s *Session) recordHTTP(base http.RoundTripper, req *http.Request, reqBody []byte) (*http.Response, error) {
+	in := &HTTPInteraction{Method: req.Method, URL: req.URL.String(), RequestBody: reqBody}
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		in.Error = recordError(err)
+		s.appendHTTP(in)
+		return nil, err
+	}
+
+	body, readErr := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	in.Status = resp.StatusCode
+	in.Header = resp.Header.Clone()
+	in.Body = body
+	if len(body) > maxRecordedBody {
+		in.Body, in.Truncated = body[:maxRecordedBody], true
+	}
+	if readErr != nil {
+		in.Error = recordError(readErr)
+	}
+	s.appendHTTP(in)
+	return resp, nil
+}
+
+func (s *Session) appendHTTP(in *HTTPInteraction) {
+	s.mu.Lock()
+	s.http = append(s.http, in)
+	s.mu.Unlock()
+}
+
+func (s *Session) replayHTTP(req *http.Request, reqBody []byte) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	idx, ok := s.next(s.httpIndex, "http", httpKey(req.Method, req.URL.String(), reqBody))
+	var in HTTPInteraction
+	if ok {
+		in = *s.http[idx]
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		return nil, &missError{what: fmt.Sprintf("%s %s", req.Method, req.URL)}
+	}
+	if in.Error != nil && in.Status == 0 {
+		return nil, in.Error
+	}
+	header := http.Header(in.Header).Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	if in.Truncated {
+		// Only the start of the body was kept.
+		header.Del("Content-Length")
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", in.Status, http.StatusText(in.Status)),
+		StatusCode:    in.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(in.Body)),
+		ContentLength: int64(len(in.Body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,212 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LookupFunc resolves a host name to addresses, like net.Resolver.LookupHost.
+type LookupFunc func(ctx context.Context, host string) ([]string, error)
+
+// DialFunc opens a connection, like net.Dialer.DialContext.
+type DialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// LookupHost records lookup's answer or replays a recorded one. Names
+// missing from a fixture resolve as NXDOMAIN so larger wordlists can be
+// replayed against a capture.
+func (s *Session) LookupHost(ctx context.Context, host string, lookup LookupFunc) ([]string, error) {
+	return s.Lookup(ctx, "", host, lookup)
+}
+
+// Lookup records or replays a DNS query of record type qtype. An empty
+// qtype is an address lookup answered with addresses; typed queries are
+// answered with records in presentation form.
+func (s *Session) Lookup(ctx context.Context, qtype, host string, lookup LookupFunc) ([]string, error) {
+	if s == nil {
+		return lookup(ctx, host)
+	}
+	if s.mode == ModeRecord {
+		answers, err := lookup(ctx, host)
+		if err != nil && ctx.Err() != nil {
+			// Lookups cut short by the caller are not part of the capture.
+			return answers, err
+		}
+		s.mu.Lock()
+		in := &DNSInteraction{Type: qtype, Name: host, Error: recordError(err)}
+		if qtype == "" {
+			in.Addrs = answers
+		} else {
+			in.Answers = answers
+		}
+		s.dns = append(s.dns, in)
+		s.mu.Unlock()
+		return answers, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	idx, ok := s.next(s.dnsIndex, "dns", dnsKey(qtype, host))
+	var in DNSInteraction
+	if ok {
+		in = *s.dns[idx]
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		return nil, &net.DNSError{Err: ErrNotRecorded.Error(), Name: host, IsNotFound: true}
+	}
+	if in.Error != nil {
+		return nil, &net.DNSError{
+			Err:        in.Error.Message,
+			Name:       host,
+			IsNotFound: in.Error.Kind == "not_found",
+			IsTimeout:  in.Error.Kind == "timeout",
+		}
+	}
+	if qtype == "" {
+		return append([]string(nil), in.Addrs...), nil
+	}
+	// Answers owned by the recorded name are re-owned by the queried one, so
+	// random probe labels matched by key normalization stay consistent.
+	answers := make([]string, len(in.Answers))
+	recorded := strings.TrimSuffix(in.Name, ".") + " "
+	for i, answer := range in.Answers {
+		if len(answer) >= len(recorded) && strings.EqualFold(answer[:len(recorded)], recorded) {
+			answer = strings.TrimSuffix(host, ".") + " " + answer[len(recorded):]
+		}
+		answers[i] = answer
+	}
+	return answers, nil
+}
+
+// DialContext records the connection made by dial, including the bytes
+// exchanged on it, or replays a recorded one. Addresses missing from a
+// fixture are refused.
+func (s *Session) DialContext(ctx context.Context, network, address string, dial DialFunc) (net.Conn, error) {
+	if s == nil {
+		return dial(ctx, network, address)
+	}
+	if s.mode == ModeRecord {
+		conn, err := dial(ctx, network, address)
+		if err != nil && ctx.Err() != nil {
+			return nil, err
+		}
+		in := &TCPInteraction{Address: address, Error: recordError(err)}
+		s.mu.Lock()
+		s.tcp = append(s.tcp, in)
+		s.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		return &recordingConn{Conn: conn, session: s, in: in}, nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	idx, ok := s.next(s.tcpIndex, "tcp", address)
+	var in TCPInteraction
+	if ok {
+		in = *s.tcp[idx]
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		return nil, &net.OpError{Op: "dial", Net: network, Err: &RecordedError{Kind: "refused", Message: "connection refused (" + ErrNotRecorded.Error() + ")"}}
+	}
+	if in.Error != nil {
+		return nil, &net.OpError{Op: "dial", Net: network, Err: in.Error}
+	}
+	var reads bytes.Buffer
+	for _, ex := range in.Exchanges {
+		if ex.Dir == "read" {
+			reads.Write(ex.Data)
+		}
+	}
+	return &replayConn{reader: bytes.NewReader(reads.Bytes()), remote: replayAddr{network: network, address: address}}, nil
+}
+
+// recordingConn appends every read and write to its interaction.
+type recordingConn struct {
+	net.Conn
+	session *Session
+	in      *TCPInteraction
+}
+
+func (c *recordingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if n > 0 {
+		c.record("read", p[:n])
+	}
+	return n, err
+}
+
+func (c *recordingConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	if n > 0 {
+		c.record("write", p[:n])
+	}
+	return n, err
+}
+
+func (c *recordingConn) record(dir string, p []byte) {
+	c.session.mu.Lock()
+	defer c.session.mu.Unlock()
+	c.in.Exchanges = append(c.in.Exchanges, Exchange{Dir: dir, Data: append(Data(nil), p...)})
+}
+
+// replayConn serves the recorded server bytes and discards writes.
+type replayConn struct {
+	mu     sync.Mutex
+	reader *bytes.Reader
+	closed bool
+	remote replayAddr
+}
+
+func (c *replayConn) Read(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return 0, net.ErrClosed
+	}
+	return c.reader.Read(p)
+}
+
+func (c *replayConn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return 0, net.ErrClosed
+	}
+	return len(p), nil
+}
+
+func (c *replayConn) Close() error {
+	c.mu.Lock()
+	c.closed = true
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *replayConn) LocalAddr() net.Addr {
+	return replayAddr{network: c.remote.network, address: "replay"}
+}
+func (c *replayConn) RemoteAddr() net.Addr             { return c.remote }
+func (c *replayConn) SetDeadline(time.Time) error      { return nil }
+func (c *replayConn) SetReadDeadline(time.Time) error  { return nil }
+func (c *replayConn) SetWriteDeadline(time.Time) error { return nil }
+
+type replayAddr struct {
+	network string
+	address string
+}
+
+func (a replayAddr) Network() string { return a.network }
+func (a replayAddr) String() string  { return a.address }
@@ -0,0 +1,324 @@
+// Package replay records the HTTP, DNS and TCP interactions of a run into a
+// JSON fixture ("cassette") and replays them offline. Every Session method
+// is safe to call on a nil *Session, which leaves network access untouched.
+package replay
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unicode/utf8"
+)
+
+// CassetteVersion is the fixture format version written by Save.
+const CassetteVersion = 1
+
+// Mode selects whether a session records or replays.
+type Mode int
+
+const (
+	ModeRecord Mode = iota + 1
+	ModeReplay
+)
+
+// String returns the mode name.
+func (m Mode) String() string {
+	switch m {
+	case ModeRecord:
+		return "record"
+	case ModeReplay:
+		return "replay"
+	default:
+		return "off"
+	}
+}
+
+// ErrNotRecorded is wrapped by errors returned in replay mode for
+// interactions missing from the fixture.
+var ErrNotRecorded = errors.New("not in replay fixture")
+
+// Cassette is the on-disk fixture format.
+type Cassette struct {
+	Version    int               `json:"version"`
+	RecordedAt time.Time         `json:"recorded_at"`
+	HTTP       []HTTPInteraction `json:"http,omitempty"`
+	DNS        []DNSInteraction  `json:"dns,omitempty"`
+	TCP        []TCPInteraction  `json:"tcp,omitempty"`
+}
+
+// HTTPInteraction is one request and its response or transport error.
+type HTTPInteraction struct {
+	Method      string              `json:"method"`
+	URL         string              `json:"url"`
+	RequestBody Data                `json:"request_body,omitempty"`
+	Status      int                 `json:"status,omitempty"`
+	Header      map[string][]string `json:"header,omitempty"`
+	Body        Data                `json:"body,omitempty"`
+	Error       *RecordedError      `json:"error,omitempty"`
+	// Truncated marks a body cut to its first maxRecordedBody bytes.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// DNSInteraction is one lookup and its answers or error. Type is empty for
+// address lookups, which fill Addrs; typed queries fill Answers.
+type DNSInteraction struct {
+	Type    string         `json:"type,omitempty"`
+	Name    string         `json:"name"`
+	Addrs   []string       `json:"addrs,omitempty"`
+	Answers []string       `json:"answers,omitempty"`
+	Error   *RecordedError `json:"error,omitempty"`
+}
+
+// TCPInteraction is one dial and the bytes exchanged on the connection.
+type TCPInteraction struct {
+	Address   string         `json:"address"`
+	Error     *RecordedError `json:"error,omitempty"`
+	Exchanges []Exchange     `json:"exchanges,omitempty"`
+}
+
+// Exchange is a chunk of data read from or written to a connection.
+type Exchange struct {
+	Dir  string `json:"dir"` // "read" or "write"
+	Data Data   `json:"data"`
+}
+
+// Data is a byte payload stored as a JSON string when it is valid UTF-8 and
+// as {"base64": "..."} otherwise, so fixtures stay readable.
+type Data []byte
+
+// MarshalJSON implements json.Marshaler.
+func (d Data) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(d) {
+		return json.Marshal(string(d))
+	}
+	return json.Marshal(map[string]string{"base64": base64.StdEncoding.EncodeToString(d)})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Data) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*d = Data(s)
+		return nil
+	}
+	var encoded struct {
+		Base64 string `json:"base64"`
+	}
+	if err := json.Unmarshal(b, &encoded); err != nil {
+		return err
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded.Base64)
+	if err != nil {
+		return err
+	}
+	*d = raw
+	return nil
+}
+
+// RecordedError is a captured failure. It implements net.Error so replayed
+// timeouts are classified like real ones.
+type RecordedError struct {
+	Kind    string `json:"kind"` // timeout, refused, not_found or error
+	Message string `json:"message"`
+}
+
+func (e *RecordedError) Error() string   { return e.Message }
+func (e *RecordedError) Timeout() bool   { return e.Kind == "timeout" }
+func (e *RecordedError) Temporary() bool { return false }
+
+// Unwrap maps refused connections back to ECONNREFUSED.
+func (e *RecordedError) Unwrap() error {
+	if e.Kind == "refused" {
+		return syscall.ECONNREFUSED
+	}
+	return nil
+}
+
+func recordError(err error) *RecordedError {
+	if err == nil {
+		return nil
+	}
+	kind := "error"
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &dnsErr) && dnsErr.IsNotFound:
+		kind = "not_found"
+	case errors.As(err, &netErr) && netErr.Timeout():
+		kind = "timeout"
+	case errors.Is(err, syscall.ECONNREFUSED):
+		kind = "refused"
+	}
+	return &RecordedError{Kind: kind, Message: err.Error()}
+}
+
+// missError is returned in replay mode for unrecorded interactions.
+type missError struct{ what string }
+
+func (e *missError) Error() string   { return fmt.Sprintf("replay: %s: %v", e.what, ErrNotRecorded) }
+func (e *missError) Timeout() bool   { return false }
+func (e *missError) Temporary() bool { return false }
+func (e *missError) Unwrap() error   { return ErrNotRecorded }
+
+// Session records or replays interactions.
+type Session struct {
+	mode Mode
+	path string
+
+	mu   sync.Mutex
+	http []*HTTPInteraction
+	dns  []*DNSInteraction
+	tcp  []*TCPInteraction
+
+	// replay cursors: interaction key -> indices and next position
+	httpIndex map[string][]int
+	dnsIndex  map[string][]int
+	tcpIndex  map[string][]int
+	cursor    map[string]int
+}
+
+// NewRecorder returns a session that records into path on Save.
+func NewRecorder(path string) *Session {
+	return &Session{mode: ModeRecord, path: path}
+}
+
+// Load reads a fixture and returns a session that replays it.
+func Load(path string) (*Session, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read replay fixture: %w", err)
+	}
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("parse replay fixture %s: %w", path, err)
+	}
+	if cassette.Version > CassetteVersion {
+		return nil, fmt.Errorf("replay fixture %s has unsupported version %d", path, cassette.Version)
+	}
+	s := NewReplayer(&cassette)
+	s.path = path
+	return s, nil
+}
+
+// NewReplayer returns a session replaying an in-memory cassette.
+func NewReplayer(cassette *Cassette) *Session {
+	s := &Session{
+		mode:      ModeReplay,
+		httpIndex: map[string][]int{},
+		dnsIndex:  map[string][]int{},
+		tcpIndex:  map[string][]int{},
+		cursor:    map[string]int{},
+	}
+	for i := range cassette.HTTP {
+		in := cassette.HTTP[i]
+		s.http = append(s.http, &in)
+		key := httpKey(in.Method, in.URL, in.RequestBody)
+		s.httpIndex[key] = append(s.httpIndex[key], i)
+	}
+	for i := range cassette.DNS {
+		in := cassette.DNS[i]
+		s.dns = append(s.dns, &in)
+		key := dnsKey(in.Type, in.Name)
+		s.dnsIndex[key] = append(s.dnsIndex[key], i)
+	}
+	for i := range cassette.TCP {
+		in := cassette.TCP[i]
+		s.tcp = append(s.tcp, &in)
+		s.tcpIndex[in.Address] = append(s.tcpIndex[in.Address], i)
+	}
+	return s
+}
+
+// Mode returns the session mode, or 0 for a nil session.
+func (s *Session) Mode() Mode {
+	if s == nil {
+		return 0
+	}
+	return s.mode
+}
+
+// Path returns the fixture path.
+func (s *Session) Path() string {
+	if s == nil {
+		return ""
+	}
+	return s.path
+}
+
+// Cassette returns a snapshot of the recorded or loaded interactions.
+func (s *Session) Cassette() *Cassette {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := &Cassette{Version: CassetteVersion, RecordedAt: time.Now().UTC()}
+	for _, in := range s.http {
+		c.HTTP = append(c.HTTP, *in)
+	}
+	for _, in := range s.dns {
+		c.DNS = append(c.DNS, *in)
+	}
+	for _, in := range s.tcp {
+		copied := *in
+		copied.Exchanges = append([]Exchange(nil), in.Exchanges...)
+		c.TCP = append(c.TCP, copied)
+	}
+	return c
+}
+
+// Save writes the recording to the fixture path. It is a no-op outside
+// record mode.
+func (s *Session) Save() error {
+	if s == nil || s.mode != ModeRecord || s.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(s.Cassette(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(s.path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("create fixture directory: %w", err)
+		}
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("write replay fixture: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("write replay fixture: %w", err)
+	}
+	return nil
+}
+
+// next returns the next recorded index for key. Repeated interactions are
+// replayed in recording order; the last one is reused once exhausted.
+// Callers must hold s.mu.
+func (s *Session) next(index map[string][]int, kind, key string) (int, bool) {
+	positions := index[key]
+	if len(positions) == 0 {
+		return 0, false
+	}
+	cursorKey := kind + " " + key
+	pos := s.cursor[cursorKey]
+	if pos >= len(positions) {
+		pos = len(positions) - 1
+	} else {
+		s.cursor[cursorKey] = pos + 1
+	}
+	return positions[pos], true
+}
+
+func dnsKey(qtype, name string) string {
+	name = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+	return qtype + " " + uuidPattern.ReplaceAllString(name, "{uuid}")
+}
@@ -0,0 +1,187 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestSessionRecordsAndReplaysHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "test")
+		w.WriteHeader(http.StatusTeapot)
+		_, _ = w.Write([]byte("brewed " + r.URL.Path))
+	}))
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	recorder := NewRecorder(path)
+	client := recorder.Client(&http.Client{})
+	resp, err := client.Get(server.URL + "/pot/0b6f8c1e-3d7a-4f43-9a2e-5c1d2e3f4a5b")
+	if err != nil {
+		t.Fatalf("record Get() error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err := recorder.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	server.Close()
+
+	player, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	client = player.Client(&http.Client{})
+	// A different random identifier still matches the recorded request.
+	resp, err = client.Get(server.URL + "/pot/9f1e2d3c-4b5a-4697-8877-665544332211")
+	if err != nil {
+		t.Fatalf("replay Get() error = %v", err)
+	}
+	replayed, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTeapot || resp.Header.Get("Server") != "test" || string(replayed) != string(body) {
+		t.Fatalf("replayed %d %q %q, want 418 test %q", resp.StatusCode, resp.Header.Get("Server"), replayed, body)
+	}
+
+	if _, err := client.Get(server.URL + "/missing"); !errors.Is(err, ErrNotRecorded) {
+		t.Fatalf("unrecorded Get() error = %v, want ErrNotRecorded", err)
+	}
+}
+
+func TestSessionRecordingPassesLargeBodiesThrough(t *testing.T) {
+	large := make([]byte, maxRecordedBody+10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(large)
+	}))
+	defer server.Close()
+
+	recorder := NewRecorder(filepath.Join(t.TempDir(), "cassette.json"))
+	resp, err := recorder.Client(&http.Client{}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if len(body) != len(large) {
+		t.Errorf("caller got %d bytes, want all %d", len(body), len(large))
+	}
+	in := recorder.Cassette().HTTP[0]
+	if len(in.Body) != maxRecordedBody || !in.Truncated {
+		t.Errorf("recorded %d bytes, truncated = %v", len(in.Body), in.Truncated)
+	}
+}
+
+func TestSessionReplaysDNSAndTCP(t *testing.T) {
+	cassette := &Cassette{
+		DNS: []DNSInteraction{
+			{Name: "www.example.com.", Addrs: []string{"192.0.2.1"}},
+			{Name: "slow.example.com", Error: &RecordedError{Kind: "timeout", Message: "i/o timeout"}},
+		},
+		TCP: []TCPInteraction{
+			{Address: "192.0.2.1:22", Exchanges: []Exchange{
+				{Dir: "read", Data: Data("SSH-2.0-test\r\n")},
+				{Dir: "write", Data: Data("hello")},
+			}},
+		},
+	}
+	noNetwork := func(context.Context, string) ([]string, error) {
+		t.Fatal("replay session touched the network")
+		return nil, nil
+	}
+	noDial := func(context.Context, string, string) (net.Conn, error) {
+		t.Fatal("replay session touched the network")
+		return nil, nil
+	}
+	s := NewReplayer(cassette)
+	ctx := context.Background()
+
+	if addrs, err := s.LookupHost(ctx, "WWW.example.com", noNetwork); err != nil || len(addrs) != 1 || addrs[0] != "192.0.2.1" {
+		t.Fatalf("LookupHost(www) = %v, %v", addrs, err)
+	}
+	var dnsErr *net.DNSError
+	if _, err := s.LookupHost(ctx, "slow.example.com", noNetwork); !errors.As(err, &dnsErr) || !dnsErr.IsTimeout {
+		t.Fatalf("LookupHost(slow) error = %v, want DNS timeout", err)
+	}
+	if _, err := s.LookupHost(ctx, "nope.example.com", noNetwork); !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+		t.Fatalf("LookupHost(nope) error = %v, want NXDOMAIN", err)
+	}
+
+	conn, err := s.DialContext(ctx, "tcp", "192.0.2.1:22", noDial)
+	if err != nil {
+		t.Fatalf("DialContext() error = %v", err)
+	}
+	if _, err := conn.Write([]byte("hello")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	banner, _ := io.ReadAll(conn)
+	conn.Close()
+	if string(banner) != "SSH-2.0-test\r\n" {
+		t.Fatalf("banner = %q", banner)
+	}
+	if _, err := s.DialContext(ctx, "tcp", "192.0.2.1:23", noDial); err == nil {
+		t.Fatal("DialContext() to unrecorded address succeeded")
+	}
+}
+
+func TestSessionRecordsTCPExchangesAndBinaryData(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		_, _ = conn.Write([]byte{0x00, 0xff, 0x10})
+		conn.Close()
+	}()
+
+	s := NewRecorder("")
+	dialer := net.Dialer{}
+	conn, err := s.DialContext(context.Background(), "tcp", listener.Addr().String(), dialer.DialContext)
+	if err != nil {
+		t.Fatalf("DialContext() error = %v", err)
+	}
+	_, _ = io.ReadAll(conn)
+	conn.Close()
+
+	data, err := json.Marshal(s.Cassette())
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var decoded Cassette
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if len(decoded.TCP) != 1 || len(decoded.TCP[0].Exchanges) != 1 {
+		t.Fatalf("tcp = %#v, want one read exchange", decoded.TCP)
+	}
+	if got := decoded.TCP[0].Exchanges[0].Data; string(got) != "\x00\xff\x10" {
+		t.Fatalf("binary data round-trip = %v", []byte(got))
+	}
+}
+
+func TestNilSessionPassesThrough(t *testing.T) {
+	var s *Session
+	base := &http.Client{}
+	if s.Client(base) != base || s.Transport(nil) != nil {
+		t.Fatal("nil session wrapped the HTTP client")
+	}
+	addrs, err := s.LookupHost(context.Background(), "x", func(context.Context, string) ([]string, error) {
+		return []string{"192.0.2.7"}, nil
+	})
+	if err != nil || len(addrs) != 1 {
+		t.Fatalf("LookupHost() = %v, %v", addrs, err)
+	}
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+}
@@ -0,0 +1,258 @@
+package telemetry
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultDurationBuckets are histogram buckets, in seconds, for module runs.
+var DefaultDurationBuckets = []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600}
+
+// Metrics holds the gospyder metric families and renders them in the
+// Prometheus text exposition format.
+type Metrics struct {
+	DNSLookups     *CounterVec
+	HTTPRequests   *CounterVec
+	OpenPorts      *CounterVec
+	ModuleDuration *HistogramVec
+	Findings       *CounterVec
+
+	families []family
+}
+
+type family interface {
+	write(w io.Writer) error
+}
+
+// NewMetrics creates the metric families exported by gospyder.
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		DNSLookups:     NewCounterVec("gospyder_dns_lookups_total", "DNS lookups by resolver and outcome.", "resolver", "outcome"),
+		HTTPRequests:   NewCounterVec("gospyder_http_requests_total", "HTTP requests by module and response status.", "module", "status"),
+		OpenPorts:      NewCounterVec("gospyder_open_ports_total", "Open ports found by protocol.", "protocol"),
+		ModuleDuration: NewHistogramVec("gospyder_module_duration_seconds", "Module run duration by module and result status.", DefaultDurationBuckets, "module", "status"),
+		Findings:       NewCounterVec("gospyder_findings_total", "Findings reported by module.", "module"),
+	}
+	m.families = []family{m.DNSLookups, m.HTTPRequests, m.OpenPorts, m.ModuleDuration, m.Findings}
+	return m
+}
+
+// Write renders all metric families in the Prometheus text format.
+func (m *Metrics) Write(w io.Writer) error {
+	for _, f := range m.families {
+		if err := f.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handler serves the metrics for Prometheus scrapes.
+func (m *Metrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		m.Write(w)
+	})
+}
+
+// CounterVec is a monotonically increasing counter partitioned by labels.
+type CounterVec struct {
+	name   string
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	values map[string]*counterValue
+}
+
+type counterValue struct {
+	labelValues []string
+	value       float64
+}
+
+// NewCounterVec creates a counter family with the given label names.
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{name: name, help: help, labels: labels, values: map[string]*counterValue{}}
+}
+
+// Inc adds one to the counter for labelValues.
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds v to the counter for labelValues. Negative values are ignored.
+func (c *CounterVec) Add(v float64, labelValues ...string) {
+	if c == nil || v < 0 {
+		return
+	}
+	key := labelKey(labelValues)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cv, ok := c.values[key]
+	if !ok {
+		cv = &counterValue{labelValues: append([]string(nil), labelValues...)}
+		c.values[key] = cv
+	}
+	cv.value += v
+}
+
+// Value returns the current counter value for labelValues.
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cv, ok := c.values[labelKey(labelValues)]; ok {
+		return cv.value
+	}
+	return 0
+}
+
+func (c *CounterVec) write(w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name); err != nil {
+		return err
+	}
+	for _, key := range sortedKeys(c.values) {
+		cv := c.values[key]
+		if _, err := fmt.Fprintf(w, "%s%s %s\n", c.name, formatLabels(c.labels, cv.labelValues, "", ""), formatFloat(cv.value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HistogramVec is a cumulative histogram partitioned by labels.
+type HistogramVec struct {
+	name    string
+	help    string
+	labels  []string
+	buckets []float64
+
+	mu     sync.Mutex
+	values map[string]*histogramValue
+}
+
+type histogramValue struct {
+	labelValues []string
+	counts      []uint64
+	sum         float64
+	count       uint64
+}
+
+// NewHistogramVec creates a histogram family with the given upper bounds.
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+	return &HistogramVec{name: name, help: help, labels: labels, buckets: sorted, values: map[string]*histogramValue{}}
+}
+
+// Observe records v in the histogram for labelValues.
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	if h == nil {
+		return
+	}
+	key := labelKey(labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	hv, ok := h.values[key]
+	if !ok {
+		hv = &histogramValue{labelValues: append([]string(nil), labelValues...), counts: make([]uint64, len(h.buckets))}
+		h.values[key] = hv
+	}
+	for i, upper := range h.buckets {
+		if v <= upper {
+			hv.counts[i]++
+		}
+	}
+	hv.sum += v
+	hv.count++
+}
+
+// Count returns the number of observations recorded for labelValues.
+func (h *HistogramVec) Count(labelValues ...string) uint64 {
+	if h == nil {
+		return 0
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if hv, ok := h.values[labelKey(labelValues)]; ok {
+		return hv.count
+	}
+	return 0
+}
+
+func (h *HistogramVec) write(w io.Writer) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name); err != nil {
+		return err
+	}
+	for _, key := range sortedKeys(h.values) {
+		hv := h.values[key]
+		for i, upper := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(h.labels, hv.labelValues, "le", formatFloat(upper)), hv.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(h.labels, hv.labelValues, "le", "+Inf"), hv.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, formatLabels(h.labels, hv.labelValues, "", ""), formatFloat(hv.sum))
+		if _, err := fmt.Fprintf(w, "%s_count%s %d\n", h.name, formatLabels(h.labels, hv.labelValues, "", ""), hv.count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func labelKey(values []string) string {
+	return strings.Join(values, "\xff")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatLabels renders {name="value",...}, optionally with one extra label
+// such as the histogram "le" bound.
+func formatLabels(names, values []string, extraName, extraValue string) string {
+	var parts []string
+	for i, name := range names {
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		parts = append(parts, fmt.Sprintf("%s=%q", name, escapeLabel(value)))
+	}
+	if extraName != "" {
+		parts = append(parts, fmt.Sprintf("%s=%q", extraName, extraValue))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// escapeLabel strips characters %q would escape differently from the
+// exposition format; label values here are resolvers, modules and statuses.
+func escapeLabel(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, value)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,145 @@
+// Package telemetry provides optional Prometheus metrics and OTLP tracing.
+// Every method is safe to call on a nil *Telemetry, so callers never need to
+// check whether observability was enabled.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Config selects which telemetry outputs are enabled.
+type Config struct {
+	MetricsAddr   string        // listen address for /metrics, e.g. :9090
+	OTLPEndpoint  string        // OTLP/HTTP collector, e.g. http://localhost:4318
+	ServiceName   string        // service.name resource attribute
+	RunID         string        // correlates spans with log records
+	FlushInterval time.Duration // span export interval
+	OnError       func(error)   // receives export and server errors
+}
+
+// Enabled reports whether any telemetry output is configured.
+func (c Config) Enabled() bool {
+	return c.MetricsAddr != "" || c.OTLPEndpoint != ""
+}
+
+// Telemetry bundles the metrics registry, its HTTP endpoint and the tracer.
+type Telemetry struct {
+	Metrics *Metrics
+	Tracer  *Tracer
+
+	listener net.Listener
+	server   *http.Server
+}
+
+// New starts the configured outputs. It returns nil when nothing is enabled.
+func New(cfg Config) (*Telemetry, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+	t := &Telemetry{}
+	if cfg.MetricsAddr != "" {
+		t.Metrics = NewMetrics()
+		ln, err := net.Listen("tcp", cfg.MetricsAddr)
+		if err != nil {
+			return nil, fmt.Errorf("metrics listener: %w", err)
+		}
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", t.Metrics.Handler())
+		t.listener = ln
+		t.server = &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+		go func() {
+			if err := t.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) && cfg.OnError != nil {
+				cfg.OnError(fmt.Errorf("metrics server: %w", err))
+			}
+		}()
+	}
+	if cfg.OTLPEndpoint != "" {
+		service := cfg.ServiceName
+		if service == "" {
+			service = "gospyder"
+		}
+		resource := []Attr{String("service.name", service)}
+		if cfg.RunID != "" {
+			resource = append(resource, String("gospyder.run_id", cfg.RunID))
+		}
+		t.Tracer = NewTracer(cfg.OTLPEndpoint, cfg.FlushInterval, resource, cfg.OnError)
+	}
+	return t, nil
+}
+
+// MetricsAddr returns the address the metrics endpoint listens on.
+func (t *Telemetry) MetricsAddr() string {
+	if t == nil || t.listener == nil {
+		return ""
+	}
+	return t.listener.Addr().String()
+}
+
+// Shutdown flushes pending spans and stops the metrics server.
+func (t *Telemetry) Shutdown(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	err := t.Tracer.Shutdown(ctx)
+	if t.server != nil {
+		if serr := t.server.Shutdown(ctx); err == nil {
+			err = serr
+		}
+	}
+	return err
+}
+
+// StartSpan begins a span; with tracing disabled it returns ctx and a nil span.
+func (t *Telemetry) StartSpan(ctx context.Context, name string, attrs ...Attr) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+	return t.Tracer.Start(ctx, name, attrs...)
+}
+
+// ObserveDNS counts a DNS lookup against resolver with the given outcome
+// (success, nxdomain, timeout, error).
+func (t *Telemetry) ObserveDNS(resolver, outcome string) {
+	if t == nil || t.Metrics == nil {
+		return
+	}
+	t.Metrics.DNSLookups.Inc(resolver, outcome)
+}
+
+// ObserveHTTP counts an HTTP request made by module. A zero status records
+// a transport error.
+func (t *Telemetry) ObserveHTTP(module string, status int) {
+	if t == nil || t.Metrics == nil {
+		return
+	}
+	label := "error"
+	if status > 0 {
+		label = strconv.Itoa(status)
+	}
+	t.Metrics.HTTPRequests.Inc(module, label)
+}
+
+// AddOpenPorts counts n open ports found over protocol (tcp or udp).
+func (t *Telemetry) AddOpenPorts(protocol string, n int) {
+	if t == nil || t.Metrics == nil || n <= 0 {
+		return
+	}
+	t.Metrics.OpenPorts.Add(float64(n), protocol)
+}
+
+// ObserveModule records a finished module run.
+func (t *Telemetry) ObserveModule(module, status string, duration time.Duration, findings int) {
+	if t == nil || t.Metrics == nil {
+		return
+	}
+	t.Metrics.ModuleDuration.Observe(duration.Seconds(), module, status)
+	if findings > 0 {
+		t.Metrics.Findings.Add(float64(findings), module)
+	}
+}
@@ -0,0 +1,166 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type collector struct {
+	mu    sync.Mutex
+	spans []otlpSpan
+	attrs []otlpKeyValue
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" {
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+		return
+	}
+	var payload otlpPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, rs := range payload.ResourceSpans {
+		c.attrs = append(c.attrs, rs.Resource.Attributes...)
+		for _, ss := range rs.ScopeSpans {
+			c.spans = append(c.spans, ss.Spans...)
+		}
+	}
+}
+
+func TestDisabledTelemetryIsNoop(t *testing.T) {
+	tel, err := New(Config{})
+	if err != nil || tel != nil {
+		t.Fatalf("New(Config{}) = %v, %v; want nil, nil", tel, err)
+	}
+
+	ctx, span := tel.StartSpan(context.Background(), "noop")
+	span.SetAttributes(String("k", "v"))
+	span.RecordError(errors.New("boom"))
+	span.End()
+	if SpanFromContext(ctx) != nil {
+		t.Fatal("disabled telemetry stored a span in the context")
+	}
+	tel.ObserveDNS("8.8.8.8", "success")
+	tel.ObserveHTTP("http", 200)
+	tel.AddOpenPorts("tcp", 3)
+	tel.ObserveModule("enum", "success", time.Second, 4)
+
+	client := &http.Client{}
+	if tel.InstrumentClient(client, "http") != client {
+		t.Fatal("InstrumentClient() on disabled telemetry returned a new client")
+	}
+	if err := tel.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+}
+
+func TestSpansExportedToCollector(t *testing.T) {
+	c := &collector{}
+	server := httptest.NewServer(c)
+	defer server.Close()
+
+	tel, err := New(Config{OTLPEndpoint: server.URL, RunID: "run-1", FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx, parent := tel.StartSpan(context.Background(), "module.run", String("module", "enum"))
+	_, child := tel.StartSpan(ctx, "dns.lookup", String("dns.name", "www.example.com"))
+	child.RecordError(errors.New("timeout"))
+	child.End()
+	parent.End()
+
+	if err := tel.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.spans) != 2 {
+		t.Fatalf("collector received %d spans, want 2", len(c.spans))
+	}
+	byName := map[string]otlpSpan{}
+	for _, s := range c.spans {
+		byName[s.Name] = s
+	}
+	run, lookup := byName["module.run"], byName["dns.lookup"]
+	if lookup.TraceID != run.TraceID || lookup.ParentSpanID != run.SpanID {
+		t.Fatalf("dns.lookup not parented to module.run: %+v / %+v", lookup, run)
+	}
+	if lookup.Status.Code != otlpStatusError || lookup.Status.Message != "timeout" {
+		t.Fatalf("dns.lookup status = %+v, want error", lookup.Status)
+	}
+	if run.TraceID != parent.TraceID() {
+		t.Fatalf("trace ID = %s, want %s", run.TraceID, parent.TraceID())
+	}
+
+	found := false
+	for _, a := range c.attrs {
+		if a.Key == "gospyder.run_id" && a.Value["stringValue"] == "run-1" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("resource attributes %+v missing gospyder.run_id", c.attrs)
+	}
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	tel, err := New(Config{MetricsAddr: "127.0.0.1:0"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer tel.Shutdown(context.Background())
+
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer target.Close()
+
+	client := tel.InstrumentClient(&http.Client{}, "fuzz")
+	resp, err := client.Get(target.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	tel.ObserveDNS("1.1.1.1", "nxdomain")
+	tel.AddOpenPorts("tcp", 2)
+	tel.ObserveModule("ports", "success", 1500*time.Millisecond, 2)
+
+	resp, err = http.Get("http://" + tel.MetricsAddr() + "/metrics")
+	if err != nil {
+		t.Fatalf("scrape error = %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	got := string(body)
+
+	for _, want := range []string{
+		"# TYPE gospyder_dns_lookups_total counter",
+		`gospyder_dns_lookups_total{resolver="1.1.1.1",outcome="nxdomain"} 1`,
+		`gospyder_http_requests_total{module="fuzz",status="404"} 1`,
+		`gospyder_open_ports_total{protocol="tcp"} 2`,
+		"# TYPE gospyder_module_duration_seconds histogram",
+		`gospyder_module_duration_seconds_bucket{module="ports",status="success",le="1"} 0`,
+		`gospyder_module_duration_seconds_bucket{module="ports",status="success",le="2.5"} 1`,
+		`gospyder_module_duration_seconds_count{module="ports",status="success"} 1`,
+		`gospyder_findings_total{module="ports"} 2`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("metrics output missing %q:\n%s", want, got)
+		}
+	}
+}
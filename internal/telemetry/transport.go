@@ -0,0 +1,54 @@
+package telemetry
+
+import (
+	"net/http"
+)
+
+// Transport wraps base so every request is counted for module and traced.
+// It returns base unchanged when telemetry is disabled; a nil base means
+// http.DefaultTransport.
+func (t *Telemetry) Transport(base http.RoundTripper, module string) http.RoundTripper {
+	if t == nil {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &instrumentedTransport{base: base, telemetry: t, module: module}
+}
+
+// InstrumentClient returns a shallow copy of client whose transport is
+// instrumented for module, or client itself when telemetry is disabled.
+func (t *Telemetry) InstrumentClient(client *http.Client, module string) *http.Client {
+	if t == nil || client == nil {
+		return client
+	}
+	clone := *client
+	clone.Transport = t.Transport(client.Transport, module)
+	return &clone
+}
+
+type instrumentedTransport struct {
+	base      http.RoundTripper
+	telemetry *Telemetry
+	module    string
+}
+
+func (it *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := it.telemetry.StartSpan(req.Context(), "http.request",
+		String("module", it.module),
+		String("http.method", req.Method),
+		String("http.url", req.URL.String()),
+	)
+	defer span.End()
+
+	resp, err := it.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		span.RecordError(err)
+		it.telemetry.ObserveHTTP(it.module, 0)
+		return nil, err
+	}
+	span.SetAttributes(Int("http.status_code", resp.StatusCode))
+	it.telemetry.ObserveHTTP(it.module, resp.StatusCode)
+	return resp, nil
+}
@@ -29,7 +29,7 @@ type WorkspaceMetadata struct {
 // ModuleState tracks module execution state
 type ModuleState struct {
 	LastRun    time.Time `json:"last_run"`
-	Status     string    `json:"status"` // "success", "error", "pending"
+	Status     string    `json:"status"` // "success", "error", "pending", "running"
 	ResultFile string    `json:"result_file"`
 }
 
@@ -96,6 +96,38 @@ func (w *Workspace) SaveResult(module, filename string, data []byte) (string, er
 	return filePath, w.saveMetadata()
 }
 
+// AppendResult appends lines to a file in the workspace root, creating it
+// when missing. Long-running watchers use it to keep results as they
+// arrive.
+func (w *Workspace) AppendResult(module, filename string, lines ...string) (string, error) {
+	if err := w.Initialize(); err != nil {
+		return "", err
+	}
+
+	filePath := filepath.Join(w.Path, filename)
+	f, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return "", err
+	}
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(f, line); err != nil {
+			f.Close()
+			return "", err
+		}
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	w.Metadata.Modules[module] = ModuleState{
+		LastRun:    time.Now(),
+		Status:     "running",
+		ResultFile: filePath,
+	}
+	w.Metadata.LastUpdated = time.Now()
+	return filePath, w.saveMetadata()
+}
+
 // SanitizeTarget converts a target into a safe workspace directory name.
 func SanitizeTarget(target string) string {
 	target = strings.TrimSpace(target)
@@ -41,3 +41,22 @@ func TestSanitizeTargetFallback(t *testing.T) {
 		t.Fatalf("SanitizeTarget() = %q, want unknown-target", got)
 	}
 }
+
+func TestAppendResultKeepsEarlierLines(t *testing.T) {
+	ws := NewForTarget(t.TempDir(), "example.com")
+	for _, name := range []string{"www.example.com", "api.example.com"} {
+		if _, err := ws.AppendResult("certstream", "certstream.txt", name); err != nil {
+			t.Fatalf("AppendResult() error = %v", err)
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(ws.Path, "certstream.txt"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(data) != "www.example.com\napi.example.com\n" {
+		t.Fatalf("appended data = %q", string(data))
+	}
+	if state := ws.Metadata.Modules["certstream"]; state.Status != "running" {
+		t.Fatalf("module state = %+v, want running", state)
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/internal/registry"
 	"golang.org/x/net/html"
 )
 
@@ -61,6 +63,9 @@ type Crawler struct {
 	urls         []string
 	pagesCrawled int
 	crawlErrors  int
+	fetchErrors  *errors.Tally
+
+	progress registry.Progress
 }
 
 // NewCrawler creates a new crawler instance.
@@ -77,18 +82,33 @@ func NewCrawler(client *http.Client, targetURL string, maxDepth, retries int) (*
 	}
 
 	return &Crawler{
-		client:   client,
-		baseURL:  u,
-		baseHost: u.Host,
-		maxDepth: maxDepth,
-		retries:  retries,
-		visited:  make(map[string]int),
-		params:   make(map[string]bool),
-		apis:     make(map[string]bool),
-		jsFiles:  make(map[string]bool),
+		client:      client,
+		baseURL:     u,
+		baseHost:    u.Host,
+		maxDepth:    maxDepth,
+		retries:     retries,
+		visited:     make(map[string]int),
+		params:      make(map[string]bool),
+		apis:        make(map[string]bool),
+		jsFiles:     make(map[string]bool),
+		progress:    registry.NopProgress,
+		fetchErrors: errors.NewTally("page fetches"),
 	}, nil
 }
 
+// SetProgress routes per-page counters to p.
+func (c *Crawler) SetProgress(p registry.Progress) {
+	if p == nil {
+		p = registry.NopProgress
+	}
+	c.progress = p
+}
+
+// Errors returns page fetch failures summarized by error type.
+func (c *Crawler) Errors() []error {
+	return c.fetchErrors.Errors()
+}
+
 // Crawl starts crawling from the target URL using a worker pool.
 func (c *Crawler) Crawl(ctx context.Context, concurrency int) (*CrawlResult, error) {
 	startURL := c.baseURL.String()
@@ -115,6 +135,7 @@ func (c *Crawler) Crawl(ctx context.Context, concurrency int) (*CrawlResult, err
 	pending.mu.Lock()
 	pending.count = 1
 	pending.mu.Unlock()
+	c.progress.AddTotal(1)
 	taskCh <- pageTask{url: startURL, depth: 0}
 
 	// Monitor completion in background
@@ -152,6 +173,8 @@ func (c *Crawler) Crawl(ctx context.Context, concurrency int) (*CrawlResult, err
 
 // processPage crawls a single page and enqueues new links.
 func (c *Crawler) processPage(ctx context.Context, task pageTask, taskCh chan<- pageTask, pending *pendingCounter) {
+	defer c.progress.Increment(1)
+
 	// Check depth
 	if task.depth > c.maxDepth {
 		return
@@ -172,6 +195,10 @@ func (c *Crawler) processPage(ctx context.Context, task pageTask, taskCh chan<-
 		c.mu.Lock()
 		c.crawlErrors++
 		c.mu.Unlock()
+		c.progress.AddError()
+		if ctx.Err() == nil {
+			c.fetchErrors.Add(err)
+		}
 		return
 	}
 
@@ -202,6 +229,7 @@ func (c *Crawler) processPage(ctx context.Context, task pageTask, taskCh chan<-
 			pending.mu.Lock()
 			pending.count++
 			pending.mu.Unlock()
+			c.progress.AddTotal(1)
 
 			select {
 			case taskCh <- pageTask{url: absURL, depth: task.depth + 1}:
@@ -491,11 +519,17 @@ func unique(slice []string) []string {
 	return result
 }
 
-// Crawl is the top-level convenience function.
-func Crawl(ctx context.Context, client *http.Client, targetURL string, maxDepth, concurrency, retries int) (*CrawlResult, error) {
+// Crawl is the top-level convenience function. Page fetch failures are
+// added to errs.
+func Crawl(ctx context.Context, client *http.Client, targetURL string, maxDepth, concurrency, retries int, progress registry.Progress, errs *errors.Collector) (*CrawlResult, error) {
 	crawler, err := NewCrawler(client, targetURL, maxDepth, retries)
 	if err != nil {
-		return nil, err
+		return nil, errors.NewValidationError(err.Error())
 	}
-	return crawler.Crawl(ctx, concurrency)
-}
\ No newline at end of file
+	crawler.SetProgress(progress)
+	result, err := crawler.Crawl(ctx, concurrency)
+	for _, fetchErr := range crawler.Errors() {
+		errs.Add(fetchErr)
+	}
+	return result, err
+}
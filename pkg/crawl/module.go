@@ -64,7 +64,7 @@ func (m *ModuleAdapter) Run(ctx context.Context, opts registry.Options) (*regist
 	defer cancel()
 
 	start := time.Now()
-	result, err := Crawl(crawlCtx, opts.HTTPClient, target, maxDepth, concurrency, retries)
+	result, err := Crawl(crawlCtx, opts.HTTPClient, target, maxDepth, concurrency, retries, opts.ProgressReporter(), opts.Errors)
 	if err != nil {
 		return nil, fmt.Errorf("crawl failed: %w", err)
 	}
@@ -121,15 +121,15 @@ func (m *ModuleAdapter) Run(ctx context.Context, opts registry.Options) (*regist
 	})
 
 	metadata := map[string]interface{}{
-		"urls_count":       result.Stats.TotalURLs,
-		"params_count":     result.Stats.TotalParams,
-		"apis_count":       result.Stats.TotalAPIs,
-		"js_files_count":   result.Stats.TotalJSFiles,
-		"pages_crawled":    result.Stats.PagesCrawled,
-		"errors":           result.Stats.Errors,
-		"max_depth":        maxDepth,
-		"concurrency":      concurrency,
-		"duration":         duration.Seconds(),
+		"urls_count":     result.Stats.TotalURLs,
+		"params_count":   result.Stats.TotalParams,
+		"apis_count":     result.Stats.TotalAPIs,
+		"js_files_count": result.Stats.TotalJSFiles,
+		"pages_crawled":  result.Stats.PagesCrawled,
+		"errors":         result.Stats.Errors,
+		"max_depth":      maxDepth,
+		"concurrency":    concurrency,
+		"duration":       duration.Seconds(),
 	}
 
 	return &registry.Result{
@@ -140,4 +140,4 @@ func (m *ModuleAdapter) Run(ctx context.Context, opts registry.Options) (*regist
 		Findings:  findings,
 		Metadata:  metadata,
 	}, nil
-}
\ No newline at end of file
+}
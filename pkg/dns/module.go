@@ -0,0 +1,209 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/internal/registry"
+	"github.com/NASHEDIxCODER/gospyder/pkg/resolver"
+)
+
+var (
+	// apexTypes are collected for the scan target.
+	apexTypes = []resolver.RecordType{
+		resolver.TypeA, resolver.TypeAAAA, resolver.TypeMX, resolver.TypeNS, resolver.TypeTXT, resolver.TypeSOA,
+	}
+	// hostTypes are collected for discovered subdomains. CNAMEs arrive with
+	// the address answers.
+	hostTypes = []resolver.RecordType{resolver.TypeA, resolver.TypeAAAA, resolver.TypeMX, resolver.TypeTXT}
+)
+
+// ModuleAdapter collects DNS records for the target and the subdomains
+// found by enum.
+type ModuleAdapter struct{}
+
+// NewModule creates a new DNS record collection module
+func NewModule() registry.Module {
+	return &ModuleAdapter{}
+}
+
+// Name returns the module name
+func (m *ModuleAdapter) Name() string {
+	return "dns"
+}
+
+// Description returns the module description
+func (m *ModuleAdapter) Description() string {
+	return "DNS record collection (A, AAAA, CNAME, MX, NS, TXT, SOA) with SPF, DMARC and verification TXT analysis"
+}
+
+// Run queries the records of the target domain and every subdomain found
+// by a prior enum run.
+func (m *ModuleAdapter) Run(ctx context.Context, opts registry.Options) (*registry.Result, error) {
+	target, ok := opts.Flags["target"].(string)
+	if !ok || target == "" {
+		return nil, fmt.Errorf("target flag required")
+	}
+	apex := strings.ToLower(strings.TrimSuffix(target, "."))
+
+	pool, err := resolver.NewPoolFromConfig(ctx, opts.Config.DNS, opts.Telemetry, opts.Replay)
+	if err != nil {
+		return nil, err
+	}
+	if dropped := pool.Dropped(); len(dropped) > 0 {
+		opts.Logger.Warn("Dropped resolvers answering for nonexistent names: %s", strings.Join(dropped, ", "))
+	}
+
+	names := queryNames(apex, opts)
+	opts.Logger.Info("Collecting DNS records for %d name(s)", len(names))
+	records := collect(ctx, pool, apex, names, opts.Config.Threads, opts.ProgressReporter(), opts.Errors)
+
+	findings := make([]registry.Finding, 0, len(records))
+	txt := map[string][]string{}
+	hosts := 0
+	for _, name := range names {
+		if len(records[name]) > 0 && name != "_dmarc."+apex {
+			hosts++
+		}
+		for _, r := range records[name] {
+			finding := recordFinding(r)
+			if kind, _ := finding.Metadata["txt_kind"].(string); kind != "" {
+				txt[kind] = append(txt[kind], r.Name+": "+r.Value)
+			}
+			findings = append(findings, finding)
+		}
+	}
+
+	metadata := map[string]interface{}{
+		"names_queried":  len(names),
+		"names_resolved": hosts,
+		"resolvers":      pool.Stats(),
+	}
+	for _, kind := range []string{TXTSPF, TXTDMARC, TXTVerification} {
+		if len(txt[kind]) > 0 {
+			metadata[kind] = txt[kind]
+		}
+	}
+
+	return &registry.Result{
+		Module:    m.Name(),
+		Timestamp: time.Now(),
+		Status:    "success",
+		Target:    target,
+		Findings:  findings,
+		Metadata:  metadata,
+	}, nil
+}
+
+// queryNames returns the apex, its DMARC policy name and the enum
+// subdomains, in that order.
+func queryNames(apex string, opts registry.Options) []string {
+	names := []string{apex, "_dmarc." + apex}
+	results, _ := opts.Flags["results"].(map[string]*registry.Result)
+	enum := results["enum"]
+	if enum == nil {
+		return names
+	}
+	seen := map[string]bool{apex: true}
+	var subdomains []string
+	for _, finding := range enum.Findings {
+		name := strings.ToLower(strings.TrimSuffix(finding.Value, "."))
+		if name != "" && !seen[name] {
+			seen[name] = true
+			subdomains = append(subdomains, name)
+		}
+	}
+	sort.Strings(subdomains)
+	return append(names, subdomains...)
+}
+
+// collect queries names concurrently and returns the records found per
+// name. Missing names are expected and not reported as errors.
+func collect(ctx context.Context, pool *resolver.Pool, apex string, names []string, threads int, progress registry.Progress, errs *errors.Collector) map[string][]resolver.Record {
+	if threads <= 0 {
+		threads = 10
+	}
+	queryErrors := errors.NewTally("DNS record queries")
+	defer queryErrors.FlushTo(errs)
+	progress.AddTotal(int64(len(names)))
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, threads)
+	records := map[string][]resolver.Record{}
+	for _, name := range names {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			defer progress.Increment(1)
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-sem }()
+
+			types := hostTypes
+			switch name {
+			case apex:
+				types = apexTypes
+			case "_dmarc." + apex:
+				types = []resolver.RecordType{resolver.TypeTXT}
+			}
+			found, err := pool.Records(ctx, name, types...)
+			if err != nil && errors.TypeOf(err) != errors.ErrorTypeNotFound && ctx.Err() == nil {
+				progress.AddError()
+				queryErrors.Add(err)
+			}
+			if len(found) == 0 {
+				return
+			}
+			for range found {
+				progress.AddFinding()
+			}
+			mu.Lock()
+			records[name] = found
+			mu.Unlock()
+		}(name)
+	}
+	wg.Wait()
+	return records
+}
+
+func recordFinding(r resolver.Record) registry.Finding {
+	metadata := map[string]interface{}{
+		"name":  r.Name,
+		"type":  string(r.Type),
+		"ttl":   r.TTL,
+		"value": r.Value,
+	}
+	if r.Type == resolver.TypeMX {
+		metadata["priority"] = r.Priority
+	}
+	description := ""
+	if r.Type == resolver.TypeTXT {
+		if kind, provider := ClassifyTXT(r.Value); kind != "" {
+			metadata["txt_kind"] = kind
+			description = strings.ToUpper(kind) + " record"
+			if kind == TXTVerification {
+				description = "Domain verification token"
+			}
+			if provider != "" {
+				metadata["provider"] = provider
+				description += " (" + provider + ")"
+			}
+		}
+	}
+	return registry.Finding{
+		Type:        "dns_record",
+		Value:       r.String(),
+		Description: description,
+		Severity:    "info",
+		Metadata:    metadata,
+	}
+}
@@ -0,0 +1,104 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/config"
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/internal/logger"
+	"github.com/NASHEDIxCODER/gospyder/internal/registry"
+	"github.com/NASHEDIxCODER/gospyder/tests/mocks"
+)
+
+func TestModuleCollectsRecordsForTargetAndSubdomains(t *testing.T) {
+	server := mocks.NewDNSServer(t,
+		"example.com 3600 SOA ns1.example.com hostmaster.example.com 1 7200 900 1209600 300",
+		"example.com 3600 NS ns1.example.com",
+		"example.com 300 A 192.0.2.10",
+		"example.com 300 MX 10 mail.example.com",
+		"example.com 300 TXT v=spf1 include:_spf.google.com -all",
+		"example.com 300 TXT google-site-verification=abc123",
+		"_dmarc.example.com 300 TXT v=DMARC1; p=reject",
+		"www.example.com 300 CNAME edge.cdn.net",
+		"edge.cdn.net 60 A 198.51.100.1",
+	)
+	cfg := config.DefaultConfig()
+	cfg.Threads = 4
+	cfg.DNS.Resolvers = []string{server.Addr}
+	enum := &registry.Result{Module: "enum", Findings: []registry.Finding{
+		{Type: "subdomain", Value: "www.example.com"},
+		{Type: "subdomain", Value: "gone.example.com"},
+	}}
+	opts := registry.Options{
+		Config: cfg,
+		Logger: logger.New(false),
+		Errors: errors.NewCollector(),
+		Flags: map[string]interface{}{
+			"target":  "example.com",
+			"results": map[string]*registry.Result{"enum": enum},
+		},
+	}
+
+	result, err := NewModule().Run(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	values := map[string]registry.Finding{}
+	for _, f := range result.Findings {
+		values[f.Value] = f
+	}
+	for _, want := range []string{
+		"example.com 300 A 192.0.2.10",
+		"example.com 300 MX 10 mail.example.com",
+		"example.com 3600 NS ns1.example.com",
+		"example.com 3600 SOA ns1.example.com hostmaster.example.com 1 7200 900 1209600 300",
+		"www.example.com 300 CNAME edge.cdn.net",
+		"edge.cdn.net 60 A 198.51.100.1",
+	} {
+		if _, ok := values[want]; !ok {
+			t.Errorf("missing finding %q in %v", want, result.Findings)
+		}
+	}
+	if mx := values["example.com 300 MX 10 mail.example.com"]; mx.Metadata["priority"] != uint16(10) {
+		t.Errorf("MX metadata = %v", mx.Metadata)
+	}
+	if got := fmt.Sprint(result.Metadata["spf"]); got != "[example.com: v=spf1 include:_spf.google.com -all]" {
+		t.Errorf("spf = %s", got)
+	}
+	if got := fmt.Sprint(result.Metadata["dmarc"]); got != "[_dmarc.example.com: v=DMARC1; p=reject]" {
+		t.Errorf("dmarc = %s", got)
+	}
+	if f := values["example.com 300 TXT google-site-verification=abc123"]; f.Metadata["provider"] != "Google" {
+		t.Errorf("verification finding = %+v", f)
+	}
+	if result.Metadata["names_resolved"] != 2 {
+		t.Errorf("names_resolved = %v, want 2", result.Metadata["names_resolved"])
+	}
+	// NXDOMAIN for gone.example.com is expected, not an error.
+	if errs := opts.Errors.Errors(); len(errs) != 0 {
+		t.Errorf("errors = %v", errs)
+	}
+}
+
+func TestClassifyTXT(t *testing.T) {
+	tests := []struct {
+		value, kind, provider string
+	}{
+		{"v=spf1 -all", TXTSPF, ""},
+		{"v=DMARC1; p=none; rua=mailto:d@example.com", TXTDMARC, ""},
+		{"MS=ms12345678", TXTVerification, "Microsoft"},
+		{"atlassian-domain-verification=xyz", TXTVerification, "Atlassian"},
+		{"acme-verification=token", TXTVerification, ""},
+		{"v=spf10", "", ""},
+		{"hello world", "", ""},
+	}
+	for _, tt := range tests {
+		kind, provider := ClassifyTXT(tt.value)
+		if kind != tt.kind || provider != tt.provider {
+			t.Errorf("ClassifyTXT(%q) = %q, %q; want %q, %q", tt.value, kind, provider, tt.kind, tt.provider)
+		}
+	}
+}
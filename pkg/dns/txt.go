@@ -0,0 +1,54 @@
+package dns
+
+import "strings"
+
+// TXT record kinds reported by ClassifyTXT.
+const (
+	TXTSPF          = "spf"
+	TXTDMARC        = "dmarc"
+	TXTVerification = "verification"
+)
+
+// verificationPrefixes maps domain ownership token prefixes to the service
+// that issued them.
+var verificationPrefixes = []struct {
+	prefix   string
+	provider string
+}{
+	{"google-site-verification=", "Google"},
+	{"ms=", "Microsoft"},
+	{"facebook-domain-verification=", "Facebook"},
+	{"apple-domain-verification=", "Apple"},
+	{"atlassian-domain-verification=", "Atlassian"},
+	{"docusign=", "DocuSign"},
+	{"adobe-idp-site-verification=", "Adobe"},
+	{"stripe-verification=", "Stripe"},
+	{"zoom-domain-verification", "Zoom"},
+	{"slack-domain-verification=", "Slack"},
+	{"globalsign-domain-verification=", "GlobalSign"},
+	{"amazonses:", "Amazon SES"},
+	{"have-i-been-pwned-verification=", "Have I Been Pwned"},
+	{"_github-challenge", "GitHub"},
+}
+
+// ClassifyTXT reports whether a TXT value is an SPF policy, a DMARC policy
+// or a domain verification token. provider names the issuing service of a
+// verification token. kind is empty for other records.
+func ClassifyTXT(value string) (kind, provider string) {
+	lower := strings.ToLower(strings.TrimSpace(value))
+	switch {
+	case lower == "v=spf1" || strings.HasPrefix(lower, "v=spf1 "):
+		return TXTSPF, ""
+	case strings.HasPrefix(lower, "v=dmarc1"):
+		return TXTDMARC, ""
+	}
+	for _, v := range verificationPrefixes {
+		if strings.HasPrefix(lower, v.prefix) {
+			return TXTVerification, v.provider
+		}
+	}
+	if strings.Contains(lower, "-verification=") {
+		return TXTVerification, ""
+	}
+	return "", ""
+}
@@ -7,16 +7,21 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/internal/registry"
 	"github.com/NASHEDIxCODER/gospyder/pkg/models"
 	"github.com/NASHEDIxCODER/gospyder/pkg/resolver"
 )
 
-func BruteForce(ctx context.Context, pool *resolver.Pool, target string, wordlist string) (<-chan models.Domain, error) {
+// BruteForce resolves wordlist entries under target. Hits answered only by a
+// wildcard record are discarded. Failed lookups other than NXDOMAIN are
+// counted in errs.
+func BruteForce(ctx context.Context, pool *resolver.Pool, target string, wordlist string, wildcards *Wildcards, progress registry.Progress, errs *errors.Tally) (<-chan models.Domain, error) {
 	out := make(chan models.Domain, 100)
 
 	file, err := os.Open(wordlist)
 	if err != nil {
-		return nil, err
+		return nil, errors.NewIOError("open wordlist", err)
 	}
 
 	go func() {
@@ -27,10 +32,11 @@ func BruteForce(ctx context.Context, pool *resolver.Pool, target string, wordlis
 		var wg sync.WaitGroup
 		sem := make(chan struct{}, 500)
 
+	scan:
 		for scanner.Scan() {
 			select {
 			case <-ctx.Done():
-				return
+				break scan
 			default:
 			}
 
@@ -40,6 +46,7 @@ func BruteForce(ctx context.Context, pool *resolver.Pool, target string, wordlis
 			}
 
 			fullDomain := sub + "." + target
+			progress.AddTotal(1)
 			wg.Add(1)
 
 			go func(domain string) {
@@ -52,18 +59,31 @@ func BruteForce(ctx context.Context, pool *resolver.Pool, target string, wordlis
 				}
 				defer func() { <-sem }()
 
-				_, err := pool.Lookup(ctx, domain)
-				if err == nil {
-					out <- models.Domain{
-						Name:   domain,
-						Source: "brute",
+				ips, err := pool.Lookup(ctx, domain)
+				progress.Increment(1)
+				recordLookupError(ctx, errs, err)
+				if err == nil && !wildcards.IsWildcard(ctx, domain, ips) {
+					select {
+					case out <- models.Domain{Name: domain, Source: "brute"}:
+					case <-ctx.Done():
 					}
 				}
 			}(fullDomain)
 		}
 
+		// Workers may still be sending; out closes only after them.
 		wg.Wait()
 	}()
 
 	return out, nil
 }
+
+// recordLookupError counts failures that indicate a resolver problem.
+// NXDOMAIN is the expected answer for most candidates, and lookups cut short
+// by ctx are not failures of the resolver.
+func recordLookupError(ctx context.Context, errs *errors.Tally, err error) {
+	if err == nil || ctx.Err() != nil || errors.TypeOf(err) == errors.ErrorTypeNotFound {
+		return
+	}
+	errs.Add(err)
+}
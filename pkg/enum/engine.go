@@ -2,10 +2,14 @@ package enum
 
 import (
 	"context"
-	"log"
+	"sort"
+	"strings"
 	"sync"
-	"time"
 
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/internal/logger"
+	"github.com/NASHEDIxCODER/gospyder/internal/registry"
+	"github.com/NASHEDIxCODER/gospyder/internal/telemetry"
 	"github.com/NASHEDIxCODER/gospyder/pkg/models"
 	"github.com/NASHEDIxCODER/gospyder/pkg/resolver"
 	"github.com/NASHEDIxCODER/gospyder/pkg/sources"
@@ -17,21 +21,182 @@ const (
 	ModePassive EnumMode = iota
 	ModeActive
 	ModeBoth
+	// ModePermute resolves permutations of the names passive sources
+	// report; SetPermuter adds permutation to the other modes.
+	ModePermute
 )
 
 type Engine struct {
-	pool    *resolver.Pool
-	threads int
-	seen    sync.Map
+	pool      *resolver.Pool
+	threads   int
+	sources   []sources.Source
+	permuter  *Permuter
+	permuted  int
+	seeds     []string
+	seedFrom  string
+	depth     int
+	recursed  []string
+	found     map[string][]string // name -> techniques that reported it
+	foundMu   sync.Mutex
+	progress  registry.Progress
+	logger    *logger.Logger
+	tel       *telemetry.Telemetry
+	errs      *errors.Collector
+	lookups   *errors.Tally
+	wildcards *Wildcards
+	zone      *ZoneReport
+	nsPort    string
 }
 
 func NewEngine(pool *resolver.Pool, threads int) *Engine {
 	return &Engine{
-		pool:    pool,
-		threads: threads,
+		pool:      pool,
+		threads:   threads,
+		found:     map[string][]string{},
+		progress:  registry.NopProgress,
+		lookups:   errors.NewTally("DNS lookups"),
+		wildcards: NewWildcards(pool),
+		depth:     DefaultRecursionDepth,
+		zone:      &ZoneReport{},
+		nsPort:    "53",
 	}
 }
 
+// Zone returns what the target's nameservers revealed during active
+// enumeration.
+func (e *Engine) Zone() *ZoneReport {
+	return e.zone
+}
+
+// Source returns the technique that first found name, e.g. "brute" or
+// "axfr".
+func (e *Engine) Source(name string) string {
+	if found := e.Sources(name); len(found) > 0 {
+		return found[0]
+	}
+	return ""
+}
+
+// Sources returns every technique and passive source that reported name,
+// in the order they did.
+func (e *Engine) Sources(name string) []string {
+	e.foundMu.Lock()
+	defer e.foundMu.Unlock()
+	return append([]string(nil), e.found[name]...)
+}
+
+// add records d and reports whether no earlier technique had found it.
+func (e *Engine) add(d models.Domain) bool {
+	e.foundMu.Lock()
+	found, loaded := e.found[d.Name]
+	known := false
+	for _, source := range found {
+		known = known || source == d.Source
+	}
+	if !known {
+		e.found[d.Name] = append(found, d.Source)
+	}
+	e.foundMu.Unlock()
+	if loaded {
+		return false
+	}
+	e.logger.With("source", d.Source).Info("Found: %s", d.Name)
+	e.progress.AddFinding()
+	return true
+}
+
+// names returns every name found so far.
+func (e *Engine) names() []string {
+	e.foundMu.Lock()
+	defer e.foundMu.Unlock()
+	names := make([]string, 0, len(e.found))
+	for name := range e.found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// namesUnder counts the names found below zone.
+func (e *Engine) namesUnder(zone string) int {
+	e.foundMu.Lock()
+	defer e.foundMu.Unlock()
+	n := 0
+	for name := range e.found {
+		if strings.HasSuffix(name, "."+zone) {
+			n++
+		}
+	}
+	return n
+}
+
+// recordZone notes that recursion brute-forced zone.
+func (e *Engine) recordZone(zone string) {
+	e.recursed = append(e.recursed, zone)
+}
+
+// RecursedZones returns the sub-zones recursion brute-forced, in order.
+func (e *Engine) RecursedZones() []string {
+	return e.recursed
+}
+
+// SetDepth sets how many labels below the target active mode recurses;
+// zero disables recursion.
+func (e *Engine) SetDepth(depth int) {
+	e.depth = depth
+}
+
+// Wildcards returns the wildcard detector used by active enumeration.
+func (e *Engine) Wildcards() *Wildcards {
+	return e.wildcards
+}
+
+// SetSources sets the passive sources queried in passive mode.
+func (e *Engine) SetSources(s []sources.Source) {
+	e.sources = s
+}
+
+// SetPermuter makes Run permute the names every technique found, in any
+// mode. Permute mode without one uses the default words and patterns.
+func (e *Engine) SetPermuter(p *Permuter) {
+	e.permuter = p
+}
+
+// SetSeeds adds names other modules found, such as the DNS names in TLS
+// certificates, to be resolved in every mode and credited to source.
+func (e *Engine) SetSeeds(source string, names []string) {
+	e.seedFrom = source
+	e.seeds = names
+}
+
+// Permutations returns how many candidates permute mode generated.
+func (e *Engine) Permutations() int {
+	return e.permuted
+}
+
+// SetErrors records source and resolver failures into c.
+func (e *Engine) SetErrors(c *errors.Collector) {
+	e.errs = c
+}
+
+// SetLogger routes engine log output to l. A nil logger discards it.
+func (e *Engine) SetLogger(l *logger.Logger) {
+	e.logger = l
+}
+
+// SetTelemetry records spans for passive sources to t. A nil t disables them.
+func (e *Engine) SetTelemetry(t *telemetry.Telemetry) {
+	e.tel = t
+}
+
+// SetProgress routes lookup and finding counters to p.
+func (e *Engine) SetProgress(p registry.Progress) {
+	if p == nil {
+		p = registry.NopProgress
+	}
+	e.progress = p
+}
+
 func (e *Engine) Run(ctx context.Context, target string, wordlist string, mode EnumMode) []string {
 	var results []string
 
@@ -39,10 +204,20 @@ func (e *Engine) Run(ctx context.Context, target string, wordlist string, mode E
 	case ModePassive:
 		results = e.runPassive(ctx, target)
 	case ModeActive:
-		results = e.runActive(ctx, target, wordlist)
+		results = append(e.runZone(ctx, target), e.runActive(ctx, target, wordlist)...)
 	case ModeBoth:
-		results = append(e.runPassive(ctx, target), e.runActive(ctx, target, wordlist)...)
+		results = append(e.runPassive(ctx, target), e.runZone(ctx, target)...)
+		results = append(results, e.runActive(ctx, target, wordlist)...)
+	case ModePermute:
+		results = e.runPassive(ctx, target)
 	}
+	results = append(results, e.runSeeds(ctx, target)...)
+	if mode == ModePermute || e.permuter != nil {
+		// Permutations build on what every technique found so far.
+		results = append(results, e.runPermute(ctx, target, e.names())...)
+	}
+
+	e.lookups.FlushTo(e.errs)
 
 	unique := []string{}
 	seen := make(map[string]bool)
@@ -55,90 +230,131 @@ func (e *Engine) Run(ctx context.Context, target string, wordlist string, mode E
 	return unique
 }
 
+// runPassive queries every passive source concurrently.
 func (e *Engine) runPassive(ctx context.Context, target string) []string {
-	log.Println("[*] Passive: Connecting to CertStream...")
-	client, err := sources.NewCertStream()
-	if err != nil {
-		log.Printf("[!] CertStream unavailable: %v", err)
+	if len(e.sources) == 0 {
+		e.logger.Warn("Passive: no sources configured")
 		return []string{}
 	}
+	names := make([]string, len(e.sources))
+	for i, source := range e.sources {
+		names[i] = source.Name()
+	}
+	e.logger.Info("Passive: querying %s", strings.Join(names, ", "))
 
 	domains := make(chan models.Domain, 100)
+	var wg sync.WaitGroup
+	for _, source := range e.sources {
+		wg.Add(1)
+		go func(source sources.Source) {
+			defer wg.Done()
+			spanCtx, span := e.tel.StartSpan(ctx, "passive.source", telemetry.String("source", source.Name()))
+			err := source.Enumerate(spanCtx, target, domains)
+			span.RecordError(err)
+			span.End()
+			if err != nil && ctx.Err() == nil {
+				e.logger.Warn("Passive source %s failed: %v", source.Name(), err)
+				e.errs.Add(errors.Wrap(source.Name()+" failed", err))
+			}
+		}(source)
+	}
 	go func() {
-		client.Watch(ctx, target, domains)
+		wg.Wait()
 		close(domains)
 	}()
 
 	var results []string
 	for domain := range domains {
-		if _, loaded := e.seen.LoadOrStore(domain.Name, true); !loaded {
-			log.Printf("[PASSIVE] Found: %s", domain.Name)
+		if e.add(domain) {
 			results = append(results, domain.Name)
 		}
 	}
 	return results
 }
 
-func (e *Engine) runActive(ctx context.Context, target string, wordlist string) []string {
-	log.Println("[*] Active: Starting brute-force...")
-	stream, err := BruteForce(ctx, e.pool, target, wordlist)
-	if err != nil {
-		log.Printf("[!] Brute-force error: %v", err)
+// runPermute resolves permutations of seeds, keeping names no other
+// technique found.
+func (e *Engine) runPermute(ctx context.Context, target string, seeds []string) []string {
+	if e.permuter == nil {
+		p, err := NewPermuter(nil, nil, 0)
+		if err != nil {
+			e.errs.Add(err)
+			return []string{}
+		}
+		e.permuter = p
+	}
+	if len(seeds) == 0 {
+		e.logger.Warn("Permute: no names to permute")
 		return []string{}
 	}
+	if wildcard := e.wildcards.Detect(ctx, target); wildcard != nil {
+		e.logger.Warn("Wildcard DNS on %s; filtering matching results", wildcard)
+	}
+
+	candidates := e.permuter.Generate(seeds, target)
+	e.permuted = len(candidates)
+	e.logger.Info("Permute: resolving %d candidates from %d names", len(candidates), len(seeds))
 
 	var results []string
-	var recursiveResults []string
-	var recursiveMu sync.Mutex
-	var recursiveWG sync.WaitGroup
-	var collectorWG sync.WaitGroup
-	recChan := make(chan string, 100)
+	for domain := range ResolvePermutations(ctx, e.pool, candidates, e.threads, e.wildcards, e.progress, e.lookups) {
+		if e.add(domain) {
+			results = append(results, domain.Name)
+		}
+	}
+	return results
+}
 
-	collectorWG.Add(1)
-	go func() {
-		defer collectorWG.Done()
-		for recDomain := range recChan {
-			recursiveMu.Lock()
-			recursiveResults = append(recursiveResults, recDomain)
-			recursiveMu.Unlock()
+// runSeeds resolves the seed names below target that no technique found.
+func (e *Engine) runSeeds(ctx context.Context, target string) []string {
+	var candidates []string
+	seen := map[string]bool{}
+	for _, name := range e.seeds {
+		name = strings.TrimSuffix(strings.ToLower(name), ".")
+		if !seen[name] && strings.HasSuffix(name, "."+target) && len(e.Sources(name)) == 0 {
+			seen[name] = true
+			candidates = append(candidates, name)
 		}
-	}()
+	}
+	if len(candidates) == 0 {
+		return []string{}
+	}
+	e.logger.Info("Seeds: resolving %d names from %s", len(candidates), e.seedFrom)
 
-	for domain := range stream {
-		if _, loaded := e.seen.LoadOrStore(domain.Name, true); !loaded {
-			log.Printf("[ACTIVE] Found: %s", domain.Name)
+	var results []string
+	for domain := range ResolvePermutations(ctx, e.pool, candidates, e.threads, e.wildcards, e.progress, e.lookups) {
+		domain.Source = e.seedFrom
+		if e.add(domain) {
 			results = append(results, domain.Name)
-
-			recursiveWG.Add(1)
-			go func(d string) {
-				defer recursiveWG.Done()
-				e.runRecursive(ctx, d, recChan)
-			}(domain.Name)
 		}
 	}
-
-	recursiveWG.Wait()
-	close(recChan)
-	collectorWG.Wait()
-
-	results = append(results, recursiveResults...)
 	return results
 }
 
-func (e *Engine) runRecursive(ctx context.Context, foundDomain string, out chan<- string) {
-	recCtx, cancel := context.WithTimeout(ctx, 1500*time.Millisecond)
-	defer cancel()
+func (e *Engine) runActive(ctx context.Context, target string, wordlist string) []string {
+	if wildcard := e.wildcards.Detect(ctx, target); wildcard != nil {
+		e.logger.Warn("Wildcard DNS on %s; filtering matching results", wildcard)
+	}
 
-	recStream, err := Recursive(recCtx, e.pool, foundDomain)
+	e.logger.Info("Active: starting brute-force")
+	stream, err := BruteForce(ctx, e.pool, target, wordlist, e.wildcards, e.progress, e.lookups)
 	if err != nil {
-		log.Printf("[!] Recursive error: %v", err)
-		return
+		e.logger.Error("Brute-force error: %v", err)
+		e.errs.Add(err)
+		return []string{}
 	}
 
-	for recDomain := range recStream {
-		if _, loaded := e.seen.LoadOrStore(recDomain, true); !loaded {
-			log.Printf("[RECURSIVE] Found: %s", recDomain)
-			out <- recDomain
+	var results []string
+	for domain := range stream {
+		if e.add(domain) {
+			results = append(results, domain.Name)
 		}
 	}
+	return append(results, e.runRecursive(ctx, target, wordlist)...)
+}
+
+func parentZone(name string) string {
+	if dot := strings.IndexByte(name, '.'); dot >= 0 {
+		return name[dot+1:]
+	}
+	return ""
 }
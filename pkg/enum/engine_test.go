@@ -0,0 +1,150 @@
+package enum
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/internal/replay"
+	"github.com/NASHEDIxCODER/gospyder/pkg/models"
+	"github.com/NASHEDIxCODER/gospyder/pkg/resolver"
+	"github.com/NASHEDIxCODER/gospyder/pkg/sources"
+)
+
+// fakeSource reports fixed names, then fails with err when set.
+type fakeSource struct {
+	name  string
+	names []string
+	err   error
+}
+
+func (s *fakeSource) Name() string { return s.name }
+
+func (s *fakeSource) Enumerate(ctx context.Context, domain string, out chan<- models.Domain) error {
+	for _, name := range s.names {
+		out <- models.Domain{Name: name, Source: s.name}
+	}
+	return s.err
+}
+
+func TestRunPassiveMergesSources(t *testing.T) {
+	engine := NewEngine(nil, 10)
+	errs := errors.NewCollector()
+	engine.SetErrors(errs)
+	engine.SetSources([]sources.Source{
+		&fakeSource{name: "crtsh", names: []string{"www.example.com", "api.example.com"}},
+		&fakeSource{name: "wayback", names: []string{"www.example.com"}, err: fmt.Errorf("HTTP 503")},
+	})
+
+	found := engine.runPassive(context.Background(), "example.com")
+	sort.Strings(found)
+	if fmt.Sprint(found) != "[api.example.com www.example.com]" {
+		t.Fatalf("runPassive() = %v", found)
+	}
+	got := engine.Sources("www.example.com")
+	sort.Strings(got)
+	if fmt.Sprint(got) != "[crtsh wayback]" {
+		t.Fatalf("Sources(www) = %v, want both sources", got)
+	}
+	if errs.Count() != 1 {
+		t.Fatalf("errors = %v, want the wayback failure", errs.Errors())
+	}
+}
+
+func TestRunActiveRecursesByPriorityToDepth(t *testing.T) {
+	pool := resolver.NewPool([]string{"192.0.2.53"})
+	pool.SetReplay(replay.NewReplayer(&replay.Cassette{
+		DNS: []replay.DNSInteraction{
+			{Name: "www.example.com", Addrs: []string{"192.0.2.50"}},
+			{Name: "api.example.com", Addrs: []string{"192.0.2.60"}},
+			{Name: "www.api.example.com", Addrs: []string{"192.0.2.61"}},
+			{Name: "api.www.api.example.com", Addrs: []string{"192.0.2.62"}},
+			// A delegated zone is brute-forced ahead of its siblings.
+			{Type: "NS", Name: "www.example.com", Answers: []string{"www.example.com 300 NS ns1.example.net"}},
+		},
+	}))
+	wordlist := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(wordlist, []byte("api\nwww\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	engine := NewEngine(pool, 10)
+	engine.SetDepth(2)
+	found := engine.runActive(context.Background(), "example.com", wordlist)
+	sort.Strings(found)
+	if fmt.Sprint(found) != "[api.example.com api.www.api.example.com www.api.example.com www.example.com]" {
+		t.Fatalf("runActive() = %v", found)
+	}
+	if got := engine.Source("www.api.example.com"); got != "recursive" {
+		t.Fatalf("Source(www.api) = %q, want recursive", got)
+	}
+	if got := fmt.Sprint(engine.RecursedZones()); got != "[www.example.com api.example.com www.api.example.com]" {
+		t.Fatalf("RecursedZones() = %s", got)
+	}
+
+	shallow := NewEngine(pool, 10)
+	shallow.SetDepth(0)
+	if found := shallow.runActive(context.Background(), "example.com", wordlist); len(found) != 2 || len(shallow.RecursedZones()) != 0 {
+		t.Fatalf("depth 0: found %v, recursed %v", found, shallow.RecursedZones())
+	}
+}
+
+func TestRunResolvesSeedsBelowTarget(t *testing.T) {
+	pool := resolver.NewPool([]string{"192.0.2.53"})
+	pool.SetReplay(replay.NewReplayer(&replay.Cassette{
+		DNS: []replay.DNSInteraction{
+			{Name: "www.example.com", Addrs: []string{"192.0.2.50"}},
+			{Name: "shop.example.com", Addrs: []string{"192.0.2.51"}},
+			{Name: "www.example.org", Addrs: []string{"192.0.2.52"}},
+		},
+	}))
+	engine := NewEngine(pool, 10)
+	engine.SetSources([]sources.Source{&fakeSource{name: "crtsh", names: []string{"www.example.com"}}})
+	engine.SetSeeds("tls", []string{"www.example.com", "Shop.Example.com.", "shop.example.com", "gone.example.com", "www.example.org"})
+
+	found := engine.Run(context.Background(), "example.com", "", ModePassive)
+	sort.Strings(found)
+	if fmt.Sprint(found) != "[shop.example.com www.example.com]" {
+		t.Fatalf("Run() = %v", found)
+	}
+	if got := fmt.Sprint(engine.Sources("shop.example.com")); got != "[tls]" {
+		t.Fatalf("Sources(shop) = %s, want tls", got)
+	}
+	if got := fmt.Sprint(engine.Sources("www.example.com")); got != "[crtsh]" {
+		t.Fatalf("Sources(www) = %s, want the seed skipped", got)
+	}
+}
+
+func TestRunPermutesActiveResults(t *testing.T) {
+	pool := resolver.NewPool([]string{"192.0.2.53"})
+	pool.SetReplay(replay.NewReplayer(&replay.Cassette{
+		DNS: []replay.DNSInteraction{
+			{Name: "api.example.com", Addrs: []string{"192.0.2.60"}},
+			{Name: "dev-api.example.com", Addrs: []string{"192.0.2.61"}},
+		},
+	}))
+	wordlist := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(wordlist, []byte("api\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	permuter, err := NewPermuter([]string{"dev"}, []string{"{{word}}-{{sub}}"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine := NewEngine(pool, 10)
+	engine.SetDepth(0)
+	engine.SetPermuter(permuter)
+	found := engine.Run(context.Background(), "example.com", wordlist, ModeActive)
+	sort.Strings(found)
+	if fmt.Sprint(found) != "[api.example.com dev-api.example.com]" {
+		t.Fatalf("Run() = %v, want the brute-force hit permuted", found)
+	}
+	if got := engine.Source("dev-api.example.com"); got != "permute" {
+		t.Fatalf("Source(dev-api) = %q, want permute", got)
+	}
+}
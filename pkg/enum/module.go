@@ -1,32 +1,33 @@
 package enum
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
 	"github.com/NASHEDIxCODER/gospyder/internal/registry"
+	"github.com/NASHEDIxCODER/gospyder/internal/workspace"
 	"github.com/NASHEDIxCODER/gospyder/pkg/resolver"
+	"github.com/NASHEDIxCODER/gospyder/pkg/sources"
 )
 
+// SeedFile is the workspace file the DNS names of the tls module's
+// certificates are appended to, one per line, for later runs to resolve.
+const SeedFile = "tls-names.txt"
+
 // ModuleAdapter wraps enum functionality as a Module
-type ModuleAdapter struct {
-	pool *resolver.Pool
-}
+type ModuleAdapter struct{}
 
 // NewModule creates a new subdomain enumeration module
 func NewModule() registry.Module {
-	// Initialize with default resolver pool
-	pool := resolver.NewPool([]string{
-		"8.8.8.8",
-		"8.8.4.4",
-		"1.1.1.1",
-		"1.0.0.1",
-	})
-
-	return &ModuleAdapter{
-		pool: pool,
-	}
+	return &ModuleAdapter{}
 }
 
 // Name returns the module name
@@ -36,7 +37,7 @@ func (m *ModuleAdapter) Name() string {
 
 // Description returns the module description
 func (m *ModuleAdapter) Description() string {
-	return "Subdomain enumeration via active DNS brute-force and passive Certificate Transparency"
+	return "Subdomain enumeration via active DNS brute-force, permutations and passive sources (crt.sh, Wayback, Common Crawl, passive DNS, CertStream)"
 }
 
 // Run executes subdomain enumeration.
@@ -63,7 +64,45 @@ func (m *ModuleAdapter) Run(ctx context.Context, opts registry.Options) (*regist
 	}
 
 	opts.Logger.Debug("Starting subdomain enumeration for %s", target)
-	engine := NewEngine(m.pool, opts.Config.Threads)
+	pool, err := resolver.NewPoolFromConfig(ctx, opts.Config.DNS, opts.Telemetry, opts.Replay)
+	if err != nil {
+		return nil, err
+	}
+	if dropped := pool.Dropped(); len(dropped) > 0 {
+		opts.Logger.Warn("Dropped resolvers answering for nonexistent names: %s", strings.Join(dropped, ", "))
+	}
+	engine := NewEngine(pool, opts.Config.Threads)
+	var passive []sources.Source
+	if mode != ModeActive {
+		if passive, err = passiveSources(opts); err != nil {
+			return nil, err
+		}
+		engine.SetSources(passive)
+	}
+	permute, _ := opts.Flags["permute"].(bool)
+	permute = permute || mode == ModePermute
+	if permute {
+		wordsFile, _ := opts.Flags["permute-words"].(string)
+		patternsFile, _ := opts.Flags["permute-patterns"].(string)
+		limit, _ := opts.Flags["permute-max"].(int)
+		permuter, err := LoadPermuter(wordsFile, patternsFile, limit)
+		if err != nil {
+			return nil, err
+		}
+		engine.SetPermuter(permuter)
+	}
+	seeds, err := seedNames(opts, target)
+	if err != nil {
+		return nil, err
+	}
+	engine.SetSeeds("tls", seeds)
+	if depth, ok := opts.Flags["depth"].(int); ok {
+		engine.SetDepth(depth)
+	}
+	engine.SetProgress(opts.ProgressReporter())
+	engine.SetLogger(opts.Logger)
+	engine.SetTelemetry(opts.Telemetry)
+	engine.SetErrors(opts.Errors)
 	subdomains := engine.Run(ctx, target, wordlist, mode)
 
 	findings := make([]registry.Finding, 0, len(subdomains))
@@ -72,8 +111,39 @@ func (m *ModuleAdapter) Run(ctx context.Context, opts registry.Options) (*regist
 			Type:     "subdomain",
 			Value:    subdomain,
 			Severity: "info",
+			Metadata: map[string]interface{}{
+				"source":  engine.Source(subdomain),
+				"sources": engine.Sources(subdomain),
+			},
 		})
 	}
+	annotateHosts(ctx, pool, findings, opts.Config.Threads)
+
+	metadata := map[string]interface{}{
+		"wordlist":  wordlist,
+		"mode":      modeStr,
+		"resolvers": pool.Stats(),
+	}
+	if len(passive) > 0 {
+		names := make([]string, len(passive))
+		for i, source := range passive {
+			names[i] = source.Name()
+		}
+		metadata["passive_sources"] = names
+	}
+	if zones := engine.RecursedZones(); len(zones) > 0 {
+		metadata["recursed_zones"] = zones
+	}
+	if permute {
+		metadata["permutations"] = engine.Permutations()
+	}
+	if wildcards := engine.Wildcards().Found(); len(wildcards) > 0 {
+		metadata["wildcards"] = wildcards
+		metadata["wildcard_filtered"] = engine.Wildcards().Filtered()
+	}
+	if zone := engine.Zone(); !zone.Empty() {
+		metadata["zone"] = zone
+	}
 
 	return &registry.Result{
 		Module:    m.Name(),
@@ -81,13 +151,96 @@ func (m *ModuleAdapter) Run(ctx context.Context, opts registry.Options) (*regist
 		Status:    "success",
 		Target:    target,
 		Findings:  findings,
-		Metadata: map[string]interface{}{
-			"wordlist": wordlist,
-			"mode":     modeStr,
-		},
+		Metadata:  metadata,
 	}, nil
 }
 
+// seedNames returns the certificate names a tls run before this one
+// found, and those earlier runs saved in the target's workspace.
+func seedNames(opts registry.Options, target string) ([]string, error) {
+	var names []string
+	results, _ := opts.Flags["results"].(map[string]*registry.Result)
+	if tls := results["tls"]; tls != nil {
+		sans, _ := tls.Metadata["san_names"].([]string)
+		names = append(names, sans...)
+	}
+	path := filepath.Join(opts.Config.Workspace.Path, workspace.SanitizeTarget(target), SeedFile)
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return names, nil
+	}
+	if err != nil {
+		return nil, errors.NewIOError("open "+path, err)
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			names = append(names, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.NewIOError("read "+path, err)
+	}
+	return names, nil
+}
+
+// passiveSources creates the sources selected by the config, as overridden
+// by the "sources" and "import" flags.
+func passiveSources(opts registry.Options) ([]sources.Source, error) {
+	cfg := opts.Config.Sources
+	if list, _ := opts.Flags["sources"].(string); list != "" {
+		cfg.Enabled = nil
+		for _, name := range strings.Split(list, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				cfg.Enabled = append(cfg.Enabled, name)
+			}
+		}
+	}
+	if path, _ := opts.Flags["import"].(string); path != "" {
+		cfg.Import = path
+	}
+	client := &http.Client{
+		Timeout:   opts.Config.HTTP.Timeout,
+		Transport: opts.Telemetry.Transport(opts.Replay.Transport(nil), "enum"),
+	}
+	return sources.DefaultRegistry().FromConfig(cfg, client)
+}
+
+// annotateHosts adds the addresses and CNAME chain of each subdomain to
+// its finding metadata. Names that no longer resolve are left bare.
+func annotateHosts(ctx context.Context, pool *resolver.Pool, findings []registry.Finding, threads int) {
+	if threads <= 0 {
+		threads = 10
+	}
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, threads)
+	for i := range findings {
+		wg.Add(1)
+		go func(f *registry.Finding) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-sem }()
+
+			host, err := pool.Resolve(ctx, f.Value)
+			if err != nil {
+				return
+			}
+			if len(host.IPs) > 0 {
+				f.Metadata["ips"] = host.IPs
+			}
+			if len(host.CNAMEs) > 0 {
+				f.Metadata["cname_chain"] = host.CNAMEs
+			}
+		}(&findings[i])
+	}
+	wg.Wait()
+}
+
 func enumMode(mode string) (EnumMode, error) {
 	switch mode {
 	case "active":
@@ -96,6 +249,8 @@ func enumMode(mode string) (EnumMode, error) {
 		return ModePassive, nil
 	case "both":
 		return ModeBoth, nil
+	case "permute":
+		return ModePermute, nil
 	default:
 		return ModeActive, fmt.Errorf("invalid enum mode %q", mode)
 	}
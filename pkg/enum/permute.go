@@ -0,0 +1,408 @@
+package enum
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/internal/registry"
+	"github.com/NASHEDIxCODER/gospyder/pkg/models"
+	"github.com/NASHEDIxCODER/gospyder/pkg/resolver"
+)
+
+// DefaultMaxPermutations caps the candidates generated per run.
+const DefaultMaxPermutations = 20000
+
+// DefaultPermutationPatterns are applied in order, so earlier patterns win
+// when the candidate cap is reached. Placeholders:
+//
+//	{{sub}}     the label being altered
+//	{{word}}    each permutation word
+//	{{env}}     environment tokens (dev, staging, prod, ...)
+//	{{region}}  region tokens (us, eu, us-east-1, ...)
+//	{{number}}  the label with its number shifted by up to 3 (web01 -> web02)
+//	{{swap}}    the label with one known token replaced (api-dev -> api-qa)
+//
+// A pattern producing a dot inserts a label.
+var DefaultPermutationPatterns = []string{
+	"{{number}}",
+	"{{swap}}",
+	"{{env}}-{{sub}}",
+	"{{sub}}-{{env}}",
+	"{{env}}.{{sub}}",
+	"{{word}}-{{sub}}",
+	"{{sub}}-{{word}}",
+	"{{word}}.{{sub}}",
+	"{{sub}}-{{region}}",
+	"{{region}}.{{sub}}",
+	"{{word}}{{sub}}",
+	"{{sub}}{{word}}",
+}
+
+// DefaultPermutationWords are used when no permutation wordlist is given.
+var DefaultPermutationWords = []string{
+	"api", "admin", "app", "auth", "backend", "beta", "cdn", "db", "gateway", "git",
+	"internal", "legacy", "m", "mail", "mobile", "new", "old", "portal", "private",
+	"sso", "static", "v1", "v2", "vpn", "web",
+}
+
+var (
+	envTokens = []string{
+		"dev", "development", "stage", "staging", "stg", "test", "testing", "qa",
+		"uat", "preprod", "prod", "production", "sandbox", "demo", "int",
+	}
+	regionTokens = []string{
+		"us", "eu", "asia", "us-east-1", "us-east-2", "us-west-1", "us-west-2",
+		"eu-west-1", "eu-central-1", "ap-southeast-1", "ap-northeast-1",
+	}
+
+	placeholderPattern = regexp.MustCompile(`\{\{\s*([a-z]+)\s*\}\}`)
+	digitRun           = regexp.MustCompile(`[0-9]+`)
+	validLabel         = regexp.MustCompile(`^[a-z0-9_]([a-z0-9_-]{0,61}[a-z0-9_])?$`)
+)
+
+// Permuter generates alterations of known subdomains: word insertions,
+// number increments, dash and dot joins, environment and region tokens and
+// word swaps, at every label position below the target.
+type Permuter struct {
+	words    []string
+	patterns [][]segment
+	limit    int
+}
+
+// segment is a literal or, when placeholder is set, a placeholder.
+type segment struct {
+	literal     string
+	placeholder string
+}
+
+// NewPermuter compiles patterns, or DefaultPermutationPatterns when none
+// are given. Empty words use DefaultPermutationWords; limit <= 0 uses
+// DefaultMaxPermutations.
+func NewPermuter(words, patterns []string, limit int) (*Permuter, error) {
+	if len(words) == 0 {
+		words = DefaultPermutationWords
+	}
+	if len(patterns) == 0 {
+		patterns = DefaultPermutationPatterns
+	}
+	if limit <= 0 {
+		limit = DefaultMaxPermutations
+	}
+	p := &Permuter{limit: limit}
+	for _, w := range words {
+		if w = strings.ToLower(strings.TrimSpace(w)); w != "" {
+			p.words = append(p.words, w)
+		}
+	}
+	for _, pattern := range patterns {
+		compiled, err := compilePattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		p.patterns = append(p.patterns, compiled)
+	}
+	return p, nil
+}
+
+// LoadPermuter builds a permuter from a words file and a patterns file,
+// one entry per line with # comments. Empty paths use the defaults.
+func LoadPermuter(wordsFile, patternsFile string, limit int) (*Permuter, error) {
+	words, err := readList(wordsFile, "permutation wordlist")
+	if err != nil {
+		return nil, err
+	}
+	patterns, err := readList(patternsFile, "permutation patterns")
+	if err != nil {
+		return nil, err
+	}
+	return NewPermuter(words, patterns, limit)
+}
+
+func readList(path, what string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.NewIOError("open "+what, err)
+	}
+	defer file.Close()
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.NewIOError("read "+what, err)
+	}
+	return lines, nil
+}
+
+func compilePattern(pattern string) ([]segment, error) {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	var segments []segment
+	placeholders := 0
+	rest := pattern
+	for {
+		loc := placeholderPattern.FindStringSubmatchIndex(rest)
+		if loc == nil {
+			break
+		}
+		if loc[0] > 0 {
+			segments = append(segments, segment{literal: rest[:loc[0]]})
+		}
+		name := rest[loc[2]:loc[3]]
+		switch name {
+		case "sub", "word", "env", "region", "number", "swap":
+		default:
+			return nil, errors.NewValidationError(fmt.Sprintf("permutation pattern %q: unknown placeholder {{%s}}", pattern, name))
+		}
+		segments = append(segments, segment{placeholder: name})
+		placeholders++
+		rest = rest[loc[1]:]
+	}
+	if rest != "" {
+		segments = append(segments, segment{literal: rest})
+	}
+	if placeholders == 0 || strings.Contains(pattern, "{{") && strings.Count(pattern, "{{") != placeholders {
+		return nil, errors.NewValidationError(fmt.Sprintf("permutation pattern %q needs valid {{placeholders}}", pattern))
+	}
+	return segments, nil
+}
+
+// Generate returns up to the cap of distinct new names derived from seeds
+// under root. Words found in the seeds' labels join the permutation words.
+func (p *Permuter) Generate(seeds []string, root string) []string {
+	root = normalizeZone(root)
+	known := map[string]bool{root: true}
+	var subs [][]string
+	for _, seed := range seeds {
+		seed = normalizeZone(seed)
+		if known[seed] || !strings.HasSuffix(seed, "."+root) {
+			continue
+		}
+		known[seed] = true
+		subs = append(subs, strings.Split(strings.TrimSuffix(seed, "."+root), "."))
+	}
+	words := p.vocabulary(subs)
+	swaps := uniqueWords(append(append([]string(nil), words...), envTokens...))
+
+	var candidates []string
+	for _, pattern := range p.patterns {
+		for _, labels := range subs {
+			for i := range labels {
+				full := p.expand(pattern, labels[i], words, swaps, func(alt string) bool {
+					altered := append(append(append([]string(nil), labels[:i]...), alt), labels[i+1:]...)
+					name := strings.Join(altered, ".") + "." + root
+					if !known[name] && validName(name) {
+						known[name] = true
+						candidates = append(candidates, name)
+					}
+					return len(candidates) < p.limit
+				})
+				if !full {
+					return candidates
+				}
+			}
+		}
+	}
+	return candidates
+}
+
+// vocabulary returns the permutation words plus the dash-separated parts
+// of the seed labels, so "api-internal" contributes "internal".
+func (p *Permuter) vocabulary(subs [][]string) []string {
+	words := append([]string(nil), p.words...)
+	for _, labels := range subs {
+		for _, label := range labels {
+			for _, part := range strings.Split(label, "-") {
+				part = strings.TrimRight(part, "0123456789")
+				if len(part) >= 2 {
+					words = append(words, part)
+				}
+			}
+		}
+	}
+	return uniqueWords(words)
+}
+
+// expand calls emit with every expansion of pattern for label until emit
+// returns false, and reports whether it ran to completion.
+func (p *Permuter) expand(pattern []segment, label string, words, swaps []string, emit func(string) bool) bool {
+	var walk func(i int, prefix string) bool
+	walk = func(i int, prefix string) bool {
+		if i == len(pattern) {
+			if prefix == label {
+				return true
+			}
+			return emit(prefix)
+		}
+		seg := pattern[i]
+		if seg.placeholder == "" {
+			return walk(i+1, prefix+seg.literal)
+		}
+		for _, value := range placeholderValues(seg.placeholder, label, words, swaps) {
+			if !walk(i+1, prefix+value) {
+				return false
+			}
+		}
+		return true
+	}
+	return walk(0, "")
+}
+
+func placeholderValues(name, label string, words, swaps []string) []string {
+	switch name {
+	case "sub":
+		return []string{label}
+	case "word":
+		return without(words, label)
+	case "env":
+		return envTokens
+	case "region":
+		return regionTokens
+	case "number":
+		return numberShifts(label)
+	case "swap":
+		return wordSwaps(label, swaps)
+	}
+	return nil
+}
+
+// numberShifts returns label with its last number moved by up to 3 either
+// way, keeping zero padding: web01 gives web02, web03, web04 and web00.
+func numberShifts(label string) []string {
+	locs := digitRun.FindAllStringIndex(label, -1)
+	if len(locs) == 0 {
+		return nil
+	}
+	loc := locs[len(locs)-1]
+	digits := label[loc[0]:loc[1]]
+	n, err := strconv.Atoi(digits)
+	if err != nil {
+		return nil
+	}
+	width := 0
+	if strings.HasPrefix(digits, "0") {
+		width = len(digits)
+	}
+	var shifted []string
+	for _, delta := range []int{1, -1, 2, -2, 3, -3} {
+		if n+delta < 0 {
+			continue
+		}
+		shifted = append(shifted, label[:loc[0]]+fmt.Sprintf("%0*d", width, n+delta)+label[loc[1]:])
+	}
+	return shifted
+}
+
+// wordSwaps replaces each dash-separated part of label that is a known
+// word with every other known word.
+func wordSwaps(label string, words []string) []string {
+	known := map[string]bool{}
+	for _, w := range words {
+		known[w] = true
+	}
+	parts := strings.Split(label, "-")
+	var swapped []string
+	for i, part := range parts {
+		if !known[part] {
+			continue
+		}
+		for _, w := range words {
+			if w == part {
+				continue
+			}
+			altered := append([]string(nil), parts...)
+			altered[i] = w
+			swapped = append(swapped, strings.Join(altered, "-"))
+		}
+	}
+	return swapped
+}
+
+// without returns words minus word, so a label is not joined to itself.
+func without(words []string, word string) []string {
+	for i, w := range words {
+		if w == word {
+			return append(append([]string(nil), words[:i]...), words[i+1:]...)
+		}
+	}
+	return words
+}
+
+func uniqueWords(words []string) []string {
+	seen := map[string]bool{}
+	var unique []string
+	for _, w := range words {
+		if !seen[w] {
+			seen[w] = true
+			unique = append(unique, w)
+		}
+	}
+	return unique
+}
+
+func validName(name string) bool {
+	if len(name) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(name, ".") {
+		if !validLabel.MatchString(label) {
+			return false
+		}
+	}
+	return true
+}
+
+// ResolvePermutations resolves candidates with up to threads lookups in
+// flight. Hits answered only by a wildcard record of their parent zone are
+// discarded.
+func ResolvePermutations(ctx context.Context, pool *resolver.Pool, candidates []string, threads int, wildcards *Wildcards, progress registry.Progress, errs *errors.Tally) <-chan models.Domain {
+	if threads <= 0 {
+		threads = 10
+	}
+	out := make(chan models.Domain, 100)
+	progress.AddTotal(int64(len(candidates)))
+
+	go func() {
+		defer close(out)
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, threads)
+		for _, candidate := range candidates {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				wg.Wait()
+				return
+			}
+			wg.Add(1)
+			go func(name string) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				ips, err := pool.Lookup(ctx, name)
+				progress.Increment(1)
+				recordLookupError(ctx, errs, err)
+				if err != nil || wildcards.IsWildcard(ctx, name, ips) {
+					return
+				}
+				select {
+				case out <- models.Domain{Name: name, Source: "permute"}:
+				case <-ctx.Done():
+				}
+			}(candidate)
+		}
+		wg.Wait()
+	}()
+	return out
+}
@@ -0,0 +1,81 @@
+package enum
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/internal/registry"
+)
+
+func TestPermuterGenerate(t *testing.T) {
+	p, err := NewPermuter([]string{"api"}, []string{"{{number}}", "{{swap}}", "{{word}}-{{sub}}", "{{sub}}.{{env}}"}, 0)
+	if err != nil {
+		t.Fatalf("NewPermuter() error = %v", err)
+	}
+	got := map[string]bool{}
+	for _, name := range p.Generate([]string{"web01.eu.example.com", "api-dev.example.com", "example.com", "other.org"}, "example.com") {
+		got[name] = true
+	}
+	for _, want := range []string{
+		"web02.eu.example.com",     // increment keeps zero padding
+		"web00.eu.example.com",     // and goes down
+		"api-qa.example.com",       // env token swapped
+		"web-dev.example.com",      // learned word swapped in
+		"api-web01.eu.example.com", // insertion at the first label
+		"web01.api-eu.example.com", // and at the second
+		"web01.eu.staging.example.com",
+	} {
+		if !got[want] {
+			t.Errorf("Generate() is missing %s", want)
+		}
+	}
+	for _, unwanted := range []string{"api-dev.example.com", "other.org", "web-1.eu.example.com"} {
+		if got[unwanted] {
+			t.Errorf("Generate() produced %s", unwanted)
+		}
+	}
+}
+
+func TestPermuterCapKeepsPatternOrder(t *testing.T) {
+	p, err := NewPermuter([]string{"a", "b", "c"}, []string{"{{word}}-{{sub}}", "{{sub}}-{{word}}"}, 4)
+	if err != nil {
+		t.Fatalf("NewPermuter() error = %v", err)
+	}
+	got := p.Generate([]string{"www.example.com"}, "example.com")
+	if fmt.Sprint(got) != "[a-www.example.com b-www.example.com c-www.example.com www-a.example.com]" {
+		t.Fatalf("Generate() = %v", got)
+	}
+}
+
+func TestLoadPermuterRejectsUnknownPlaceholder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "patterns.txt")
+	if err := os.WriteFile(path, []byte("# comment\n{{sub}}-{{colour}}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPermuter("", path, 0); err == nil {
+		t.Fatal("LoadPermuter() accepted {{colour}}")
+	}
+	if _, err := NewPermuter(nil, []string{"static"}, 0); err == nil {
+		t.Fatal("NewPermuter() accepted a pattern without placeholders")
+	}
+}
+
+func TestResolvePermutationsFiltersWildcards(t *testing.T) {
+	pool := wildcardPool()
+	stream := ResolvePermutations(context.Background(), pool, []string{"www.example.com", "api.example.com", "nope.example.com"},
+		4, NewWildcards(pool), registry.NopProgress, errors.NewTally("DNS lookups"))
+	var found []string
+	for d := range stream {
+		if d.Source != "permute" {
+			t.Fatalf("Source = %q", d.Source)
+		}
+		found = append(found, d.Name)
+	}
+	if fmt.Sprint(found) != "[www.example.com]" {
+		t.Fatalf("found = %v, want only www.example.com", found)
+	}
+}
@@ -1,58 +1,183 @@
 package enum
 
 import (
+	"container/heap"
 	"context"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/NASHEDIxCODER/gospyder/pkg/resolver"
 )
 
-func Recursive(ctx context.Context, pool *resolver.Pool, foundDomain string) (<-chan string, error) {
-	out := make(chan string, 50)
+// DefaultRecursionDepth is how many labels below the target recursion
+// brute-forces when no depth is set: *.api.example.com but not
+// *.v1.api.example.com.
+const DefaultRecursionDepth = 1
 
-	go func() {
-		defer close(out)
+// delegationBonus puts zones with their own NS records ahead of the rest.
+const delegationBonus = 100
 
-		parts := strings.Split(foundDomain, ".")
-		if len(parts) < 2 {
-			return
-		}
+// subZone is a found name queued for brute-forcing.
+type subZone struct {
+	name     string
+	depth    int // labels below the target
+	priority int
+	seq      int // queue order, breaking ties
+}
+
+// zoneQueue is a heap of sub-zones, highest priority first, then
+// shallowest, then oldest.
+type zoneQueue []*subZone
+
+func (q zoneQueue) Len() int { return len(q) }
+
+func (q zoneQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	if q[i].depth != q[j].depth {
+		return q[i].depth < q[j].depth
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q zoneQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
 
-		base := parts[0]
-		suffix := strings.Join(parts[1:], ".")
-
-		// Only use meaningful prefix/suffix mutations that could produce
-		// valid subdomains. Avoid artificial numeric mutations (www1, www2)
-		// that almost always return 404.
-		perms := []string{
-			"dev-" + base,
-			"test-" + base,
-			"staging-" + base,
-			base + "-dev",
-			base + "-test",
-			"admin-" + base,
-			base + "-admin",
-			"api-" + base,
-			base + "-api",
+func (q *zoneQueue) Push(x any) { *q = append(*q, x.(*subZone)) }
+
+func (q *zoneQueue) Pop() any {
+	old := *q
+	z := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return z
+}
+
+// runRecursive brute-forces the names found under target with the
+// wordlist, then the names that turn up, down to the engine's depth. The
+// most promising zones go first: delegated ones, then those with the most
+// known names beneath them or the most productive parent. It runs until the
+// queue empties or the module's time budget is nearly spent.
+func (e *Engine) runRecursive(ctx context.Context, target, wordlist string) []string {
+	if e.depth <= 0 {
+		return nil
+	}
+	ctx, cancel := recursionBudget(ctx)
+	defer cancel()
+
+	queue := &zoneQueue{}
+	queued := map[string]bool{}
+	seq := 0
+	push := func(zones []string, parentHits int) {
+		var fresh []string
+		for _, zone := range zones {
+			if depth := labelsBelow(zone, target); depth >= 1 && depth <= e.depth && !queued[zone] {
+				queued[zone] = true
+				fresh = append(fresh, zone)
+			}
 		}
+		delegated := e.delegated(ctx, fresh)
+		for _, zone := range fresh {
+			priority := 2*e.namesUnder(zone) + parentHits
+			if delegated[zone] {
+				priority += delegationBonus
+			}
+			seq++
+			heap.Push(queue, &subZone{name: zone, depth: labelsBelow(zone, target), priority: priority, seq: seq})
+		}
+	}
+	// Seed with every name known so far and the zones between it and the
+	// target, so a.b.example.com from a passive source queues b.example.com.
+	var seeds []string
+	for _, name := range e.names() {
+		for zone := name; labelsBelow(zone, target) > 0; zone = parentZone(zone) {
+			seeds = append(seeds, zone)
+		}
+	}
+	push(seeds, 0)
 
-		for _, perm := range perms {
-			candidate := perm + "." + suffix
+	var results []string
+	for queue.Len() > 0 && ctx.Err() == nil {
+		zone := heap.Pop(queue).(*subZone)
+		if wildcard := e.wildcards.Detect(ctx, zone.name); wildcard != nil {
+			e.logger.Warn("Wildcard DNS on %s; filtering matching results", wildcard)
+		}
+		e.logger.Debug("Recursive: brute-forcing %s (depth %d, priority %d)", zone.name, zone.depth, zone.priority)
 
-			// Only yield if DNS resolves AND the validation (done by caller) succeeds.
-			// If DNS does not resolve, skip immediately - no need to push to channel.
-			ips, err := pool.Lookup(ctx, candidate)
-			if err != nil || len(ips) == 0 {
-				continue
+		stream, err := BruteForce(ctx, e.pool, zone.name, wordlist, e.wildcards, e.progress, e.lookups)
+		if err != nil {
+			e.errs.Add(err)
+			return results
+		}
+		e.recordZone(zone.name)
+		var hits []string
+		for domain := range stream {
+			domain.Source = "recursive"
+			if e.add(domain) {
+				hits = append(hits, domain.Name)
 			}
+		}
+		results = append(results, hits...)
+		push(hits, len(hits))
+	}
+	if queue.Len() > 0 {
+		e.logger.Warn("Recursive: time budget spent with %d zone(s) left", queue.Len())
+	}
+	return results
+}
 
+// delegated reports which of zones have NS records of their own.
+func (e *Engine) delegated(ctx context.Context, zones []string) map[string]bool {
+	threads := e.threads
+	if threads <= 0 {
+		threads = 10
+	}
+	found := map[string]bool{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, threads)
+	for _, zone := range zones {
+		wg.Add(1)
+		go func(zone string) {
+			defer wg.Done()
 			select {
+			case sem <- struct{}{}:
 			case <-ctx.Done():
 				return
-			case out <- candidate:
 			}
-		}
-	}()
+			defer func() { <-sem }()
+
+			records, err := e.pool.Query(ctx, zone, resolver.TypeNS)
+			recordLookupError(ctx, e.lookups, err)
+			for _, r := range records {
+				if r.Type == resolver.TypeNS && strings.EqualFold(r.Name, zone) {
+					mu.Lock()
+					found[zone] = true
+					mu.Unlock()
+					return
+				}
+			}
+		}(zone)
+	}
+	wg.Wait()
+	return found
+}
+
+// recursionBudget leaves a tenth of the module's remaining time for
+// annotating and reporting what recursion found.
+func recursionBudget(ctx context.Context) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, deadline.Add(-time.Until(deadline)/10))
+}
 
-	return out, nil
+// labelsBelow returns how many labels name has below target, or 0 when it
+// is not a subdomain of target.
+func labelsBelow(name, target string) int {
+	if !strings.HasSuffix(name, "."+target) {
+		return 0
+	}
+	return strings.Count(strings.TrimSuffix(name, "."+target), ".") + 1
 }
@@ -0,0 +1,222 @@
+package enum
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/NASHEDIxCODER/gospyder/pkg/resolver"
+	"github.com/google/uuid"
+)
+
+// wildcardProbes is how many random labels are resolved per zone. Several
+// probes catch wildcards that rotate through a pool of addresses.
+const wildcardProbes = 3
+
+// Wildcard is the answer a zone returns for names that do not exist.
+type Wildcard struct {
+	Zone  string   `json:"zone"`
+	IPs   []string `json:"ips,omitempty"`
+	CNAME string   `json:"cname,omitempty"`
+}
+
+// String describes the wildcard as "*.zone (answers)".
+func (w Wildcard) String() string {
+	answers := append([]string(nil), w.IPs...)
+	if w.CNAME != "" {
+		answers = append(answers, "CNAME "+w.CNAME)
+	}
+	return fmt.Sprintf("*.%s (%s)", w.Zone, strings.Join(answers, ", "))
+}
+
+// Wildcards detects wildcard DNS records per zone and recognizes brute-force
+// hits that are only answered by them. A nil *Wildcards filters nothing.
+type Wildcards struct {
+	pool     *resolver.Pool
+	filtered atomic.Int64
+
+	mu    sync.Mutex
+	zones map[string]*wildcardZone
+}
+
+type wildcardZone struct {
+	done     chan struct{}
+	complete bool
+	wildcard *Wildcard // nil when the zone has no wildcard
+	ips      map[string]bool
+}
+
+// NewWildcards returns a detector resolving its probes through pool.
+func NewWildcards(pool *resolver.Pool) *Wildcards {
+	return &Wildcards{pool: pool, zones: map[string]*wildcardZone{}}
+}
+
+// Detect probes random labels under zone once and returns its wildcard
+// answer, or nil when random names do not resolve. Concurrent callers for
+// the same zone share one detection.
+func (w *Wildcards) Detect(ctx context.Context, zone string) *Wildcard {
+	if w == nil {
+		return nil
+	}
+	z := w.zone(ctx, normalizeZone(zone))
+	if z == nil {
+		return nil
+	}
+	return z.wildcard
+}
+
+// IsWildcard reports whether name resolving to ips is explained by the
+// wildcard of its parent zone. Matches are counted for Filtered.
+func (w *Wildcards) IsWildcard(ctx context.Context, name string, ips []string) bool {
+	if w == nil {
+		return false
+	}
+	name = normalizeZone(name)
+	dot := strings.IndexByte(name, '.')
+	if dot < 0 {
+		return false
+	}
+	z := w.zone(ctx, name[dot+1:])
+	if z == nil || z.wildcard == nil {
+		return false
+	}
+
+	if len(ips) > 0 && subsetOf(ips, z.ips) {
+		w.filtered.Add(1)
+		return true
+	}
+	if z.wildcard.CNAME != "" {
+		host, err := w.pool.Resolve(ctx, name)
+		if err == nil && len(host.CNAMEs) > 0 && normalizeZone(host.CNAMEs[0]) == z.wildcard.CNAME {
+			w.filtered.Add(1)
+			return true
+		}
+	}
+	return false
+}
+
+// Found returns the detected wildcards sorted by zone.
+func (w *Wildcards) Found() []Wildcard {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var found []Wildcard
+	for _, z := range w.zones {
+		select {
+		case <-z.done:
+			if z.complete && z.wildcard != nil {
+				found = append(found, *z.wildcard)
+			}
+		default:
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].Zone < found[j].Zone })
+	return found
+}
+
+// Filtered returns how many hits were discarded as wildcard answers.
+func (w *Wildcards) Filtered() int64 {
+	if w == nil {
+		return 0
+	}
+	return w.filtered.Load()
+}
+
+// zone returns the detection state for zone, running detection on first use.
+// It returns nil if ctx ends before the detection completes; an interrupted
+// detection is retried by the next caller.
+func (w *Wildcards) zone(ctx context.Context, zone string) *wildcardZone {
+	w.mu.Lock()
+	z, ok := w.zones[zone]
+	if !ok {
+		z = &wildcardZone{done: make(chan struct{})}
+		w.zones[zone] = z
+	}
+	w.mu.Unlock()
+
+	if ok {
+		select {
+		case <-z.done:
+			if !z.complete {
+				return nil
+			}
+			return z
+		case <-ctx.Done():
+			return nil
+		}
+	}
+
+	wildcard, ips, complete := w.probe(ctx, zone)
+	if !complete {
+		w.mu.Lock()
+		delete(w.zones, zone)
+		w.mu.Unlock()
+		close(z.done)
+		return nil
+	}
+	z.wildcard, z.ips, z.complete = wildcard, ips, true
+	close(z.done)
+	return z
+}
+
+// probe resolves random labels under zone concurrently and merges their
+// answers. complete is false when ctx ended first.
+func (w *Wildcards) probe(ctx context.Context, zone string) (*Wildcard, map[string]bool, bool) {
+	hosts := make(chan *resolver.Host, wildcardProbes)
+	var wg sync.WaitGroup
+	for i := 0; i < wildcardProbes; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			host, err := w.pool.Resolve(ctx, uuid.New().String()+"."+zone)
+			if err == nil && (len(host.IPs) > 0 || len(host.CNAMEs) > 0) {
+				hosts <- host
+			}
+		}()
+	}
+	wg.Wait()
+	close(hosts)
+	if ctx.Err() != nil {
+		return nil, nil, false
+	}
+
+	ips := map[string]bool{}
+	var wildcard *Wildcard
+	for host := range hosts {
+		if wildcard == nil {
+			wildcard = &Wildcard{Zone: zone}
+		}
+		if len(host.CNAMEs) > 0 {
+			wildcard.CNAME = normalizeZone(host.CNAMEs[0])
+		}
+		for _, ip := range host.IPs {
+			ips[ip] = true
+		}
+	}
+	if wildcard == nil {
+		return nil, nil, true
+	}
+	for ip := range ips {
+		wildcard.IPs = append(wildcard.IPs, ip)
+	}
+	sort.Strings(wildcard.IPs)
+	return wildcard, ips, true
+}
+
+func subsetOf(ips []string, set map[string]bool) bool {
+	for _, ip := range ips {
+		if !set[ip] {
+			return false
+		}
+	}
+	return true
+}
+
+func normalizeZone(name string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+}
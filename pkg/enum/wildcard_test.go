@@ -0,0 +1,127 @@
+package enum
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/internal/registry"
+	"github.com/NASHEDIxCODER/gospyder/internal/replay"
+	"github.com/NASHEDIxCODER/gospyder/pkg/resolver"
+)
+
+const probeName = "0b6f8c1e-3d7a-4f43-9a2e-5c1d2e3f4a5b"
+
+// wildcardPool answers from a fixture where *.example.com resolves to
+// 192.0.2.1 via CNAME wild.example.net and *.dev.example.com to 192.0.2.9.
+func wildcardPool() *resolver.Pool {
+	pool := resolver.NewPool([]string{"192.0.2.53"})
+	pool.SetReplay(replay.NewReplayer(&replay.Cassette{
+		DNS: []replay.DNSInteraction{
+			{Type: "A", Name: probeName + ".example.com", Answers: []string{
+				probeName + ".example.com 300 CNAME wild.example.net",
+				"wild.example.net 300 A 192.0.2.1",
+			}},
+			{Type: "A", Name: probeName + ".dev.example.com", Answers: []string{probeName + ".dev.example.com 300 A 192.0.2.9"}},
+			{Type: "A", Name: "www.example.com", Answers: []string{"www.example.com 300 A 192.0.2.50"}},
+			// Brute force resolves candidates with address lookups.
+			{Name: "www.example.com", Addrs: []string{"192.0.2.50"}},
+			{Name: "api.example.com", Addrs: []string{"192.0.2.1"}},
+			{Name: "cdn.example.com", Addrs: []string{"198.51.100.7"}},
+			{Type: "A", Name: "cdn.example.com", Answers: []string{
+				"cdn.example.com 300 CNAME wild.example.net",
+				"wild.example.net 300 A 198.51.100.7",
+			}},
+		},
+	}))
+	return pool
+}
+
+func TestBruteForceFiltersWildcardAnswers(t *testing.T) {
+	wordlist := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(wordlist, []byte("www\napi\ncdn\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	pool := wildcardPool()
+	wildcards := NewWildcards(pool)
+	ctx := context.Background()
+
+	wildcard := wildcards.Detect(ctx, "example.com")
+	if wildcard == nil || len(wildcard.IPs) != 1 || wildcard.IPs[0] != "192.0.2.1" || wildcard.CNAME != "wild.example.net" {
+		t.Fatalf("Detect() = %#v, want 192.0.2.1 via wild.example.net", wildcard)
+	}
+
+	stream, err := BruteForce(ctx, pool, "example.com", wordlist, wildcards, registry.NopProgress, errors.NewTally("DNS lookups"))
+	if err != nil {
+		t.Fatalf("BruteForce() error = %v", err)
+	}
+	var found []string
+	for domain := range stream {
+		found = append(found, domain.Name)
+	}
+	if len(found) != 1 || found[0] != "www.example.com" {
+		t.Fatalf("found = %v, want only www.example.com", found)
+	}
+	if got := wildcards.Filtered(); got != 2 {
+		t.Fatalf("Filtered() = %d, want 2 (api by address, cdn by CNAME)", got)
+	}
+}
+
+func TestBruteForceClosesAfterWorkersOnCancel(t *testing.T) {
+	var words []string
+	cassette := &replay.Cassette{}
+	for i := range 500 {
+		word := fmt.Sprintf("host%d", i)
+		words = append(words, word)
+		cassette.DNS = append(cassette.DNS, replay.DNSInteraction{Name: word + ".example.com", Addrs: []string{"192.0.2.50"}})
+	}
+	// Misses keep the wordlist loop running when the scan is cancelled.
+	for i := range 100000 {
+		words = append(words, fmt.Sprintf("miss%d", i))
+	}
+	wordlist := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(wordlist, []byte(strings.Join(words, "\n")), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	pool := resolver.NewPool([]string{"192.0.2.53"})
+	pool.SetReplay(replay.NewReplayer(cassette))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := BruteForce(ctx, pool, "example.com", wordlist, NewWildcards(pool), registry.NopProgress, errors.NewTally("DNS lookups"))
+	if err != nil {
+		t.Fatalf("BruteForce() error = %v", err)
+	}
+	<-stream
+	// Hits fill the channel while nobody reads; workers blocked on it
+	// must give up rather than send after it closes.
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+	for range stream {
+	}
+}
+
+func TestWildcardsDetectNestedZones(t *testing.T) {
+	wildcards := NewWildcards(wildcardPool())
+	ctx := context.Background()
+
+	if !wildcards.IsWildcard(ctx, "anything.dev.example.com", []string{"192.0.2.9"}) {
+		t.Fatal("IsWildcard() = false for an answer of *.dev.example.com")
+	}
+	if wildcards.IsWildcard(ctx, "www.example.com", []string{"192.0.2.50"}) {
+		t.Fatal("IsWildcard() = true for a real host")
+	}
+
+	found := wildcards.Found()
+	if len(found) != 2 || found[0].Zone != "dev.example.com" || found[1].Zone != "example.com" {
+		t.Fatalf("Found() = %v, want dev.example.com and example.com", found)
+	}
+	if got := found[1].String(); got != "*.example.com (192.0.2.1, CNAME wild.example.net)" {
+		t.Fatalf("String() = %q", got)
+	}
+}
@@ -0,0 +1,199 @@
+package enum
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/NASHEDIxCODER/gospyder/pkg/models"
+	"github.com/NASHEDIxCODER/gospyder/pkg/resolver"
+	"github.com/google/uuid"
+)
+
+const (
+	// maxNSECWalk bounds an NSEC chain walk.
+	maxNSECWalk = 10000
+	// nsec3Probes is the number of random names queried to collect NSEC3
+	// hashes from negative answers.
+	nsec3Probes = 16
+)
+
+// ZoneReport summarizes what the target's nameservers revealed.
+type ZoneReport struct {
+	Nameservers []string     `json:"nameservers,omitempty"`
+	AXFR        []string     `json:"axfr,omitempty"`        // servers that allowed a zone transfer
+	NSECWalked  int          `json:"nsec_walked,omitempty"` // names read from the NSEC chain
+	NSEC3       *NSEC3Hashes `json:"nsec3,omitempty"`
+}
+
+// NSEC3Hashes are owner name hashes collected from NSEC3 denial records,
+// with the parameters needed to crack them offline.
+type NSEC3Hashes struct {
+	Algorithm  uint8    `json:"algorithm"`
+	Iterations uint16   `json:"iterations"`
+	Salt       string   `json:"salt"`
+	Hashes     []string `json:"hashes"`
+}
+
+// Empty reports whether the nameservers revealed nothing.
+func (z *ZoneReport) Empty() bool {
+	return z == nil || len(z.AXFR) == 0 && z.NSECWalked == 0 && z.NSEC3 == nil
+}
+
+// runZone asks the target's nameservers for the zone contents: an AXFR
+// against each, then an NSEC walk or NSEC3 hash collection when no
+// transfer was allowed.
+func (e *Engine) runZone(ctx context.Context, target string) []string {
+	e.logger.Info("Active: querying nameservers of %s", target)
+	servers := e.nameservers(ctx, target)
+	if len(servers) == 0 {
+		return []string{}
+	}
+	e.zone.Nameservers = servers
+
+	var results []string
+	for _, server := range servers {
+		records, err := e.pool.Transfer(ctx, server, target)
+		if err != nil {
+			e.logger.Debug("AXFR of %s denied by %s: %v", target, server, err)
+			continue
+		}
+		e.logger.Warn("Zone transfer of %s allowed by %s (%d records)", target, server, len(records))
+		e.zone.AXFR = append(e.zone.AXFR, server)
+		for _, r := range records {
+			if name, ok := e.addZoneName(r.Name, target, "axfr"); ok {
+				results = append(results, name)
+			}
+		}
+	}
+	if len(e.zone.AXFR) > 0 {
+		return results
+	}
+
+	for _, server := range servers {
+		walked, ok := e.walkNSEC(ctx, server, target)
+		if ok {
+			return append(results, walked...)
+		}
+		if hashes := e.collectNSEC3(ctx, server, target); hashes != nil {
+			e.logger.Info("Collected %d NSEC3 hashes for %s from %s", len(hashes.Hashes), target, server)
+			e.zone.NSEC3 = hashes
+			break
+		}
+	}
+	return results
+}
+
+// nameservers returns the "ip:port" addresses of target's NS hosts.
+func (e *Engine) nameservers(ctx context.Context, target string) []string {
+	records, err := e.pool.Query(ctx, target, resolver.TypeNS)
+	if err != nil {
+		e.logger.Debug("NS lookup for %s failed: %v", target, err)
+		return nil
+	}
+	var servers []string
+	for _, r := range records {
+		if r.Type != resolver.TypeNS {
+			continue
+		}
+		host, err := e.pool.Resolve(ctx, r.Value)
+		if err != nil {
+			e.logger.Debug("Cannot resolve nameserver %s: %v", r.Value, err)
+			continue
+		}
+		for _, ip := range host.IPs {
+			servers = append(servers, net.JoinHostPort(ip, e.nsPort))
+		}
+	}
+	sort.Strings(servers)
+	return servers
+}
+
+// walkNSEC follows the NSEC chain from the zone apex. ok is false when the
+// server does not serve NSEC records for target.
+func (e *Engine) walkNSEC(ctx context.Context, server, target string) ([]string, bool) {
+	var results []string
+	visited := map[string]bool{target: true}
+	current := target
+	for steps := 0; steps < maxNSECWalk && ctx.Err() == nil; steps++ {
+		resp, err := e.pool.Exchange(ctx, server, current, resolver.TypeNSEC)
+		if err != nil {
+			e.logger.Debug("NSEC query for %s at %s failed: %v", current, server, err)
+			break
+		}
+		next := ""
+		for _, r := range resp.Answers {
+			if r.Type == resolver.TypeNSEC && strings.EqualFold(r.Name, current) {
+				next, _, _ = strings.Cut(r.Value, " ")
+				break
+			}
+		}
+		if next == "" {
+			break
+		}
+		next = strings.ToLower(next)
+		if visited[next] {
+			// The chain wrapped around to the apex.
+			break
+		}
+		visited[next] = true
+		e.zone.NSECWalked++
+		if name, ok := e.addZoneName(next, target, "nsec"); ok {
+			results = append(results, name)
+		}
+		current = next
+	}
+	return results, len(visited) > 1
+}
+
+// collectNSEC3 queries random names under target and gathers the NSEC3
+// hashes returned in the negative answers. It returns nil when the zone
+// does not use NSEC3.
+func (e *Engine) collectNSEC3(ctx context.Context, server, target string) *NSEC3Hashes {
+	var hashes *NSEC3Hashes
+	seen := map[string]bool{}
+	for i := 0; i < nsec3Probes && ctx.Err() == nil; i++ {
+		if i > 0 && hashes == nil {
+			// The first negative answer had no NSEC3 records.
+			break
+		}
+		resp, _ := e.pool.Exchange(ctx, server, uuid.New().String()+"."+target, resolver.TypeA)
+		if resp == nil {
+			continue
+		}
+		for _, r := range resp.Authority {
+			if r.Type != resolver.TypeNSEC3 {
+				continue
+			}
+			n, err := resolver.ParseNSEC3(r)
+			if err != nil {
+				continue
+			}
+			if hashes == nil {
+				hashes = &NSEC3Hashes{Algorithm: n.Algorithm, Iterations: n.Iterations, Salt: n.Salt}
+			}
+			for _, h := range []string{n.Owner, n.Next} {
+				if !seen[h] {
+					seen[h] = true
+					hashes.Hashes = append(hashes.Hashes, h)
+				}
+			}
+		}
+	}
+	if hashes != nil {
+		sort.Strings(hashes.Hashes)
+	}
+	return hashes
+}
+
+// addZoneName records a name learned from the zone itself and returns it
+// normalized. The apex, wildcard owners and names outside the zone are
+// skipped.
+func (e *Engine) addZoneName(name, target, source string) (string, bool) {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	if name == target || strings.HasPrefix(name, "*.") || !strings.HasSuffix(name, "."+target) {
+		return "", false
+	}
+	return name, e.add(models.Domain{Name: name, Source: source})
+}
@@ -0,0 +1,88 @@
+package enum
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/NASHEDIxCODER/gospyder/pkg/resolver"
+	"github.com/NASHEDIxCODER/gospyder/tests/mocks"
+)
+
+var zoneFixture = []string{
+	"example.com 3600 SOA ns1.example.com hostmaster.example.com 1 7200 900 1209600 300",
+	"example.com 3600 NS ns1.example.com",
+	"ns1.example.com 300 A 127.0.0.1",
+}
+
+// zoneEngine returns an engine whose resolver and nameserver are server.
+func zoneEngine(t *testing.T, server *mocks.DNSServer) *Engine {
+	t.Helper()
+	_, port, err := net.SplitHostPort(server.Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine := NewEngine(resolver.NewPool([]string{server.Addr}), 10)
+	engine.nsPort = port
+	return engine
+}
+
+func TestRunZoneCollectsZoneTransfer(t *testing.T) {
+	server := mocks.NewDNSServer(t, append(zoneFixture,
+		"www.example.com 300 A 192.0.2.10",
+		"*.example.com 300 A 192.0.2.99",
+		"vpn.internal.example.com 300 A 10.0.0.1",
+	)...)
+	server.AllowTransfer()
+	engine := zoneEngine(t, server)
+
+	found := engine.runZone(context.Background(), "example.com")
+	if fmt.Sprint(found) != "[ns1.example.com www.example.com vpn.internal.example.com]" {
+		t.Fatalf("runZone() = %v", found)
+	}
+	if got := engine.Source("vpn.internal.example.com"); got != "axfr" {
+		t.Fatalf("Source() = %q, want axfr", got)
+	}
+	if zone := engine.Zone(); len(zone.AXFR) != 1 || zone.AXFR[0] != server.Addr {
+		t.Fatalf("Zone().AXFR = %v, want [%s]", zone.AXFR, server.Addr)
+	}
+}
+
+func TestRunZoneWalksNSECChain(t *testing.T) {
+	server := mocks.NewDNSServer(t, append(zoneFixture,
+		"example.com 3600 NSEC api.example.com A NS SOA RRSIG NSEC",
+		"api.example.com 3600 NSEC ns1.example.com A RRSIG NSEC",
+		"ns1.example.com 3600 NSEC staging.example.com A RRSIG NSEC",
+		"staging.example.com 3600 NSEC example.com A RRSIG NSEC",
+	)...)
+	engine := zoneEngine(t, server)
+
+	found := engine.runZone(context.Background(), "example.com")
+	if fmt.Sprint(found) != "[api.example.com ns1.example.com staging.example.com]" {
+		t.Fatalf("runZone() = %v", found)
+	}
+	if got := engine.Source("staging.example.com"); got != "nsec" {
+		t.Fatalf("Source() = %q, want nsec", got)
+	}
+	if zone := engine.Zone(); len(zone.AXFR) != 0 || zone.NSECWalked != 3 {
+		t.Fatalf("Zone() = %+v, want a refused transfer and 3 walked names", zone)
+	}
+}
+
+func TestRunZoneCollectsNSEC3Hashes(t *testing.T) {
+	server := mocks.NewDNSServer(t, append(zoneFixture,
+		"2t7b4g4vsa5smi47k61mv5bv1a22bojr.example.com 3600 NSEC3 1 0 12 AABBCCDD 2vptu5timamqttgl4luu9kg21e0aor3s A RRSIG",
+		"2vptu5timamqttgl4luu9kg21e0aor3s.example.com 3600 NSEC3 1 0 12 AABBCCDD 2t7b4g4vsa5smi47k61mv5bv1a22bojr A NS SOA RRSIG",
+	)...)
+	engine := zoneEngine(t, server)
+
+	if found := engine.runZone(context.Background(), "example.com"); len(found) != 0 {
+		t.Fatalf("runZone() = %v, want no names from hashed denial", found)
+	}
+	hashes := engine.Zone().NSEC3
+	if hashes == nil || hashes.Iterations != 12 || hashes.Salt != "AABBCCDD" ||
+		fmt.Sprint(hashes.Hashes) != "[2t7b4g4vsa5smi47k61mv5bv1a22bojr 2vptu5timamqttgl4luu9kg21e0aor3s]" {
+		t.Fatalf("Zone().NSEC3 = %+v", hashes)
+	}
+}
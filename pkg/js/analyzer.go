@@ -12,6 +12,9 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/internal/registry"
 )
 
 const maxJSFileSize = 10 * 1024 * 1024 // 10MB
@@ -22,10 +25,10 @@ const maxJSFileSize = 10 * 1024 * 1024 // 10MB
 
 // JSEndpoint represents an extracted API endpoint/path.
 type JSEndpoint struct {
-	Path     string `json:"path"`
-	Source   string `json:"source"`  // the JS file URL where found
-	Method   string `json:"method"`  // inferred HTTP method if known
-	Context  string `json:"context"` // surrounding code snippet
+	Path    string `json:"path"`
+	Source  string `json:"source"`  // the JS file URL where found
+	Method  string `json:"method"`  // inferred HTTP method if known
+	Context string `json:"context"` // surrounding code snippet
 }
 
 // JSDomain represents an external domain found in JS.
@@ -55,49 +58,65 @@ type JSFile struct {
 type EndpointCategory string
 
 const (
-	CategoryAuth       EndpointCategory = "Authentication"
-	CategoryAPI        EndpointCategory = "API"
-	CategoryGraphQL    EndpointCategory = "GraphQL"
-	CategoryUploads    EndpointCategory = "Uploads"
-	CategoryAdmin      EndpointCategory = "Admin"
-	CategoryUser       EndpointCategory = "User"
-	CategoryOther      EndpointCategory = "Other"
+	CategoryAuth    EndpointCategory = "Authentication"
+	CategoryAPI     EndpointCategory = "API"
+	CategoryGraphQL EndpointCategory = "GraphQL"
+	CategoryUploads EndpointCategory = "Uploads"
+	CategoryAdmin   EndpointCategory = "Admin"
+	CategoryUser    EndpointCategory = "User"
+	CategoryOther   EndpointCategory = "Other"
 )
 
 // Result is the aggregated JS analysis result.
 type Result struct {
-	Files          []JSFile                 `json:"files"`
-	Endpoints      []JSEndpoint             `json:"endpoints"`
-	Domains        []JSDomain               `json:"domains"`
-	Secrets        []JSSecret               `json:"secrets"`
-	Stats          ResultStats              `json:"stats"`
+	Files          []JSFile                          `json:"files"`
+	Endpoints      []JSEndpoint                      `json:"endpoints"`
+	Domains        []JSDomain                        `json:"domains"`
+	Secrets        []JSSecret                        `json:"secrets"`
+	Stats          ResultStats                       `json:"stats"`
 	EndpointGroups map[EndpointCategory][]JSEndpoint `json:"endpoint_groups,omitempty"`
 }
 
 // ResultStats holds summary statistics.
 type ResultStats struct {
-	JSFiles    int `json:"js_files"`
-	Endpoints  int `json:"endpoints"`
-	Domains    int `json:"domains"`
-	Secrets    int `json:"secrets"`
+	JSFiles   int `json:"js_files"`
+	Endpoints int `json:"endpoints"`
+	Domains   int `json:"domains"`
+	Secrets   int `json:"secrets"`
 }
 
 // Analyzer performs JavaScript discovery and analysis.
 type Analyzer struct {
-	client  *http.Client
-	timeout time.Duration
-	retries int
-	threads int
+	client   *http.Client
+	timeout  time.Duration
+	retries  int
+	threads  int
+	progress registry.Progress
+	errs     *errors.Collector
 }
 
 // NewAnalyzer creates a new JS analyzer.
 func NewAnalyzer(client *http.Client, timeout time.Duration, retries, threads int) *Analyzer {
 	return &Analyzer{
-		client:  client,
-		timeout: timeout,
-		retries: retries,
-		threads: threads,
+		client:   client,
+		timeout:  timeout,
+		retries:  retries,
+		threads:  threads,
+		progress: registry.NopProgress,
+	}
+}
+
+// SetErrors records download failures into c.
+func (a *Analyzer) SetErrors(c *errors.Collector) {
+	a.errs = c
+}
+
+// SetProgress routes per-file download counters to p.
+func (a *Analyzer) SetProgress(p registry.Progress) {
+	if p == nil {
+		p = registry.NopProgress
 	}
+	a.progress = p
 }
 
 // ---------------------------------------------------------------------------
@@ -290,12 +309,23 @@ func (a *Analyzer) downloadJSFiles(ctx context.Context, urls []string) ([]JSFile
 		numWorkers = 1
 	}
 
+	downloadErrors := errors.NewTally("JS downloads")
+	defer downloadErrors.FlushTo(a.errs)
+
+	a.progress.AddTotal(int64(len(urls)))
 	for i := 0; i < numWorkers; i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
 			for j := range jobs {
 				content, err := a.downloadJSFile(ctx, j.url)
+				if err != nil {
+					a.progress.AddError()
+					if ctx.Err() == nil {
+						downloadErrors.Add(err)
+					}
+				}
+				a.progress.Increment(1)
 				results <- result{url: j.url, content: content, err: err}
 			}
 		}()
@@ -335,8 +365,8 @@ func (a *Analyzer) downloadJSFiles(ctx context.Context, urls []string) ([]JSFile
 // ---------------------------------------------------------------------------
 
 var endpointPatterns = []struct {
-	re      *regexp.Regexp
-	method  string
+	re     *regexp.Regexp
+	method string
 }{
 	// fetch("/api/...") or fetch('...')
 	{regexp.MustCompile(`fetch\(["']([^"']+)["']`), ""},
@@ -900,4 +930,4 @@ func (a *Analyzer) AnalyzeAll(files []JSFile, contentMap map[string][]byte) *Res
 	}
 
 	return result
-}
\ No newline at end of file
+}
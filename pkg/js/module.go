@@ -47,6 +47,8 @@ func (m *JSModuleAdapter) Run(ctx context.Context, opts registry.Options) (*regi
 	}
 
 	analyzer := NewAnalyzer(opts.HTTPClient, timeout, retries, threads)
+	analyzer.SetProgress(opts.ProgressReporter())
+	analyzer.SetErrors(opts.Errors)
 	opts.Logger.Debug("Starting JS analysis for %s (threads=%d, timeout=%v)", target, threads, timeout)
 
 	start := time.Now()
@@ -149,25 +151,25 @@ func (m *JSModuleAdapter) Run(ctx context.Context, opts registry.Options) (*regi
 
 	// Create structured JSON for reporting
 	analysisData, _ := json.Marshal(map[string]interface{}{
-		"files":            jsResult.Files,
-		"endpoints":        jsResult.Endpoints,
-		"domains":          jsResult.Domains,
-		"secrets":          jsResult.Secrets,
-		"endpoint_groups":  jsResult.EndpointGroups,
-		"stats":            jsResult.Stats,
+		"files":           jsResult.Files,
+		"endpoints":       jsResult.Endpoints,
+		"domains":         jsResult.Domains,
+		"secrets":         jsResult.Secrets,
+		"endpoint_groups": jsResult.EndpointGroups,
+		"stats":           jsResult.Stats,
 	})
 
 	metadata := map[string]interface{}{
-		"js_files_count":       jsResult.Stats.JSFiles,
-		"endpoints_count":      jsResult.Stats.Endpoints,
-		"domains_count":        jsResult.Stats.Domains,
-		"secrets_count":        jsResult.Stats.Secrets,
-		"duration":             duration.Seconds(),
-		"js_files":             jsURLs,
-		"endpoints":            endpointPaths,
-		"external_domains":     domainNames,
-		"endpoint_categories":  endpointByCategory,
-		"_analysis_json":       string(analysisData),
+		"js_files_count":      jsResult.Stats.JSFiles,
+		"endpoints_count":     jsResult.Stats.Endpoints,
+		"domains_count":       jsResult.Stats.Domains,
+		"secrets_count":       jsResult.Stats.Secrets,
+		"duration":            duration.Seconds(),
+		"js_files":            jsURLs,
+		"endpoints":           endpointPaths,
+		"external_domains":    domainNames,
+		"endpoint_categories": endpointByCategory,
+		"_analysis_json":      string(analysisData),
 	}
 
 	return &registry.Result{
@@ -189,4 +191,4 @@ func secretSeverity(confidence string) string {
 	default:
 		return "medium"
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,23 @@
+package mail
+
+import "context"
+
+// BIMI is a domain's default BIMI record.
+type BIMI struct {
+	Record string `json:"record"`
+	// Logo is the l= tag, the URL of the SVG brand logo.
+	Logo string `json:"logo,omitempty"`
+	// Certificate is the a= tag, the URL of the mark certificate.
+	Certificate string `json:"certificate,omitempty"`
+}
+
+// BIMI fetches the record at default._bimi.<domain>. It returns nil
+// without an error when there is none.
+func (c *Checker) BIMI(ctx context.Context, domain string) (*BIMI, error) {
+	records, err := c.txt(ctx, "default._bimi."+domain, "v=BIMI1")
+	if err != nil || len(records) == 0 {
+		return nil, err
+	}
+	tags := parseTags(records[0])
+	return &BIMI{Record: records[0], Logo: tags["l"], Certificate: tags["a"]}, nil
+}
@@ -0,0 +1,131 @@
+package mail
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/internal/registry"
+)
+
+// DefaultSelectors are DKIM selectors used by common mail providers and
+// senders. DKIM keys cannot be listed, so only these are tried.
+var DefaultSelectors = []string{
+	"default", "dkim", "mail", "email", "smtp", "key1", "key2",
+	"google", "selector1", "selector2", "k1", "k2", "k3", "s1", "s2",
+	"fm1", "fm2", "fm3", "protonmail", "protonmail2", "protonmail3",
+	"zoho", "zmail", "mandrill", "mxvault", "sig1", "pm", "cm",
+	"hs1", "hs2", "zendesk1", "zendesk2", "everlytickey1", "everlytickey2",
+	"mailjet", "sendinblue", "amazonses", "dk", "mta",
+}
+
+// DKIMKey is a public key published for a DKIM selector.
+type DKIMKey struct {
+	Selector string `json:"selector"`
+	Record   string `json:"record"`
+	KeyType  string `json:"key_type"`
+	// Bits is the key size, or 0 when the key could not be parsed.
+	Bits int `json:"bits"`
+	// Revoked reports an empty p= tag, which withdraws the key.
+	Revoked bool `json:"revoked,omitempty"`
+}
+
+// DKIM looks up each selector under <selector>._domainkey.<domain> with up
+// to threads queries in flight and returns the keys found, in selector
+// order. Lookup failures other than missing names go to failures.
+func (c *Checker) DKIM(ctx context.Context, domain string, selectors []string, threads int, progress registry.Progress, failures *errors.Tally) []DKIMKey {
+	if threads <= 0 {
+		threads = 10
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, threads)
+	found := map[string]DKIMKey{}
+	for _, selector := range selectors {
+		wg.Add(1)
+		go func(selector string) {
+			defer wg.Done()
+			defer progress.Increment(1)
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-sem }()
+
+			// Many keys omit the optional v=DKIM1 tag, so any TXT
+			// record with a p= tag counts.
+			answers, err := c.txt(ctx, selector+"._domainkey."+domain, "")
+			if err != nil {
+				if ctx.Err() == nil {
+					progress.AddError()
+					failures.Add(err)
+				}
+				return
+			}
+			for _, record := range answers {
+				if key, ok := ParseDKIM(selector, record); ok {
+					mu.Lock()
+					found[selector] = key
+					mu.Unlock()
+					return
+				}
+			}
+		}(selector)
+	}
+	wg.Wait()
+
+	keys := make([]DKIMKey, 0, len(found))
+	for _, key := range found {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Selector < keys[j].Selector })
+	return keys
+}
+
+// ParseDKIM parses a DKIM key record. ok is false when the record has no
+// p= tag and so is not a key.
+func ParseDKIM(selector, record string) (key DKIMKey, ok bool) {
+	tags := parseTags(record)
+	p, ok := tags["p"]
+	if !ok {
+		return DKIMKey{}, false
+	}
+	key = DKIMKey{Selector: selector, Record: record, KeyType: strings.ToLower(tags["k"])}
+	if key.KeyType == "" {
+		key.KeyType = "rsa"
+	}
+	p = strings.Join(strings.Fields(p), "")
+	if p == "" {
+		key.Revoked = true
+		return key, true
+	}
+	der, err := base64.StdEncoding.DecodeString(p)
+	if err != nil {
+		return key, true
+	}
+	if key.KeyType == "ed25519" {
+		if len(der) == ed25519.PublicKeySize {
+			key.Bits = 256
+		}
+		return key, true
+	}
+	pub, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		// Some signers publish a bare PKCS #1 key.
+		if rsaKey, err := x509.ParsePKCS1PublicKey(der); err == nil {
+			key.Bits = rsaKey.N.BitLen()
+		}
+		return key, true
+	}
+	if rsaKey, isRSA := pub.(*rsa.PublicKey); isRSA {
+		key.Bits = rsaKey.N.BitLen()
+	}
+	return key, true
+}
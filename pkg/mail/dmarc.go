@@ -0,0 +1,79 @@
+package mail
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// DMARC is a domain's published DMARC policy.
+type DMARC struct {
+	Record string `json:"record"`
+	Policy string `json:"policy"`
+	// SubdomainPolicy is the sp tag, or Policy when sp is absent.
+	SubdomainPolicy string   `json:"subdomain_policy"`
+	Percent         int      `json:"pct"`
+	AggregateReport []string `json:"rua,omitempty"`
+	ForensicReport  []string `json:"ruf,omitempty"`
+	// Duplicate reports that several DMARC records were published, which
+	// makes receivers ignore them all.
+	Duplicate bool `json:"duplicate,omitempty"`
+}
+
+// Enforcing reports whether the policy quarantines or rejects all failing
+// mail.
+func (d *DMARC) Enforcing() bool {
+	return d != nil && !d.Duplicate && (d.Policy == "quarantine" || d.Policy == "reject") && d.Percent == 100
+}
+
+// DMARC fetches the DMARC policy published at _dmarc.<domain>. It returns
+// nil without an error when there is none.
+func (c *Checker) DMARC(ctx context.Context, domain string) (*DMARC, error) {
+	records, err := c.txt(ctx, "_dmarc."+domain, "v=DMARC1")
+	if err != nil || len(records) == 0 {
+		return nil, err
+	}
+	dmarc := ParseDMARC(records[0])
+	dmarc.Duplicate = len(records) > 1
+	return dmarc, nil
+}
+
+// ParseDMARC parses a DMARC record. An unknown policy is kept as written
+// so the caller can report it.
+func ParseDMARC(record string) *DMARC {
+	tags := parseTags(record)
+	dmarc := &DMARC{
+		Record:          record,
+		Policy:          strings.ToLower(tags["p"]),
+		SubdomainPolicy: strings.ToLower(tags["sp"]),
+		Percent:         100,
+		AggregateReport: reportAddresses(tags["rua"]),
+		ForensicReport:  reportAddresses(tags["ruf"]),
+	}
+	if dmarc.SubdomainPolicy == "" {
+		dmarc.SubdomainPolicy = dmarc.Policy
+	}
+	if pct, err := strconv.Atoi(tags["pct"]); err == nil && pct >= 0 && pct <= 100 {
+		dmarc.Percent = pct
+	}
+	return dmarc
+}
+
+// reportAddresses returns the addresses of a rua or ruf list without their
+// mailto: scheme and size limits.
+func reportAddresses(list string) []string {
+	var addrs []string
+	for _, uri := range strings.Split(list, ",") {
+		uri = strings.TrimSpace(uri)
+		if i := strings.LastIndexByte(uri, '!'); i > 0 {
+			uri = uri[:i]
+		}
+		if len(uri) > len("mailto:") && strings.EqualFold(uri[:len("mailto:")], "mailto:") {
+			uri = uri[len("mailto:"):]
+		}
+		if uri != "" {
+			addrs = append(addrs, uri)
+		}
+	}
+	return addrs
+}
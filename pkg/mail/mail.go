@@ -0,0 +1,86 @@
+// Package mail audits a domain's email authentication and transport
+// security: SPF, DMARC, DKIM, MTA-STS and BIMI.
+package mail
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/pkg/resolver"
+)
+
+// Checker looks up mail policies through a resolver pool. Client fetches
+// MTA-STS policies and defaults to http.DefaultClient.
+type Checker struct {
+	Pool   *resolver.Pool
+	Client *http.Client
+}
+
+// txt returns the TXT records of name that start with the version tag,
+// such as "v=spf1". A missing name is not an error.
+func (c *Checker) txt(ctx context.Context, name, version string) ([]string, error) {
+	answers, err := c.Pool.Query(ctx, name, resolver.TypeTXT)
+	if err != nil {
+		if errors.TypeOf(err) == errors.ErrorTypeNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var records []string
+	for _, r := range answers {
+		if r.Type == resolver.TypeTXT && hasVersion(r.Value, version) {
+			records = append(records, strings.TrimSpace(r.Value))
+		}
+	}
+	return records, nil
+}
+
+// mx returns the lowercased mail exchangers of domain. A null MX is
+// returned as an empty slice.
+func (c *Checker) mx(ctx context.Context, domain string) ([]string, error) {
+	answers, err := c.Pool.Query(ctx, domain, resolver.TypeMX)
+	if err != nil {
+		if errors.TypeOf(err) == errors.ErrorTypeNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var hosts []string
+	for _, r := range answers {
+		if r.Type == resolver.TypeMX && r.Value != "" && r.Value != "." {
+			hosts = append(hosts, strings.ToLower(r.Value))
+		}
+	}
+	return hosts, nil
+}
+
+// hasVersion reports whether record starts with the version tag, ignoring
+// case, followed by a space, a semicolon or nothing. An empty version
+// matches every record.
+func hasVersion(record, version string) bool {
+	if version == "" {
+		return true
+	}
+	record = strings.TrimSpace(record)
+	if len(record) < len(version) || !strings.EqualFold(record[:len(version)], version) {
+		return false
+	}
+	rest := record[len(version):]
+	return rest == "" || rest[0] == ' ' || rest[0] == ';'
+}
+
+// parseTags splits a "k=v; k=v" record, as used by DMARC, DKIM, MTA-STS
+// and BIMI, into lowercased keys and trimmed values.
+func parseTags(record string) map[string]string {
+	tags := map[string]string{}
+	for _, part := range strings.Split(record, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+		tags[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+	}
+	return tags
+}
@@ -0,0 +1,160 @@
+package mail
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/config"
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/internal/logger"
+	"github.com/NASHEDIxCODER/gospyder/internal/registry"
+	"github.com/NASHEDIxCODER/gospyder/pkg/resolver"
+	"github.com/NASHEDIxCODER/gospyder/tests/mocks"
+)
+
+func TestModuleReportsWeakMailPolicies(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dns := mocks.NewDNSServer(t,
+		"example.com 300 MX 10 mx1.example.com",
+		"example.com 300 MX 20 mx2.backup.net",
+		"example.com 300 TXT v=spf1 include:_spf.example.net ~all",
+		"_spf.example.net 300 TXT v=spf1 ip4:192.0.2.0/24 include:missing.example.org -all",
+		"_dmarc.example.com 300 TXT v=DMARC1; p=none; rua=mailto:dmarc@example.com",
+		"selector1._domainkey.example.com 300 TXT v=DKIM1; k=rsa; p="+base64.StdEncoding.EncodeToString(der),
+		"_mta-sts.example.com 300 TXT v=STSv1; id=20240101",
+		"default._bimi.example.com 300 TXT v=BIMI1; l=https://example.com/logo.svg",
+	)
+	web := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Host != "mta-sts.example.com" || r.URL.Path != "/.well-known/mta-sts.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("version: STSv1\nmode: testing\nmx: mx1.example.com\nmax_age: 86400\n"))
+	}))
+	defer web.Close()
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, web.Listener.Addr().String())
+		},
+	}}
+
+	cfg := config.DefaultConfig()
+	cfg.Threads = 8
+	cfg.DNS.Resolvers = []string{dns.Addr}
+	result, err := NewModule().Run(context.Background(), registry.Options{
+		Config:     cfg,
+		Logger:     logger.New(false),
+		Errors:     errors.NewCollector(),
+		HTTPClient: client,
+		Flags:      map[string]interface{}{"target": "example.com"},
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	var got []string
+	for _, f := range result.Findings {
+		got = append(got, f.Type+" "+f.Severity+" "+f.Description)
+	}
+	sort.Strings(got)
+	want := []string{
+		"bimi low BIMI record is ignored because DMARC is not enforced",
+		"dkim low DKIM RSA key is 1024 bits; 2048 is recommended",
+		"dmarc medium DMARC policy is p=none, which only monitors failing mail",
+		"mta_sts low MTA-STS policy is in testing mode and not enforced",
+		"mta_sts medium MX host mx2.backup.net is not covered by the MTA-STS policy",
+		"spf low SPF policy ends in ~all, only soft-failing unlisted senders",
+		"spf medium SPF policy fails to evaluate: include:missing.example.org has no SPF record",
+		"spoofing high Mail from this domain can be spoofed: no enforcing DMARC policy and no hard-failing SPF policy",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("findings:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	spf, _ := result.Metadata["spf"].(*SPF)
+	if spf == nil || spf.Lookups != 2 || spf.VoidLookups != 1 || spf.All != "~" {
+		t.Errorf("spf = %+v", spf)
+	}
+}
+
+func TestModuleDoesNotReportPoliciesItCouldNotLookUp(t *testing.T) {
+	dns := mocks.NewDNSServer(t, "example.com 300 MX 10 mx1.example.com")
+	dns.FailNames("example.com", "_dmarc.example.com")
+	cfg := config.DefaultConfig()
+	cfg.DNS.Resolvers = []string{dns.Addr}
+	collector := errors.NewCollector()
+	result, err := NewModule().Run(context.Background(), registry.Options{
+		Config: cfg,
+		Logger: logger.New(false),
+		Errors: collector,
+		Flags:  map[string]interface{}{"target": "example.com"},
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	for _, f := range result.Findings {
+		if f.Type == "spf" || f.Type == "dmarc" || f.Type == "spoofing" {
+			t.Errorf("finding %s %q despite failed lookups", f.Type, f.Description)
+		}
+	}
+	if _, ok := result.Metadata["spoofable"]; ok {
+		t.Errorf("spoofable = %v, want unset", result.Metadata["spoofable"])
+	}
+	if !collector.HasErrors() {
+		t.Error("failed lookups were not reported")
+	}
+}
+
+func TestSPFCountsLookupsAcrossIncludesAndRedirects(t *testing.T) {
+	dns := mocks.NewDNSServer(t,
+		"example.com 300 TXT v=spf1 a mx include:one.example.net redirect=two.example.net",
+		"one.example.net 300 TXT v=spf1 a mx ptr include:example.com -all",
+		"two.example.net 300 TXT v=spf1 a mx a:x.example.net a:y.example.net a:z.example.net -all",
+	)
+	cfg := config.DefaultConfig()
+	cfg.DNS.Resolvers = []string{dns.Addr}
+	pool, err := resolver.NewPoolFromConfig(context.Background(), cfg.DNS, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spf, err := (&Checker{Pool: pool}).SPF(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("SPF() error = %v", err)
+	}
+	if spf.Lookups != 13 || spf.All != "-" || !spf.PTR {
+		t.Errorf("spf = %+v, want 13 lookups ending in -all via the redirect", spf)
+	}
+	want := []string{"include:example.com loops back on itself", "13 DNS lookups exceed the limit of 10"}
+	if strings.Join(spf.Errors, "; ") != strings.Join(want, "; ") {
+		t.Errorf("errors = %q, want %q", spf.Errors, want)
+	}
+}
+
+func TestParseDMARC(t *testing.T) {
+	d := ParseDMARC("v=DMARC1; p=Reject; pct=50; rua=mailto:a@example.com!10m, mailto:b@example.net")
+	if d.Policy != "reject" || d.SubdomainPolicy != "reject" || d.Percent != 50 || d.Enforcing() {
+		t.Errorf("ParseDMARC() = %+v", d)
+	}
+	if strings.Join(d.AggregateReport, ",") != "a@example.com,b@example.net" {
+		t.Errorf("rua = %v", d.AggregateReport)
+	}
+}
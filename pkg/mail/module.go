@@ -0,0 +1,318 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/internal/registry"
+	"github.com/NASHEDIxCODER/gospyder/pkg/resolver"
+)
+
+// ModuleAdapter audits the target's email spoofing and transport security
+// posture.
+type ModuleAdapter struct{}
+
+// NewModule creates a new email security module
+func NewModule() registry.Module {
+	return &ModuleAdapter{}
+}
+
+// Name returns the module name
+func (m *ModuleAdapter) Name() string {
+	return "mail"
+}
+
+// Description returns the module description
+func (m *ModuleAdapter) Description() string {
+	return "Email security posture (SPF, DMARC, DKIM selectors, MTA-STS, BIMI)"
+}
+
+// Run checks the SPF, DMARC, DKIM, MTA-STS and BIMI records of the target
+// and reports weak or missing ones. The "selectors" flag adds DKIM
+// selectors to DefaultSelectors.
+func (m *ModuleAdapter) Run(ctx context.Context, opts registry.Options) (*registry.Result, error) {
+	target, ok := opts.Flags["target"].(string)
+	if !ok || target == "" {
+		return nil, fmt.Errorf("target flag required")
+	}
+	domain := strings.ToLower(strings.TrimSuffix(target, "."))
+
+	pool, err := resolver.NewPoolFromConfig(ctx, opts.Config.DNS, opts.Telemetry, opts.Replay)
+	if err != nil {
+		return nil, err
+	}
+	checker := &Checker{Pool: pool, Client: opts.HTTPClient}
+	extra, _ := opts.Flags["selectors"].(string)
+	selectors := selectorList(extra)
+
+	failures := errors.NewTally("mail checks")
+	defer failures.FlushTo(opts.Errors)
+	progress := opts.ProgressReporter()
+	progress.AddTotal(int64(5 + len(selectors)))
+	step := func(err error) {
+		if err != nil && ctx.Err() == nil {
+			progress.AddError()
+			failures.Add(err)
+		}
+		progress.Increment(1)
+	}
+
+	opts.Logger.Info("Checking mail policies of %s (%d DKIM selectors)", domain, len(selectors))
+	mx, err := checker.mx(ctx, domain)
+	step(err)
+	spf, spfErr := checker.SPF(ctx, domain)
+	step(spfErr)
+	dmarc, dmarcErr := checker.DMARC(ctx, domain)
+	step(dmarcErr)
+	// A failed lookup says nothing about whether a record exists.
+	spfKnown, dmarcKnown := spf != nil || spfErr == nil, dmarcErr == nil
+	keys := checker.DKIM(ctx, domain, selectors, opts.Config.Threads, progress, failures)
+	sts, err := checker.MTASTS(ctx, domain)
+	step(err)
+	bimi, err := checker.BIMI(ctx, domain)
+	step(err)
+
+	var findings []registry.Finding
+	if spfKnown {
+		findings = append(findings, spfFindings(domain, spf)...)
+	}
+	if dmarcKnown {
+		findings = append(findings, dmarcFindings(domain, dmarc)...)
+	}
+	findings = append(findings, dkimFindings(domain, keys, len(selectors))...)
+	findings = append(findings, mtastsFindings(domain, sts, mx)...)
+	findings = append(findings, bimiFindings(domain, bimi, dmarc, dmarcKnown)...)
+
+	// Without an enforcing DMARC policy or a hard-failing SPF policy,
+	// nothing stops mail claiming to be from the domain. Either policy
+	// may be the enforcing one, so both must be known to say so.
+	hardFail := spf != nil && spf.All == "-" && len(spf.Errors) == 0
+	spoofable := spfKnown && dmarcKnown && !dmarc.Enforcing() && !hardFail
+	if spoofable {
+		findings = append(findings, registry.Finding{
+			Type:        "spoofing",
+			Value:       domain,
+			Description: "Mail from this domain can be spoofed: no enforcing DMARC policy and no hard-failing SPF policy",
+			Severity:    "high",
+		})
+	}
+	for range findings {
+		progress.AddFinding()
+	}
+
+	metadata := map[string]interface{}{
+		"mx":              mx,
+		"dkim":            keys,
+		"selectors_tried": len(selectors),
+	}
+	if dmarcKnown {
+		metadata["dmarc_enforced"] = dmarc.Enforcing()
+	}
+	if spfKnown && dmarcKnown {
+		metadata["spoofable"] = spoofable
+	}
+	if spf != nil {
+		metadata["spf"] = spf
+	}
+	if dmarc != nil {
+		metadata["dmarc"] = dmarc
+	}
+	if sts != nil {
+		metadata["mta_sts"] = sts
+	}
+	if bimi != nil {
+		metadata["bimi"] = bimi
+	}
+
+	return &registry.Result{
+		Module:    m.Name(),
+		Timestamp: time.Now(),
+		Status:    "success",
+		Target:    target,
+		Findings:  findings,
+		Metadata:  metadata,
+	}, nil
+}
+
+// selectorList returns DefaultSelectors followed by the comma-separated
+// extra selectors, without duplicates.
+func selectorList(extra string) []string {
+	seen := map[string]bool{}
+	var selectors []string
+	for _, s := range append(append([]string{}, DefaultSelectors...), strings.Split(extra, ",")...) {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if s != "" && !seen[s] {
+			seen[s] = true
+			selectors = append(selectors, s)
+		}
+	}
+	return selectors
+}
+
+func mailFinding(kind, name, severity, description, record string) registry.Finding {
+	f := registry.Finding{
+		Type:        kind,
+		Value:       name,
+		Description: description,
+		Severity:    severity,
+		Metadata:    map[string]interface{}{},
+	}
+	if record != "" {
+		f.Evidence = []string{record}
+		f.Metadata["record"] = record
+	}
+	return f
+}
+
+func spfFindings(domain string, spf *SPF) []registry.Finding {
+	if spf == nil {
+		return []registry.Finding{mailFinding("spf", domain, "medium",
+			"No SPF record; receivers cannot tell which servers may send for the domain", "")}
+	}
+	var findings []registry.Finding
+	add := func(severity, description string) {
+		f := mailFinding("spf", domain, severity, description, spf.Record)
+		f.Metadata["lookups"] = spf.Lookups
+		f.Metadata["all"] = spf.All
+		findings = append(findings, f)
+	}
+	for _, problem := range spf.Errors {
+		add("medium", "SPF policy fails to evaluate: "+problem)
+	}
+	switch spf.All {
+	case "+":
+		add("high", "SPF policy ends in +all, authorizing every server to send")
+	case "?":
+		add("medium", "SPF policy ends in ?all, leaving unlisted senders neutral")
+	case "~":
+		add("low", "SPF policy ends in ~all, only soft-failing unlisted senders")
+	case "":
+		add("medium", "SPF policy has no all mechanism, leaving unlisted senders neutral")
+	}
+	if spf.PTR {
+		add("low", "SPF policy uses the deprecated ptr mechanism")
+	}
+	if len(findings) == 0 {
+		add("info", fmt.Sprintf("SPF policy ends in -all (%d DNS lookups)", spf.Lookups))
+	}
+	return findings
+}
+
+func dmarcFindings(domain string, dmarc *DMARC) []registry.Finding {
+	name := "_dmarc." + domain
+	if dmarc == nil {
+		return []registry.Finding{mailFinding("dmarc", name, "medium",
+			"No DMARC record; receivers apply no policy to mail failing SPF and DKIM", "")}
+	}
+	var findings []registry.Finding
+	add := func(severity, description string) {
+		f := mailFinding("dmarc", name, severity, description, dmarc.Record)
+		f.Metadata["policy"] = dmarc.Policy
+		findings = append(findings, f)
+	}
+	if dmarc.Duplicate {
+		add("medium", "Several DMARC records are published, so receivers ignore them all")
+	}
+	switch dmarc.Policy {
+	case "none":
+		add("medium", "DMARC policy is p=none, which only monitors failing mail")
+	case "quarantine", "reject":
+		if dmarc.Percent < 100 {
+			add("low", fmt.Sprintf("DMARC policy applies to only %d%% of failing mail", dmarc.Percent))
+		}
+		if dmarc.SubdomainPolicy == "none" {
+			add("low", "DMARC subdomain policy is sp=none, leaving subdomains unprotected")
+		}
+	default:
+		add("medium", fmt.Sprintf("DMARC record has an invalid or missing policy %q", dmarc.Policy))
+	}
+	if len(dmarc.AggregateReport) == 0 {
+		add("low", "DMARC record has no rua address, so failures go unreported")
+	}
+	if len(findings) == 0 {
+		add("info", "DMARC policy is p="+dmarc.Policy)
+	}
+	return findings
+}
+
+func dkimFindings(domain string, keys []DKIMKey, tried int) []registry.Finding {
+	if len(keys) == 0 {
+		return []registry.Finding{mailFinding("dkim", domain, "low",
+			fmt.Sprintf("No DKIM key found under %d common selectors", tried), "")}
+	}
+	findings := make([]registry.Finding, 0, len(keys))
+	for _, key := range keys {
+		severity, description := "info", fmt.Sprintf("DKIM %s key of %d bits", key.KeyType, key.Bits)
+		switch {
+		case key.Revoked:
+			description = "DKIM key is revoked"
+		case key.Bits == 0:
+			severity, description = "low", "DKIM key could not be parsed"
+		case key.KeyType == "rsa" && key.Bits < 1024:
+			severity, description = "high", fmt.Sprintf("DKIM RSA key is only %d bits and can be factored", key.Bits)
+		case key.KeyType == "rsa" && key.Bits < 2048:
+			severity, description = "low", fmt.Sprintf("DKIM RSA key is %d bits; 2048 is recommended", key.Bits)
+		}
+		f := mailFinding("dkim", key.Selector+"._domainkey."+domain, severity, description, key.Record)
+		f.Metadata["selector"] = key.Selector
+		f.Metadata["bits"] = key.Bits
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+func mtastsFindings(domain string, sts *MTASTS, mx []string) []registry.Finding {
+	name := "_mta-sts." + domain
+	if sts == nil {
+		if len(mx) == 0 {
+			return nil
+		}
+		return []registry.Finding{mailFinding("mta_sts", name, "low",
+			"No MTA-STS policy; inbound SMTP TLS can be downgraded", "")}
+	}
+	var findings []registry.Finding
+	add := func(severity, description string) {
+		f := mailFinding("mta_sts", name, severity, description, sts.Record)
+		f.Metadata["policy_url"] = sts.PolicyURL
+		f.Metadata["mode"] = sts.Mode
+		findings = append(findings, f)
+	}
+	switch {
+	case sts.FetchError != "":
+		add("medium", "MTA-STS record is published but the policy could not be fetched: "+sts.FetchError)
+		return findings
+	case !sts.Valid():
+		add("medium", "MTA-STS policy is malformed")
+		return findings
+	case sts.Mode == "none":
+		add("low", "MTA-STS policy mode is none")
+		return findings
+	case sts.Mode == "testing":
+		add("low", "MTA-STS policy is in testing mode and not enforced")
+	}
+	for _, host := range mx {
+		if !sts.Covers(host) {
+			add("medium", "MX host "+host+" is not covered by the MTA-STS policy")
+		}
+	}
+	if len(findings) == 0 {
+		add("info", "MTA-STS policy is enforced")
+	}
+	return findings
+}
+
+func bimiFindings(domain string, bimi *BIMI, dmarc *DMARC, dmarcKnown bool) []registry.Finding {
+	if bimi == nil {
+		return nil
+	}
+	f := mailFinding("bimi", "default._bimi."+domain, "info", "BIMI logo published", bimi.Record)
+	f.Metadata["logo"] = bimi.Logo
+	if dmarcKnown && !dmarc.Enforcing() {
+		f.Severity = "low"
+		f.Description = "BIMI record is ignored because DMARC is not enforced"
+	}
+	return []registry.Finding{f}
+}
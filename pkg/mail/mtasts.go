@@ -0,0 +1,136 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+)
+
+// maxPolicySize is the largest MTA-STS policy RFC 8461 lets senders accept.
+const maxPolicySize = 64 << 10
+
+// MTASTS is a domain's MTA-STS record and the policy it announces.
+type MTASTS struct {
+	Record string `json:"record"`
+	ID     string `json:"id"`
+	// PolicyURL is where the policy was fetched from.
+	PolicyURL string   `json:"policy_url"`
+	Version   string   `json:"version,omitempty"`
+	Mode      string   `json:"mode,omitempty"`
+	MX        []string `json:"mx,omitempty"`
+	MaxAge    int      `json:"max_age,omitempty"`
+	// FetchError explains why the policy could not be fetched.
+	FetchError string `json:"fetch_error,omitempty"`
+}
+
+// Valid reports whether the policy was fetched and is well formed.
+func (m *MTASTS) Valid() bool {
+	if m.FetchError != "" || m.Version != "STSv1" {
+		return false
+	}
+	switch m.Mode {
+	case "enforce", "testing":
+		return len(m.MX) > 0 && m.MaxAge > 0
+	case "none":
+		return m.MaxAge > 0
+	}
+	return false
+}
+
+// Covers reports whether the policy's mx patterns match host. A pattern
+// like *.example.net matches exactly one extra label.
+func (m *MTASTS) Covers(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, pattern := range m.MX {
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+			if label, rest, found := strings.Cut(host, "."); found && label != "" && rest == suffix {
+				return true
+			}
+		} else if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// MTASTS fetches the _mta-sts TXT record of domain and, when it exists,
+// the policy at https://mta-sts.<domain>/.well-known/mta-sts.txt. It
+// returns nil without an error when no record is published. Policy fetch
+// failures are kept in FetchError rather than returned.
+func (c *Checker) MTASTS(ctx context.Context, domain string) (*MTASTS, error) {
+	records, err := c.txt(ctx, "_mta-sts."+domain, "v=STSv1")
+	if err != nil || len(records) == 0 {
+		return nil, err
+	}
+	sts := &MTASTS{
+		Record:    records[0],
+		ID:        parseTags(records[0])["id"],
+		PolicyURL: "https://mta-sts." + domain + "/.well-known/mta-sts.txt",
+	}
+	body, err := c.fetchPolicy(ctx, sts.PolicyURL)
+	if err != nil {
+		sts.FetchError = err.Error()
+		return sts, nil
+	}
+	parsePolicy(sts, body)
+	return sts, nil
+}
+
+// fetchPolicy gets an MTA-STS policy. RFC 8461 forbids following
+// redirects, so a redirect is reported as a failure.
+func (c *Checker) fetchPolicy(ctx context.Context, url string) (string, error) {
+	client := http.DefaultClient
+	if c.Client != nil {
+		client = c.Client
+	}
+	noRedirects := *client
+	noRedirects.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := noRedirects.Do(req)
+	if err != nil {
+		return "", errors.NewNetworkError("fetch MTA-STS policy", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetch MTA-STS policy: HTTP %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPolicySize))
+	if err != nil {
+		return "", errors.NewNetworkError("read MTA-STS policy", err)
+	}
+	return string(body), nil
+}
+
+// parsePolicy fills sts from the "key: value" lines of a policy.
+func parsePolicy(sts *MTASTS, body string) {
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "version":
+			sts.Version = value
+		case "mode":
+			sts.Mode = strings.ToLower(value)
+		case "mx":
+			sts.MX = append(sts.MX, strings.ToLower(strings.TrimSuffix(value, ".")))
+		case "max_age":
+			sts.MaxAge, _ = strconv.Atoi(value)
+		}
+	}
+}
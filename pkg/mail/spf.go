@@ -0,0 +1,144 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	// SPFLookupLimit is the number of DNS-querying terms RFC 7208 allows
+	// one SPF evaluation before it fails with a permanent error.
+	SPFLookupLimit = 10
+	// SPFVoidLimit is the number of lookups returning nothing RFC 7208
+	// allows before evaluation fails.
+	SPFVoidLimit = 2
+	// maxSPFRecords stops pathological include trees from being walked
+	// forever.
+	maxSPFRecords = 50
+)
+
+// SPF is a domain's SPF policy with its include and redirect tree walked.
+type SPF struct {
+	Record string `json:"record"`
+	// All is the qualifier of the effective "all" mechanism, following
+	// redirects: "-", "~", "?", "+", or empty when there is none.
+	All         string   `json:"all"`
+	Includes    []string `json:"includes,omitempty"`
+	Lookups     int      `json:"lookups"`
+	VoidLookups int      `json:"void_lookups"`
+	// PTR reports use of the deprecated ptr mechanism.
+	PTR bool `json:"ptr,omitempty"`
+	// Errors lists problems that make receivers fail the policy.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// SPF fetches and walks the SPF policy of domain. It returns nil without
+// an error when the domain publishes none.
+func (c *Checker) SPF(ctx context.Context, domain string) (*SPF, error) {
+	records, err := c.txt(ctx, domain, "v=spf1")
+	if err != nil || len(records) == 0 {
+		return nil, err
+	}
+	spf := &SPF{Record: records[0]}
+	if len(records) > 1 {
+		spf.Errors = append(spf.Errors, fmt.Sprintf("%d SPF records published for %s", len(records), domain))
+	}
+	w := &spfWalk{checker: c, spf: spf, path: map[string]bool{domain: true}}
+	spf.All = w.walk(ctx, records[0])
+	if spf.Lookups > SPFLookupLimit {
+		spf.Errors = append(spf.Errors, fmt.Sprintf("%d DNS lookups exceed the limit of %d", spf.Lookups, SPFLookupLimit))
+	}
+	if spf.VoidLookups > SPFVoidLimit {
+		spf.Errors = append(spf.Errors, fmt.Sprintf("%d void lookups exceed the limit of %d", spf.VoidLookups, SPFVoidLimit))
+	}
+	return spf, w.err
+}
+
+type spfWalk struct {
+	checker *Checker
+	spf     *SPF
+	path    map[string]bool
+	fetched int
+	err     error
+}
+
+// walk counts the lookups of one record, descending into includes and a
+// redirect, and returns the record's effective "all" qualifier.
+func (w *spfWalk) walk(ctx context.Context, record string) string {
+	all, redirect := "", ""
+	for _, term := range strings.Fields(record)[1:] {
+		qualifier := "+"
+		if strings.ContainsRune("+-~?", rune(term[0])) {
+			qualifier, term = term[:1], term[1:]
+		}
+		name, arg := term, ""
+		if i := strings.IndexAny(term, ":=/"); i >= 0 {
+			name, arg = term[:i], term[i+1:]
+		}
+		switch strings.ToLower(name) {
+		case "all":
+			all = qualifier
+		case "include":
+			w.spf.Lookups++
+			w.follow(ctx, "include:", arg)
+		case "a", "mx", "exists":
+			w.spf.Lookups++
+		case "ptr":
+			w.spf.Lookups++
+			w.spf.PTR = true
+		case "redirect":
+			redirect = arg
+		case "ip4", "ip6", "exp":
+		default:
+			if !strings.Contains(term, "=") {
+				w.spf.Errors = append(w.spf.Errors, fmt.Sprintf("unknown mechanism %q", term))
+			}
+		}
+	}
+	// A redirect only applies when the record has no "all" of its own.
+	if redirect != "" && all == "" {
+		w.spf.Lookups++
+		return w.follow(ctx, "redirect=", redirect)
+	}
+	return all
+}
+
+// follow walks the record an include or redirect points at and returns
+// its effective "all" qualifier.
+func (w *spfWalk) follow(ctx context.Context, via, domain string) string {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	// Macros expand per message, so the target cannot be fetched here.
+	if domain == "" || strings.Contains(domain, "%") {
+		return ""
+	}
+	if w.path[domain] {
+		w.spf.Errors = append(w.spf.Errors, fmt.Sprintf("%s%s loops back on itself", via, domain))
+		return ""
+	}
+	if w.fetched >= maxSPFRecords || ctx.Err() != nil {
+		return ""
+	}
+	w.fetched++
+
+	records, err := w.checker.txt(ctx, domain, "v=spf1")
+	if err != nil {
+		if w.err == nil {
+			w.err = err
+		}
+		return ""
+	}
+	if len(records) == 0 {
+		w.spf.VoidLookups++
+		w.spf.Errors = append(w.spf.Errors, fmt.Sprintf("%s%s has no SPF record", via, domain))
+		return ""
+	}
+	if len(records) > 1 {
+		w.spf.Errors = append(w.spf.Errors, fmt.Sprintf("%d SPF records published for %s", len(records), domain))
+	}
+	w.spf.Includes = append(w.spf.Includes, domain)
+
+	w.path[domain] = true
+	defer delete(w.path, domain)
+	return w.walk(ctx, records[0])
+}
@@ -3,5 +3,5 @@ package models
 // Domain represents a discovered subdomain
 type Domain struct {
 	Name   string
-	Source string // "certstream", "brute", "recursive"
+	Source string // "certstream", "brute", "recursive", "axfr", "nsec"
 }
\ No newline at end of file
@@ -0,0 +1,193 @@
+package origin
+
+import (
+	"bufio"
+	"context"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/pkg/resolver"
+)
+
+// HistoryFile is the workspace file the dns module appends the A and
+// AAAA records it sees to, one "<RFC3339 time> <record>" per line.
+const HistoryFile = "dns-history.txt"
+
+// maxSPFRange caps how many addresses of one SPF ip4:/ip6: range become
+// candidates; larger ranges are provider blocks, not servers.
+const maxSPFRange = 16
+
+// Candidate is an address that may serve the site directly.
+type Candidate struct {
+	IP      string   `json:"ip"`
+	Sources []string `json:"sources"`
+}
+
+// candidateSet collects candidates and the reasons they were found.
+type candidateSet map[string]*Candidate
+
+func (s candidateSet) add(ip, source string) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return
+	}
+	ip = addr.Unmap().String()
+	c, ok := s[ip]
+	if !ok {
+		c = &Candidate{IP: ip}
+		s[ip] = c
+	}
+	for _, existing := range c.Sources {
+		if existing == source {
+			return
+		}
+	}
+	c.Sources = append(c.Sources, source)
+}
+
+func (s candidateSet) sorted() []*Candidate {
+	list := make([]*Candidate, 0, len(s))
+	for _, c := range s {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].IP < list[j].IP })
+	return list
+}
+
+// HistoryRecord is an address record seen for a name in the past.
+type HistoryRecord struct {
+	Record resolver.Record
+	Seen   time.Time
+}
+
+// ReadHistory reads A and AAAA records for apex and names below it from
+// a history file. Lines may omit the leading timestamp. A missing file
+// holds no history.
+func ReadHistory(path, apex string) ([]HistoryRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.NewIOError("open DNS history", err)
+	}
+	defer file.Close()
+
+	var records []HistoryRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var seen time.Time
+		if stamp, rest, ok := strings.Cut(line, " "); ok {
+			if t, err := time.Parse(time.RFC3339, stamp); err == nil {
+				seen, line = t, rest
+			}
+		}
+		r, err := resolver.ParseRecord(line)
+		if err != nil || (r.Type != resolver.TypeA && r.Type != resolver.TypeAAAA) {
+			continue
+		}
+		r.Name = strings.ToLower(strings.TrimSuffix(r.Name, "."))
+		if r.Name == apex || strings.HasSuffix(r.Name, "."+apex) {
+			records = append(records, HistoryRecord{Record: r, Seen: seen})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.NewIOError("read DNS history", err)
+	}
+	return records, nil
+}
+
+// mailHosts returns the addresses of domain's mail exchangers and of the
+// hosts and addresses its own SPF record authorizes, keyed by address
+// with the reason each was found. Includes are skipped: they name third
+// party senders.
+func mailHosts(ctx context.Context, pool *resolver.Pool, domain string) (map[string]string, error) {
+	found := map[string]string{}
+	resolve := func(host, source string) {
+		if h, err := pool.Resolve(ctx, host); err == nil {
+			for _, ip := range h.IPs {
+				if _, ok := found[ip]; !ok {
+					found[ip] = source
+				}
+			}
+		}
+	}
+
+	var firstErr error
+	mx, err := pool.Query(ctx, domain, resolver.TypeMX)
+	if err != nil && errors.TypeOf(err) != errors.ErrorTypeNotFound {
+		firstErr = err
+	}
+	for _, r := range mx {
+		if r.Type == resolver.TypeMX && r.Value != "" && r.Value != "." {
+			host := strings.ToLower(strings.TrimSuffix(r.Value, "."))
+			resolve(host, "MX "+host)
+		}
+	}
+
+	txt, err := pool.Query(ctx, domain, resolver.TypeTXT)
+	if err != nil && errors.TypeOf(err) != errors.ErrorTypeNotFound && firstErr == nil {
+		firstErr = err
+	}
+	for _, r := range txt {
+		fields := strings.Fields(strings.ToLower(r.Value))
+		if r.Type != resolver.TypeTXT || len(fields) == 0 || fields[0] != "v=spf1" {
+			continue
+		}
+		for _, term := range fields[1:] {
+			term = strings.TrimLeft(term, "+")
+			name, value, _ := strings.Cut(term, ":")
+			switch name {
+			case "ip4", "ip6":
+				for _, ip := range spfRange(value) {
+					if _, ok := found[ip]; !ok {
+						found[ip] = "SPF " + term
+					}
+				}
+			case "a", "mx":
+				host, _, _ := strings.Cut(value, "/")
+				if host == "" || host == domain {
+					// The bare forms name the domain itself, which is the
+					// CDN front, or its MX hosts, already covered.
+					continue
+				}
+				if name == "a" {
+					resolve(host, "SPF "+term)
+					continue
+				}
+				hosts, _ := pool.Query(ctx, host, resolver.TypeMX)
+				for _, h := range hosts {
+					if h.Type == resolver.TypeMX && h.Value != "" && h.Value != "." {
+						resolve(strings.TrimSuffix(h.Value, "."), "SPF "+term)
+					}
+				}
+			}
+		}
+	}
+	return found, firstErr
+}
+
+// spfRange expands an SPF address or CIDR, returning nothing for ranges
+// above maxSPFRange addresses.
+func spfRange(value string) []string {
+	if addr, err := netip.ParseAddr(value); err == nil {
+		return []string{addr.Unmap().String()}
+	}
+	prefix, err := netip.ParsePrefix(value)
+	if err != nil || prefix.Addr().BitLen()-prefix.Bits() > 4 {
+		return nil
+	}
+	var ips []string
+	for addr := prefix.Masked().Addr(); prefix.Contains(addr); addr = addr.Next() {
+		ips = append(ips, addr.Unmap().String())
+	}
+	return ips
+}
@@ -0,0 +1,346 @@
+package origin
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/internal/registry"
+	"github.com/NASHEDIxCODER/gospyder/internal/workspace"
+	"github.com/NASHEDIxCODER/gospyder/pkg/ptr"
+	"github.com/NASHEDIxCODER/gospyder/pkg/resolver"
+)
+
+// ModuleAdapter looks for origin servers behind a CDN or WAF.
+type ModuleAdapter struct{}
+
+// NewModule creates a new origin discovery module
+func NewModule() registry.Module {
+	return &ModuleAdapter{}
+}
+
+// Name returns the module name
+func (m *ModuleAdapter) Name() string {
+	return "origin"
+}
+
+// Description returns the module description
+func (m *ModuleAdapter) Description() string {
+	return "Origin IP discovery behind CDNs and WAFs, verified by response similarity"
+}
+
+// Run gathers candidate origin addresses from the workspace DNS history
+// (and the "history" flag's file), enum results outside the CDN, the
+// domain's MX and SPF hosts, and certificates for the domain served by
+// candidates, scanned hosts and the "ips" flag's addresses. Each candidate
+// is then asked for the site by Host header and compared with the
+// CDN-fronted response; a score of at least the "threshold" flag marks
+// the origin. When a prior waf run found nothing, there is no CDN to
+// look behind and the module does nothing.
+func (m *ModuleAdapter) Run(ctx context.Context, opts registry.Options) (*registry.Result, error) {
+	target, ok := opts.Flags["target"].(string)
+	if !ok || target == "" {
+		return nil, fmt.Errorf("target flag required")
+	}
+	domain := strings.ToLower(strings.TrimSuffix(target, "."))
+	result := &registry.Result{
+		Module:    m.Name(),
+		Timestamp: time.Now(),
+		Status:    "success",
+		Target:    target,
+		Findings:  []registry.Finding{},
+		Metadata:  map[string]interface{}{},
+	}
+
+	waf := priorResult(opts, "waf")
+	if waf != nil && len(waf.Findings) == 0 {
+		opts.Logger.Info("No CDN or WAF detected in front of %s, skipping origin discovery", domain)
+		result.Metadata["skipped"] = "no CDN or WAF detected"
+		return result, nil
+	}
+	if waf != nil {
+		result.Metadata["waf"] = waf.Findings[0].Value
+	}
+	threshold, _ := opts.Flags["threshold"].(float64)
+	if threshold <= 0 {
+		threshold = DefaultThreshold
+	}
+
+	pool, err := resolver.NewPoolFromConfig(ctx, opts.Config.DNS, opts.Telemetry, opts.Replay)
+	if err != nil {
+		return nil, err
+	}
+	failures := errors.NewTally("origin checks")
+	defer failures.FlushTo(opts.Errors)
+	progress := opts.ProgressReporter()
+	fail := func(err error) {
+		if err != nil && ctx.Err() == nil && errors.TypeOf(err) != errors.ErrorTypeNotFound {
+			progress.AddError()
+			failures.Add(err)
+		}
+	}
+
+	// The domain's current addresses are the CDN front.
+	front := map[string]bool{}
+	host, err := pool.Resolve(ctx, domain)
+	fail(err)
+	if host != nil {
+		for _, ip := range host.IPs {
+			front[ip] = true
+		}
+	}
+
+	candidates := candidateSet{}
+	histories := []string{filepath.Join(opts.Config.Workspace.Path, workspace.SanitizeTarget(domain), HistoryFile)}
+	if path, _ := opts.Flags["history"].(string); path != "" {
+		histories = append(histories, path)
+	}
+	for _, path := range histories {
+		records, err := ReadHistory(path, domain)
+		if err != nil {
+			return nil, err
+		}
+		for _, h := range records {
+			source := "DNS history " + h.Record.Name
+			if !h.Seen.IsZero() {
+				source += " (" + h.Seen.Format("2006-01-02") + ")"
+			}
+			candidates.add(h.Record.Value, source)
+		}
+	}
+	if enum := priorResult(opts, "enum"); enum != nil {
+		for _, f := range enum.Findings {
+			ips, _ := f.Metadata["ips"].([]string)
+			for _, ip := range ips {
+				candidates.add(ip, "enum "+f.Value)
+			}
+		}
+	}
+	mail, err := mailHosts(ctx, pool, domain)
+	fail(err)
+	for ip, source := range mail {
+		candidates.add(ip, source)
+	}
+
+	ipList, _ := opts.Flags["ips"].(string)
+	extra, err := ptr.Addresses(splitList(ipList))
+	if err != nil {
+		return nil, err
+	}
+	scanned := scannedAddresses(opts)
+	for _, addr := range extra {
+		scanned = append(scanned, addr.String())
+	}
+
+	excluded := map[string]string{}
+	skip := func(ip string) bool {
+		if front[ip] {
+			excluded[ip] = "current address of " + domain
+			return true
+		}
+		if info, ok := opts.Intel.Lookup(ip); ok && info.CDN {
+			excluded[ip] = "CDN range (" + info.Provider + ")"
+			return true
+		}
+		return false
+	}
+	var certTargets []string
+	seen := map[string]bool{}
+	for _, ip := range append(keys(candidates), scanned...) {
+		if !seen[ip] && !skip(ip) {
+			seen[ip] = true
+			certTargets = append(certTargets, ip)
+		}
+	}
+
+	verifier := &Verifier{
+		Host:    domain,
+		Timeout: opts.Config.HTTP.Timeout,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			dialer := &net.Dialer{Timeout: opts.Config.HTTP.Timeout}
+			return opts.Replay.DialContext(ctx, network, address, dialer.DialContext)
+		},
+	}
+
+	opts.Logger.Info("Checking certificates of %d address(es) for %s", len(certTargets), domain)
+	var mu sync.Mutex
+	each(ctx, certTargets, opts.Config.Threads, progress, func(ip string) {
+		names, err := verifier.Certificate(ctx, ip)
+		if err != nil {
+			// Most addresses have nothing on 443; that is not a failure.
+			return
+		}
+		if name, ok := MatchingName(names, domain); ok {
+			mu.Lock()
+			candidates.add(ip, "TLS certificate for "+name)
+			mu.Unlock()
+		}
+	})
+
+	var list []*Candidate
+	for _, c := range candidates.sorted() {
+		if !skip(c.IP) {
+			list = append(list, c)
+		}
+	}
+
+	baseline, err := Baseline(ctx, opts.HTTPClient, domain)
+	fail(err)
+	if baseline != nil {
+		result.Metadata["baseline"] = baseline.describe()
+	}
+
+	opts.Logger.Info("Verifying %d candidate origin(s) for %s", len(list), domain)
+	responses := make(map[string]*Response, len(list))
+	each(ctx, ips(list), opts.Config.Threads, progress, func(ip string) {
+		resp, err := verifier.Fetch(ctx, ip)
+		if err != nil {
+			return
+		}
+		mu.Lock()
+		responses[ip] = resp
+		mu.Unlock()
+	})
+
+	verified := 0
+	for _, c := range list {
+		finding := candidateFinding(domain, c, responses[c.IP], baseline, threshold)
+		if finding.Type == "origin" {
+			verified++
+		}
+		result.Findings = append(result.Findings, finding)
+		progress.AddFinding()
+	}
+	sort.SliceStable(result.Findings, func(i, j int) bool {
+		return result.Findings[i].Type == "origin" && result.Findings[j].Type != "origin"
+	})
+
+	result.Metadata["candidates"] = len(list)
+	result.Metadata["verified"] = verified
+	result.Metadata["certificates_checked"] = len(certTargets)
+	result.Metadata["threshold"] = threshold
+	if len(excluded) > 0 {
+		result.Metadata["excluded"] = excluded
+	}
+	return result, nil
+}
+
+// candidateFinding rates one candidate against the baseline response.
+func candidateFinding(domain string, c *Candidate, resp, baseline *Response, threshold float64) registry.Finding {
+	finding := registry.Finding{
+		Type:     "origin_candidate",
+		Value:    c.IP,
+		Severity: "info",
+		Evidence: append([]string{}, c.Sources...),
+		Metadata: map[string]interface{}{
+			"ip":      c.IP,
+			"sources": c.Sources,
+		},
+	}
+	switch {
+	case resp == nil:
+		finding.Description = "No HTTP response for " + domain
+		return finding
+	case resp.Edge != "":
+		finding.Description = "Answers through " + resp.Edge + ", not an origin"
+		finding.Metadata["edge"] = resp.Edge
+	case baseline == nil:
+		finding.Severity = "low"
+		finding.Description = "Serves " + domain + " directly; no CDN baseline to compare with"
+	default:
+		score := Similarity(baseline, resp)
+		finding.Metadata["similarity"] = score
+		finding.Description = fmt.Sprintf("Serves %s directly (similarity %.2f)", domain, score)
+		finding.Severity = "low"
+		if score >= threshold {
+			finding.Type = "origin"
+			finding.Severity = "high"
+			finding.Description = fmt.Sprintf("Origin server of %s reachable without the CDN (similarity %.2f)", domain, score)
+		}
+	}
+	finding.Evidence = append(finding.Evidence, resp.describe())
+	finding.Metadata["status"] = resp.Status
+	if resp.Title != "" {
+		finding.Metadata["title"] = resp.Title
+	}
+	return finding
+}
+
+// each runs fn for every item with up to threads calls in flight.
+func each(ctx context.Context, items []string, threads int, progress registry.Progress, fn func(string)) {
+	if threads <= 0 {
+		threads = 10
+	}
+	progress.AddTotal(int64(len(items)))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, threads)
+	for _, item := range items {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		}
+		wg.Add(1)
+		go func(item string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			defer progress.Increment(1)
+			fn(item)
+		}(item)
+	}
+	wg.Wait()
+}
+
+// scannedAddresses returns the addresses ports scanned and those ptr
+// swept.
+func scannedAddresses(opts registry.Options) []string {
+	var addrs []string
+	if ports := priorResult(opts, "ports"); ports != nil {
+		for _, f := range ports.Findings {
+			ips, _ := f.Metadata["ips"].([]string)
+			addrs = append(addrs, ips...)
+		}
+	}
+	if sweep := priorResult(opts, "ptr"); sweep != nil {
+		mapping, _ := sweep.Metadata["ptr"].(map[string][]string)
+		for ip := range mapping {
+			addrs = append(addrs, ip)
+		}
+	}
+	sort.Strings(addrs)
+	return addrs
+}
+
+func priorResult(opts registry.Options, module string) *registry.Result {
+	results, _ := opts.Flags["results"].(map[string]*registry.Result)
+	return results[module]
+}
+
+func keys(s candidateSet) []string {
+	return ips(s.sorted())
+}
+
+func ips(list []*Candidate) []string {
+	out := make([]string, len(list))
+	for i, c := range list {
+		out[i] = c.IP
+	}
+	return out
+}
+
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,251 @@
+// Package origin finds the real servers behind a CDN or WAF and verifies
+// them by comparing their responses with the CDN-fronted site.
+package origin
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/internal/replay"
+)
+
+// DefaultThreshold is the similarity at which a candidate counts as the
+// origin.
+const DefaultThreshold = 0.75
+
+// maxBody caps how much of each response is compared.
+const maxBody = 512 << 10
+
+var (
+	titlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	tokenPattern = regexp.MustCompile(`[\pL\pN_]{3,}`)
+)
+
+// edgeHeaders identify responses served by a CDN edge rather than an
+// origin. Header names are canonical; an empty value matches any.
+var edgeHeaders = []struct {
+	header, value, provider string
+}{
+	{"Cf-Ray", "", "Cloudflare"},
+	{"Server", "cloudflare", "Cloudflare"},
+	{"Server", "akamaighost", "Akamai"},
+	{"X-Akamai-Transformed", "", "Akamai"},
+	{"X-Amz-Cf-Id", "", "CloudFront"},
+	{"X-Fastly-Request-Id", "", "Fastly"},
+	{"X-Sucuri-Id", "", "Sucuri"},
+	{"X-Iinfo", "", "Imperva"},
+}
+
+// Response is the part of an HTTP response compared between the site and
+// a candidate.
+type Response struct {
+	URL      string
+	Status   int
+	Title    string
+	Location string
+	// Edge names the CDN whose headers the response carries, if any.
+	Edge   string
+	tokens map[string]bool
+}
+
+// Baseline fetches the site through its public, CDN-fronted address,
+// trying HTTPS first. Redirects are not followed, so they compare like
+// for like with candidates.
+func Baseline(ctx context.Context, client *http.Client, host string) (*Response, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	noRedirects := *client
+	noRedirects.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+
+	var lastErr error
+	for _, scheme := range []string{"https", "http"} {
+		resp, err := fetch(ctx, &noRedirects, scheme+"://"+host+"/", "")
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+	}
+	return nil, errors.NewNetworkError("fetch baseline "+host, lastErr)
+}
+
+// Verifier requests the site directly from candidate addresses.
+type Verifier struct {
+	// Host is the site's name, sent as the Host header and TLS server name.
+	Host string
+	// Dial opens connections; nil uses a net.Dialer.
+	Dial replay.DialFunc
+	// Timeout bounds each request and handshake; zero means 10 seconds.
+	Timeout time.Duration
+}
+
+func (v *Verifier) timeout() time.Duration {
+	if v.Timeout > 0 {
+		return v.Timeout
+	}
+	return 10 * time.Second
+}
+
+func (v *Verifier) dial(ctx context.Context, network, address string) (net.Conn, error) {
+	if v.Dial != nil {
+		return v.Dial(ctx, network, address)
+	}
+	return (&net.Dialer{Timeout: v.timeout()}).DialContext(ctx, network, address)
+}
+
+// Fetch requests the site from ip, trying HTTPS and then HTTP. Origins
+// often serve self-signed or origin-CA certificates, so they are not
+// verified.
+func (v *Verifier) Fetch(ctx context.Context, ip string) (*Response, error) {
+	client := &http.Client{
+		Timeout: v.timeout(),
+		Transport: &http.Transport{
+			DialContext:       v.dial,
+			TLSClientConfig:   &tls.Config{ServerName: v.Host, InsecureSkipVerify: true},
+			DisableKeepAlives: true,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	var lastErr error
+	for _, scheme := range []string{"https", "http"} {
+		resp, err := fetch(ctx, client, scheme+"://"+net.JoinHostPort(ip, defaultPort(scheme))+"/", v.Host)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+	}
+	return nil, errors.NewNetworkError("fetch "+v.Host+" from "+ip, lastErr)
+}
+
+// Certificate returns the names on the certificate ip serves on port 443
+// for the site.
+func (v *Verifier) Certificate(ctx context.Context, ip string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, v.timeout())
+	defer cancel()
+	conn, err := v.dial(ctx, "tcp", net.JoinHostPort(ip, "443"))
+	if err != nil {
+		return nil, errors.NewNetworkError("connect to "+ip, err)
+	}
+	defer conn.Close()
+	client := tls.Client(conn, &tls.Config{ServerName: v.Host, InsecureSkipVerify: true})
+	if err := client.HandshakeContext(ctx); err != nil {
+		return nil, errors.NewNetworkError("TLS handshake with "+ip, err)
+	}
+	certs := client.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return nil, nil
+	}
+	names := append([]string{}, certs[0].DNSNames...)
+	if cn := certs[0].Subject.CommonName; cn != "" {
+		names = append(names, cn)
+	}
+	return names, nil
+}
+
+// MatchingName returns the first certificate name that is apex, below it
+// or a wildcard for it.
+func MatchingName(names []string, apex string) (string, bool) {
+	for _, name := range names {
+		n := strings.ToLower(strings.TrimPrefix(name, "*."))
+		if n == apex || strings.HasSuffix(n, "."+apex) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// Similarity scores how alike two responses are, from 0 to 1: body word
+// overlap counts for 60%, and matching titles and status codes 20% each.
+// Redirects compare by status and target.
+func Similarity(a, b *Response) float64 {
+	score := 0.6 * jaccard(a.tokens, b.tokens)
+	if a.Title == b.Title {
+		score += 0.2
+	}
+	if a.Status == b.Status && a.Location == b.Location {
+		score += 0.2
+	}
+	return score
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	shared := 0
+	for token := range a {
+		if b[token] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+func fetch(ctx context.Context, client *http.Client, url, host string) (*Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if host != "" {
+		req.Host = host
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBody))
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Response{
+		URL:      url,
+		Status:   resp.StatusCode,
+		Location: resp.Header.Get("Location"),
+		tokens:   map[string]bool{},
+	}
+	if m := titlePattern.FindSubmatch(body); m != nil {
+		r.Title = strings.Join(strings.Fields(html.UnescapeString(string(m[1]))), " ")
+	}
+	for _, token := range tokenPattern.FindAllString(strings.ToLower(string(body)), -1) {
+		r.tokens[token] = true
+	}
+	for _, edge := range edgeHeaders {
+		value := resp.Header.Get(edge.header)
+		if value != "" && (edge.value == "" || strings.Contains(strings.ToLower(value), edge.value)) {
+			r.Edge = edge.provider
+			break
+		}
+	}
+	return r, nil
+}
+
+func defaultPort(scheme string) string {
+	if scheme == "https" {
+		return "443"
+	}
+	return "80"
+}
+
+// describe summarizes a response for evidence lines.
+func (r *Response) describe() string {
+	s := fmt.Sprintf("%s: HTTP %d", r.URL, r.Status)
+	if r.Title != "" {
+		s += " " + fmt.Sprintf("%q", r.Title)
+	}
+	if r.Location != "" {
+		s += " -> " + r.Location
+	}
+	return s
+}
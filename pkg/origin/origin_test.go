@@ -0,0 +1,210 @@
+package origin
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/config"
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/internal/logger"
+	"github.com/NASHEDIxCODER/gospyder/internal/registry"
+	"github.com/NASHEDIxCODER/gospyder/tests/mocks"
+)
+
+const page = `<html><head><title>Example Shop</title></head><body>
+<h1>Welcome to the example shop</h1><p>Browse our catalogue of widgets, gadgets and gizmos.</p>
+<a href="/cart">Cart</a> <a href="/account">Account</a></body></html>`
+
+func TestModuleGathersCandidates(t *testing.T) {
+	dns := mocks.NewDNSServer(t,
+		"example.com 300 A 127.0.0.1",
+		"example.com 300 MX 10 mail.example.com",
+		"mail.example.com 300 A 127.0.0.10",
+		"example.com 300 TXT v=spf1 ip4:127.0.0.20 a:relay.example.com include:_spf.example.net -all",
+		"relay.example.com 300 A 127.0.0.30",
+	)
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "example.com"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	history := strings.Join([]string{
+		"2023-05-01T10:00:00Z example.com 300 A 127.0.0.40",
+		"2024-02-01T10:00:00Z example.com 300 A 127.0.0.1",
+		"other.org 300 A 127.0.0.99",
+	}, "\n")
+	if err := os.WriteFile(filepath.Join(root, "example.com", HistoryFile), []byte(history), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cdn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(page))
+	}))
+	defer cdn.Close()
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, cdn.Listener.Addr().String())
+		},
+	}}
+
+	cfg := config.DefaultConfig()
+	cfg.DNS.Resolvers = []string{dns.Addr}
+	cfg.Workspace.Path = root
+	cfg.HTTP.Timeout = 500 * time.Millisecond
+	results := map[string]*registry.Result{
+		"waf": {Module: "waf", Findings: []registry.Finding{{Type: "waf", Value: "Cloudflare"}}},
+		"enum": {Module: "enum", Findings: []registry.Finding{
+			{Type: "subdomain", Value: "dev.example.com", Metadata: map[string]interface{}{"ips": []string{"127.0.0.50"}}},
+			{Type: "subdomain", Value: "www.example.com", Metadata: map[string]interface{}{"ips": []string{"127.0.0.1"}}},
+		}},
+	}
+	result, err := NewModule().Run(context.Background(), registry.Options{
+		Config:     cfg,
+		Logger:     logger.New(false),
+		Errors:     errors.NewCollector(),
+		HTTPClient: client,
+		Flags:      map[string]interface{}{"target": "example.com", "results": results},
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	got := map[string]string{}
+	for _, f := range result.Findings {
+		got[f.Value] = strings.Join(f.Metadata["sources"].([]string), "; ")
+	}
+	want := map[string]string{
+		"127.0.0.10": "MX mail.example.com",
+		"127.0.0.20": "SPF ip4:127.0.0.20",
+		"127.0.0.30": "SPF a:relay.example.com",
+		"127.0.0.40": "DNS history example.com (2023-05-01)",
+		"127.0.0.50": "enum dev.example.com",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("candidates = %v, want %v", got, want)
+	}
+	for ip, sources := range want {
+		if got[ip] != sources {
+			t.Errorf("sources of %s = %q, want %q", ip, got[ip], sources)
+		}
+	}
+	if excluded, _ := result.Metadata["excluded"].(map[string]string); excluded["127.0.0.1"] == "" {
+		t.Errorf("front address not excluded: %v", result.Metadata["excluded"])
+	}
+}
+
+func TestModuleSkipsWithoutWAF(t *testing.T) {
+	result, err := NewModule().Run(context.Background(), registry.Options{
+		Config: config.DefaultConfig(),
+		Logger: logger.New(false),
+		Flags: map[string]interface{}{
+			"target":  "example.com",
+			"results": map[string]*registry.Result{"waf": {Module: "waf"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Metadata["skipped"] == nil || len(result.Findings) != 0 {
+		t.Fatalf("result = %+v, want skipped", result)
+	}
+}
+
+func TestVerifierComparesWithBaseline(t *testing.T) {
+	cdn := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "cloudflare")
+		w.Write([]byte(page))
+	}))
+	defer cdn.Close()
+	backends := map[string]http.HandlerFunc{
+		"origin": func(w http.ResponseWriter, r *http.Request) {
+			if r.Host != "example.com" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write([]byte(strings.Replace(page, "gizmos", "gizmos and more", 1)))
+		},
+		"default": func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("<html><head><title>Welcome to nginx!</title></head><body>It works</body></html>"))
+		},
+		"edge": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("CF-RAY", "8a1b2c3d4e5f-AMS")
+			w.Write([]byte(page))
+		},
+	}
+	servers := map[string]string{}
+	for name, handler := range backends {
+		srv := httptest.NewTLSServer(handler)
+		defer srv.Close()
+		servers[name] = srv.Listener.Addr().String()
+	}
+	// Candidate addresses stand for the backends: 192.0.2.1 is the origin,
+	// .2 a default vhost and .3 another CDN edge.
+	byIP := map[string]string{"192.0.2.1": servers["origin"], "192.0.2.2": servers["default"], "192.0.2.3": servers["edge"]}
+	verifier := &Verifier{
+		Host:    "example.com",
+		Timeout: 2 * time.Second,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			host, port, _ := net.SplitHostPort(address)
+			if port != "443" || byIP[host] == "" {
+				return nil, &net.OpError{Op: "dial", Net: network, Err: errors.NewValidationError("refused")}
+			}
+			return (&net.Dialer{}).DialContext(ctx, network, byIP[host])
+		},
+	}
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, cdn.Listener.Addr().String())
+		},
+	}}
+
+	ctx := context.Background()
+	baseline, err := Baseline(ctx, client, "example.com")
+	if err != nil {
+		t.Fatalf("Baseline() error = %v", err)
+	}
+	if baseline.Edge != "Cloudflare" || baseline.Title != "Example Shop" {
+		t.Fatalf("baseline = %+v", baseline)
+	}
+
+	origin, err := verifier.Fetch(ctx, "192.0.2.1")
+	if err != nil {
+		t.Fatalf("Fetch(origin) error = %v", err)
+	}
+	if score := Similarity(baseline, origin); score < DefaultThreshold {
+		t.Errorf("origin similarity = %.2f, want >= %.2f", score, DefaultThreshold)
+	}
+	other, err := verifier.Fetch(ctx, "192.0.2.2")
+	if err != nil {
+		t.Fatalf("Fetch(default) error = %v", err)
+	}
+	if score := Similarity(baseline, other); score >= DefaultThreshold {
+		t.Errorf("default vhost similarity = %.2f, want < %.2f", score, DefaultThreshold)
+	}
+	edge, err := verifier.Fetch(ctx, "192.0.2.3")
+	if err != nil || edge.Edge != "Cloudflare" {
+		t.Errorf("Fetch(edge) = %+v, %v; want a Cloudflare edge", edge, err)
+	}
+	if _, err := verifier.Fetch(ctx, "192.0.2.4"); err == nil {
+		t.Error("Fetch(unreachable) succeeded")
+	}
+
+	// httptest certificates are issued for example.com and its subdomains.
+	names, err := verifier.Certificate(ctx, "192.0.2.1")
+	if err != nil {
+		t.Fatalf("Certificate() error = %v", err)
+	}
+	if name, ok := MatchingName(names, "example.com"); !ok {
+		t.Errorf("MatchingName(%v) = %q, false", names, name)
+	}
+	if _, ok := MatchingName(names, "example.org"); ok {
+		t.Errorf("MatchingName(%v, example.org) matched", names)
+	}
+}
@@ -0,0 +1,223 @@
+package ptr
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/internal/registry"
+	"github.com/NASHEDIxCODER/gospyder/pkg/resolver"
+)
+
+// ModuleAdapter sweeps PTR records of known addresses for sibling
+// hostnames.
+type ModuleAdapter struct{}
+
+// NewModule creates a new reverse DNS module
+func NewModule() registry.Module {
+	return &ModuleAdapter{}
+}
+
+// Name returns the module name
+func (m *ModuleAdapter) Name() string {
+	return "ptr"
+}
+
+// Description returns the module description
+func (m *ModuleAdapter) Description() string {
+	return "Reverse DNS (PTR) sweep of IPs, CIDRs and enum addresses for sibling hostnames"
+}
+
+// Run looks up the PTR records of the target (an IP or CIDR, or the
+// addresses of a domain), the "ips" and "list" flags, and the addresses
+// of prior enum and ports results. Names under the target domain or the
+// "apex" flag's domains become findings and are added to the enum result.
+func (m *ModuleAdapter) Run(ctx context.Context, opts registry.Options) (*registry.Result, error) {
+	target, ok := opts.Flags["target"].(string)
+	if !ok || target == "" {
+		return nil, fmt.Errorf("target flag required")
+	}
+	target = strings.ToLower(strings.TrimSuffix(target, "."))
+
+	pool, err := resolver.NewPoolFromConfig(ctx, opts.Config.DNS, opts.Telemetry, opts.Replay)
+	if err != nil {
+		return nil, err
+	}
+
+	var apexes, specs []string
+	if isAddress(target) {
+		specs = append(specs, target)
+	} else {
+		apexes = append(apexes, target)
+		if host, err := pool.Resolve(ctx, target); err == nil {
+			specs = append(specs, host.IPs...)
+		}
+	}
+	apexList, _ := opts.Flags["apex"].(string)
+	apexes = append(apexes, splitList(apexList)...)
+	ipList, _ := opts.Flags["ips"].(string)
+	specs = append(specs, splitList(ipList)...)
+	if path, _ := opts.Flags["list"].(string); path != "" {
+		lines, err := readList(path)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, lines...)
+	}
+	enum := priorResult(opts, "enum")
+	specs = append(specs, resultAddresses(enum, priorResult(opts, "ports"))...)
+
+	addrs, err := Addresses(specs)
+	if err != nil {
+		return nil, err
+	}
+	if sweep, _ := opts.Flags["sweep"].(int); sweep > 0 {
+		if addrs, err = Addresses(Widen(addrs, sweep)); err != nil {
+			return nil, err
+		}
+	}
+
+	opts.Logger.Info("Looking up PTR records for %d address(es)", len(addrs))
+	mapping := Reverse(ctx, pool, addrs, opts.Config.Threads, opts.ProgressReporter(), opts.Errors)
+
+	known := map[string]bool{}
+	if enum != nil {
+		for _, f := range enum.Findings {
+			known[strings.ToLower(f.Value)] = true
+		}
+	}
+	names := map[string][]string{}
+	outOfScope := 0
+	for ip, ptrs := range mapping {
+		for _, name := range ptrs {
+			if InScope(name, apexes) {
+				names[name] = append(names[name], ip)
+			} else {
+				outOfScope++
+			}
+		}
+	}
+
+	findings := make([]registry.Finding, 0, len(names))
+	added := 0
+	for _, name := range sortedKeys(names) {
+		ips := names[name]
+		sort.Strings(ips)
+		finding := registry.Finding{
+			Type:        "subdomain",
+			Value:       name,
+			Description: "PTR of " + strings.Join(ips, ", "),
+			Severity:    "info",
+			Metadata: map[string]interface{}{
+				"source": "ptr",
+				"ips":    ips,
+				"new":    !known[name],
+			},
+		}
+		findings = append(findings, finding)
+		if enum != nil && !known[name] {
+			enum.Findings = append(enum.Findings, registry.Finding{
+				Type:     "subdomain",
+				Value:    name,
+				Severity: "info",
+				Metadata: map[string]interface{}{"source": "ptr", "sources": []string{"ptr"}, "ips": ips},
+			})
+			added++
+		}
+	}
+
+	metadata := map[string]interface{}{
+		"addresses":    len(addrs),
+		"ptr":          mapping,
+		"out_of_scope": outOfScope,
+	}
+	if len(apexes) > 0 {
+		metadata["apex"] = apexes
+	}
+	if enum != nil {
+		metadata["added_to_enum"] = added
+	}
+
+	return &registry.Result{
+		Module:    m.Name(),
+		Timestamp: time.Now(),
+		Status:    "success",
+		Target:    target,
+		Findings:  findings,
+		Metadata:  metadata,
+	}, nil
+}
+
+// resultAddresses returns the addresses enum resolved its subdomains to
+// and those of every host ports found open.
+func resultAddresses(enum, ports *registry.Result) []string {
+	var addrs []string
+	for _, result := range []*registry.Result{enum, ports} {
+		if result == nil {
+			continue
+		}
+		for _, f := range result.Findings {
+			ips, _ := f.Metadata["ips"].([]string)
+			addrs = append(addrs, ips...)
+		}
+	}
+	return addrs
+}
+
+func priorResult(opts registry.Options, module string) *registry.Result {
+	results, _ := opts.Flags["results"].(map[string]*registry.Result)
+	return results[module]
+}
+
+// isAddress reports whether s is an IP address or CIDR.
+func isAddress(s string) bool {
+	if _, err := netip.ParseAddr(s); err == nil {
+		return true
+	}
+	_, err := netip.ParsePrefix(s)
+	return err == nil
+}
+
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func readList(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.NewIOError("open address list", err)
+	}
+	defer file.Close()
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.NewIOError("read address list", err)
+	}
+	return lines, nil
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,146 @@
+package ptr
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/internal/registry"
+	"github.com/NASHEDIxCODER/gospyder/pkg/resolver"
+)
+
+// MaxAddresses caps how many addresses one sweep looks up.
+const MaxAddresses = 1 << 16
+
+// Addresses expands IPs and CIDRs into sorted, distinct addresses. Network
+// and broadcast addresses of IPv4 prefixes are kept, since they may carry
+// PTR records too. A sweep larger than MaxAddresses is refused.
+func Addresses(specs []string) ([]netip.Addr, error) {
+	seen := map[netip.Addr]bool{}
+	var addrs []netip.Addr
+	add := func(addr netip.Addr) error {
+		if seen[addr] {
+			return nil
+		}
+		if len(addrs) == MaxAddresses {
+			return errors.NewValidationError(fmt.Sprintf("PTR sweep exceeds %d addresses", MaxAddresses))
+		}
+		seen[addr] = true
+		addrs = append(addrs, addr)
+		return nil
+	}
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		if !strings.Contains(spec, "/") {
+			addr, err := netip.ParseAddr(spec)
+			if err != nil {
+				return nil, errors.NewValidationError(fmt.Sprintf("invalid IP address %q", spec))
+			}
+			if err := add(addr.Unmap()); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		prefix, err := netip.ParsePrefix(spec)
+		if err != nil {
+			return nil, errors.NewValidationError(fmt.Sprintf("invalid CIDR %q", spec))
+		}
+		prefix = prefix.Masked()
+		if bits := prefix.Addr().BitLen() - prefix.Bits(); bits > 16 {
+			return nil, errors.NewValidationError(fmt.Sprintf("CIDR %s is larger than a /%d", spec, prefix.Addr().BitLen()-16))
+		}
+		for addr := prefix.Addr(); prefix.Contains(addr); addr = addr.Next() {
+			if err := add(addr); err != nil {
+				return nil, err
+			}
+		}
+	}
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i].Less(addrs[j]) })
+	return addrs, nil
+}
+
+// Widen returns the /bits prefix enclosing each IPv4 address, so the
+// neighbours of a known host are swept too. IPv6 addresses are kept as
+// they are; bits of 0 or 32 widens nothing.
+func Widen(addrs []netip.Addr, bits int) []string {
+	seen := map[string]bool{}
+	var specs []string
+	for _, addr := range addrs {
+		spec := addr.String()
+		if addr.Is4() && bits > 0 && bits < 32 {
+			prefix, _ := addr.Prefix(bits)
+			spec = prefix.String()
+		}
+		if !seen[spec] {
+			seen[spec] = true
+			specs = append(specs, spec)
+		}
+	}
+	return specs
+}
+
+// Reverse looks up the PTR records of addrs with up to threads queries in
+// flight and returns the names found per address.
+func Reverse(ctx context.Context, pool *resolver.Pool, addrs []netip.Addr, threads int, progress registry.Progress, errs *errors.Collector) map[string][]string {
+	if threads <= 0 {
+		threads = 10
+	}
+	failures := errors.NewTally("PTR lookups")
+	defer failures.FlushTo(errs)
+	progress.AddTotal(int64(len(addrs)))
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, threads)
+	found := map[string][]string{}
+	for _, addr := range addrs {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return found
+		}
+		wg.Add(1)
+		go func(ip string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			defer progress.Increment(1)
+
+			names, err := pool.LookupAddr(ctx, ip)
+			if err != nil && ctx.Err() == nil && errors.TypeOf(err) != errors.ErrorTypeNotFound {
+				progress.AddError()
+				failures.Add(err)
+			}
+			if len(names) == 0 {
+				return
+			}
+			sort.Strings(names)
+			mu.Lock()
+			found[ip] = names
+			mu.Unlock()
+		}(addr.String())
+	}
+	wg.Wait()
+	return found
+}
+
+// InScope reports whether name is one of apexes or below one. With no
+// apexes every name is in scope.
+func InScope(name string, apexes []string) bool {
+	if len(apexes) == 0 {
+		return true
+	}
+	for _, apex := range apexes {
+		if name == apex || strings.HasSuffix(name, "."+apex) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package ptr
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/config"
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/internal/logger"
+	"github.com/NASHEDIxCODER/gospyder/internal/registry"
+	"github.com/NASHEDIxCODER/gospyder/tests/mocks"
+)
+
+func TestAddressesExpandsCIDRs(t *testing.T) {
+	addrs, err := Addresses([]string{"192.0.2.6", "192.0.2.4/30", "2001:db8::1"})
+	if err != nil {
+		t.Fatalf("Addresses() error = %v", err)
+	}
+	if fmt.Sprint(addrs) != "[192.0.2.4 192.0.2.5 192.0.2.6 192.0.2.7 2001:db8::1]" {
+		t.Fatalf("Addresses() = %v", addrs)
+	}
+	if fmt.Sprint(Widen(addrs[:2], 24)) != "[192.0.2.0/24]" {
+		t.Fatalf("Widen() = %v", Widen(addrs[:2], 24))
+	}
+	for _, spec := range []string{"10.0.0.0/8", "2001:db8::/64", "example.com"} {
+		if _, err := Addresses([]string{spec}); err == nil {
+			t.Errorf("Addresses(%s) succeeded", spec)
+		}
+	}
+}
+
+func TestModuleFeedsInScopeNamesToEnum(t *testing.T) {
+	dns := mocks.NewDNSServer(t,
+		"example.com 300 A 192.0.2.10",
+		"10.2.0.192.in-addr.arpa 300 PTR www.example.com",
+		"11.2.0.192.in-addr.arpa 300 PTR mail.example.com",
+		"12.2.0.192.in-addr.arpa 300 PTR host.other.net",
+		"13.2.0.192.in-addr.arpa 300 PTR vpn.example.org",
+	)
+	enum := &registry.Result{Module: "enum", Findings: []registry.Finding{
+		{Type: "subdomain", Value: "www.example.com", Metadata: map[string]interface{}{"ips": []string{"192.0.2.13"}}},
+	}}
+
+	cfg := config.DefaultConfig()
+	cfg.Threads = 4
+	cfg.DNS.Resolvers = []string{dns.Addr}
+	result, err := NewModule().Run(context.Background(), registry.Options{
+		Config: cfg,
+		Logger: logger.New(false),
+		Errors: errors.NewCollector(),
+		Flags: map[string]interface{}{
+			"target":  "example.com",
+			"ips":     "192.0.2.11/32, 192.0.2.12",
+			"apex":    "example.org",
+			"results": map[string]*registry.Result{"enum": enum},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	var names []string
+	for _, f := range result.Findings {
+		names = append(names, fmt.Sprintf("%s:%v", f.Value, f.Metadata["new"]))
+	}
+	if fmt.Sprint(names) != "[mail.example.com:true vpn.example.org:true www.example.com:false]" {
+		t.Fatalf("findings = %v", names)
+	}
+	if len(enum.Findings) != 3 || enum.Findings[1].Value != "mail.example.com" || enum.Findings[2].Metadata["source"] != "ptr" {
+		t.Fatalf("enum findings = %+v, want the two new names appended", enum.Findings)
+	}
+	mapping, _ := result.Metadata["ptr"].(map[string][]string)
+	if fmt.Sprint(mapping["192.0.2.12"]) != "[host.other.net]" || result.Metadata["out_of_scope"] != 1 || result.Metadata["addresses"] != 4 {
+		t.Fatalf("metadata = %v", result.Metadata)
+	}
+}
+
+func TestResultAddressesReadsEveryScannedHost(t *testing.T) {
+	enum := &registry.Result{Module: "enum", Findings: []registry.Finding{
+		{Type: "subdomain", Value: "www.example.com", Metadata: map[string]interface{}{"ips": []string{"192.0.2.10"}}},
+	}}
+	ports := &registry.Result{Module: "ports", Metadata: map[string]interface{}{"scan_host": "multiple"}, Findings: []registry.Finding{
+		{Type: "open_port", Value: "api.example.com:443/tcp", Metadata: map[string]interface{}{"ips": []string{"192.0.2.20", "2001:db8::20"}}},
+		{Type: "open_port", Value: "mail.example.com:25/tcp", Metadata: map[string]interface{}{"ips": []string{"192.0.2.30"}}},
+	}}
+	if got := fmt.Sprint(resultAddresses(enum, ports)); got != "[192.0.2.10 192.0.2.20 2001:db8::20 192.0.2.30]" {
+		t.Fatalf("resultAddresses() = %s", got)
+	}
+	if got := resultAddresses(nil, nil); len(got) != 0 {
+		t.Fatalf("resultAddresses(nil, nil) = %v", got)
+	}
+}
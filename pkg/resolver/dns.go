@@ -0,0 +1,461 @@
+package resolver
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// RecordType names a DNS record type.
+type RecordType string
+
+const (
+	TypeA     RecordType = "A"
+	TypeAAAA  RecordType = "AAAA"
+	TypeCNAME RecordType = "CNAME"
+	TypeMX    RecordType = "MX"
+	TypeNS    RecordType = "NS"
+	TypePTR   RecordType = "PTR"
+	TypeTXT   RecordType = "TXT"
+	TypeSOA   RecordType = "SOA"
+	TypeNSEC  RecordType = "NSEC"
+	TypeNSEC3 RecordType = "NSEC3"
+)
+
+var wireTypes = map[RecordType]dnsmessage.Type{
+	TypeA:     dnsmessage.TypeA,
+	TypeAAAA:  dnsmessage.TypeAAAA,
+	TypeCNAME: dnsmessage.TypeCNAME,
+	TypeMX:    dnsmessage.TypeMX,
+	TypeNS:    dnsmessage.TypeNS,
+	TypePTR:   dnsmessage.TypePTR,
+	TypeTXT:   dnsmessage.TypeTXT,
+	TypeSOA:   dnsmessage.TypeSOA,
+	TypeNSEC:  wireNSEC,
+	TypeNSEC3: wireNSEC3,
+}
+
+// Record is one resource record. Names are stored without the trailing dot.
+// Value holds the record data in presentation form: an address, a target
+// name, the joined TXT strings, the SOA fields, or the NSEC/NSEC3 data.
+type Record struct {
+	Name     string     `json:"name"`
+	Type     RecordType `json:"type"`
+	TTL      uint32     `json:"ttl"`
+	Value    string     `json:"value"`
+	Priority uint16     `json:"priority,omitempty"` // MX preference
+}
+
+// String formats the record as a zone-file style line.
+func (r Record) String() string {
+	value := r.Value
+	if r.Type == TypeMX {
+		value = fmt.Sprintf("%d %s", r.Priority, r.Value)
+	}
+	return fmt.Sprintf("%s %d %s %s", r.Name, r.TTL, r.Type, value)
+}
+
+// ParseRecord parses the output of Record.String.
+func ParseRecord(line string) (Record, error) {
+	fields := strings.SplitN(line, " ", 4)
+	if len(fields) != 4 {
+		return Record{}, fmt.Errorf("invalid record %q", line)
+	}
+	ttl, err := strconv.ParseUint(fields[1], 10, 32)
+	if err != nil {
+		return Record{}, fmt.Errorf("invalid record TTL in %q", line)
+	}
+	r := Record{Name: fields[0], Type: RecordType(fields[2]), TTL: uint32(ttl), Value: fields[3]}
+	if r.Type == TypeMX {
+		prio, target, ok := strings.Cut(r.Value, " ")
+		p, err := strconv.ParseUint(prio, 10, 16)
+		if !ok || err != nil {
+			return Record{}, fmt.Errorf("invalid MX record %q", line)
+		}
+		r.Priority, r.Value = uint16(p), target
+	}
+	return r, nil
+}
+
+// Response is the parsed reply to one query.
+type Response struct {
+	RCode     dnsmessage.RCode
+	Answers   []Record
+	Authority []Record
+}
+
+// Client sends raw DNS queries over UDP, TCP, TLS or HTTPS, chosen by the
+// server's scheme. UDP answers that arrive truncated are retried over TCP.
+// The zero value is ready to use.
+type Client struct {
+	// Timeout bounds each exchange; zero means 2 seconds.
+	Timeout time.Duration
+	// Dial opens connections; nil uses net.Dialer.
+	Dial func(ctx context.Context, network, address string) (net.Conn, error)
+	// DNSSEC sets the DO bit so servers include NSEC/NSEC3 denial records.
+	DNSSEC bool
+	// TLSConfig configures DNS over TLS; nil verifies against the system
+	// roots. ServerName defaults to the server's host.
+	TLSConfig *tls.Config
+	// HTTPClient sends DNS over HTTPS requests; nil uses http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// Exchange queries server for name and qtype. server is "host",
+// "host:port" (UDP) or a URL with a udp, tcp, tls or https scheme.
+func (c *Client) Exchange(ctx context.Context, server, name string, qtype RecordType) (*Response, error) {
+	wire, ok := wireTypes[qtype]
+	if !ok {
+		return nil, fmt.Errorf("unsupported record type %q", qtype)
+	}
+	query, id, err := buildQuery(name, wire, c.DNSSEC)
+	if err != nil {
+		return nil, &net.DNSError{Err: err.Error(), Name: name, Server: server}
+	}
+
+	e, err := parseServer(server)
+	if err != nil {
+		return nil, &net.DNSError{Err: err.Error(), Name: name, Server: server}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout())
+	defer cancel()
+
+	var reply []byte
+	switch e.transport {
+	case TransportUDP:
+		reply, err = c.exchangeUDP(ctx, e.address, query, id)
+		if err == nil {
+			var header dnsmessage.Header
+			if header, err = peekHeader(reply); err == nil && header.Truncated {
+				reply, err = c.exchangeStream(ctx, e, query, id)
+			}
+		}
+	case TransportTCP, TransportTLS:
+		reply, err = c.exchangeStream(ctx, e, query, id)
+	case TransportHTTPS:
+		reply, err = c.exchangeHTTPS(ctx, e, query)
+	}
+	if err != nil {
+		return nil, wrapNetError(err, name, server)
+	}
+	return parseResponse(reply, name, server)
+}
+
+// Transfer requests the full zone from server with AXFR over TCP (or TLS
+// for tls:// servers) and returns its records, including the opening and
+// closing SOA. A server that denies the transfer returns an error.
+func (c *Client) Transfer(ctx context.Context, server, zone string) ([]Record, error) {
+	query, id, err := buildQuery(zone, dnsmessage.TypeAXFR, false)
+	if err != nil {
+		return nil, &net.DNSError{Err: err.Error(), Name: zone, Server: server}
+	}
+	e, err := parseServer(server)
+	if err == nil && e.transport == TransportHTTPS {
+		err = fmt.Errorf("zone transfers are not supported over HTTPS")
+	}
+	if err != nil {
+		return nil, &net.DNSError{Err: err.Error(), Name: zone, Server: server}
+	}
+	// Large zones take a while to stream.
+	ctx, cancel := context.WithTimeout(ctx, 10*c.timeout())
+	defer cancel()
+
+	conn, err := c.dialStream(ctx, e)
+	if err != nil {
+		return nil, wrapNetError(err, zone, server)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if err := writeTCPMessage(conn, query); err != nil {
+		return nil, wrapNetError(err, zone, server)
+	}
+
+	// The zone arrives in one or more messages, bracketed by its SOA.
+	var records []Record
+	soas := 0
+	for soas < 2 {
+		reply, err := readTCPMessage(conn, id)
+		if err != nil {
+			return nil, wrapNetError(err, zone, server)
+		}
+		resp, err := parseResponse(reply, zone, server)
+		if err != nil {
+			if resp != nil && resp.RCode != dnsmessage.RCodeSuccess {
+				return nil, &net.DNSError{Err: "zone transfer denied: " + resp.RCode.String(), Name: zone, Server: server}
+			}
+			return nil, err
+		}
+		if len(resp.Answers) == 0 {
+			return nil, &net.DNSError{Err: "zone transfer denied: empty answer", Name: zone, Server: server}
+		}
+		for _, r := range resp.Answers {
+			if r.Type == TypeSOA {
+				soas++
+			}
+			records = append(records, r)
+		}
+		if len(records) > 0 && records[0].Type != TypeSOA {
+			return nil, &net.DNSError{Err: "zone transfer did not start with SOA", Name: zone, Server: server}
+		}
+	}
+	return records, nil
+}
+
+func (c *Client) timeout() time.Duration {
+	if c.Timeout <= 0 {
+		return 2 * time.Second
+	}
+	return c.Timeout
+}
+
+func (c *Client) dial(ctx context.Context, network, address string) (net.Conn, error) {
+	if c.Dial != nil {
+		return c.Dial(ctx, network, address)
+	}
+	var d net.Dialer
+	return d.DialContext(ctx, network, address)
+}
+
+func (c *Client) exchangeUDP(ctx context.Context, address string, query []byte, id uint16) ([]byte, error) {
+	conn, err := c.dial(ctx, "udp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if _, err := conn.Write(query); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 65535)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		// Ignore stray datagrams that do not answer this query.
+		if n >= 2 && binary.BigEndian.Uint16(buf) == id {
+			return append([]byte(nil), buf[:n]...), nil
+		}
+	}
+}
+
+// writeTCPMessage sends msg with the two-byte length prefix DNS uses on TCP.
+func writeTCPMessage(conn net.Conn, msg []byte) error {
+	framed := make([]byte, 2+len(msg))
+	binary.BigEndian.PutUint16(framed, uint16(len(msg)))
+	copy(framed[2:], msg)
+	_, err := conn.Write(framed)
+	return err
+}
+
+// readTCPMessage reads one length-prefixed message answering query id.
+func readTCPMessage(conn net.Conn, id uint16) ([]byte, error) {
+	var length [2]byte
+	if _, err := io.ReadFull(conn, length[:]); err != nil {
+		return nil, err
+	}
+	reply := make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return nil, err
+	}
+	if len(reply) < 2 || binary.BigEndian.Uint16(reply) != id {
+		return nil, errors.New("mismatched DNS response ID")
+	}
+	return reply, nil
+}
+
+func buildQuery(name string, qtype dnsmessage.Type, dnssec bool) ([]byte, uint16, error) {
+	qname, err := dnsmessage.NewName(fqdn(name))
+	if err != nil {
+		return nil, 0, err
+	}
+	id := uint16(rand.UintN(1 << 16))
+	msg := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: id, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: qname, Type: qtype, Class: dnsmessage.ClassINET}},
+	}
+	if dnssec {
+		var opt dnsmessage.ResourceHeader
+		if err := opt.SetEDNS0(1232, dnsmessage.RCodeSuccess, true); err != nil {
+			return nil, 0, err
+		}
+		msg.Additionals = []dnsmessage.Resource{{Header: opt, Body: &dnsmessage.OPTResource{}}}
+	}
+	packed, err := msg.Pack()
+	return packed, id, err
+}
+
+func peekHeader(reply []byte) (dnsmessage.Header, error) {
+	var p dnsmessage.Parser
+	return p.Start(reply)
+}
+
+func parseResponse(reply []byte, name, server string) (*Response, error) {
+	var p dnsmessage.Parser
+	header, err := p.Start(reply)
+	if err != nil {
+		return nil, &net.DNSError{Err: "malformed response: " + err.Error(), Name: name, Server: server}
+	}
+	if err := p.SkipAllQuestions(); err != nil {
+		return nil, &net.DNSError{Err: "malformed response: " + err.Error(), Name: name, Server: server}
+	}
+
+	resp := &Response{RCode: header.RCode}
+	if resp.Answers, err = parseSection(&p, p.AnswerHeader, p.SkipAnswer); err != nil {
+		return nil, &net.DNSError{Err: "malformed response: " + err.Error(), Name: name, Server: server}
+	}
+	if resp.Authority, err = parseSection(&p, p.AuthorityHeader, p.SkipAuthority); err != nil {
+		return nil, &net.DNSError{Err: "malformed response: " + err.Error(), Name: name, Server: server}
+	}
+
+	return resp, rcodeError(header.RCode, name, server)
+}
+
+// rcodeError maps a response code to the error Exchange returns for it.
+func rcodeError(rcode dnsmessage.RCode, name, server string) error {
+	switch rcode {
+	case dnsmessage.RCodeSuccess:
+		return nil
+	case dnsmessage.RCodeNameError:
+		return &net.DNSError{Err: "no such host", Name: name, Server: server, IsNotFound: true}
+	case dnsmessage.RCodeServerFailure:
+		return &net.DNSError{Err: "server misbehaving", Name: name, Server: server, IsTemporary: true}
+	default:
+		return &net.DNSError{Err: "server returned " + rcode.String(), Name: name, Server: server}
+	}
+}
+
+// parseSection reads the records of the current section, skipping types
+// Record does not represent.
+func parseSection(p *dnsmessage.Parser, next func() (dnsmessage.ResourceHeader, error), skip func() error) ([]Record, error) {
+	var records []Record
+	for {
+		h, err := next()
+		if err == dnsmessage.ErrSectionDone {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		r := Record{Name: trimDot(h.Name.String()), TTL: h.TTL}
+		switch h.Type {
+		case dnsmessage.TypeA:
+			body, err := p.AResource()
+			if err != nil {
+				return nil, err
+			}
+			r.Type, r.Value = TypeA, net.IP(body.A[:]).String()
+		case dnsmessage.TypeAAAA:
+			body, err := p.AAAAResource()
+			if err != nil {
+				return nil, err
+			}
+			r.Type, r.Value = TypeAAAA, net.IP(body.AAAA[:]).String()
+		case dnsmessage.TypeCNAME:
+			body, err := p.CNAMEResource()
+			if err != nil {
+				return nil, err
+			}
+			r.Type, r.Value = TypeCNAME, trimDot(body.CNAME.String())
+		case dnsmessage.TypeMX:
+			body, err := p.MXResource()
+			if err != nil {
+				return nil, err
+			}
+			r.Type, r.Value, r.Priority = TypeMX, trimDot(body.MX.String()), body.Pref
+		case dnsmessage.TypeNS:
+			body, err := p.NSResource()
+			if err != nil {
+				return nil, err
+			}
+			r.Type, r.Value = TypeNS, trimDot(body.NS.String())
+		case dnsmessage.TypePTR:
+			body, err := p.PTRResource()
+			if err != nil {
+				return nil, err
+			}
+			r.Type, r.Value = TypePTR, trimDot(body.PTR.String())
+		case dnsmessage.TypeTXT:
+			body, err := p.TXTResource()
+			if err != nil {
+				return nil, err
+			}
+			r.Type, r.Value = TypeTXT, strings.Join(body.TXT, "")
+		case dnsmessage.TypeSOA:
+			body, err := p.SOAResource()
+			if err != nil {
+				return nil, err
+			}
+			r.Type = TypeSOA
+			r.Value = fmt.Sprintf("%s %s %d %d %d %d %d", trimDot(body.NS.String()), trimDot(body.MBox.String()),
+				body.Serial, body.Refresh, body.Retry, body.Expire, body.MinTTL)
+		case wireNSEC:
+			body, err := p.UnknownResource()
+			if err != nil {
+				return nil, err
+			}
+			if r.Value, err = parseNSEC(body.Data); err != nil {
+				return nil, err
+			}
+			r.Type = TypeNSEC
+		case wireNSEC3:
+			body, err := p.UnknownResource()
+			if err != nil {
+				return nil, err
+			}
+			if r.Value, err = parseNSEC3(body.Data); err != nil {
+				return nil, err
+			}
+			r.Type = TypeNSEC3
+		default:
+			if err := skip(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		records = append(records, r)
+	}
+}
+
+// wrapNetError converts transport failures to *net.DNSError so callers can
+// classify them like stdlib resolver errors.
+func wrapNetError(err error, name, server string) error {
+	var netErr net.Error
+	timeout := errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout()
+	return &net.DNSError{Err: err.Error(), Name: name, Server: server, IsTimeout: timeout}
+}
+
+// serverAddress appends the DNS port to a bare host.
+func serverAddress(server string) string {
+	if _, _, err := net.SplitHostPort(server); err == nil {
+		return server
+	}
+	return net.JoinHostPort(server, "53")
+}
+
+func fqdn(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
+
+func trimDot(name string) string {
+	return strings.TrimSuffix(name, ".")
+}
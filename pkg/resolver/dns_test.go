@@ -0,0 +1,170 @@
+package resolver_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/NASHEDIxCODER/gospyder/pkg/resolver"
+	"github.com/NASHEDIxCODER/gospyder/tests/mocks"
+)
+
+func TestPoolRecordsReturnsTypedAnswers(t *testing.T) {
+	server := mocks.NewDNSServer(t,
+		"example.com 3600 SOA ns1.example.com hostmaster.example.com 2024010101 7200 900 1209600 300",
+		"example.com 3600 NS ns1.example.com",
+		"example.com 300 MX 10 mail.example.com",
+		"example.com 300 TXT v=spf1 include:_spf.example.net -all",
+		"example.com 300 A 192.0.2.10",
+		"example.com 300 AAAA 2001:db8::10",
+	)
+	pool := resolver.NewPool([]string{server.Addr})
+
+	records, err := pool.Records(context.Background(), "example.com",
+		resolver.TypeA, resolver.TypeAAAA, resolver.TypeMX, resolver.TypeNS, resolver.TypeTXT, resolver.TypeSOA)
+	if err != nil {
+		t.Fatalf("Records() error = %v", err)
+	}
+	var got []string
+	for _, r := range records {
+		got = append(got, r.String())
+	}
+	want := []string{
+		"example.com 300 A 192.0.2.10",
+		"example.com 300 AAAA 2001:db8::10",
+		"example.com 300 MX 10 mail.example.com",
+		"example.com 3600 NS ns1.example.com",
+		"example.com 300 TXT v=spf1 include:_spf.example.net -all",
+		"example.com 3600 SOA ns1.example.com hostmaster.example.com 2024010101 7200 900 1209600 300",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("records =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	for _, line := range want {
+		if r, err := resolver.ParseRecord(line); err != nil || r.String() != line {
+			t.Fatalf("ParseRecord(%q) = %v, %v", line, r, err)
+		}
+	}
+}
+
+func TestPoolResolveFollowsCNAMEChain(t *testing.T) {
+	server := mocks.NewDNSServer(t,
+		"www.example.com 300 CNAME www.example.cdn.net",
+		"www.example.cdn.net 60 CNAME edge.cdn.net",
+		"edge.cdn.net 60 A 198.51.100.1",
+		"edge.cdn.net 60 A 198.51.100.2",
+	)
+	pool := resolver.NewPool([]string{server.Addr})
+
+	host, err := pool.Resolve(context.Background(), "www.example.com")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if fmt.Sprint(host.CNAMEs) != "[www.example.cdn.net edge.cdn.net]" || fmt.Sprint(host.IPs) != "[198.51.100.1 198.51.100.2]" {
+		t.Fatalf("host = %+v", host)
+	}
+
+	_, err = pool.Resolve(context.Background(), "missing.example.com")
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+		t.Fatalf("Resolve(missing) error = %v, want NXDOMAIN", err)
+	}
+}
+
+func TestPoolLookupAddrQueriesReverseNames(t *testing.T) {
+	server := mocks.NewDNSServer(t,
+		"10.2.0.192.in-addr.arpa 300 PTR Mail.Example.com",
+		"1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa 300 PTR v6.example.com",
+	)
+	pool := resolver.NewPool([]string{server.Addr})
+
+	for ip, want := range map[string]string{"192.0.2.10": "[mail.example.com]", "2001:db8::1": "[v6.example.com]"} {
+		names, err := pool.LookupAddr(context.Background(), ip)
+		if err != nil || fmt.Sprint(names) != want {
+			t.Fatalf("LookupAddr(%s) = %v, %v; want %s", ip, names, err, want)
+		}
+	}
+	if _, err := resolver.ReverseName("example.com"); err == nil {
+		t.Fatal("ReverseName() accepted a hostname")
+	}
+}
+
+func TestClientRetriesTruncatedAnswersOverTCP(t *testing.T) {
+	var records []string
+	for i := 0; i < 20; i++ {
+		records = append(records, fmt.Sprintf("big.example.com 300 TXT verification-token-%02d-%s", i, strings.Repeat("x", 40)))
+	}
+	server := mocks.NewDNSServer(t, records...)
+
+	var client resolver.Client
+	resp, err := client.Exchange(context.Background(), server.Addr, "big.example.com", resolver.TypeTXT)
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	if len(resp.Answers) != 20 {
+		t.Fatalf("answers = %d, want 20 via TCP fallback", len(resp.Answers))
+	}
+}
+
+func TestPoolLookupOverTCP(t *testing.T) {
+	server := mocks.NewDNSServer(t, "www.example.com 300 A 192.0.2.1")
+	pool := resolver.NewPool([]string{"tcp://" + server.Addr})
+
+	ips, err := pool.Lookup(context.Background(), "www.example.com")
+	if err != nil || fmt.Sprint(ips) != "[192.0.2.1]" {
+		t.Fatalf("Lookup() = %v, %v", ips, err)
+	}
+}
+
+func TestPoolLookupDoesNotRetryNXDOMAIN(t *testing.T) {
+	server := mocks.NewDNSServer(t, "www.example.com 300 A 192.0.2.1")
+	pool := resolver.NewPool([]string{server.Addr})
+
+	_, err := pool.Lookup(context.Background(), "missing.example.com")
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+		t.Fatalf("Lookup(missing) error = %v, want NXDOMAIN", err)
+	}
+	if got := fmt.Sprint(server.Queries()); got != "[missing.example.com A]" {
+		t.Fatalf("queries = %s, want one attempt", got)
+	}
+}
+
+func TestClientExchangesOverTLSAndHTTPS(t *testing.T) {
+	var records []string
+	for i := 0; i < 20; i++ {
+		records = append(records, fmt.Sprintf("big.example.com 300 TXT verification-token-%02d-%s", i, strings.Repeat("x", 40)))
+	}
+	server := mocks.NewDNSServer(t, records...)
+	dot, doh := "tls://"+server.ServeTLS(t), server.ServeHTTPS(t)
+	config := server.TLSConfig()
+	client := resolver.Client{
+		TLSConfig:  config,
+		HTTPClient: &http.Client{Transport: &http.Transport{TLSClientConfig: config}},
+	}
+
+	for _, addr := range []string{dot, doh} {
+		resp, err := client.Exchange(context.Background(), addr, "big.example.com", resolver.TypeTXT)
+		if err != nil {
+			t.Fatalf("Exchange(%s) error = %v", addr, err)
+		}
+		if len(resp.Answers) != 20 {
+			t.Fatalf("Exchange(%s) answers = %d, want 20", addr, len(resp.Answers))
+		}
+		_, err = client.Exchange(context.Background(), addr, "missing.example.com", resolver.TypeA)
+		var dnsErr *net.DNSError
+		if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+			t.Fatalf("Exchange(%s, missing) error = %v, want NXDOMAIN", addr, err)
+		}
+	}
+
+	// Certificates are verified unless the caller trusts them.
+	var untrusted resolver.Client
+	if _, err := untrusted.Exchange(context.Background(), dot, "big.example.com", resolver.TypeTXT); err == nil {
+		t.Fatal("Exchange() accepted an untrusted DoT certificate")
+	}
+}
@@ -0,0 +1,151 @@
+package resolver
+
+import (
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	wireNSEC  = dnsmessage.Type(47)
+	wireNSEC3 = dnsmessage.Type(50)
+)
+
+// bitmapTypes names the types that commonly appear in NSEC type bitmaps.
+var bitmapTypes = map[dnsmessage.Type]string{
+	dnsmessage.TypeA: "A", dnsmessage.TypeNS: "NS", dnsmessage.TypeCNAME: "CNAME",
+	dnsmessage.TypeSOA: "SOA", dnsmessage.TypePTR: "PTR", dnsmessage.TypeMX: "MX",
+	dnsmessage.TypeTXT: "TXT", dnsmessage.TypeAAAA: "AAAA", dnsmessage.TypeSRV: "SRV",
+	43: "DS", 46: "RRSIG", wireNSEC: "NSEC", 48: "DNSKEY", wireNSEC3: "NSEC3", 51: "NSEC3PARAM",
+	52: "TLSA", dnsmessage.TypeSVCB: "SVCB", dnsmessage.TypeHTTPS: "HTTPS", 257: "CAA",
+}
+
+// nsec3Encoding is the unpadded base32hex alphabet NSEC3 owner hashes use.
+var nsec3Encoding = base32.HexEncoding.WithPadding(base32.NoPadding)
+
+// NSEC3 holds the parsed data of an NSEC3 record. Owner and Next are the
+// base32hex hashes of two adjacent names in the zone.
+type NSEC3 struct {
+	Algorithm  uint8
+	Iterations uint16
+	Salt       string // hex, "-" when empty
+	Owner      string
+	Next       string
+}
+
+// ParseNSEC3 reads an NSEC3 record as returned by the client. Its value is
+// "alg flags iterations salt next types...".
+func ParseNSEC3(r Record) (NSEC3, error) {
+	fields := strings.Fields(r.Value)
+	if r.Type != TypeNSEC3 || len(fields) < 5 {
+		return NSEC3{}, fmt.Errorf("invalid NSEC3 record %q", r.String())
+	}
+	alg, err1 := strconv.ParseUint(fields[0], 10, 8)
+	iterations, err2 := strconv.ParseUint(fields[2], 10, 16)
+	if err1 != nil || err2 != nil {
+		return NSEC3{}, fmt.Errorf("invalid NSEC3 record %q", r.String())
+	}
+	owner, _, _ := strings.Cut(r.Name, ".")
+	return NSEC3{
+		Algorithm:  uint8(alg),
+		Iterations: uint16(iterations),
+		Salt:       fields[3],
+		Owner:      strings.ToLower(owner),
+		Next:       strings.ToLower(fields[4]),
+	}, nil
+}
+
+// parseNSEC formats NSEC rdata as "next types...". The next owner name is
+// stored uncompressed (RFC 4034 section 4.1.1).
+func parseNSEC(data []byte) (string, error) {
+	next, n, err := readName(data)
+	if err != nil {
+		return "", err
+	}
+	types, err := readTypeBitmap(data[n:])
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(next + " " + types), nil
+}
+
+// parseNSEC3 formats NSEC3 rdata as "alg flags iterations salt next types...".
+func parseNSEC3(data []byte) (string, error) {
+	if len(data) < 5 {
+		return "", errors.New("short NSEC3 rdata")
+	}
+	alg, flags := data[0], data[1]
+	iterations := uint16(data[2])<<8 | uint16(data[3])
+	saltLen := int(data[4])
+	data = data[5:]
+	if len(data) < saltLen+1 {
+		return "", errors.New("short NSEC3 salt")
+	}
+	salt := "-"
+	if saltLen > 0 {
+		salt = strings.ToUpper(hex.EncodeToString(data[:saltLen]))
+	}
+	data = data[saltLen:]
+	hashLen := int(data[0])
+	data = data[1:]
+	if len(data) < hashLen {
+		return "", errors.New("short NSEC3 hash")
+	}
+	next := strings.ToLower(nsec3Encoding.EncodeToString(data[:hashLen]))
+	types, err := readTypeBitmap(data[hashLen:])
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(fmt.Sprintf("%d %d %d %s %s %s", alg, flags, iterations, salt, next, types)), nil
+}
+
+// readName reads an uncompressed wire-format name and returns it without
+// the trailing dot, along with the number of bytes consumed.
+func readName(data []byte) (string, int, error) {
+	var labels []string
+	for off := 0; off < len(data); {
+		n := int(data[off])
+		off++
+		if n == 0 {
+			return strings.ToLower(strings.Join(labels, ".")), off, nil
+		}
+		if n > 63 || off+n > len(data) {
+			return "", 0, errors.New("invalid name in rdata")
+		}
+		labels = append(labels, string(data[off:off+n]))
+		off += n
+	}
+	return "", 0, errors.New("unterminated name in rdata")
+}
+
+// readTypeBitmap decodes the window blocks of an NSEC type bitmap
+// (RFC 4034 section 4.1.2) into space-separated type names.
+func readTypeBitmap(data []byte) (string, error) {
+	var types []string
+	for len(data) > 0 {
+		if len(data) < 2 || int(data[1]) > 32 || len(data) < 2+int(data[1]) {
+			return "", errors.New("invalid type bitmap")
+		}
+		window, length := int(data[0]), int(data[1])
+		for i, b := range data[2 : 2+length] {
+			for bit := 0; bit < 8; bit++ {
+				if b&(0x80>>bit) == 0 {
+					continue
+				}
+				t := dnsmessage.Type(window<<8 | i<<3 | bit)
+				name, ok := bitmapTypes[t]
+				if !ok {
+					name = fmt.Sprintf("TYPE%d", t)
+				}
+				types = append(types, name)
+			}
+		}
+		data = data[2+length:]
+	}
+	return strings.Join(types, " "), nil
+}
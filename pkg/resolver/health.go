@@ -0,0 +1,229 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/google/uuid"
+)
+
+const (
+	// evictAfter consecutive failures take a resolver out of rotation.
+	evictAfter = 3
+	// evictFor is the first eviction period; it doubles on each repeat up
+	// to maxEvict.
+	evictFor = 30 * time.Second
+	maxEvict = 5 * time.Minute
+	// latencyScale is the latency at which a resolver's weight halves.
+	latencyScale = 200 * time.Millisecond
+)
+
+// sanityNames returns names that cannot exist. A resolver answering them
+// with addresses is hijacking NXDOMAIN.
+func sanityNames() []string {
+	return []string{
+		uuid.New().String() + ".com",
+		uuid.New().String() + ".example.com",
+	}
+}
+
+// health tracks a resolver's outcomes. It is guarded by Resolver.mu.
+type health struct {
+	queries      int64
+	failures     int64
+	timeouts     int64
+	latency      time.Duration // moving average of answered queries
+	consecutive  int
+	evictions    int
+	evictedUntil time.Time
+}
+
+// ResolverStats reports one resolver's health for result metadata.
+type ResolverStats struct {
+	Server    string  `json:"server"`
+	Status    string  `json:"status"` // active, evicted or dropped
+	Queries   int64   `json:"queries"`
+	Failures  int64   `json:"failures"`
+	Timeouts  int64   `json:"timeouts"`
+	LatencyMs float64 `json:"latency_ms"`
+	Evictions int     `json:"evictions,omitempty"`
+}
+
+func (s ResolverStats) String() string {
+	return fmt.Sprintf("%s %s: %d queries, %d failed, %.0fms", s.Server, s.Status, s.Queries, s.Failures, s.LatencyMs)
+}
+
+// observe records the outcome of one query. NXDOMAIN is a healthy answer.
+func (r *Resolver) observe(outcome string, elapsed time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	h := &r.health
+	h.queries++
+	switch outcome {
+	case "success", "nxdomain":
+		h.consecutive = 0
+		if h.latency == 0 {
+			h.latency = elapsed
+		} else {
+			h.latency = (h.latency*4 + elapsed) / 5
+		}
+		return
+	case "timeout":
+		h.timeouts++
+	}
+	h.failures++
+	h.consecutive++
+	if h.consecutive >= evictAfter {
+		period := evictFor << h.evictions
+		if period > maxEvict {
+			period = maxEvict
+		}
+		h.evictions++
+		h.consecutive = 0
+		h.evictedUntil = time.Now().Add(period)
+	}
+}
+
+// weight is the resolver's selection weight: its smoothed success rate,
+// discounted by latency. Evicted resolvers weigh zero.
+func (r *Resolver) weight(now time.Time) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	h := &r.health
+	if now.Before(h.evictedUntil) {
+		return 0
+	}
+	success := float64(h.queries-h.failures+1) / float64(h.queries+2)
+	return success / (1 + float64(h.latency)/float64(latencyScale))
+}
+
+// pick chooses a resolver at random, weighted by health. When every
+// resolver is evicted, the one returning soonest is used.
+func (p *Pool) pick() *Resolver {
+	p.mu.RLock()
+	resolvers := p.resolvers
+	p.mu.RUnlock()
+
+	now := time.Now()
+	weights := make([]float64, len(resolvers))
+	total := 0.0
+	for i, r := range resolvers {
+		weights[i] = r.weight(now)
+		total += weights[i]
+	}
+	if total == 0 {
+		soonest := resolvers[0]
+		for _, r := range resolvers[1:] {
+			if r.evictedUntil().Before(soonest.evictedUntil()) {
+				soonest = r
+			}
+		}
+		return soonest
+	}
+	x := rand.Float64() * total
+	for i, w := range weights {
+		if x < w {
+			return resolvers[i]
+		}
+		x -= w
+	}
+	return resolvers[len(resolvers)-1]
+}
+
+func (r *Resolver) evictedUntil() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.health.evictedUntil
+}
+
+// Sanitize queries names that cannot exist on every resolver and drops
+// those that answer with addresses. It fails when no resolver is left.
+func (p *Pool) Sanitize(ctx context.Context) error {
+	p.mu.RLock()
+	resolvers := p.resolvers
+	p.mu.RUnlock()
+
+	bogus := make([]bool, len(resolvers))
+	var wg sync.WaitGroup
+	for i, r := range resolvers {
+		wg.Add(1)
+		go func(i int, r *Resolver) {
+			defer wg.Done()
+			for _, name := range sanityNames() {
+				records, err := p.query(ctx, r, name, TypeA)
+				if err == nil && len(HostFromRecords(name, records).IPs) > 0 {
+					bogus[i] = true
+					return
+				}
+			}
+		}(i, r)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var kept []*Resolver
+	for i, r := range resolvers {
+		if bogus[i] {
+			p.dropped = append(p.dropped, r)
+		} else {
+			kept = append(kept, r)
+		}
+	}
+	if len(kept) == 0 {
+		return errors.NewConfigError("no usable resolvers", fmt.Errorf("all %d resolvers answer for nonexistent names", len(resolvers)))
+	}
+	p.resolvers = kept
+	return nil
+}
+
+// Dropped returns the resolvers removed by Sanitize.
+func (p *Pool) Dropped() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	servers := make([]string, len(p.dropped))
+	for i, r := range p.dropped {
+		servers[i] = r.server
+	}
+	return servers
+}
+
+// Stats reports the health of every resolver, including dropped ones.
+func (p *Pool) Stats() []ResolverStats {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	now := time.Now()
+	stats := make([]ResolverStats, 0, len(p.resolvers)+len(p.dropped))
+	for _, r := range p.resolvers {
+		stats = append(stats, r.stats(now, "active"))
+	}
+	for _, r := range p.dropped {
+		stats = append(stats, r.stats(now, "dropped"))
+	}
+	return stats
+}
+
+func (r *Resolver) stats(now time.Time, status string) ResolverStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	h := r.health
+	if status == "active" && now.Before(h.evictedUntil) {
+		status = "evicted"
+	}
+	return ResolverStats{
+		Server:    r.server,
+		Status:    status,
+		Queries:   h.queries,
+		Failures:  h.failures,
+		Timeouts:  h.timeouts,
+		LatencyMs: float64(h.latency.Microseconds()) / 1000,
+		Evictions: h.evictions,
+	}
+}
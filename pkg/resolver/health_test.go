@@ -0,0 +1,91 @@
+package resolver_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/config"
+	"github.com/NASHEDIxCODER/gospyder/pkg/resolver"
+	"github.com/NASHEDIxCODER/gospyder/tests/mocks"
+)
+
+func TestSanitizeDropsHijackingResolvers(t *testing.T) {
+	honest := mocks.NewDNSServer(t, "www.example.com 300 A 192.0.2.10")
+	hijacker := mocks.NewDNSServer(t, "www.example.com 300 A 192.0.2.10")
+	hijacker.HijackNXDOMAIN("198.51.100.66")
+
+	path := filepath.Join(t.TempDir(), "resolvers.txt")
+	if err := os.WriteFile(path, []byte("# test resolvers\n"+honest.Addr+"\n\n"+hijacker.Addr+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pool, err := resolver.NewPoolFromConfig(context.Background(), config.DNSConfig{ResolversFile: path}, nil, nil)
+	if err != nil {
+		t.Fatalf("NewPoolFromConfig() error = %v", err)
+	}
+	if dropped := pool.Dropped(); len(dropped) != 1 || dropped[0] != hijacker.Addr {
+		t.Fatalf("Dropped() = %v, want [%s]", dropped, hijacker.Addr)
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := pool.Lookup(context.Background(), "www.example.com"); err != nil {
+			t.Fatalf("Lookup() error = %v", err)
+		}
+	}
+	stats := pool.Stats()
+	if len(stats) != 2 || stats[0].Status != "active" || stats[1].Status != "dropped" || stats[1].Server != hijacker.Addr {
+		t.Fatalf("Stats() = %v", stats)
+	}
+
+	hijacker2 := mocks.NewDNSServer(t)
+	hijacker2.HijackNXDOMAIN("198.51.100.66")
+	if _, err := resolver.NewPoolFromConfig(context.Background(), config.DNSConfig{Resolvers: []string{hijacker2.Addr}}, nil, nil); err == nil {
+		t.Fatal("NewPoolFromConfig() succeeded with only a hijacking resolver")
+	}
+}
+
+func TestPoolEvictsFailingResolver(t *testing.T) {
+	// Nothing listens on the discard port, so queries are refused.
+	const dead = "127.0.0.1:9"
+	pool := resolver.NewPool([]string{dead})
+	if _, err := pool.Query(context.Background(), "www.example.com", resolver.TypeA); err == nil {
+		t.Fatal("Query() succeeded against a dead resolver")
+	}
+	if stats := pool.Stats(); stats[0].Status != "evicted" || stats[0].Failures != 3 || stats[0].Evictions != 1 {
+		t.Fatalf("Stats() = %+v, want evicted after 3 failures", stats[0])
+	}
+
+	server := mocks.NewDNSServer(t, "www.example.com 300 A 192.0.2.10")
+	pool = resolver.NewPool([]string{dead, server.Addr})
+	failed := 0
+	for i := 0; i < 20; i++ {
+		if _, err := pool.Query(context.Background(), "www.example.com", resolver.TypeA); err != nil {
+			failed++
+		}
+	}
+	// The dead resolver is evicted by its third failure, so at most one
+	// query can exhaust its attempts on it.
+	stats := pool.Stats()
+	if failed > 1 || stats[0].Queries > 3 || stats[1].Failures != 0 || stats[1].Queries < 19 {
+		t.Fatalf("%d queries failed; Stats() = %v", failed, stats)
+	}
+}
+
+func TestServersValidatesConfiguredResolvers(t *testing.T) {
+	if servers, err := resolver.Servers(config.DNSConfig{}); err != nil || len(servers) != len(resolver.DefaultServers) {
+		t.Fatalf("Servers(empty) = %v, %v; want defaults", servers, err)
+	}
+	if _, err := resolver.Servers(config.DNSConfig{Resolvers: []string{"dns.google"}}); err == nil {
+		t.Fatal("Servers() accepted a host name")
+	}
+	servers := []string{"tcp://9.9.9.9", "tls://dns.quad9.net", "https://cloudflare-dns.com/dns-query"}
+	if got, err := resolver.Servers(config.DNSConfig{Resolvers: servers}); err != nil || len(got) != 3 {
+		t.Fatalf("Servers(%v) = %v, %v", servers, got, err)
+	}
+	if _, err := resolver.Servers(config.DNSConfig{Resolvers: []string{"quic://9.9.9.9"}}); err == nil {
+		t.Fatal("Servers() accepted an unsupported transport")
+	}
+	if _, err := resolver.Servers(config.DNSConfig{ResolversFile: filepath.Join(t.TempDir(), "missing.txt")}); err == nil {
+		t.Fatal("Servers() accepted a missing file")
+	}
+}
@@ -2,71 +2,85 @@ package resolver
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"sync"
 	"time"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/replay"
+	"github.com/NASHEDIxCODER/gospyder/internal/telemetry"
 )
 
 type Resolver struct {
 	server    string
-	client    *net.Resolver
 	rateLimit time.Duration
 	lastReq   time.Time
+	health    health
 	mu        sync.Mutex
 }
 
 type Pool struct {
 	resolvers []*Resolver
+	dropped   []*Resolver
 	mu        sync.RWMutex
-	current   int
+	telemetry *telemetry.Telemetry
+	replay    *replay.Session
+	client    *Client
 }
 
+// DefaultServers are the public resolvers used when none are configured.
+var DefaultServers = []string{"8.8.8.8", "8.8.4.4", "1.1.1.1", "1.0.0.1"}
+
+// NewPool returns a pool over servers, given as "host", "host:port" or a
+// udp://, tcp://, tls:// or https:// URL.
 func NewPool(servers []string) *Pool {
-	pool := &Pool{}
+	pool := &Pool{client: &Client{Timeout: 2 * time.Second}}
 	for _, server := range servers {
-		r := &net.Resolver{
-			PreferGo: true,
-			Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
-				d := net.Dialer{Timeout: 2 * time.Second}
-				return d.DialContext(ctx, "udp", server+":53")
-			},
-		}
 		pool.resolvers = append(pool.resolvers, &Resolver{
 			server:    server,
-			client:    r,
 			rateLimit: 50 * time.Millisecond,
 		})
 	}
 	return pool
 }
 
+// SetTelemetry records lookup metrics and spans to t. A nil t disables them.
+func (p *Pool) SetTelemetry(t *telemetry.Telemetry) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.telemetry = t
+}
+
+// SetReplay records lookups into s or answers them from it. A nil s
+// resolves against the configured servers.
+func (p *Pool) SetReplay(s *replay.Session) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.replay = s
+}
+
 func (p *Pool) Lookup(ctx context.Context, name string) ([]string, error) {
+	var lastErr error
 	for retries := 0; retries < 3; retries++ {
 		if err := ctx.Err(); err != nil {
 			return nil, err
 		}
 
-		resolver := p.nextResolver()
-
-		resolver.mu.Lock()
-		if time.Since(resolver.lastReq) < resolver.rateLimit {
-			wait := resolver.rateLimit - time.Since(resolver.lastReq)
-			resolver.mu.Unlock()
-			select {
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			case <-time.After(wait):
-			}
-			resolver.mu.Lock()
+		resolver, err := p.acquire(ctx)
+		if err != nil {
+			return nil, err
 		}
-		resolver.lastReq = time.Now()
-		resolver.mu.Unlock()
 
-		ips, err := resolver.client.LookupHost(ctx, name)
+		ips, err := p.lookupHost(ctx, resolver, name)
 		if err == nil && len(ips) > 0 {
 			return ips, nil
 		}
+		lastErr = err
+		if lookupOutcome(err) == "nxdomain" {
+			// The name does not exist; asking again will not change that.
+			break
+		}
 
 		select {
 		case <-ctx.Done():
@@ -75,14 +89,98 @@ func (p *Pool) Lookup(ctx context.Context, name string) ([]string, error) {
 		}
 	}
 
-	return nil, fmt.Errorf("failed to resolve %s", name)
+	if lastErr == nil {
+		lastErr = errors.New("no addresses returned")
+	}
+	return nil, fmt.Errorf("failed to resolve %s: %w", name, lastErr)
 }
 
-func (p *Pool) nextResolver() *Resolver {
-	p.mu.Lock()
-	defer p.mu.Unlock()
+// acquire picks a resolver by health and waits out its rate limit.
+func (p *Pool) acquire(ctx context.Context) (*Resolver, error) {
+	resolver := p.pick()
+
+	resolver.mu.Lock()
+	if time.Since(resolver.lastReq) < resolver.rateLimit {
+		wait := resolver.rateLimit - time.Since(resolver.lastReq)
+		resolver.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+		resolver.mu.Lock()
+	}
+	resolver.lastReq = time.Now()
+	resolver.mu.Unlock()
+	return resolver, nil
+}
+
+// lookupHost performs one instrumented lookup against resolver.
+func (p *Pool) lookupHost(ctx context.Context, resolver *Resolver, name string) ([]string, error) {
+	p.mu.RLock()
+	tel, rec := p.telemetry, p.replay
+	p.mu.RUnlock()
+
+	spanCtx, span := tel.StartSpan(ctx, "dns.lookup",
+		telemetry.String("dns.name", name),
+		telemetry.String("dns.resolver", resolver.server),
+	)
+	start := time.Now()
+	ips, err := rec.LookupHost(spanCtx, name, func(ctx context.Context, name string) ([]string, error) {
+		return p.client.lookupHost(ctx, resolver.server, name)
+	})
+	outcome := lookupOutcome(err)
+	if ctx.Err() == nil {
+		resolver.observe(outcome, time.Since(start))
+	}
+	span.SetAttributes(telemetry.String("dns.outcome", outcome))
+	if outcome != "success" && outcome != "nxdomain" {
+		span.RecordError(err)
+	}
+	span.End()
+	tel.ObserveDNS(resolver.server, outcome)
+	return ips, err
+}
 
-	r := p.resolvers[p.current]
-	p.current = (p.current + 1) % len(p.resolvers)
-	return r
+// lookupHost returns the IPv4 and IPv6 addresses of name from server. A
+// name without addresses is reported as not found, like net.Resolver does.
+func (c *Client) lookupHost(ctx context.Context, server, name string) ([]string, error) {
+	var answers []Record
+	var firstErr error
+	for _, qtype := range []RecordType{TypeA, TypeAAAA} {
+		resp, err := c.Exchange(ctx, server, name, qtype)
+		if err != nil {
+			if isNotFound(err) || ctx.Err() != nil {
+				return nil, err
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		answers = append(answers, resp.Answers...)
+	}
+	if ips := HostFromRecords(name, answers).IPs; len(ips) > 0 {
+		return ips, nil
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: name, Server: server, IsNotFound: true}
+}
+
+// lookupOutcome classifies a lookup error for metrics.
+func lookupOutcome(err error) string {
+	if err == nil {
+		return "success"
+	}
+	var dnsErr *net.DNSError
+	switch {
+	case errors.As(err, &dnsErr) && dnsErr.IsNotFound:
+		return "nxdomain"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &dnsErr) && dnsErr.IsTimeout:
+		return "timeout"
+	default:
+		return "error"
+	}
 }
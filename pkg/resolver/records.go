@@ -0,0 +1,218 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/telemetry"
+)
+
+// maxCNAMEChain bounds CNAME chain following to break loops.
+const maxCNAMEChain = 10
+
+// Host is a name's addresses and the CNAME chain that led to them.
+type Host struct {
+	Name   string   `json:"name"`
+	IPs    []string `json:"ips,omitempty"`
+	CNAMEs []string `json:"cname_chain,omitempty"`
+}
+
+// Query sends a raw query for name to a healthy resolver and returns the
+// answer section. Failures are retried on other resolvers; NXDOMAIN is
+// returned immediately.
+func (p *Pool) Query(ctx context.Context, name string, qtype RecordType) ([]Record, error) {
+	var lastErr error
+	for attempt := 0; attempt < 3; attempt++ {
+		resolver, err := p.acquire(ctx)
+		if err != nil {
+			return nil, err
+		}
+		records, err := p.query(ctx, resolver, name, qtype)
+		if err == nil || isNotFound(err) || ctx.Err() != nil {
+			return records, err
+		}
+		lastErr = err
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(100 * time.Millisecond * time.Duration(attempt+1)):
+		}
+	}
+	return nil, lastErr
+}
+
+// query performs one instrumented raw query against resolver.
+func (p *Pool) query(ctx context.Context, resolver *Resolver, name string, qtype RecordType) ([]Record, error) {
+	p.mu.RLock()
+	tel, rec := p.telemetry, p.replay
+	p.mu.RUnlock()
+
+	spanCtx, span := tel.StartSpan(ctx, "dns.query",
+		telemetry.String("dns.name", name),
+		telemetry.String("dns.type", string(qtype)),
+		telemetry.String("dns.resolver", resolver.server),
+	)
+	start := time.Now()
+	lines, err := rec.Lookup(spanCtx, string(qtype), name, func(ctx context.Context, name string) ([]string, error) {
+		resp, err := p.client.Exchange(ctx, resolver.server, name, qtype)
+		if err != nil {
+			return nil, err
+		}
+		lines := make([]string, 0, len(resp.Answers))
+		for _, r := range resp.Answers {
+			lines = append(lines, r.String())
+		}
+		return lines, nil
+	})
+	outcome := lookupOutcome(err)
+	if ctx.Err() == nil {
+		resolver.observe(outcome, time.Since(start))
+	}
+	span.SetAttributes(telemetry.String("dns.outcome", outcome))
+	if outcome != "success" && outcome != "nxdomain" {
+		span.RecordError(err)
+	}
+	span.End()
+	tel.ObserveDNS(resolver.server, outcome)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]Record, 0, len(lines))
+	for _, line := range lines {
+		r, err := ParseRecord(line)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+// Records queries each type for name and returns the distinct answers in
+// query order. A missing name fails immediately; other failures are
+// returned only when no type produced records.
+func (p *Pool) Records(ctx context.Context, name string, types ...RecordType) ([]Record, error) {
+	var records []Record
+	var firstErr error
+	seen := map[string]bool{}
+	for _, qtype := range types {
+		answers, err := p.Query(ctx, name, qtype)
+		if err != nil {
+			if isNotFound(err) || ctx.Err() != nil {
+				return records, err
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		for _, r := range answers {
+			if key := r.String(); !seen[key] {
+				seen[key] = true
+				records = append(records, r)
+			}
+		}
+	}
+	if len(records) == 0 {
+		return nil, firstErr
+	}
+	return records, nil
+}
+
+// Resolve returns the IPv4 and IPv6 addresses of name together with the
+// CNAME chain leading to them.
+func (p *Pool) Resolve(ctx context.Context, name string) (*Host, error) {
+	answers, err := p.Query(ctx, name, TypeA)
+	if err != nil {
+		return nil, err
+	}
+	if v6, err := p.Query(ctx, name, TypeAAAA); err == nil {
+		answers = append(answers, v6...)
+	}
+	return HostFromRecords(name, answers), nil
+}
+
+// LookupAddr returns the names the PTR records of ip point to.
+func (p *Pool) LookupAddr(ctx context.Context, ip string) ([]string, error) {
+	name, err := ReverseName(ip)
+	if err != nil {
+		return nil, err
+	}
+	records, err := p.Query(ctx, name, TypePTR)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, r := range records {
+		if r.Type == TypePTR {
+			names = append(names, strings.ToLower(r.Value))
+		}
+	}
+	return names, nil
+}
+
+// ReverseName returns the in-addr.arpa or ip6.arpa name of ip.
+func ReverseName(ip string) (string, error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return "", fmt.Errorf("invalid IP address %q", ip)
+	}
+	addr = addr.Unmap()
+	var labels []string
+	if addr.Is4() {
+		b := addr.As4()
+		for i := len(b) - 1; i >= 0; i-- {
+			labels = append(labels, strconv.Itoa(int(b[i])))
+		}
+		return strings.Join(labels, ".") + ".in-addr.arpa", nil
+	}
+	const hex = "0123456789abcdef"
+	b := addr.As16()
+	for i := len(b) - 1; i >= 0; i-- {
+		labels = append(labels, string(hex[b[i]&0xf]), string(hex[b[i]>>4]))
+	}
+	return strings.Join(labels, ".") + ".ip6.arpa", nil
+}
+
+// HostFromRecords follows the CNAME chain from name through records and
+// collects the addresses of its final target.
+func HostFromRecords(name string, records []Record) *Host {
+	host := &Host{Name: trimDot(name)}
+	current := host.Name
+	for len(host.CNAMEs) < maxCNAMEChain {
+		next := ""
+		for _, r := range records {
+			if r.Type == TypeCNAME && strings.EqualFold(r.Name, current) {
+				next = r.Value
+				break
+			}
+		}
+		if next == "" {
+			break
+		}
+		host.CNAMEs = append(host.CNAMEs, next)
+		current = next
+	}
+
+	seen := map[string]bool{}
+	for _, r := range records {
+		if (r.Type == TypeA || r.Type == TypeAAAA) && strings.EqualFold(r.Name, current) && !seen[r.Value] {
+			seen[r.Value] = true
+			host.IPs = append(host.IPs, r.Value)
+		}
+	}
+	return host
+}
+
+func isNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
@@ -0,0 +1,92 @@
+package resolver
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/config"
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/internal/replay"
+	"github.com/NASHEDIxCODER/gospyder/internal/telemetry"
+)
+
+// Servers returns the resolvers selected by cfg: the resolvers file when
+// set, then the configured list, then DefaultServers.
+func Servers(cfg config.DNSConfig) ([]string, error) {
+	if cfg.ResolversFile != "" {
+		return LoadServers(cfg.ResolversFile)
+	}
+	if len(cfg.Resolvers) == 0 {
+		return DefaultServers, nil
+	}
+	for _, server := range cfg.Resolvers {
+		if err := validateServer(server); err != nil {
+			return nil, err
+		}
+	}
+	return cfg.Resolvers, nil
+}
+
+// LoadServers reads resolvers from path, one "ip", "ip:port" or
+// scheme://host[:port] URL per line. Blank lines and lines starting with # are ignored.
+func LoadServers(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.NewIOError("open resolvers file", err)
+	}
+	defer file.Close()
+
+	var servers []string
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		server := strings.TrimSpace(scanner.Text())
+		if server == "" || strings.HasPrefix(server, "#") {
+			continue
+		}
+		if err := validateServer(server); err != nil {
+			return nil, errors.NewValidationError(fmt.Sprintf("%s line %d: %v", path, line, err))
+		}
+		servers = append(servers, server)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.NewIOError("read resolvers file", err)
+	}
+	if len(servers) == 0 {
+		return nil, errors.NewValidationError(fmt.Sprintf("no resolvers in %s", path))
+	}
+	return servers, nil
+}
+
+// validateServer checks a server entry. Plain DNS needs an IP address; DoT
+// and DoH servers may be named, since the name is verified by TLS.
+func validateServer(server string) error {
+	e, err := parseServer(server)
+	if err != nil {
+		return errors.NewValidationError(err.Error())
+	}
+	if (e.transport == TransportUDP || e.transport == TransportTCP) && net.ParseIP(e.host) == nil {
+		return errors.NewValidationError(fmt.Sprintf("invalid resolver address %q", server))
+	}
+	return nil
+}
+
+// NewPoolFromConfig builds a pool over the resolvers selected by cfg and
+// drops those that fail the sanity check. The dropped servers are listed in
+// Stats.
+func NewPoolFromConfig(ctx context.Context, cfg config.DNSConfig, tel *telemetry.Telemetry, rec *replay.Session) (*Pool, error) {
+	servers, err := Servers(cfg)
+	if err != nil {
+		return nil, err
+	}
+	pool := NewPool(servers)
+	pool.SetTelemetry(tel)
+	pool.SetReplay(rec)
+	if err := pool.Sanitize(ctx); err != nil {
+		return nil, err
+	}
+	return pool, nil
+}
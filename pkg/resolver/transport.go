@@ -0,0 +1,143 @@
+package resolver
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Resolver transports, selected by the scheme of a server entry.
+const (
+	TransportUDP   = "udp"   // plain DNS, retried over TCP when truncated
+	TransportTCP   = "tcp"   // plain DNS over TCP
+	TransportTLS   = "tls"   // DNS over TLS (RFC 7858)
+	TransportHTTPS = "https" // DNS over HTTPS (RFC 8484)
+)
+
+// dohContentType is the RFC 8484 media type of wire-format DNS messages.
+const dohContentType = "application/dns-message"
+
+// endpoint is a parsed server entry.
+type endpoint struct {
+	transport string
+	address   string // host:port
+	host      string
+	url       string // DoH only
+}
+
+// parseServer reads a server entry: "ip", "ip:port", or a URL such as
+// "tcp://ip:port", "tls://host:853" or "https://host/dns-query".
+func parseServer(server string) (endpoint, error) {
+	if !strings.Contains(server, "://") {
+		address := serverAddress(server)
+		host, _, _ := net.SplitHostPort(address)
+		return endpoint{transport: TransportUDP, address: address, host: host}, nil
+	}
+	u, err := url.Parse(server)
+	if err != nil || u.Hostname() == "" {
+		return endpoint{}, fmt.Errorf("invalid resolver %q", server)
+	}
+	e := endpoint{transport: u.Scheme, host: u.Hostname()}
+	port := u.Port()
+	switch u.Scheme {
+	case TransportUDP, TransportTCP:
+		if port == "" {
+			port = "53"
+		}
+	case TransportTLS:
+		if port == "" {
+			port = "853"
+		}
+	case TransportHTTPS:
+		if u.Path == "" {
+			u.Path = "/dns-query"
+		}
+		e.url = u.String()
+		if port == "" {
+			port = "443"
+		}
+	default:
+		return endpoint{}, fmt.Errorf("unsupported resolver transport %q in %q", u.Scheme, server)
+	}
+	e.address = net.JoinHostPort(e.host, port)
+	return e, nil
+}
+
+// dialStream opens a TCP or TLS connection to e.
+func (c *Client) dialStream(ctx context.Context, e endpoint) (net.Conn, error) {
+	conn, err := c.dial(ctx, "tcp", e.address)
+	if err != nil || e.transport != TransportTLS {
+		return conn, err
+	}
+	config := &tls.Config{}
+	if c.TLSConfig != nil {
+		config = c.TLSConfig.Clone()
+	}
+	if config.ServerName == "" {
+		config.ServerName = e.host
+	}
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+// exchangeStream sends query over a fresh TCP or TLS connection.
+func (c *Client) exchangeStream(ctx context.Context, e endpoint, query []byte, id uint16) ([]byte, error) {
+	conn, err := c.dialStream(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if err := writeTCPMessage(conn, query); err != nil {
+		return nil, err
+	}
+	return readTCPMessage(conn, id)
+}
+
+// exchangeHTTPS POSTs query to a DoH endpoint. The message ID is zeroed as
+// RFC 8484 recommends.
+func (c *Client) exchangeHTTPS(ctx context.Context, e endpoint, query []byte) ([]byte, error) {
+	query[0], query[1] = 0, 0
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(query))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", dohContentType)
+	req.Header.Set("Accept", dohContentType)
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("DoH server returned %s", resp.Status)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, dohContentType) {
+		return nil, fmt.Errorf("DoH server returned content type %q", ct)
+	}
+	reply, err := io.ReadAll(io.LimitReader(resp.Body, 65535))
+	if err != nil {
+		return nil, err
+	}
+	if len(reply) < 2 || reply[0] != 0 || reply[1] != 0 {
+		return nil, fmt.Errorf("mismatched DNS response ID")
+	}
+	return reply, nil
+}
@@ -0,0 +1,127 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/replay"
+	"github.com/NASHEDIxCODER/gospyder/internal/telemetry"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// Exchange sends one DNSSEC-enabled query straight to server, typically a
+// zone's authoritative nameserver, bypassing the pool's resolvers. The
+// response is returned alongside NXDOMAIN so callers can read the denial
+// records in its authority section.
+func (p *Pool) Exchange(ctx context.Context, server, name string, qtype RecordType) (*Response, error) {
+	lines, err := p.direct(ctx, "dns.exchange", server, string(qtype), name, func(ctx context.Context, _ string) ([]string, error) {
+		client := *p.client
+		client.DNSSEC = true
+		resp, err := client.Exchange(ctx, server, name, qtype)
+		if resp == nil {
+			return nil, err
+		}
+		return responseLines(resp), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := parseResponseLines(lines)
+	if err != nil {
+		return nil, err
+	}
+	return resp, rcodeError(resp.RCode, name, server)
+}
+
+// Transfer attempts an AXFR of zone from server.
+func (p *Pool) Transfer(ctx context.Context, server, zone string) ([]Record, error) {
+	lines, err := p.direct(ctx, "dns.axfr", server, "AXFR", zone, func(ctx context.Context, _ string) ([]string, error) {
+		records, err := p.client.Transfer(ctx, server, zone)
+		if err != nil {
+			return nil, err
+		}
+		lines := make([]string, 0, len(records))
+		for _, r := range records {
+			lines = append(lines, r.String())
+		}
+		return lines, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	records := make([]Record, 0, len(lines))
+	for _, line := range lines {
+		r, err := ParseRecord(line)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+// direct runs an instrumented query against a specific server. Recordings
+// are keyed by "name@server" since answers differ between nameservers.
+func (p *Pool) direct(ctx context.Context, spanName, server, qtype, name string, fn replay.LookupFunc) ([]string, error) {
+	p.mu.RLock()
+	tel, rec := p.telemetry, p.replay
+	p.mu.RUnlock()
+
+	spanCtx, span := tel.StartSpan(ctx, spanName,
+		telemetry.String("dns.name", name),
+		telemetry.String("dns.type", qtype),
+		telemetry.String("dns.server", server),
+	)
+	lines, err := rec.Lookup(spanCtx, qtype, name+"@"+server, fn)
+	outcome := lookupOutcome(err)
+	span.SetAttributes(telemetry.String("dns.outcome", outcome))
+	if outcome != "success" && outcome != "nxdomain" {
+		span.RecordError(err)
+	}
+	span.End()
+	tel.ObserveDNS(server, outcome)
+	return lines, err
+}
+
+// responseLines flattens resp into lines for the replay fixture: the
+// response code, then each record tagged with its section.
+func responseLines(resp *Response) []string {
+	lines := []string{fmt.Sprintf("RCODE %d", resp.RCode)}
+	for _, r := range resp.Answers {
+		lines = append(lines, "ANSWER "+r.String())
+	}
+	for _, r := range resp.Authority {
+		lines = append(lines, "AUTHORITY "+r.String())
+	}
+	return lines
+}
+
+func parseResponseLines(lines []string) (*Response, error) {
+	resp := &Response{}
+	for _, line := range lines {
+		section, rest, _ := strings.Cut(line, " ")
+		if section == "RCODE" {
+			code, err := strconv.ParseUint(rest, 10, 16)
+			if err != nil {
+				return nil, fmt.Errorf("invalid response line %q", line)
+			}
+			resp.RCode = dnsmessage.RCode(code)
+			continue
+		}
+		r, err := ParseRecord(rest)
+		if err != nil {
+			return nil, err
+		}
+		switch section {
+		case "ANSWER":
+			resp.Answers = append(resp.Answers, r)
+		case "AUTHORITY":
+			resp.Authority = append(resp.Authority, r)
+		default:
+			return nil, fmt.Errorf("invalid response line %q", line)
+		}
+	}
+	return resp, nil
+}
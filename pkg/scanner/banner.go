@@ -21,7 +21,7 @@ type BannerResult struct {
 // GrabBanner connects to a port and reads the initial banner.
 // Timeout controls how long we wait for banner data.
 func GrabBanner(ctx context.Context, target string, port int, timeout time.Duration) (string, error) {
-	address := fmt.Sprintf("%s:%d", target, port)
+	address := net.JoinHostPort(target, fmt.Sprint(port))
 
 	dialer := net.Dialer{Timeout: timeout}
 	conn, err := dialer.DialContext(ctx, "tcp", address)
@@ -137,14 +137,14 @@ var HTTPPorts = map[int]bool{
 }
 
 // ProbeHTTPPort performs an HTTP GET request to detect web server details.
-// Returns the Server header value.
-func ProbeHTTPPort(ctx context.Context, target string, port int, timeout time.Duration) string {
+// Returns the Server header value. A nil transport means http.DefaultTransport.
+func ProbeHTTPPort(ctx context.Context, transport http.RoundTripper, target string, port int, timeout time.Duration) string {
 	scheme := "http"
 	if port == 443 || port == 8443 {
 		scheme = "https"
 	}
 
-	address := fmt.Sprintf("%s://%s:%d/", scheme, target, port)
+	address := fmt.Sprintf("%s://%s/", scheme, net.JoinHostPort(target, fmt.Sprint(port)))
 
 	req, err := http.NewRequestWithContext(ctx, "GET", address, nil)
 	if err != nil {
@@ -152,7 +152,7 @@ func ProbeHTTPPort(ctx context.Context, target string, port int, timeout time.Du
 	}
 	req.Header.Set("User-Agent", "GoSpyder/3.0")
 
-	client := &http.Client{Timeout: timeout}
+	client := &http.Client{Timeout: timeout, Transport: transport}
 	resp, err := client.Do(req)
 	if err != nil {
 		return ""
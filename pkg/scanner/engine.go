@@ -0,0 +1,324 @@
+package scanner
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/internal/registry"
+	"github.com/NASHEDIxCODER/gospyder/internal/replay"
+)
+
+const (
+	// discoveryPorts of the top TCP ports are probed first on every host,
+	// so that a host filtering the rest still answers before DeadAfter.
+	discoveryPorts = 10
+	// retryWindow is how many retried ports a host gets before retries
+	// stop, if none of them answered on a retry.
+	retryWindow = 16
+)
+
+// Engine connect-scans TCP ports across many hosts. A fixed pool of
+// workers takes host and port pairs from one queue, ordered port by port
+// so that consecutive probes go to different hosts.
+//
+// Each host's timeout follows its connect round-trip time as TCP's
+// retransmission timer does (RFC 6298), and doubles on each retry.
+// Retries stop on hosts where they never get an answer, which filter
+// rather than drop. A host that answers none of its first DeadAfter
+// ports is taken as down and its remaining ports are skipped.
+type Engine struct {
+	// Workers is the number of probes in flight; zero means 100.
+	Workers int
+	// Retries is how often an unanswered port is probed again.
+	Retries int
+	// Timeout is the connect timeout until a host's round-trip time is
+	// known, and the most it grows to; zero means 3s.
+	Timeout time.Duration
+	// MinTimeout is the least a host's timeout shrinks to; zero means
+	// 100ms.
+	MinTimeout time.Duration
+	// DeadAfter is how many unanswered ports make a silent host count as
+	// down; zero scans every port of every host.
+	DeadAfter int
+	// Dial opens connections; nil dials directly.
+	Dial replay.DialFunc
+	// Open is called by the worker with each open port's connection,
+	// which the engine closes afterwards.
+	Open func(ctx context.Context, host string, port int, conn net.Conn)
+	// Progress counts one probe per host and port; nil disables it.
+	Progress registry.Progress
+	// Failures tallies dial errors that say nothing about the host, such
+	// as running out of file descriptors; nil discards them.
+	Failures *errors.Tally
+}
+
+// HostStats reports how the scan of one host went, for result metadata.
+type HostStats struct {
+	Host      string  `json:"host"`
+	Open      int     `json:"open"`
+	Closed    int     `json:"closed"`
+	Filtered  int     `json:"filtered"`
+	Skipped   int     `json:"skipped,omitempty"`
+	Down      bool    `json:"down,omitempty"`
+	RTTMs     float64 `json:"rtt_ms"`
+	TimeoutMs float64 `json:"timeout_ms"`
+	Retries   int     `json:"retries"`
+	Errors    int     `json:"errors,omitempty"`
+}
+
+func (s HostStats) String() string {
+	if s.Down {
+		return fmt.Sprintf("%s down: no answer on %d ports, %d skipped", s.Host, s.Filtered, s.Skipped)
+	}
+	return fmt.Sprintf("%s: %d open, %d closed, %d filtered, %.1fms", s.Host, s.Open, s.Closed, s.Filtered, s.RTTMs)
+}
+
+// hostState tracks one host during a scan.
+type hostState struct {
+	mu      sync.Mutex
+	stats   HostStats
+	srtt    time.Duration
+	rttvar  time.Duration
+	sampled bool
+	// retried counts ports that needed a retry, and recovered those
+	// answering on one.
+	retried   int
+	recovered int
+}
+
+// observe records an answer that took rtt, on the given attempt.
+func (h *hostState) observe(rtt time.Duration, attempt int, open bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if open {
+		h.stats.Open++
+	} else {
+		h.stats.Closed++
+	}
+	if attempt > 0 {
+		h.retried++
+		h.recovered++
+		// Which attempt answered is unknown, so it gives no sample
+		// (Karn's algorithm).
+		return
+	}
+	if !h.sampled {
+		h.srtt, h.rttvar, h.sampled = rtt, rtt/2, true
+		return
+	}
+	diff := h.srtt - rtt
+	if diff < 0 {
+		diff = -diff
+	}
+	h.rttvar = (3*h.rttvar + diff) / 4
+	h.srtt = (7*h.srtt + rtt) / 8
+}
+
+// silent records a port unanswered after retries tries, marking the
+// host down once deadAfter ports went unanswered and none answered.
+func (h *hostState) silent(retries, deadAfter int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.stats.Filtered++
+	if retries > 0 {
+		h.retried++
+	}
+	if deadAfter > 0 && h.stats.Filtered >= deadAfter && h.stats.Open+h.stats.Closed == 0 {
+		h.stats.Down = true
+	}
+}
+
+// failed records a port that could not be probed for a local reason.
+func (h *hostState) failed() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.stats.Errors++
+}
+
+func (h *hostState) skip() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.stats.Down {
+		h.stats.Skipped++
+	}
+	return h.stats.Down
+}
+
+// timeout is the host's retransmission timeout, clamped to [lo, hi].
+func (h *hostState) timeout(lo, hi time.Duration) time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.sampled {
+		return hi
+	}
+	return min(max(h.srtt+4*h.rttvar, lo), hi)
+}
+
+// retries is how often the host's ports are retried: max, until
+// retryWindow retried ports have gone without an answer.
+func (h *hostState) retries(limit int) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.retried >= retryWindow && h.recovered == 0 {
+		return 0
+	}
+	return limit
+}
+
+// Scan probes ports on every host and returns each host's statistics,
+// in the order given. Open ports are reported through Open.
+func (e *Engine) Scan(ctx context.Context, hosts []string, ports []int) []HostStats {
+	states := make([]*hostState, len(hosts))
+	for i, host := range hosts {
+		states[i] = &hostState{stats: HostStats{Host: host}}
+	}
+	progress := e.Progress
+	if progress == nil {
+		progress = registry.NopProgress
+	}
+	progress.AddTotal(int64(len(hosts) * len(ports)))
+
+	type probe struct {
+		host *hostState
+		port int
+	}
+	queue := make(chan probe)
+	workers := e.Workers
+	if workers <= 0 {
+		workers = 100
+	}
+	var wg sync.WaitGroup
+	for range min(workers, len(hosts)*len(ports)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range queue {
+				e.probe(ctx, p.host, p.port)
+				progress.Increment(1)
+			}
+		}()
+	}
+
+feed:
+	for _, port := range discoveryFirst(ports) {
+		for _, h := range states {
+			if h.skip() {
+				progress.Increment(1)
+				continue
+			}
+			select {
+			case queue <- probe{h, port}:
+			case <-ctx.Done():
+				break feed
+			}
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	lo, hi := e.timeouts()
+	stats := make([]HostStats, len(states))
+	for i, h := range states {
+		stats[i] = h.stats
+		stats[i].RTTMs = float64(h.srtt.Microseconds()) / 1000
+		stats[i].TimeoutMs = float64(h.timeout(lo, hi).Microseconds()) / 1000
+		stats[i].Retries = h.retries(e.Retries)
+	}
+	return stats
+}
+
+// probe connects to one port, retrying while it goes unanswered.
+func (e *Engine) probe(ctx context.Context, h *hostState, port int) {
+	address := net.JoinHostPort(h.stats.Host, strconv.Itoa(port))
+	dial := e.Dial
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	lo, hi := e.timeouts()
+	retries := h.retries(e.Retries)
+	var lastErr error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 && h.skip() {
+			return
+		}
+		timeout := min(h.timeout(lo, hi)<<attempt, hi)
+		dialCtx, cancel := context.WithTimeout(ctx, timeout)
+		start := time.Now()
+		conn, err := dial(dialCtx, "tcp", address)
+		rtt := time.Since(start)
+		cancel()
+		if ctx.Err() != nil {
+			if conn != nil {
+				conn.Close()
+			}
+			return
+		}
+		switch {
+		case err == nil:
+			h.observe(rtt, attempt, true)
+			if e.Open != nil {
+				e.Open(ctx, h.stats.Host, port, conn)
+			}
+			conn.Close()
+			return
+		case stderrors.Is(err, syscall.ECONNREFUSED), stderrors.Is(err, syscall.ECONNRESET):
+			h.observe(rtt, attempt, false)
+			return
+		case unreachable(err):
+			// An ICMP unreachable will not change on a retry.
+			h.silent(0, e.DeadAfter)
+			return
+		}
+		lastErr = err
+	}
+	if !timedOut(lastErr) {
+		// A local failure, such as EMFILE, is no sign of a dead host.
+		e.Failures.Add(lastErr)
+		h.failed()
+		return
+	}
+	h.silent(retries, e.DeadAfter)
+}
+
+// timedOut reports whether a dial went unanswered until its deadline.
+func timedOut(err error) bool {
+	var netErr net.Error
+	return stderrors.Is(err, context.DeadlineExceeded) || stderrors.As(err, &netErr) && netErr.Timeout()
+}
+
+func (e *Engine) timeouts() (time.Duration, time.Duration) {
+	lo, hi := e.MinTimeout, e.Timeout
+	if hi <= 0 {
+		hi = 3 * time.Second
+	}
+	if lo <= 0 {
+		lo = 100 * time.Millisecond
+	}
+	return min(lo, hi), hi
+}
+
+// discoveryFirst moves the most commonly open TCP ports among ports to
+// the front, keeping the order of both parts.
+func discoveryFirst(ports []int) []int {
+	common := TopPorts("tcp", discoveryPorts)
+	ordered := make([]int, 0, len(ports))
+	for _, port := range ports {
+		if slices.Contains(common, port) {
+			ordered = append(ordered, port)
+		}
+	}
+	for _, port := range ports {
+		if !slices.Contains(common, port) {
+			ordered = append(ordered, port)
+		}
+	}
+	return ordered
+}
@@ -0,0 +1,150 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+)
+
+// fakeNetwork answers dials by address: "open" connects, "closed"
+// refuses, "emfile" fails locally and anything else never answers.
+type fakeNetwork struct {
+	mu     sync.Mutex
+	states map[string]string
+	dials  []string
+}
+
+func (n *fakeNetwork) dial(ctx context.Context, _, address string) (net.Conn, error) {
+	n.mu.Lock()
+	n.dials = append(n.dials, address)
+	state := n.states[address]
+	if state == "" {
+		host, _, _ := net.SplitHostPort(address)
+		state = n.states[host]
+	}
+	n.mu.Unlock()
+	switch state {
+	case "open":
+		client, server := net.Pipe()
+		server.Close()
+		return client, nil
+	case "closed":
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	case "emfile":
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.EMFILE}
+	}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestEngineInterleavesHostsAndReportsOpenPorts(t *testing.T) {
+	network := &fakeNetwork{states: map[string]string{"a": "closed", "b": "closed", "c": "closed", "b:2": "open"}}
+	var open []string
+	engine := &Engine{
+		Workers: 1,
+		Dial:    network.dial,
+		Open: func(_ context.Context, host string, port int, _ net.Conn) {
+			open = append(open, fmt.Sprintf("%s:%d", host, port))
+		},
+	}
+	stats := engine.Scan(context.Background(), []string{"a", "b", "c"}, []int{1, 2})
+
+	if got := strings.Join(network.dials, " "); got != "a:1 b:1 c:1 a:2 b:2 c:2" {
+		t.Errorf("dial order = %s", got)
+	}
+	if fmt.Sprint(open) != "[b:2]" {
+		t.Errorf("open = %v", open)
+	}
+	if stats[1].Host != "b" || stats[1].Open != 1 || stats[1].Closed != 1 || stats[0].Closed != 2 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestEngineSkipsDeadHosts(t *testing.T) {
+	network := &fakeNetwork{states: map[string]string{"alive": "closed"}}
+	engine := &Engine{
+		Workers:   1,
+		Retries:   1,
+		Timeout:   10 * time.Millisecond,
+		DeadAfter: 3,
+		Dial:      network.dial,
+	}
+	ports := []int{1, 2, 3, 4, 5, 6, 7, 8}
+	stats := engine.Scan(context.Background(), []string{"dead", "alive"}, ports)
+
+	dead, alive := stats[0], stats[1]
+	if !dead.Down || dead.Filtered != 3 || dead.Skipped != 5 {
+		t.Errorf("dead host = %+v, want down after 3 ports", dead)
+	}
+	if alive.Down || alive.Closed != len(ports) {
+		t.Errorf("alive host = %+v", alive)
+	}
+	deadDials := 0
+	for _, address := range network.dials {
+		if strings.HasPrefix(address, "dead:") {
+			deadDials++
+		}
+	}
+	if deadDials != 6 {
+		t.Errorf("dead host dialed %d times, want 3 ports with one retry each", deadDials)
+	}
+}
+
+func TestEngineDoesNotTakeLocalFailuresForDeadHosts(t *testing.T) {
+	network := &fakeNetwork{states: map[string]string{"busy": "emfile"}}
+	failures := errors.NewTally("TCP connects")
+	engine := &Engine{
+		Workers:   1,
+		Retries:   1,
+		Timeout:   10 * time.Millisecond,
+		DeadAfter: 3,
+		Dial:      network.dial,
+		Failures:  failures,
+	}
+	ports := []int{1, 2, 3, 4, 5, 6}
+	stats := engine.Scan(context.Background(), []string{"busy"}, ports)[0]
+
+	if stats.Down || stats.Filtered != 0 || stats.Skipped != 0 || stats.Errors != len(ports) {
+		t.Errorf("stats = %+v, want every port failed without the host down", stats)
+	}
+	if errs := failures.Errors(); len(errs) != 1 || !strings.Contains(errs[0].Error(), "6 failed") {
+		t.Errorf("failures = %v", errs)
+	}
+}
+
+func TestEngineAdaptsTimeoutsAndRetries(t *testing.T) {
+	network := &fakeNetwork{states: map[string]string{"filtering:1000": "closed"}}
+	engine := &Engine{
+		Workers:    1,
+		Retries:    2,
+		Timeout:    time.Second,
+		MinTimeout: 5 * time.Millisecond,
+		Dial:       network.dial,
+	}
+	ports := make([]int, retryWindow+5)
+	for i := range ports {
+		ports[i] = 1000 + i
+	}
+	start := time.Now()
+	stats := engine.Scan(context.Background(), []string{"filtering"}, ports)[0]
+
+	// The refused port 1000 sets a timeout near the floor, so the filtered
+	// ports cost milliseconds rather than the full second each.
+	if stats.TimeoutMs > 50 || time.Since(start) > 5*time.Second {
+		t.Errorf("timeout = %.1fms after %v", stats.TimeoutMs, time.Since(start))
+	}
+	// Retries stop once retryWindow ports were retried without an answer.
+	if stats.Retries != 0 || stats.Filtered != len(ports)-1 || stats.Down {
+		t.Errorf("stats = %+v", stats)
+	}
+	if dials := len(network.dials); dials >= 1+len(ports)*3 {
+		t.Errorf("%d dials, want retries to stop", dials)
+	}
+}
@@ -14,7 +14,11 @@ import (
 	"sync"
 	"time"
 
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
 	"github.com/NASHEDIxCODER/gospyder/internal/logger"
+	"github.com/NASHEDIxCODER/gospyder/internal/registry"
+	"github.com/NASHEDIxCODER/gospyder/internal/replay"
+	"github.com/NASHEDIxCODER/gospyder/internal/telemetry"
 	"github.com/google/uuid"
 )
 
@@ -46,13 +50,17 @@ type FuzzerConfig struct {
 	DetectSoft404     bool
 	DetectCatchAll    bool
 	DetectSPA         bool
+	Progress          registry.Progress    // per-request counters; nil disables reporting
+	Telemetry         *telemetry.Telemetry // request metrics and spans; nil disables them
+	Errors            *errors.Collector    // request and baseline failures; nil discards them
+	Replay            *replay.Session      // records or replays requests; nil sends them directly
 }
 
 // DefaultFuzzerConfig returns a sensible default configuration.
 func DefaultFuzzerConfig() FuzzerConfig {
 	return FuzzerConfig{
-		TolerancePercent:  0.05,  // 5% content length tolerance
-		MinToleranceBytes: 50,    // minimum 50 bytes absolute difference
+		TolerancePercent:  0.05, // 5% content length tolerance
+		MinToleranceBytes: 50,   // minimum 50 bytes absolute difference
 		Debug:             false,
 		DetectSoft404:     true,
 		DetectCatchAll:    true,
@@ -92,7 +100,7 @@ func NewFuzzer(config FuzzerConfig) *Fuzzer {
 		fingerprints: make(map[string]int),
 		client: &http.Client{
 			Timeout:   10 * time.Second,
-			Transport: transport,
+			Transport: config.Telemetry.Transport(config.Replay.Transport(transport), "fuzz"),
 			CheckRedirect: func(req *http.Request, via []*http.Request) error {
 				return http.ErrUseLastResponse
 			},
@@ -294,7 +302,9 @@ func (f *Fuzzer) establishBaseline(ctx context.Context, baseURL string) error {
 }
 
 // Scan performs directory fuzzing with wildcard detection and response fingerprinting.
-func (f *Fuzzer) Scan(ctx context.Context, baseURL, wordlist string, threads int) []string {
+// It returns an error when the wordlist cannot be read; failed requests are
+// recorded in the configured error collector.
+func (f *Fuzzer) Scan(ctx context.Context, baseURL, wordlist string, threads int) ([]string, error) {
 	var found []string
 	var mu sync.Mutex
 	var wg sync.WaitGroup
@@ -308,17 +318,25 @@ func (f *Fuzzer) Scan(ctx context.Context, baseURL, wordlist string, threads int
 	// Establish baseline first
 	if err := f.establishBaseline(ctx, baseURL); err != nil {
 		f.config.Logger.Debug("Failed to establish baseline: %v", err)
+		f.config.Errors.Add(errors.Wrap("establish wildcard baseline", err))
 		// Continue without baseline filtering
 	}
 
 	file, err := os.Open(wordlist)
 	if err != nil {
-		return found
+		return found, errors.NewIOError("open wordlist", err)
 	}
 	defer file.Close()
 
+	requestErrors := errors.NewTally("fuzz requests")
+	defer requestErrors.FlushTo(f.config.Errors)
+
 	sem := make(chan struct{}, threads)
 	scanner := bufio.NewScanner(file)
+	progress := f.config.Progress
+	if progress == nil {
+		progress = registry.NopProgress
+	}
 
 	// Track stats
 	keptCount := 0
@@ -333,7 +351,8 @@ func (f *Fuzzer) Scan(ctx context.Context, baseURL, wordlist string, threads int
 				f.config.Logger.Info("Findings Kept: %d", keptCount)
 				f.config.Logger.Info("Findings Discarded: %d", discardedCount)
 			}
-			return found
+			wg.Wait()
+			return found, nil
 		default:
 		}
 
@@ -342,9 +361,11 @@ func (f *Fuzzer) Scan(ctx context.Context, baseURL, wordlist string, threads int
 			continue
 		}
 
+		progress.AddTotal(1)
 		wg.Add(1)
 		go func(p string) {
 			defer wg.Done()
+			defer progress.Increment(1)
 
 			sem <- struct{}{}
 			defer func() { <-sem }()
@@ -353,6 +374,10 @@ func (f *Fuzzer) Scan(ctx context.Context, baseURL, wordlist string, threads int
 
 			result, err := f.fetchResponse(ctx, url)
 			if err != nil {
+				progress.AddError()
+				if ctx.Err() == nil {
+					requestErrors.Add(err)
+				}
 				return
 			}
 
@@ -394,6 +419,7 @@ func (f *Fuzzer) Scan(ctx context.Context, baseURL, wordlist string, threads int
 			statsMu.Lock()
 			keptCount++
 			statsMu.Unlock()
+			progress.AddFinding()
 
 			mu.Lock()
 			found = append(found,
@@ -415,5 +441,5 @@ func (f *Fuzzer) Scan(ctx context.Context, baseURL, wordlist string, threads int
 		f.config.Logger.Info("Findings Discarded: %d", discardedCount)
 	}
 
-	return found
-}
\ No newline at end of file
+	return found, nil
+}
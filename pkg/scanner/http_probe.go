@@ -5,13 +5,16 @@ import (
 	"fmt"
 	"html"
 	"io"
+	"net"
 	"net/http"
+	"net/http/httptrace"
 	"regexp"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
 	"github.com/NASHEDIxCODER/gospyder/internal/registry"
 )
 
@@ -38,7 +41,7 @@ func (m *HTTPProbeModuleAdapter) Run(ctx context.Context, opts registry.Options)
 	targets := probeTargets(target, priorResult(opts, "enum"))
 	opts.Logger.Info("Starting HTTP probe for %d target(s)", len(targets))
 
-	findings := probeHTTP(ctx, opts.HTTPClient, targets, opts.Config.Threads)
+	findings := probeHTTP(ctx, opts.HTTPClient, targets, opts.Config.Threads, opts.ProgressReporter(), opts.Errors)
 	return &registry.Result{
 		Module:    m.Name(),
 		Timestamp: time.Now(),
@@ -88,7 +91,7 @@ func (m *LiveHostModuleAdapter) Run(ctx context.Context, opts registry.Options)
 	httpResult := priorResult(opts, "http")
 	if httpResult == nil {
 		httpTargets := probeTargets(target, priorResult(opts, "enum"))
-		httpFindings := probeHTTP(ctx, opts.HTTPClient, httpTargets, opts.Config.Threads)
+		httpFindings := probeHTTP(ctx, opts.HTTPClient, httpTargets, opts.Config.Threads, opts.ProgressReporter(), opts.Errors)
 		httpResult = &registry.Result{Module: "http", Target: target, Findings: httpFindings}
 	}
 
@@ -164,7 +167,7 @@ func (m *TechModuleAdapter) Run(ctx context.Context, opts registry.Options) (*re
 		targets = urlsFromHTTPResult(httpResult)
 	}
 
-	findings := fingerprintTech(ctx, opts.HTTPClient, targets, opts.Config.Threads)
+	findings := fingerprintTech(ctx, opts.HTTPClient, targets, opts.Config.Threads, opts.ProgressReporter(), opts.Errors)
 	return &registry.Result{
 		Module:    m.Name(),
 		Timestamp: time.Now(),
@@ -205,7 +208,7 @@ func probeTargets(target string, enumResult *registry.Result) []string {
 	return out
 }
 
-func probeHTTP(ctx context.Context, client *http.Client, targets []string, threads int) []registry.Finding {
+func probeHTTP(ctx context.Context, client *http.Client, targets []string, threads int, progress registry.Progress, errs *errors.Collector) []registry.Finding {
 	if threads <= 0 {
 		threads = 10
 	}
@@ -217,11 +220,15 @@ func probeHTTP(ctx context.Context, client *http.Client, targets []string, threa
 	var wg sync.WaitGroup
 	sem := make(chan struct{}, threads)
 	findings := []registry.Finding{}
+	probeErrors := errors.NewTally("HTTP probes")
+	defer probeErrors.FlushTo(errs)
+	progress.AddTotal(int64(len(targets)))
 
 	for _, target := range targets {
 		wg.Add(1)
 		go func(tgt string) {
 			defer wg.Done()
+			defer progress.Increment(1)
 			select {
 			case sem <- struct{}{}:
 			case <-ctx.Done():
@@ -230,12 +237,18 @@ func probeHTTP(ctx context.Context, client *http.Client, targets []string, threa
 			defer func() { <-sem }()
 
 			// HTTPS-first probing: try HTTPS, fallback to HTTP
-			finding, ok := probeHTTPSFirst(ctx, client, tgt)
-			if ok {
-				mu.Lock()
-				findings = append(findings, finding)
-				mu.Unlock()
+			finding, err := probeHTTPSFirst(ctx, client, tgt)
+			if err != nil {
+				progress.AddError()
+				if ctx.Err() == nil {
+					probeErrors.Add(err)
+				}
+				return
 			}
+			progress.AddFinding()
+			mu.Lock()
+			findings = append(findings, finding)
+			mu.Unlock()
 		}(target)
 	}
 
@@ -245,29 +258,40 @@ func probeHTTP(ctx context.Context, client *http.Client, targets []string, threa
 }
 
 // probeHTTPSFirst tries HTTPS first, falls back to HTTP if HTTPS fails.
-// Returns the first successful probe result.
-func probeHTTPSFirst(ctx context.Context, client *http.Client, target string) (registry.Finding, bool) {
-	baseURLs := httpsFirstCandidateURLs(target)
-	for _, probeURL := range baseURLs {
-		finding, ok := doHTTPProbe(ctx, client, probeURL)
-		if ok {
-			return finding, true
+// Returns the first successful probe result, or the last error.
+func probeHTTPSFirst(ctx context.Context, client *http.Client, target string) (registry.Finding, error) {
+	var lastErr error
+	for _, probeURL := range httpsFirstCandidateURLs(target) {
+		finding, err := doHTTPProbe(ctx, client, probeURL)
+		if err == nil {
+			return finding, nil
 		}
+		lastErr = err
 	}
-	return registry.Finding{}, false
+	return registry.Finding{}, lastErr
 }
 
-func doHTTPProbe(ctx context.Context, client *http.Client, probeURL string) (registry.Finding, bool) {
+func doHTTPProbe(ctx context.Context, client *http.Client, probeURL string) (registry.Finding, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, probeURL, nil)
 	if err != nil {
-		return registry.Finding{}, false
+		return registry.Finding{}, errors.NewValidationError(fmt.Sprintf("invalid probe URL %q", probeURL))
 	}
 	req.Header.Set("User-Agent", "GoSpyder/3.0")
+	// Note the address that answered, for IP enrichment. After redirects
+	// it is the final hop's.
+	var remoteIP string
+	req = req.WithContext(httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if host, _, err := net.SplitHostPort(info.Conn.RemoteAddr().String()); err == nil {
+				remoteIP = host
+			}
+		},
+	}))
 
 	start := time.Now()
 	resp, err := client.Do(req)
 	if err != nil {
-		return registry.Finding{}, false
+		return registry.Finding{}, err
 	}
 	defer resp.Body.Close()
 
@@ -280,23 +304,27 @@ func doHTTPProbe(ctx context.Context, client *http.Client, probeURL string) (reg
 	title := extractTitle(string(body))
 	server := resp.Header.Get("Server")
 
+	metadata := map[string]interface{}{
+		"url":              probeURL,
+		"status_code":      resp.StatusCode,
+		"title":            title,
+		"server":           server,
+		"content_length":   contentLength,
+		"response_time_ms": elapsed.Milliseconds(),
+	}
+	if remoteIP != "" {
+		metadata["ip"] = remoteIP
+	}
 	return registry.Finding{
 		Type:        "http_probe",
 		Value:       probeURL,
 		Description: fmt.Sprintf("%d %s", resp.StatusCode, title),
 		Severity:    "info",
-		Metadata: map[string]interface{}{
-			"url":              probeURL,
-			"status_code":      resp.StatusCode,
-			"title":            title,
-			"server":           server,
-			"content_length":   contentLength,
-			"response_time_ms": elapsed.Milliseconds(),
-		},
-	}, true
+		Metadata:    metadata,
+	}, nil
 }
 
-func fingerprintTech(ctx context.Context, client *http.Client, targets []string, threads int) []registry.Finding {
+func fingerprintTech(ctx context.Context, client *http.Client, targets []string, threads int, progress registry.Progress, errs *errors.Collector) []registry.Finding {
 	if threads <= 0 {
 		threads = 10
 	}
@@ -309,12 +337,22 @@ func fingerprintTech(ctx context.Context, client *http.Client, targets []string,
 	sem := make(chan struct{}, threads)
 	findings := []registry.Finding{}
 	seen := map[string]bool{}
+	// A target only counts as failed when none of its URLs answered.
+	probeErrors := errors.NewTally("technology probes")
+	defer probeErrors.FlushTo(errs)
+	pending := map[string]int{}
+	answered := map[string]bool{}
+	lastErr := map[string]error{}
 
 	for _, target := range targets {
-		for _, probeURL := range candidateURLs(target) {
+		urls := candidateURLs(target)
+		pending[target] = len(urls)
+		for _, probeURL := range urls {
+			progress.AddTotal(1)
 			wg.Add(1)
-			go func(url string) {
+			go func(target, url string) {
 				defer wg.Done()
+				defer progress.Increment(1)
 				select {
 				case sem <- struct{}{}:
 				case <-ctx.Done():
@@ -322,8 +360,17 @@ func fingerprintTech(ctx context.Context, client *http.Client, targets []string,
 				}
 				defer func() { <-sem }()
 
-				detections := detectTechnologies(ctx, client, url)
+				detections, err := detectTechnologies(ctx, client, url)
 				mu.Lock()
+				pending[target]--
+				if err != nil {
+					lastErr[target] = err
+				} else {
+					answered[target] = true
+				}
+				if pending[target] == 0 && !answered[target] && ctx.Err() == nil {
+					probeErrors.Add(lastErr[target])
+				}
 				for _, detection := range detections {
 					key := detection.Value + "|" + url
 					if seen[key] {
@@ -331,9 +378,10 @@ func fingerprintTech(ctx context.Context, client *http.Client, targets []string,
 					}
 					seen[key] = true
 					findings = append(findings, detection)
+					progress.AddFinding()
 				}
 				mu.Unlock()
-			}(probeURL)
+			}(target, probeURL)
 		}
 	}
 
@@ -347,16 +395,16 @@ func fingerprintTech(ctx context.Context, client *http.Client, targets []string,
 	return findings
 }
 
-func detectTechnologies(ctx context.Context, client *http.Client, probeURL string) []registry.Finding {
+func detectTechnologies(ctx context.Context, client *http.Client, probeURL string) ([]registry.Finding, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, probeURL, nil)
 	if err != nil {
-		return nil
+		return nil, errors.NewValidationError(fmt.Sprintf("invalid probe URL %q", probeURL))
 	}
 	req.Header.Set("User-Agent", "GoSpyder/3.0")
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -403,7 +451,7 @@ func detectTechnologies(ctx context.Context, client *http.Client, probeURL strin
 			},
 		})
 	}
-	return findings
+	return findings, nil
 }
 
 // httpsFirstCandidateURLs tries HTTPS first, falls back to HTTP.
@@ -475,4 +523,4 @@ func hostFromFinding(finding registry.Finding) string {
 		urlValue = urlValue[:idx]
 	}
 	return urlValue
-}
\ No newline at end of file
+}
@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"net/netip"
 	"net/url"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
 	"github.com/NASHEDIxCODER/gospyder/internal/registry"
 )
 
@@ -28,7 +30,7 @@ func (m *PortScanModuleAdapter) Name() string {
 }
 
 func (m *PortScanModuleAdapter) Description() string {
-	return "TCP port scanning with banner grabbing and service/version detection"
+	return "TCP and UDP port scanning with service probes and version detection"
 }
 
 func (m *PortScanModuleAdapter) Run(ctx context.Context, opts registry.Options) (*registry.Result, error) {
@@ -37,9 +39,20 @@ func (m *PortScanModuleAdapter) Run(ctx context.Context, opts registry.Options)
 		return nil, fmt.Errorf("target flag required")
 	}
 
-	ports, err := portsFromOptions(opts)
-	if err != nil {
-		return nil, err
+	scanUDP, _ := opts.Flags["udp"].(bool)
+	scanTCP, _ := opts.Flags["tcp"].(bool)
+	scanTCP = scanTCP || !scanUDP
+	var ports, udpPorts []int
+	var err error
+	if scanTCP {
+		if ports, err = portsFromOptions(opts, "tcp", opts.Config.Scanner.DefaultPorts); err != nil {
+			return nil, err
+		}
+	}
+	if scanUDP {
+		if udpPorts, err = portsFromOptions(opts, "udp", opts.Config.Scanner.DefaultUDPPorts); err != nil {
+			return nil, err
+		}
 	}
 
 	retries, _ := opts.Flags["retry"].(int)
@@ -47,13 +60,225 @@ func (m *PortScanModuleAdapter) Run(ctx context.Context, opts registry.Options)
 		retries = opts.Config.Retries
 	}
 
-	scanHost := tcpScanHost(target)
-	opts.Logger.Debug("Starting enhanced port scan for %s (%d ports)", scanHost, len(ports))
+	scanHost, hosts, err := scanHosts(target)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve once up front: an unresolvable host would otherwise look like
+	// every port being closed.
+	addrs := make(map[string][]string, len(hosts))
+	resolved := hosts[:0:0]
+	for _, host := range hosts {
+		if net.ParseIP(host) != nil {
+			addrs[host] = []string{host}
+			resolved = append(resolved, host)
+			continue
+		}
+		ips, err := opts.Replay.LookupHost(ctx, host, net.DefaultResolver.LookupHost)
+		if err != nil {
+			opts.Errors.Add(errors.Wrap(fmt.Sprintf("resolve %s", host), err))
+			continue
+		}
+		addrs[host] = ips
+		resolved = append(resolved, host)
+	}
+	hosts = resolved
+	if len(hosts) == 0 {
+		return &registry.Result{
+			Module:    m.Name(),
+			Timestamp: time.Now(),
+			Status:    "success",
+			Target:    target,
+			Metadata:  map[string]interface{}{"ports_scanned": 0, "scan_host": scanHost},
+		}, nil
+	}
+
+	rate, _ := opts.Flags["udp-rate"].(int)
+	if rate <= 0 {
+		rate = opts.Config.Scanner.UDPRate
+	}
+
+	probesPath, _ := opts.Flags["probes"].(string)
+	if probesPath == "" {
+		probesPath = opts.Config.Scanner.ServiceProbes
+	}
+	probes, err := LoadServiceProbes(probesPath)
+	if err != nil {
+		return nil, err
+	}
+	if probes.Skipped > 0 {
+		opts.Logger.Warn("Skipped %d match lines in %s that Go's regexp cannot compile", probes.Skipped, probesPath)
+	}
+	intensity, ok := opts.Flags["intensity"].(int)
+	if !ok {
+		intensity = opts.Config.Scanner.ProbeIntensity
+	}
+	if intensity < 0 || intensity > 9 {
+		return nil, errors.NewValidationError(fmt.Sprintf("probe intensity %d outside 0-9", intensity))
+	}
 
-	scanner := &PortScanner{}
-	// Use ScanWithBanners for banner grabbing + service detection in one pass
-	portResults := scanner.ScanWithBanners(ctx, scanHost, ports, opts.Config.Threads, retries, opts.Config.Scanner.PortTimeout, opts.Config.Verbose)
+	deadAfter, ok := opts.Flags["dead-after"].(int)
+	if !ok {
+		deadAfter = opts.Config.Scanner.DeadHostPorts
+	}
 
+	failures := errors.NewTally("TCP connects")
+	scanner := &PortScanner{Progress: opts.ProgressReporter(), Logger: opts.Logger, Telemetry: opts.Telemetry, Replay: opts.Replay, Rate: rate, Probes: probes, Intensity: intensity, DeadAfter: deadAfter, Failures: failures}
+	scanType := "connect"
+	if syn, _ := opts.Flags["syn"].(bool); syn && scanTCP {
+		synScanner, err := openSYNScanner(opts)
+		if err != nil {
+			opts.Logger.Warn("%v; falling back to connect scan", err)
+		} else {
+			defer synScanner.Close()
+			scanner.SYN = synScanner
+			scanType = "syn"
+		}
+	}
+	findings := []registry.Finding{}
+	metadata := map[string]interface{}{
+		"retries":         retries,
+		"scan_host":       scanHost,
+		"probe_intensity": intensity,
+		"service_probes":  len(probes.Probes),
+	}
+	if len(hosts) > 1 {
+		metadata["hosts_scanned"] = len(hosts)
+	}
+	if scanTCP {
+		opts.Logger.Debug("Starting enhanced port scan for %s (%d hosts, %d ports)", scanHost, len(hosts), len(ports))
+		tcpFindings, stats, httpProbed := m.scanTCP(ctx, opts, scanner, hosts, ports, retries, addrs)
+		failures.FlushTo(opts.Errors)
+		findings = append(findings, tcpFindings...)
+		metadata["ports_scanned"] = len(ports)
+		metadata["scan_type"] = scanType
+		metadata["hosts"] = stats
+		down := []string{}
+		for _, s := range stats {
+			if s.Down {
+				down = append(down, s.Host)
+			}
+		}
+		if len(down) > 0 {
+			metadata["hosts_down"] = down
+		}
+		if httpProbed > 0 {
+			metadata["http_probed"] = httpProbed
+		}
+	}
+	if scanUDP {
+		// UDP probes are paced by rate across the whole scan, so hosts
+		// are taken one at a time.
+		multi := len(hosts) > 1
+		openFiltered, filtered := []int{}, []int{}
+		openFilteredAt, filteredAt := []string{}, []string{}
+		closed := 0
+		for _, host := range hosts {
+			if ctx.Err() != nil {
+				break
+			}
+			opts.Logger.Debug("Starting UDP scan for %s (%d ports, %d probes/s)", host, len(udpPorts), rate)
+			results := scanner.ScanUDP(ctx, host, udpPorts, opts.Config.Threads, retries, opts.Config.Scanner.PortTimeout)
+			findings = append(findings, udpFindings(results, host, addrs[host], multi)...)
+			for _, port := range sortedUDPResults(results) {
+				switch results[port].State {
+				case StateOpenFiltered:
+					openFiltered = append(openFiltered, port)
+					openFilteredAt = append(openFilteredAt, net.JoinHostPort(host, strconv.Itoa(port)))
+				case StateFiltered:
+					filtered = append(filtered, port)
+					filteredAt = append(filteredAt, net.JoinHostPort(host, strconv.Itoa(port)))
+				case StateClosed:
+					closed++
+				}
+			}
+		}
+		if ctx.Err() != nil {
+			opts.Errors.Add(errors.NewTimeoutError(fmt.Sprintf("UDP scan of %s stopped before all %d ports were checked", scanHost, len(udpPorts))))
+		}
+		metadata["udp_ports_scanned"] = len(udpPorts)
+		// One host's ports are listed by number, several hosts' as host:port.
+		if multi {
+			metadata["udp_open_filtered"] = openFilteredAt
+			metadata["udp_filtered"] = filteredAt
+		} else {
+			metadata["udp_open_filtered"] = openFiltered
+			metadata["udp_filtered"] = filtered
+		}
+		metadata["udp_closed"] = closed
+		metadata["udp_rate"] = rate
+	}
+
+	// Hosts keep the order they were given in; ports sort within them.
+	hostIndex := make(map[string]int, len(hosts))
+	for i, host := range hosts {
+		hostIndex[host] = i
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		hi, _ := findings[i].Metadata["host"].(string)
+		hj, _ := findings[j].Metadata["host"].(string)
+		if hostIndex[hi] != hostIndex[hj] {
+			return hostIndex[hi] < hostIndex[hj]
+		}
+		pi, _ := findings[i].Metadata["port"].(int)
+		pj, _ := findings[j].Metadata["port"].(int)
+		return pi < pj
+	})
+	metadata["ports_open"] = len(findings)
+
+	return &registry.Result{
+		Module:    m.Name(),
+		Timestamp: time.Now(),
+		Status:    "success",
+		Target:    target,
+		Findings:  findings,
+		Metadata:  metadata,
+	}, nil
+}
+
+// openSYNScanner opens a SYN scanner paced by the "syn-rate" flag. Replay
+// sessions only see connections, so they cannot use one.
+func openSYNScanner(opts registry.Options) (*SYNScanner, error) {
+	if opts.Replay.Mode() != 0 {
+		return nil, fmt.Errorf("%w: replay sessions record connections only", ErrSYNUnavailable)
+	}
+	syn, err := NewSYNScanner()
+	if err != nil {
+		return nil, err
+	}
+	syn.Rate, _ = opts.Flags["syn-rate"].(int)
+	if syn.Rate <= 0 {
+		syn.Rate = opts.Config.Scanner.SYNRate
+	}
+	return syn, nil
+}
+
+// scanTCP scans ports on every host with banner grabbing,
+// probing web ports over HTTP for their server. It returns the open-port
+// findings, each host's statistics and how many ports the HTTP probe
+// identified.
+func (m *PortScanModuleAdapter) scanTCP(ctx context.Context, opts registry.Options, scanner *PortScanner, hosts []string, ports []int, retries int, addrs map[string][]string) ([]registry.Finding, []HostStats, int) {
+	// Use ScanHosts for banner grabbing + service detection in one pass
+	hostResults, stats := scanner.ScanHosts(ctx, hosts, ports, opts.Config.Threads, retries, opts.Config.Scanner.PortTimeout)
+	if ctx.Err() != nil {
+		opts.Errors.Add(errors.NewTimeoutError(fmt.Sprintf("port scan of %d host(s) stopped before all %d ports were checked", len(hosts), len(ports))))
+	}
+
+	findings := []registry.Finding{}
+	httpProbed := 0
+	probeTransport := opts.Telemetry.Transport(opts.Replay.Transport(nil), m.Name())
+	for _, host := range hosts {
+		hostFindings, probed := m.hostFindings(ctx, opts, scanner, probeTransport, host, hostResults[host], addrs[host], len(hosts) > 1)
+		findings = append(findings, hostFindings...)
+		httpProbed += probed
+	}
+	return findings, stats, httpProbed
+}
+
+// hostFindings builds the open-port findings of one host. Values name
+// the host too when several were scanned.
+func (m *PortScanModuleAdapter) hostFindings(ctx context.Context, opts registry.Options, scanner *PortScanner, probeTransport http.RoundTripper, scanHost string, portResults map[int]*PortResult, addrs []string, multi bool) ([]registry.Finding, int) {
 	// For HTTP ports, perform HTTP probing to get better service/version info
 	httpResults := make(map[int]string)
 	for _, port := range sortedPortResults(portResults) {
@@ -65,7 +290,7 @@ func (m *PortScanModuleAdapter) Run(ctx context.Context, opts registry.Options)
 				}
 			}
 			// Probe HTTP to detect web server
-			serverHeader := ProbeHTTPPort(ctx, scanHost, port, opts.Config.Scanner.PortTimeout)
+			serverHeader := ProbeHTTPPort(ctx, probeTransport, scanHost, port, opts.Config.Scanner.PortTimeout)
 			if serverHeader != "" {
 				httpResults[port] = serverHeader
 			}
@@ -82,18 +307,20 @@ func (m *PortScanModuleAdapter) Run(ctx context.Context, opts registry.Options)
 
 		service := pr.Service
 		version := pr.Version
+		product := pr.Product
 		banner := pr.Banner
 
 		// Override with HTTP probe results if available (more accurate for web services)
 		if httpServer, ok := httpResults[port]; ok && httpServer != "" {
-			// Parse version from Server header if present
-			httpService, httpVersion := DetectService(port, httpServer)
-			if httpService != "" {
-				service = httpService
+			// Match the Server header as the GetRequest probe would
+			// have seen it
+			service = "HTTP"
+			product = httpServer
+			version = ""
+			if m := scanner.serviceProbes().MatchBanner([]byte("HTTP/1.1 200 OK\r\nServer: " + httpServer + "\r\n")); m != nil && m.Service == "http" {
+				product, version = m.Product, m.Version
 			}
-			if httpVersion != "" {
-				version = httpVersion
-			} else {
+			if version == "" {
 				// Try to extract version from Server header directly
 				version = extractVersionFromServerHeader(httpServer)
 			}
@@ -106,50 +333,87 @@ func (m *PortScanModuleAdapter) Run(ctx context.Context, opts registry.Options)
 		}
 
 		displayValue := fmt.Sprintf("%d/tcp", port)
-		description := service
-		if version != "" {
-			description = service + " " + version
+		if multi {
+			displayValue = net.JoinHostPort(scanHost, strconv.Itoa(port)) + "/tcp"
 		}
+		metadata := map[string]interface{}{
+			"host":     scanHost,
+			"port":     port,
+			"protocol": "tcp",
+			"state":    StateOpen,
+			"service":  service,
+			"version":  version,
+			"banner":   banner,
+			"ips":      addrs,
+		}
+		addServiceMetadata(metadata, product, pr.Info, pr.CPE, pr.Probe)
 
 		findings = append(findings, registry.Finding{
 			Type:        "open_port",
 			Value:       displayValue,
-			Description: description,
+			Description: describeService(service, product, version),
 			Severity:    "info",
 			Evidence:    []string{banner},
-			Metadata: map[string]interface{}{
-				"port":    port,
-				"service": service,
-				"version": version,
-				"banner":  banner,
-			},
+			Metadata:    metadata,
 		})
 	}
 
-	sort.Slice(findings, func(i, j int) bool {
-		pi, _ := findings[i].Metadata["port"].(int)
-		pj, _ := findings[j].Metadata["port"].(int)
-		return pi < pj
-	})
+	return findings, len(httpResults)
+}
 
-	metadata := map[string]interface{}{
-		"ports_scanned": len(ports),
-		"retries":       retries,
-		"scan_host":     scanHost,
-		"ports_open":    len(findings),
-	}
-	if len(httpResults) > 0 {
-		metadata["http_probed"] = len(httpResults)
+// udpFindings reports the open UDP ports of host; silent and closed
+// ones are summarized in the result metadata instead. Values name the
+// host too when several were scanned.
+func udpFindings(results map[int]*UDPResult, host string, addrs []string, multi bool) []registry.Finding {
+	var findings []registry.Finding
+	for _, port := range sortedUDPResults(results) {
+		r := results[port]
+		if r.State != StateOpen {
+			continue
+		}
+		metadata := map[string]interface{}{
+			"host":     host,
+			"port":     port,
+			"protocol": "udp",
+			"state":    r.State,
+			"probe":    r.Probe,
+			"service":  r.Service,
+			"version":  r.Version,
+			"banner":   r.Banner,
+			"ips":      addrs,
+		}
+		addServiceMetadata(metadata, r.Product, "", r.CPE, "")
+		value := fmt.Sprintf("%d/udp", port)
+		if multi {
+			value = net.JoinHostPort(host, strconv.Itoa(port)) + "/udp"
+		}
+		findings = append(findings, registry.Finding{
+			Type:        "open_port",
+			Value:       value,
+			Description: describeService(r.Service, r.Product, r.Version),
+			Severity:    "info",
+			Evidence:    []string{r.Banner},
+			Metadata:    metadata,
+		})
 	}
+	return findings
+}
 
-	return &registry.Result{
-		Module:    m.Name(),
-		Timestamp: time.Now(),
-		Status:    "success",
-		Target:    target,
-		Findings:  findings,
-		Metadata:  metadata,
-	}, nil
+// addServiceMetadata records what a service probe matched, leaving out
+// fields it did not fill.
+func addServiceMetadata(metadata map[string]interface{}, product, info string, cpe []string, probe string) {
+	if product != "" {
+		metadata["product"] = product
+	}
+	if info != "" {
+		metadata["info"] = info
+	}
+	if len(cpe) > 0 {
+		metadata["cpe"] = cpe
+	}
+	if probe != "" {
+		metadata["probe"] = probe
+	}
 }
 
 // sortedPortResults returns a sorted slice of port numbers from the results map.
@@ -217,9 +481,16 @@ func (m *FuzzerModuleAdapter) Run(ctx context.Context, opts registry.Options) (*
 	fuzzerConfig := DefaultFuzzerConfig()
 	fuzzerConfig.Logger = opts.Logger
 	fuzzerConfig.Debug = debugMode
+	fuzzerConfig.Progress = opts.ProgressReporter()
+	fuzzerConfig.Telemetry = opts.Telemetry
+	fuzzerConfig.Replay = opts.Replay
+	fuzzerConfig.Errors = opts.Errors
 
 	fuzzer := NewFuzzer(fuzzerConfig)
-	found := fuzzer.Scan(ctx, baseURL, wordlist, opts.Config.Threads)
+	found, err := fuzzer.Scan(ctx, baseURL, wordlist, opts.Config.Threads)
+	if err != nil {
+		opts.Errors.Add(err)
+	}
 
 	opts.Logger.Debug("Fuzzing complete for %s: %d findings", baseURL, len(found))
 
@@ -282,7 +553,7 @@ func (m *WAFModuleAdapter) Run(ctx context.Context, opts registry.Options) (*reg
 	}
 
 	opts.Logger.Debug("Starting WAF detection for %s", target)
-	scanner := &WAFScanner{}
+	scanner := &WAFScanner{Transport: opts.Telemetry.Transport(opts.Replay.Transport(nil), m.Name()), Errors: opts.Errors}
 	detection := scanner.DetectDetailed(ctx, target)
 
 	// isWAF helper checks if a technology name is a known WAF.
@@ -329,7 +600,7 @@ func (m *WAFModuleAdapter) Run(ctx context.Context, opts registry.Options) (*reg
 					}
 
 					// Check for Cloudflare indicators.
-					wafDetected, evidence := checkWAFFromURL(ctx, url)
+					wafDetected, evidence := checkWAFFromURL(ctx, scanner.Transport, url)
 					if wafDetected != nil {
 						detection = *wafDetected
 						detection.Evidence = append(detection.Evidence, evidence...)
@@ -354,16 +625,34 @@ func (m *WAFModuleAdapter) Run(ctx context.Context, opts registry.Options) (*reg
 		}
 	}
 
+	// An address inside a CDN's published ranges backs up a matching
+	// detection, or stands in for a missing one at low confidence.
+	cdn, cdnEvidence := cdnMembership(ctx, opts, target)
+	switch {
+	case cdn == "":
+	case detection.Name == "":
+		detection = WAFDetection{Name: cdn, Confidence: "Low", Evidence: cdnEvidence}
+	case sameProvider(detection.Name, cdn):
+		detection.Evidence = append(detection.Evidence, cdnEvidence...)
+		if detection.Confidence == "Medium" {
+			detection.Confidence = "High"
+		}
+	}
+
 	findings := []registry.Finding{}
 	if detection.Name != "" {
+		metadata := map[string]interface{}{
+			"confidence": detection.Confidence,
+		}
+		if cdn != "" {
+			metadata["cdn"] = cdn
+		}
 		findings = append(findings, registry.Finding{
 			Type:     "waf",
 			Value:    detection.Name,
 			Severity: "info",
 			Evidence: detection.Evidence,
-			Metadata: map[string]interface{}{
-				"confidence": detection.Confidence,
-			},
+			Metadata: metadata,
 		})
 	}
 
@@ -376,10 +665,50 @@ func (m *WAFModuleAdapter) Run(ctx context.Context, opts registry.Options) (*reg
 	}, nil
 }
 
+// cdnMembership resolves the target and returns the CDN whose published
+// ranges contain its addresses, with one line of evidence per address.
+func cdnMembership(ctx context.Context, opts registry.Options, target string) (string, []string) {
+	if opts.Intel == nil {
+		return "", nil
+	}
+	host := tcpScanHost(target)
+	addrs := []string{host}
+	if net.ParseIP(host) == nil {
+		var err error
+		if addrs, err = opts.Replay.LookupHost(ctx, host, net.DefaultResolver.LookupHost); err != nil {
+			return "", nil
+		}
+	}
+	provider := ""
+	var evidence []string
+	for _, addr := range addrs {
+		info, ok := opts.Intel.Lookup(addr)
+		if !ok || !info.CDN {
+			continue
+		}
+		if provider == "" {
+			provider = info.Provider
+		}
+		owner := info.Provider
+		if info.Service != "" {
+			owner += " " + info.Service
+		}
+		evidence = append(evidence, fmt.Sprintf("%s is in the published %s IP ranges", addr, owner))
+	}
+	return provider, evidence
+}
+
+// sameProvider reports whether a WAF name and a CDN provider name the
+// same company, e.g. "AWS WAF" and "AWS".
+func sameProvider(waf, cdn string) bool {
+	waf, cdn = strings.ToLower(waf), strings.ToLower(cdn)
+	return strings.Contains(waf, cdn) || strings.Contains(cdn, waf)
+}
+
 // checkWAFFromURL performs a lightweight HTTP HEAD/GET to check for WAF indicators
 // from a known URL. Returns the WAFDetection if found, along with evidence.
-func checkWAFFromURL(ctx context.Context, urlStr string) (*WAFDetection, []string) {
-	client := &http.Client{Timeout: 5 * time.Second}
+func checkWAFFromURL(ctx context.Context, transport http.RoundTripper, urlStr string) (*WAFDetection, []string) {
+	client := &http.Client{Timeout: 5 * time.Second, Transport: transport}
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlStr, nil)
 	if err != nil {
 		return nil, nil
@@ -549,41 +878,21 @@ func checkWAFFromURL(ctx context.Context, urlStr string) (*WAFDetection, []strin
 	return nil, nil
 }
 
-func portsFromOptions(opts registry.Options) ([]int, error) {
-	raw, _ := opts.Flags["ports-list"].(string)
-	if raw == "" {
-		return append([]int(nil), opts.Config.Scanner.DefaultPorts...), nil
+// portsFromOptions expands the "ports-list", "top-ports" and
+// "exclude-ports" flags for protocol, falling back to its configured
+// ports. Unless the "sequential" flag is set or the config disables it,
+// the ports come back in random order.
+func portsFromOptions(opts registry.Options, protocol string, defaults []int) ([]int, error) {
+	spec := PortSpec{}
+	spec.List, _ = opts.Flags["ports-list"].(string)
+	spec.Top, _ = opts.Flags["top-ports"].(int)
+	spec.Exclude, _ = opts.Flags["exclude-ports"].(string)
+	ports, err := spec.Expand(protocol, defaults)
+	if err != nil {
+		return nil, err
 	}
-
-	ports := []int{}
-	for _, part := range strings.Split(raw, ",") {
-		part = strings.TrimSpace(part)
-		if part == "" {
-			continue
-		}
-		if strings.Contains(part, "-") {
-			bounds := strings.SplitN(part, "-", 2)
-			start, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
-			if err != nil {
-				return nil, fmt.Errorf("invalid port range %q", part)
-			}
-			end, err := strconv.Atoi(strings.TrimSpace(bounds[1]))
-			if err != nil {
-				return nil, fmt.Errorf("invalid port range %q", part)
-			}
-			if start > end || start < 1 || end > 65535 {
-				return nil, fmt.Errorf("invalid port range %q", part)
-			}
-			for port := start; port <= end; port++ {
-				ports = append(ports, port)
-			}
-			continue
-		}
-		port, err := strconv.Atoi(part)
-		if err != nil || port < 1 || port > 65535 {
-			return nil, fmt.Errorf("invalid port %q", part)
-		}
-		ports = append(ports, port)
+	if sequential, _ := opts.Flags["sequential"].(bool); !sequential && opts.Config.Scanner.RandomizePorts {
+		ShufflePorts(ports)
 	}
 	return ports, nil
 }
@@ -658,5 +967,48 @@ func tcpScanHost(target string) string {
 	if host, _, err := net.SplitHostPort(target); err == nil {
 		return host
 	}
-	return target
+	return strings.TrimSuffix(strings.TrimPrefix(target, "["), "]")
+}
+
+// maxScanHosts caps how many addresses a target may expand to.
+const maxScanHosts = 1 << 16
+
+// scanHosts expands a target into the hosts to scan: a comma-separated
+// list of names, addresses and CIDR ranges, IPv4 or IPv6, each of which
+// may also be given as a URL or host:port. It returns the hosts joined
+// back up for display, and the hosts themselves.
+func scanHosts(target string) (string, []string, error) {
+	var items, hosts []string
+	seen := map[string]bool{}
+	for _, item := range strings.Split(target, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		item = tcpScanHost(item)
+		items = append(items, item)
+		expanded := []string{item}
+		if prefix, err := netip.ParsePrefix(item); err == nil {
+			prefix = prefix.Masked()
+			if bits := prefix.Addr().BitLen() - prefix.Bits(); bits > 16 {
+				return "", nil, errors.NewValidationError(fmt.Sprintf("range %s is larger than %d addresses", item, maxScanHosts))
+			}
+			expanded = expanded[:0]
+			for addr := prefix.Addr(); prefix.Contains(addr); addr = addr.Next() {
+				expanded = append(expanded, addr.String())
+			}
+		}
+		for _, host := range expanded {
+			if !seen[host] {
+				seen[host] = true
+				hosts = append(hosts, host)
+			}
+		}
+	}
+	if len(hosts) > maxScanHosts {
+		return "", nil, errors.NewValidationError(fmt.Sprintf("%d hosts to scan, more than %d", len(hosts), maxScanHosts))
+	}
+	if len(hosts) == 0 {
+		return "", nil, errors.NewValidationError("no hosts to scan in " + target)
+	}
+	return strings.Join(items, ","), hosts, nil
 }
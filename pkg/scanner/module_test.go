@@ -14,10 +14,15 @@ import (
 	"time"
 
 	"github.com/NASHEDIxCODER/gospyder/internal/config"
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/internal/intel"
 	"github.com/NASHEDIxCODER/gospyder/internal/logger"
 	"github.com/NASHEDIxCODER/gospyder/internal/registry"
+	"github.com/NASHEDIxCODER/gospyder/tests/mocks"
 )
 
+const replayFixture = "../../tests/testdata/replay/example.json"
+
 func testOptions(flags map[string]interface{}) registry.Options {
 	cfg := config.DefaultConfig()
 	cfg.Threads = 10
@@ -27,6 +32,7 @@ func testOptions(flags map[string]interface{}) registry.Options {
 		Config: cfg,
 		Logger: logger.New(false),
 		Flags:  flags,
+		Errors: errors.NewCollector(),
 	}
 }
 
@@ -68,6 +74,26 @@ func TestPortScanModuleProducesOpenPortFinding(t *testing.T) {
 	}
 }
 
+func TestPortScanModuleReplaysRecordedBanners(t *testing.T) {
+	opts := testOptions(map[string]interface{}{
+		"target":     "example.com",
+		"ports-list": "22,80",
+		"retry":      0,
+	})
+	opts.Replay = mocks.ReplaySession(t, replayFixture)
+
+	result, err := NewPortScanModule().Run(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(result.Findings) != 1 || result.Findings[0].Value != "22/tcp" {
+		t.Fatalf("findings = %#v, want only 22/tcp", result.Findings)
+	}
+	if got := result.Findings[0].Description; !strings.HasPrefix(got, "SSH") {
+		t.Fatalf("description = %q, want SSH service from recorded banner", got)
+	}
+}
+
 func TestPortScanModuleAcceptsURLTarget(t *testing.T) {
 	listener, err := net.Listen("tcp", "127.0.0.1:0")
 	if err != nil {
@@ -136,6 +162,46 @@ func TestFuzzerModuleProducesInterestingPathFinding(t *testing.T) {
 	}
 }
 
+func TestFuzzerModuleRecordsMissingWordlist(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	opts := testOptions(map[string]interface{}{
+		"target":   server.URL,
+		"wordlist": filepath.Join(t.TempDir(), "missing.txt"),
+	})
+	result, err := NewFuzzerModule().Run(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	result.AddErrors(opts.Errors.Errors()...)
+	if result.Status != "error" {
+		t.Fatalf("Status = %q, want error", result.Status)
+	}
+	if result.ErrorCounts["io"] != 1 || !strings.Contains(result.Errors[0], "open wordlist") {
+		t.Fatalf("errors = %v (%v), want one io error for the wordlist", result.Errors, result.ErrorCounts)
+	}
+}
+
+func TestHTTPProbeModuleRecordsUnreachableTargets(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	opts := testOptions(map[string]interface{}{"target": "http://" + addr})
+	result, err := NewHTTPProbeModule().Run(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	counts := opts.Errors.CountByType()
+	if counts["network"] != 1 || len(result.Findings) != 0 {
+		t.Fatalf("error counts = %v, findings = %d; want one network error", counts, len(result.Findings))
+	}
+}
+
 func TestWAFModuleProducesEvidence(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Server", "cloudflare")
@@ -163,6 +229,31 @@ func TestWAFModuleProducesEvidence(t *testing.T) {
 	}
 }
 
+func TestWAFModuleUsesCDNRangesAsEvidence(t *testing.T) {
+	ranges := filepath.Join(t.TempDir(), "ips-v4")
+	if err := os.WriteFile(ranges, []byte("127.0.0.0/8\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	db, err := intel.Open(config.IntelConfig{Ranges: []string{"Cloudflare=" + ranges}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Nothing listens on port 1, so only range membership can detect.
+	opts := testOptions(map[string]interface{}{"target": "http://127.0.0.1:1"})
+	opts.Intel = db
+	result, err := NewWAFModule().Run(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(result.Findings) != 1 || result.Findings[0].Value != "Cloudflare" {
+		t.Fatalf("findings = %#v, want Cloudflare from its ranges", result.Findings)
+	}
+	f := result.Findings[0]
+	if f.Metadata["confidence"] != "Low" || f.Metadata["cdn"] != "Cloudflare" || len(f.Evidence) != 1 {
+		t.Fatalf("finding = %#v", f)
+	}
+}
+
 func TestHTTPProbeModuleCollectsResponseDetails(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Server", "nginx")
@@ -195,6 +286,52 @@ func TestHTTPProbeModuleCollectsResponseDetails(t *testing.T) {
 	if _, ok := finding.Metadata["response_time_ms"].(int64); !ok {
 		t.Fatalf("response_time_ms type = %T, want int64", finding.Metadata["response_time_ms"])
 	}
+	if finding.Metadata["ip"] != "127.0.0.1" {
+		t.Fatalf("ip = %#v, want 127.0.0.1", finding.Metadata["ip"])
+	}
+}
+
+func TestHTTPProbeModuleRecordAndReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("<html><head><title>Recorded</title></head></html>"))
+	}))
+	fixture := filepath.Join(t.TempDir(), "http.json")
+
+	recordOpts := testOptions(map[string]interface{}{"target": server.URL})
+	recordOpts.Replay = mocks.RecordSession(t, fixture)
+	recordOpts.HTTPClient = recordOpts.Replay.Client(&http.Client{Timeout: 2 * time.Second})
+	if _, err := NewHTTPProbeModule().Run(context.Background(), recordOpts); err != nil {
+		t.Fatalf("record Run() error = %v", err)
+	}
+	if err := recordOpts.Replay.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	server.Close()
+
+	replayOpts := testOptions(map[string]interface{}{"target": server.URL})
+	replayOpts.Replay = mocks.ReplaySession(t, fixture)
+	replayOpts.HTTPClient = replayOpts.Replay.Client(&http.Client{Timeout: 2 * time.Second})
+	result, err := NewHTTPProbeModule().Run(context.Background(), replayOpts)
+	if err != nil {
+		t.Fatalf("replay Run() error = %v", err)
+	}
+	if len(result.Findings) != 1 || result.Findings[0].Metadata["title"] != "Recorded" {
+		t.Fatalf("findings = %#v, want the recorded response", result.Findings)
+	}
+}
+
+func TestHTTPProbeModuleReplaysFixture(t *testing.T) {
+	opts := testOptions(map[string]interface{}{"target": "https://example.com"})
+	opts.Replay = mocks.ReplaySession(t, replayFixture)
+	opts.HTTPClient = opts.Replay.Client(&http.Client{})
+
+	result, err := NewHTTPProbeModule().Run(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(result.Findings) != 1 || result.Findings[0].Metadata["title"] != "Example Domain" {
+		t.Fatalf("findings = %#v, want the recorded example.com page", result.Findings)
+	}
 }
 
 func TestLiveHostModuleUsesHTTPProbeResults(t *testing.T) {
@@ -257,6 +394,24 @@ func TestTechModuleDetectsRequestedTechnologies(t *testing.T) {
 	}
 }
 
+func TestPortScanModuleFallsBackToConnectScanOnReplay(t *testing.T) {
+	opts := testOptions(map[string]interface{}{
+		"target":     "example.com",
+		"ports-list": "22",
+		"retry":      0,
+		"syn":        true,
+	})
+	opts.Replay = mocks.ReplaySession(t, replayFixture)
+
+	result, err := NewPortScanModule().Run(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Metadata["scan_type"] != "connect" || len(result.Findings) != 1 {
+		t.Fatalf("scan_type = %v, findings = %#v, want connect scan of 22/tcp", result.Metadata["scan_type"], result.Findings)
+	}
+}
+
 func TestPortScanModuleHonorsContextCancellation(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
@@ -281,7 +436,7 @@ func TestPortScanModuleHonorsContextCancellation(t *testing.T) {
 func TestPortsFromOptionsRejectsInvalidPort(t *testing.T) {
 	_, err := portsFromOptions(testOptions(map[string]interface{}{
 		"ports-list": "70000",
-	}))
+	}), "tcp", nil)
 	if err == nil {
 		t.Fatal("portsFromOptions() error = nil, want invalid port error")
 	}
@@ -306,3 +461,69 @@ func TestServiceNameKnownAndUnknown(t *testing.T) {
 		t.Fatalf("serviceName(%d) = %q, want unknown", port, serviceName(port))
 	}
 }
+
+func TestPortScanModuleScansSeveralHosts(t *testing.T) {
+	listen := func(network, address string) int {
+		listener, err := net.Listen(network, address)
+		if err != nil {
+			t.Skipf("Listen(%s) error = %v", address, err)
+		}
+		t.Cleanup(func() { listener.Close() })
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				conn.Write([]byte("SSH-2.0-OpenSSH_9.6\r\n"))
+				conn.Close()
+			}
+		}()
+		return listener.Addr().(*net.TCPAddr).Port
+	}
+	v4 := listen("tcp4", "127.0.0.1:0")
+	v6 := listen("tcp6", "[::1]:0")
+
+	result, err := NewPortScanModule().Run(context.Background(), testOptions(map[string]interface{}{
+		"target":     "127.0.0.1,[::1]",
+		"ports-list": fmt.Sprintf("%d,%d", v4, v6),
+		"retry":      0,
+	}))
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	var values []string
+	for _, f := range result.Findings {
+		values = append(values, f.Value+" "+f.Metadata["host"].(string))
+	}
+	want := []string{
+		fmt.Sprintf("127.0.0.1:%d/tcp 127.0.0.1", v4),
+		fmt.Sprintf("[::1]:%d/tcp ::1", v6),
+	}
+	if fmt.Sprint(values) != fmt.Sprint(want) {
+		t.Errorf("findings = %v, want %v", values, want)
+	}
+	stats, _ := result.Metadata["hosts"].([]HostStats)
+	if len(stats) != 2 || stats[1].Host != "::1" || stats[1].Open == 0 || result.Metadata["hosts_scanned"] != 2 {
+		t.Errorf("hosts = %+v, metadata = %v", stats, result.Metadata)
+	}
+}
+
+func TestScanHostsExpandsRanges(t *testing.T) {
+	display, hosts, err := scanHosts("https://[2001:db8::1]:8443/, 10.0.0.4/30 ,10.0.0.5,2001:db8::/127")
+	if err != nil {
+		t.Fatalf("scanHosts() error = %v", err)
+	}
+	if display != "2001:db8::1,10.0.0.4/30,10.0.0.5,2001:db8::/127" {
+		t.Errorf("display = %s", display)
+	}
+	if got := fmt.Sprint(hosts); got != "[2001:db8::1 10.0.0.4 10.0.0.5 10.0.0.6 10.0.0.7 2001:db8::]" {
+		t.Errorf("hosts = %s, want each address once", got)
+	}
+	for _, target := range []string{"10.0.0.0/8", "2001:db8::/64", " , "} {
+		if _, _, err := scanHosts(target); err == nil {
+			t.Errorf("scanHosts(%q) error = nil", target)
+		}
+	}
+}
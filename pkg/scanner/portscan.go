@@ -3,12 +3,17 @@ package scanner
 import (
 	"bufio"
 	"context"
-	"fmt"
-	"log"
 	"net"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/internal/logger"
+	"github.com/NASHEDIxCODER/gospyder/internal/registry"
+	"github.com/NASHEDIxCODER/gospyder/internal/replay"
+	"github.com/NASHEDIxCODER/gospyder/internal/telemetry"
 )
 
 // PortResult holds detailed information about an open port.
@@ -17,6 +22,11 @@ type PortResult struct {
 	Banner  string
 	Service string
 	Version string
+	Product string
+	Info    string
+	CPE     []string
+	// Probe names the service probe whose reply identified the service.
+	Probe string
 }
 
 // ScanResult holds the results of an advanced port scan.
@@ -25,7 +35,46 @@ type ScanResult struct {
 	Banners   map[int]string
 }
 
-type PortScanner struct{}
+type PortScanner struct {
+	// Progress receives per-port counters; nil disables reporting.
+	Progress registry.Progress
+	// Logger receives per-port debug output; nil discards it.
+	Logger *logger.Logger
+	// Telemetry records open-port metrics and a span per host; nil disables it.
+	Telemetry *telemetry.Telemetry
+	// Replay records or replays connections and banners; nil dials directly.
+	Replay *replay.Session
+	// Rate caps UDP probes per second across the scan; zero is unlimited.
+	Rate int
+	// Probes identifies services on open ports; nil uses the built-in
+	// database.
+	Probes *ServiceProbes
+	// Intensity is the highest probe rarity sent to ports a probe does
+	// not list, from 0 to 9; see DefaultIntensity.
+	Intensity int
+	// DeadAfter is how many unanswered ports make a silent host count as
+	// down; see Engine.
+	DeadAfter int
+	// SYN finds open TCP ports by SYN scan before their banners are
+	// grabbed; nil connect-scans them.
+	SYN *SYNScanner
+	// Failures tallies connects that failed for a local reason; nil
+	// discards them.
+	Failures *errors.Tally
+}
+
+func (ps *PortScanner) progress() registry.Progress {
+	if ps.Progress == nil {
+		return registry.NopProgress
+	}
+	return ps.Progress
+}
+
+// dial opens a TCP connection through the replay session, if any.
+func (ps *PortScanner) dial(ctx context.Context, address string, timeout time.Duration) (net.Conn, error) {
+	dialer := net.Dialer{Timeout: timeout}
+	return ps.Replay.DialContext(ctx, "tcp", address, dialer.DialContext)
+}
 
 func (ps *PortScanner) Scan(ctx context.Context, target string, ports []int, threads int) []int {
 	var openPorts []int
@@ -53,14 +102,10 @@ func (ps *PortScanner) Scan(ctx context.Context, target string, ports []int, thr
 			sem <- struct{}{}
 			defer func() { <-sem }()
 
-			address := fmt.Sprintf("%s:%d", target, p)
+			address := net.JoinHostPort(target, strconv.Itoa(p))
 
 			// Use TCP connection with optimized timeout
-			dialer := net.Dialer{
-				Timeout: 3 * time.Second,
-			}
-
-			conn, err := dialer.DialContext(ctx, "tcp", address)
+			conn, err := ps.dial(ctx, address, 3*time.Second)
 			if err != nil {
 				return
 			}
@@ -77,7 +122,7 @@ func (ps *PortScanner) Scan(ctx context.Context, target string, ports []int, thr
 }
 
 // ScanWithRetry performs port scanning with retry logic
-func (ps *PortScanner) ScanWithRetry(ctx context.Context, target string, ports []int, threads, retries int, verbose bool) []int {
+func (ps *PortScanner) ScanWithRetry(ctx context.Context, target string, ports []int, threads, retries int) []int {
 	var openPorts []int
 	var mu sync.Mutex
 	var wg sync.WaitGroup
@@ -103,26 +148,20 @@ func (ps *PortScanner) ScanWithRetry(ctx context.Context, target string, ports [
 			sem <- struct{}{}
 			defer func() { <-sem }()
 
-			address := fmt.Sprintf("%s:%d", target, p)
+			address := net.JoinHostPort(target, strconv.Itoa(p))
 
 			// Try with retries
 			var conn net.Conn
 			var err error
 
 			for attempt := 0; attempt <= retries; attempt++ {
-				dialer := net.Dialer{
-					Timeout: 3 * time.Second,
-				}
-
-				conn, err = dialer.DialContext(ctx, "tcp", address)
+				conn, err = ps.dial(ctx, address, 3*time.Second)
 				if err == nil {
 					defer conn.Close()
 					mu.Lock()
 					openPorts = append(openPorts, p)
 					mu.Unlock()
-					if verbose {
-						log.Printf("[✓] Port %d open\n", p)
-					}
+					ps.Logger.Debug("Port %d open", p)
 					return
 				}
 
@@ -134,8 +173,8 @@ func (ps *PortScanner) ScanWithRetry(ctx context.Context, target string, ports [
 					case <-time.After(100 * time.Millisecond):
 						// Backoff before retry
 					}
-					if verbose && attempt == 0 {
-						log.Printf("[*] Retrying port %d (attempt %d/%d)\n", p, attempt+2, retries+1)
+					if attempt == 0 {
+						ps.Logger.Debug("Retrying port %d (attempt %d/%d)", p, attempt+2, retries+1)
 					}
 				}
 			}
@@ -148,100 +187,189 @@ func (ps *PortScanner) ScanWithRetry(ctx context.Context, target string, ports [
 
 // ScanWithBanners performs port scanning with banner grabbing in a single pass.
 // This is more efficient than scanning first and grabbing banners in a second pass.
-// Uses the same worker pool pattern as ScanWithRetry.
-func (ps *PortScanner) ScanWithBanners(ctx context.Context, target string, ports []int, threads, retries int, timeout time.Duration, verbose bool) map[int]*PortResult {
-	results := make(map[int]*PortResult)
-	var mu sync.Mutex
-	var wg sync.WaitGroup
-	resultChan := make(chan *PortResult, len(ports))
+func (ps *PortScanner) ScanWithBanners(ctx context.Context, target string, ports []int, threads, retries int, timeout time.Duration) map[int]*PortResult {
+	results, _ := ps.ScanHosts(ctx, []string{target}, ports, threads, retries, timeout)
+	if results[target] == nil {
+		return map[int]*PortResult{}
+	}
+	return results[target]
+}
 
-	if threads > len(ports) {
-		threads = len(ports)
+// ScanHosts scans ports on every host with an Engine of threads workers,
+// or SYN if set, grabbing the banner of each open port and identifying
+// its service. Results are keyed by host and port; the statistics follow
+// hosts.
+func (ps *PortScanner) ScanHosts(ctx context.Context, hosts []string, ports []int, threads, retries int, timeout time.Duration) (map[string]map[int]*PortResult, []HostStats) {
+	ctx, span := ps.Telemetry.StartSpan(ctx, "ports.scan",
+		telemetry.Int("hosts", len(hosts)),
+		telemetry.Int("ports", len(ports)),
+	)
+	defer span.End()
+
+	results := make(map[string]map[int]*PortResult, len(hosts))
+	var mu sync.Mutex
+	open := 0
+	progress := ps.progress()
+	add := func(host string, pr *PortResult) {
+		progress.AddFinding()
+		mu.Lock()
+		defer mu.Unlock()
+		if results[host] == nil {
+			results[host] = map[int]*PortResult{}
+		}
+		results[host][pr.Port] = pr
+		open++
+	}
+	engine := &Engine{
+		Workers:   threads,
+		Retries:   retries,
+		Timeout:   timeout,
+		DeadAfter: ps.DeadAfter,
+		Progress:  progress,
+		Failures:  ps.Failures,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return ps.Replay.DialContext(ctx, network, address, (&net.Dialer{}).DialContext)
+		},
+		Open: func(ctx context.Context, host string, port int, conn net.Conn) {
+			add(host, ps.grab(ctx, net.JoinHostPort(host, strconv.Itoa(port)), port, conn, timeout))
+		},
+	}
+	var stats []HostStats
+	if ps.SYN != nil {
+		stats = ps.scanSYN(ctx, engine, hosts, ports, add)
+	} else {
+		stats = engine.Scan(ctx, hosts, ports)
 	}
 
-	sem := make(chan struct{}, threads)
+	span.SetAttributes(telemetry.Int("open_ports", open))
+	ps.Telemetry.AddOpenPorts("tcp", open)
+	return results, stats
+}
 
-	for _, port := range ports {
-		wg.Add(1)
-		go func(p int) {
-			defer wg.Done()
+// scanSYN finds open ports with ps.SYN and connects to each, with as
+// many workers as engine, to grab its banner. Hosts the SYN scanner
+// cannot reach, such as IPv6 ones without an IPv6 raw socket, are
+// scanned by engine instead.
+func (ps *PortScanner) scanSYN(ctx context.Context, engine *Engine, hosts []string, ports []int, add func(host string, pr *PortResult)) []HostStats {
+	// Every port is counted once here, whichever scan ends up probing it.
+	engine.Progress.AddTotal(int64(len(hosts) * len(ports)))
+	connect := *engine
+	connect.Progress = fixedTotal{engine.Progress}
+	syn := ps.SYN
+	syn.Retries, syn.Timeout, syn.DeadAfter, syn.Progress = engine.Retries, engine.Timeout, engine.DeadAfter, connect.Progress
+
+	var addrs, synHosts, connectHosts []string
+	seen := map[string]bool{}
+	for _, host := range hosts {
+		addr, ok := syn.address(ctx, host)
+		if !ok || seen[addr] {
+			connectHosts = append(connectHosts, host)
+			continue
+		}
+		seen[addr] = true
+		addrs = append(addrs, addr)
+		synHosts = append(synHosts, host)
+	}
 
-			select {
-			case <-ctx.Done():
-				return
-			default:
+	byHost := make(map[string]HostStats, len(hosts))
+	if len(addrs) > 0 {
+		open, stats, err := syn.Scan(ctx, addrs, ports)
+		if err != nil && ctx.Err() == nil {
+			ps.Logger.Warn("SYN scan failed, connect-scanning instead: %v", err)
+			connectHosts, addrs = hosts, nil
+		}
+		// Ports found before a cancellation are kept; grabAt then records
+		// them without banners.
+		sem := make(chan struct{}, max(engine.Workers, 1))
+		var wg sync.WaitGroup
+		for i, addr := range addrs {
+			host := synHosts[i]
+			stats[i].Host = host
+			byHost[host] = stats[i]
+			for _, port := range open[addr] {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					sem <- struct{}{}
+					defer func() { <-sem }()
+					add(host, ps.grabAt(ctx, engine, host, addr, port))
+				}()
 			}
+		}
+		wg.Wait()
+	}
+	if len(connectHosts) > 0 {
+		for _, s := range connect.Scan(ctx, connectHosts, ports) {
+			byHost[s.Host] = s
+		}
+	}
 
-			sem <- struct{}{}
-			defer func() { <-sem }()
-
-			address := fmt.Sprintf("%s:%d", target, p)
-
-			for attempt := 0; attempt <= retries; attempt++ {
-				dialer := net.Dialer{Timeout: timeout}
-				conn, err := dialer.DialContext(ctx, "tcp", address)
-				if err != nil {
-					if attempt < retries {
-						select {
-						case <-ctx.Done():
-							return
-						case <-time.After(100 * time.Millisecond):
-						}
-					}
-					continue
-				}
-
-				// Connection successful - grab banner on the same connection
-				conn.SetDeadline(time.Now().Add(timeout))
-				reader := bufio.NewReaderSize(conn, 4096)
-				bannerBytes := make([]byte, 4096)
-				n, readErr := reader.Read(bannerBytes)
-
-				banner := ""
-				if readErr == nil || n > 0 {
-					banner = sanitizeBanner(string(bannerBytes[:n]))
-				}
-				conn.Close()
+	stats := make([]HostStats, 0, len(hosts))
+	for _, host := range hosts {
+		if s, ok := byHost[host]; ok {
+			stats = append(stats, s)
+		}
+	}
+	return stats
+}
 
-				// Detect service and version from banner
-				service, version := DetectService(p, banner)
+// fixedTotal passes progress on, except for growing the total.
+type fixedTotal struct {
+	registry.Progress
+}
 
-				if verbose {
-					svcInfo := service
-					if version != "" {
-						svcInfo += " " + version
-					}
-					if banner != "" {
-						log.Printf("[✓] Port %d open - %s (banner: %s)\n", p, svcInfo, truncateBanner(banner, 60))
-					} else {
-						log.Printf("[✓] Port %d open - %s\n", p, svcInfo)
-					}
-				}
+func (fixedTotal) AddTotal(int64) {}
 
-				resultChan <- &PortResult{
-					Port:    p,
-					Banner:  banner,
-					Service: service,
-					Version: version,
-				}
-				return
-			}
-		}(port)
+// grabAt connects to a port the SYN scan found open at addr to grab its
+// banner. The port stays open if the connection fails or ctx is done.
+func (ps *PortScanner) grabAt(ctx context.Context, engine *Engine, host, addr string, port int) *PortResult {
+	if ctx.Err() != nil {
+		return &PortResult{Port: port, Service: serviceFromPort(port)}
 	}
+	_, timeout := engine.timeouts()
+	dialCtx, cancel := context.WithTimeout(ctx, timeout)
+	conn, err := engine.Dial(dialCtx, "tcp", net.JoinHostPort(addr, strconv.Itoa(port)))
+	cancel()
+	if err != nil {
+		ps.Logger.Debug("Port %s open, banner connect failed: %v", net.JoinHostPort(host, strconv.Itoa(port)), err)
+		return &PortResult{Port: port, Service: serviceFromPort(port)}
+	}
+	return ps.grab(ctx, net.JoinHostPort(host, strconv.Itoa(port)), port, conn, timeout)
+}
 
-	// Collect results
-	go func() {
-		wg.Wait()
-		close(resultChan)
-	}()
-
-	for pr := range resultChan {
-		mu.Lock()
-		results[pr.Port] = pr
-		mu.Unlock()
+// grab reads the banner of an open port and identifies its service,
+// probing when the banner is missing or not enough.
+func (ps *PortScanner) grab(ctx context.Context, address string, port int, conn net.Conn, timeout time.Duration) *PortResult {
+	conn.SetDeadline(time.Now().Add(timeout))
+	reader := bufio.NewReaderSize(conn, 4096)
+	bannerBytes := make([]byte, 4096)
+	n, readErr := reader.Read(bannerBytes)
+	conn.Close()
+
+	pr := &PortResult{Port: port}
+	if readErr == nil || n > 0 {
+		pr.Banner = sanitizeBanner(string(bannerBytes[:n]))
+	}
+	match, reply := ps.detectService(ctx, address, port, bannerBytes[:n], readErr, timeout)
+	if match != nil {
+		pr.Service, pr.Version = match.DisplayService(), match.Version
+		pr.Product, pr.Info, pr.CPE, pr.Probe = match.Product, match.Info, match.CPE, match.Probe
+		if pr.Banner == "" || match.Probe != "NULL" {
+			pr.Banner = sanitizeBanner(string(reply[:min(len(reply), 4096)]))
+		}
+	} else {
+		pr.Service = serviceFromPort(port)
 	}
 
-	return results
+	if ps.Logger.Enabled(logger.LevelDebug) {
+		svcInfo := describeService(pr.Service, pr.Product, pr.Version)
+		if pr.Banner != "" {
+			ps.Logger.Debug("Port %s open - %s (banner: %s)", address, svcInfo, truncateBanner(pr.Banner, 60))
+		} else {
+			ps.Logger.Debug("Port %s open - %s", address, svcInfo)
+		}
+	}
+	return pr
 }
 
 // truncateBanner truncates a banner string to the given max length for display.
@@ -255,4 +383,4 @@ func truncateBanner(banner string, maxLen int) string {
 		truncated = truncated[:idx]
 	}
 	return truncated + "..."
-}
\ No newline at end of file
+}
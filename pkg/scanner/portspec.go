@@ -0,0 +1,164 @@
+package scanner
+
+import (
+	_ "embed"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+)
+
+//go:embed top-ports.txt
+var builtinTopPorts string
+
+// PortSets are the named port sets a port list may use, e.g. "web,db".
+var PortSets = map[string]string{
+	"web":  "80,81,443,591,593,2082,2083,3000,4443,5000,7001,8000,8008,8080-8090,8443,8888,9000,9090,9443",
+	"db":   "1433,1434,1521,2483,2484,3050,3306,5432,5984,6379,7474,8086,9042,9200,9300,11211,27017-27019,28015,50000",
+	"mail": "25,106,110,143,465,587,993,995,2525,4190",
+	"all":  "1-65535",
+}
+
+// PortSpec selects ports the way nmap's -p, --top-ports and
+// --exclude-ports do. It is shared by the TCP and UDP scans.
+type PortSpec struct {
+	// List holds ports, ranges and named sets, e.g. "22,web,8000-8010".
+	// A range may leave out either end, and "-" alone means every port.
+	List string
+	// Top adds the protocol's Top most frequently open ports.
+	Top int
+	// Exclude holds ports never scanned, in List's syntax.
+	Exclude string
+}
+
+// Expand returns the ports s selects for protocol, "tcp" or "udp", in
+// the order given, without duplicates. With neither List nor Top set it
+// selects defaults.
+func (s PortSpec) Expand(protocol string, defaults []int) ([]int, error) {
+	if s.Top < 0 {
+		return nil, errors.NewValidationError(fmt.Sprintf("top ports %d must not be negative", s.Top))
+	}
+	selected := append([]int(nil), defaults...)
+	if s.List != "" || s.Top > 0 {
+		list, err := ParsePorts(s.List)
+		if err != nil {
+			return nil, err
+		}
+		selected = append(list, TopPorts(protocol, s.Top)...)
+	}
+	excluded, err := ParsePorts(s.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	skip := make(map[int]bool, len(excluded))
+	for _, port := range excluded {
+		skip[port] = true
+	}
+
+	ports := make([]int, 0, len(selected))
+	for _, port := range selected {
+		if !skip[port] {
+			skip[port] = true
+			ports = append(ports, port)
+		}
+	}
+	if len(ports) == 0 && len(selected) > 0 {
+		return nil, errors.NewValidationError(fmt.Sprintf("no %s ports left to scan after excluding %s", protocol, s.Exclude))
+	}
+	return ports, nil
+}
+
+// ParsePorts expands a comma-separated list of ports, ranges and named
+// sets in order. Duplicates are kept.
+func ParsePorts(list string) ([]int, error) {
+	var ports []int
+	for _, item := range strings.Split(list, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item == "" {
+			continue
+		}
+		if set, ok := PortSets[item]; ok {
+			expanded, err := ParsePorts(set)
+			if err != nil {
+				return nil, err
+			}
+			ports = append(ports, expanded...)
+			continue
+		}
+		lo, hi, isRange := strings.Cut(item, "-")
+		start, end := 1, 65535
+		var err error
+		if lo != "" {
+			start, err = strconv.Atoi(strings.TrimSpace(lo))
+		}
+		if err == nil && isRange && hi != "" {
+			end, err = strconv.Atoi(strings.TrimSpace(hi))
+		} else if !isRange {
+			end = start
+		}
+		if err != nil || start < 1 || end > 65535 || start > end {
+			return nil, errors.NewValidationError(fmt.Sprintf("invalid port or range %q", item))
+		}
+		for port := start; port <= end; port++ {
+			ports = append(ports, port)
+		}
+	}
+	return ports, nil
+}
+
+// topPorts parses the embedded list once, by protocol.
+var topPorts = sync.OnceValue(func() map[string][]int {
+	lists := map[string][]int{}
+	for _, line := range strings.Split(builtinTopPorts, "\n") {
+		line = strings.TrimSpace(line)
+		protocol := ""
+		switch {
+		case strings.HasPrefix(line, "T:"):
+			protocol = "tcp"
+		case strings.HasPrefix(line, "U:"):
+			protocol = "udp"
+		default:
+			continue
+		}
+		for _, field := range strings.Split(line[2:], ",") {
+			if port, err := strconv.Atoi(field); err == nil {
+				lists[protocol] = append(lists[protocol], port)
+			}
+		}
+	}
+	return lists
+})
+
+// TopPorts returns the n ports of protocol most frequently found open,
+// most frequent first. Past the end of the embedded list it continues
+// with the remaining ports in port order.
+func TopPorts(protocol string, n int) []int {
+	n = min(n, 65535)
+	if n <= 0 {
+		return nil
+	}
+	listed := topPorts()[protocol]
+	ports := append([]int(nil), listed[:min(n, len(listed))]...)
+	if len(ports) == n {
+		return ports
+	}
+	seen := make(map[int]bool, len(ports))
+	for _, port := range ports {
+		seen[port] = true
+	}
+	for port := 1; len(ports) < n; port++ {
+		if !seen[port] {
+			ports = append(ports, port)
+		}
+	}
+	return ports
+}
+
+// ShufflePorts puts ports in random order, so that consecutive probes do
+// not walk the port space in a pattern intrusion detection looks for.
+func ShufflePorts(ports []int) {
+	rand.Shuffle(len(ports), func(i, j int) { ports[i], ports[j] = ports[j], ports[i] })
+}
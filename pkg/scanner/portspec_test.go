@@ -0,0 +1,76 @@
+package scanner
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestPortSpecExpand(t *testing.T) {
+	defaults := []int{22, 80, 443}
+	for _, tt := range []struct {
+		name     string
+		spec     PortSpec
+		protocol string
+		want     string
+	}{
+		{"defaults", PortSpec{}, "tcp", "[22 80 443]"},
+		{"excluded defaults", PortSpec{Exclude: "80"}, "tcp", "[22 443]"},
+		{"list in order", PortSpec{List: "443, 20-22,80,443"}, "tcp", "[443 20 21 22 80]"},
+		{"named set", PortSpec{List: "mail", Exclude: "106,2525,4190"}, "tcp", "[25 110 143 465 587 993 995]"},
+		{"open-ended ranges", PortSpec{List: "65533-,-2"}, "tcp", "[65533 65534 65535 1 2]"},
+		{"top tcp", PortSpec{Top: 5}, "tcp", "[80 23 443 21 22]"},
+		{"top udp", PortSpec{Top: 3}, "udp", "[631 161 137]"},
+		{"top after list", PortSpec{List: "8080", Top: 2, Exclude: "23"}, "tcp", "[8080 80]"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.spec.Expand(tt.protocol, defaults)
+			if err != nil {
+				t.Fatalf("Expand() error = %v", err)
+			}
+			if fmt.Sprint(got) != tt.want {
+				t.Errorf("Expand() = %v, want %s", got, tt.want)
+			}
+		})
+	}
+
+	all, err := PortSpec{List: "-", Exclude: "web,db"}.Expand("tcp", nil)
+	if err != nil {
+		t.Fatalf("Expand(-) error = %v", err)
+	}
+	if all[0] != 1 || all[len(all)-1] != 65535 || slices.Contains(all, 443) || slices.Contains(all, 5432) {
+		t.Errorf("Expand(-) = %d ports from %d to %d", len(all), all[0], all[len(all)-1])
+	}
+
+	for _, spec := range []PortSpec{
+		{List: "0"},
+		{List: "80-20"},
+		{List: "http"},
+		{Top: -1},
+		{List: "80", Exclude: "web"},
+	} {
+		if _, err := spec.Expand("tcp", defaults); err == nil {
+			t.Errorf("Expand(%+v) error = nil", spec)
+		}
+	}
+}
+
+func TestTopPorts(t *testing.T) {
+	tcp := TopPorts("tcp", 1000)
+	if len(tcp) != 1000 || !slices.Contains(tcp, 8443) || !slices.Contains(tcp, 65389) {
+		t.Fatalf("TopPorts(tcp, 1000) = %d ports", len(tcp))
+	}
+	// Past the embedded list, the unlisted ports follow in port order.
+	more := TopPorts("tcp", 1002)
+	if fmt.Sprint(more[1000:]) != "[2 5]" {
+		t.Errorf("TopPorts(tcp, 1002) tail = %v", more[1000:])
+	}
+	all := TopPorts("udp", 70000)
+	seen := map[int]bool{}
+	for _, port := range all {
+		seen[port] = true
+	}
+	if len(all) != 65535 || len(seen) != 65535 {
+		t.Errorf("TopPorts(udp, 70000) = %d ports, %d distinct", len(all), len(seen))
+	}
+}
@@ -0,0 +1,624 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+)
+
+//go:embed service-probes.txt
+var builtinServiceProbes []byte
+
+// DefaultIntensity is the highest rarity tried on ports a probe does not
+// list, as nmap's --version-intensity 7.
+const DefaultIntensity = 7
+
+// ServiceProbes is a probe database in nmap-service-probes syntax: each
+// probe is a payload to send, and its match lines identify the replies.
+type ServiceProbes struct {
+	Probes []*ServiceProbe
+	// Exclude lists ports never sent probes, e.g. printers that print them.
+	Exclude map[string]portSet
+	// Skipped counts match lines whose pattern Go's regexp cannot compile,
+	// typically back-references and lookarounds.
+	Skipped int
+}
+
+// ServiceProbe is one Probe section.
+type ServiceProbe struct {
+	Protocol  string // "TCP" or "UDP"
+	Name      string
+	Payload   []byte
+	Ports     portSet
+	SSLPorts  portSet
+	Rarity    int
+	TotalWait time.Duration
+	Fallback  []string
+	matches   []*serviceMatcher
+}
+
+// ServiceMatch is a service identified from a reply.
+type ServiceMatch struct {
+	Service    string // nmap's name, e.g. "http" or "ssl/imap"
+	Product    string
+	Version    string
+	Info       string
+	Hostname   string
+	OS         string
+	DeviceType string
+	CPE        []string
+	Probe      string // the probe whose reply matched
+	Soft       bool   // the service is known but not the product
+}
+
+// DisplayService returns the service in this repo's naming, e.g. "SSH"
+// for nmap's "ssh".
+func (m *ServiceMatch) DisplayService() string {
+	return displayService(m.Service)
+}
+
+type serviceMatcher struct {
+	service string
+	pattern *regexp.Regexp
+	soft    bool
+	// fields maps p, v, i, h, o and d to their templates.
+	fields map[byte]string
+	cpe    []string
+}
+
+type portSet map[int]bool
+
+// DefaultServiceProbes returns the built-in probe database.
+func DefaultServiceProbes() *ServiceProbes {
+	db, err := ParseServiceProbes(builtinServiceProbes)
+	if err != nil {
+		panic("scanner: built-in service probes: " + err.Error())
+	}
+	if db.Skipped > 0 {
+		panic(fmt.Sprintf("scanner: built-in service probes: %d patterns do not compile", db.Skipped))
+	}
+	return db
+}
+
+// LoadServiceProbes returns the built-in probes extended by the file at
+// path, which may be nmap's own nmap-service-probes. Probes sharing a
+// protocol and name with a built-in one replace it. An empty path
+// returns the built-ins.
+func LoadServiceProbes(path string) (*ServiceProbes, error) {
+	db := DefaultServiceProbes()
+	if path == "" {
+		return db, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.NewIOError("read service probes", err)
+	}
+	extra, err := ParseServiceProbes(data)
+	if err != nil {
+		return nil, errors.NewValidationError(fmt.Sprintf("%s: %v", path, err))
+	}
+	index := map[string]int{}
+	for i, p := range db.Probes {
+		index[p.Protocol+" "+p.Name] = i
+	}
+	for _, p := range extra.Probes {
+		if i, ok := index[p.Protocol+" "+p.Name]; ok {
+			db.Probes[i] = p
+			continue
+		}
+		db.Probes = append(db.Probes, p)
+	}
+	for proto, ports := range extra.Exclude {
+		if db.Exclude[proto] == nil {
+			db.Exclude[proto] = portSet{}
+		}
+		for port := range ports {
+			db.Exclude[proto][port] = true
+		}
+	}
+	db.Skipped = extra.Skipped
+	return db, nil
+}
+
+// ParseServiceProbes parses nmap-service-probes syntax.
+func ParseServiceProbes(data []byte) (*ServiceProbes, error) {
+	db := &ServiceProbes{Exclude: map[string]portSet{}}
+	var probe *ServiceProbe
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		directive, rest, _ := strings.Cut(line, " ")
+		rest = strings.TrimSpace(rest)
+		if probe == nil && directive != "Probe" && directive != "Exclude" {
+			return nil, fmt.Errorf("line %d: %s before the first Probe", lineNo, directive)
+		}
+		var err error
+		switch directive {
+		case "Exclude":
+			err = parseExclude(db, rest)
+		case "Probe":
+			probe, err = parseProbe(rest)
+			if err == nil {
+				db.Probes = append(db.Probes, probe)
+			}
+		case "match", "softmatch":
+			var m *serviceMatcher
+			m, err = parseMatch(rest, directive == "softmatch")
+			if err == errUnsupportedPattern {
+				db.Skipped++
+				err = nil
+			} else if err == nil {
+				probe.matches = append(probe.matches, m)
+			}
+		case "ports":
+			probe.Ports, err = parsePortSet(rest)
+		case "sslports":
+			probe.SSLPorts, err = parsePortSet(rest)
+		case "rarity":
+			probe.Rarity, err = strconv.Atoi(rest)
+			if err == nil && (probe.Rarity < 1 || probe.Rarity > 9) {
+				err = fmt.Errorf("rarity %d outside 1-9", probe.Rarity)
+			}
+		case "totalwaitms":
+			var ms int
+			ms, err = strconv.Atoi(rest)
+			probe.TotalWait = time.Duration(ms) * time.Millisecond
+		case "fallback":
+			probe.Fallback = strings.Split(rest, ",")
+		case "tcpwrappedms":
+			// Connections closed without data are treated as tcpwrapped
+			// whenever they close; the window is not configurable.
+		default:
+			return nil, fmt.Errorf("line %d: unknown directive %q", lineNo, directive)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNo, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+func parseExclude(db *ServiceProbes, spec string) error {
+	for _, part := range strings.Split(spec, ",") {
+		proto := "TCP"
+		if p, ports, ok := strings.Cut(part, ":"); ok {
+			switch strings.ToUpper(p) {
+			case "T":
+			case "U":
+				proto = "UDP"
+			default:
+				return fmt.Errorf("exclude protocol %q", p)
+			}
+			part = ports
+		}
+		set, err := parsePortSet(part)
+		if err != nil {
+			return err
+		}
+		if db.Exclude[proto] == nil {
+			db.Exclude[proto] = portSet{}
+		}
+		for port := range set {
+			db.Exclude[proto][port] = true
+		}
+	}
+	return nil
+}
+
+// parseProbe parses "TCP GetRequest q|GET / HTTP/1.0\r\n\r\n|".
+func parseProbe(spec string) (*ServiceProbe, error) {
+	fields := strings.SplitN(spec, " ", 3)
+	if len(fields) < 3 || (fields[0] != "TCP" && fields[0] != "UDP") {
+		return nil, fmt.Errorf("malformed Probe %q", spec)
+	}
+	payload := strings.TrimSpace(fields[2])
+	if len(payload) < 3 || payload[0] != 'q' {
+		return nil, fmt.Errorf("probe %s has no q|payload|", fields[1])
+	}
+	end := strings.IndexByte(payload[2:], payload[1])
+	if end < 0 {
+		return nil, fmt.Errorf("probe %s payload is not terminated", fields[1])
+	}
+	return &ServiceProbe{
+		Protocol: fields[0],
+		Name:     fields[1],
+		Payload:  unescapePayload(payload[2 : 2+end]),
+		Rarity:   5,
+	}, nil
+}
+
+// unescapePayload decodes the C-style escapes nmap allows in payloads.
+func unescapePayload(s string) []byte {
+	var out []byte
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			out = append(out, s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case '0':
+			out = append(out, 0)
+		case 'a':
+			out = append(out, '\a')
+		case 'b':
+			out = append(out, '\b')
+		case 'f':
+			out = append(out, '\f')
+		case 'n':
+			out = append(out, '\n')
+		case 'r':
+			out = append(out, '\r')
+		case 't':
+			out = append(out, '\t')
+		case 'v':
+			out = append(out, '\v')
+		case 'x':
+			if i+2 < len(s) {
+				if b, err := strconv.ParseUint(s[i+1:i+3], 16, 8); err == nil {
+					out = append(out, byte(b))
+					i += 2
+					continue
+				}
+			}
+			out = append(out, 'x')
+		default:
+			out = append(out, s[i])
+		}
+	}
+	return out
+}
+
+func parsePortSet(spec string) (portSet, error) {
+	set := portSet{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(lo)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port %q", part)
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(hi); err != nil {
+				return nil, fmt.Errorf("invalid port range %q", part)
+			}
+		}
+		if start < 1 || end > 65535 || start > end {
+			return nil, fmt.Errorf("invalid port range %q", part)
+		}
+		for p := start; p <= end; p++ {
+			set[p] = true
+		}
+	}
+	return set, nil
+}
+
+var errUnsupportedPattern = fmt.Errorf("pattern not supported by Go's regexp")
+
+// parseMatch parses "<service> m|<regex>|[opts] [p/product/ v/version/ ...]".
+func parseMatch(spec string, soft bool) (*serviceMatcher, error) {
+	service, rest, ok := strings.Cut(spec, " ")
+	if !ok || !strings.HasPrefix(rest, "m") || len(rest) < 3 {
+		return nil, fmt.Errorf("malformed match %q", spec)
+	}
+	delim := rest[1]
+	end := strings.IndexByte(rest[2:], delim)
+	if end < 0 {
+		return nil, fmt.Errorf("match %s pattern is not terminated", service)
+	}
+	pattern := rest[2 : 2+end]
+	rest = rest[3+end:]
+
+	flags := ""
+	for len(rest) > 0 && (rest[0] == 'i' || rest[0] == 's') {
+		flags += rest[:1]
+		rest = rest[1:]
+	}
+	if flags != "" {
+		pattern = "(?" + flags + ")" + pattern
+	}
+	re, err := regexp.Compile(latin1(pattern))
+	if err != nil {
+		return nil, errUnsupportedPattern
+	}
+	m := &serviceMatcher{service: service, pattern: re, soft: soft, fields: map[byte]string{}}
+
+	for rest = strings.TrimSpace(rest); rest != ""; rest = strings.TrimSpace(rest) {
+		name := rest[:1]
+		if strings.HasPrefix(rest, "cpe:") {
+			name = "cpe:"
+		}
+		rest = rest[len(name):]
+		if rest == "" {
+			return nil, fmt.Errorf("match %s field %s has no value", service, name)
+		}
+		delim := rest[0]
+		end := strings.IndexByte(rest[1:], delim)
+		if end < 0 {
+			return nil, fmt.Errorf("match %s field %s is not terminated", service, name)
+		}
+		value := rest[1 : 1+end]
+		rest = rest[2+end:]
+		if name == "cpe:" {
+			rest = strings.TrimPrefix(rest, "a")
+			m.cpe = append(m.cpe, "cpe:/"+value)
+			continue
+		}
+		if !strings.Contains("pvihod", name) {
+			return nil, fmt.Errorf("match %s has unknown field %s", service, name)
+		}
+		m.fields[name[0]] = value
+	}
+	return m, nil
+}
+
+// latin1 maps each byte to the rune of the same value. Patterns and
+// replies both go through it, so \xff in a pattern matches a 0xff byte
+// where Go's regexp would otherwise expect UTF-8.
+func latin1(s string) string {
+	runes := make([]rune, len(s))
+	for i := 0; i < len(s); i++ {
+		runes[i] = rune(s[i])
+	}
+	return string(runes)
+}
+
+// fromLatin1 reverses latin1.
+func fromLatin1(s string) string {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		out = append(out, byte(r))
+	}
+	return string(out)
+}
+
+// match returns the service m identifies in reply, or nil.
+func (m *serviceMatcher) match(reply string) *ServiceMatch {
+	groups := m.pattern.FindStringSubmatch(reply)
+	if groups == nil {
+		return nil
+	}
+	for i := range groups {
+		groups[i] = fromLatin1(groups[i])
+	}
+	result := &ServiceMatch{
+		Service:    m.service,
+		Product:    expandTemplate(m.fields['p'], groups),
+		Version:    expandTemplate(m.fields['v'], groups),
+		Info:       expandTemplate(m.fields['i'], groups),
+		Hostname:   expandTemplate(m.fields['h'], groups),
+		OS:         expandTemplate(m.fields['o'], groups),
+		DeviceType: expandTemplate(m.fields['d'], groups),
+		Soft:       m.soft,
+	}
+	for _, cpe := range m.cpe {
+		result.CPE = append(result.CPE, expandTemplate(cpe, groups))
+	}
+	return result
+}
+
+var templateHelper = regexp.MustCompile(`\$(P|SUBST|I)\((\d)(?:,"([^"]*)"(?:,"([^"]*)")?)?\)|\$(\d)`)
+
+// expandTemplate substitutes $1-$9 and nmap's $P(n), $SUBST(n,"a","b")
+// and $I(n,">") helpers with the captured groups.
+func expandTemplate(template string, groups []string) string {
+	if !strings.Contains(template, "$") {
+		return template
+	}
+	out := templateHelper.ReplaceAllStringFunc(template, func(ref string) string {
+		sub := templateHelper.FindStringSubmatch(ref)
+		index := sub[2]
+		if index == "" {
+			index = sub[5]
+		}
+		n, _ := strconv.Atoi(index)
+		if n >= len(groups) {
+			return ""
+		}
+		value := groups[n]
+		switch sub[1] {
+		case "P":
+			return printable(value)
+		case "SUBST":
+			return strings.ReplaceAll(value, sub[3], sub[4])
+		case "I":
+			var v uint64
+			for i := 0; i < len(value) && i < 8; i++ {
+				b := value[i]
+				if sub[3] == "<" {
+					b = value[len(value)-1-i]
+				}
+				v = v<<8 | uint64(b)
+			}
+			return strconv.FormatUint(v, 10)
+		}
+		return printable(value)
+	})
+	return strings.TrimSpace(out)
+}
+
+// printable drops the bytes $P() drops: everything outside printable ASCII.
+func printable(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x20 && s[i] < 0x7f {
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// probe returns the probe of protocol named name, or nil.
+func (db *ServiceProbes) probe(protocol, name string) *ServiceProbe {
+	for _, p := range db.Probes {
+		if p.Protocol == protocol && p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// Match identifies reply, the response to probe, trying probe's own
+// match lines and then its fallbacks. TCP probes fall back to the NULL
+// probe last, as a banner may arrive before the probe is read. A hard
+// match wins over a soft one; nil means nothing matched.
+func (db *ServiceProbes) Match(probe *ServiceProbe, reply []byte) *ServiceMatch {
+	if len(reply) == 0 {
+		return nil
+	}
+	text := latin1(string(reply))
+	candidates := []*ServiceProbe{probe}
+	for _, name := range probe.Fallback {
+		if p := db.probe(probe.Protocol, strings.TrimSpace(name)); p != nil {
+			candidates = append(candidates, p)
+		}
+	}
+	if probe.Protocol == "TCP" && probe.Name != "NULL" {
+		if p := db.probe("TCP", "NULL"); p != nil {
+			candidates = append(candidates, p)
+		}
+	}
+	var soft *ServiceMatch
+	for _, p := range candidates {
+		for _, m := range p.matches {
+			result := m.match(text)
+			if result == nil {
+				continue
+			}
+			result.Probe = probe.Name
+			if !result.Soft {
+				return result
+			}
+			if soft == nil {
+				soft = result
+			}
+		}
+	}
+	return soft
+}
+
+// MatchBanner identifies text a server sent unprompted, or a response
+// captured some other way, against every TCP probe's match lines.
+func (db *ServiceProbes) MatchBanner(banner []byte) *ServiceMatch {
+	var soft *ServiceMatch
+	for _, p := range db.Probes {
+		if p.Protocol != "TCP" {
+			continue
+		}
+		if m := db.Match(p, banner); m != nil {
+			if !m.Soft {
+				return m
+			}
+			if soft == nil {
+				soft = m
+			}
+		}
+	}
+	return soft
+}
+
+// ordered returns the protocol's probes, other than NULL, to try on
+// port: those listing the port first, then the rest up to intensity
+// rarity, rarest last. ssl selects sslports instead of ports.
+func (db *ServiceProbes) ordered(protocol string, port, intensity int, ssl bool) []*ServiceProbe {
+	if db.Exclude[protocol][port] {
+		return nil
+	}
+	var listed, others []*ServiceProbe
+	for _, p := range db.Probes {
+		if p.Protocol != protocol || p.Name == "NULL" {
+			continue
+		}
+		ports := p.Ports
+		if ssl {
+			ports = p.SSLPorts
+		}
+		switch {
+		case ports[port]:
+			listed = append(listed, p)
+		case p.Rarity <= intensity:
+			others = append(others, p)
+		}
+	}
+	sort.SliceStable(listed, func(i, j int) bool { return listed[i].Rarity < listed[j].Rarity })
+	sort.SliceStable(others, func(i, j int) bool { return others[i].Rarity < others[j].Rarity })
+	return append(listed, others...)
+}
+
+// hasMatch reports whether p can hard-match service, which limits the
+// probes worth sending once a softmatch has named the service.
+func (p *ServiceProbe) hasMatch(service string) bool {
+	for _, m := range p.matches {
+		if !m.soft && m.service == service {
+			return true
+		}
+	}
+	return false
+}
+
+// serviceNames maps nmap's service names to the ones this repo reports.
+var serviceNames = map[string]string{
+	"domain":        "DNS",
+	"ftp":           "FTP",
+	"http":          "HTTP",
+	"https":         "HTTPS",
+	"imap":          "IMAP",
+	"imaps":         "IMAPS",
+	"isakmp":        "IKE",
+	"mdns":          "mDNS",
+	"memcached":     "Memcached",
+	"microsoft-ds":  "SMB",
+	"mongodb":       "MongoDB",
+	"ms-sql-s":      "MSSQL",
+	"ms-wbt-server": "RDP",
+	"mysql":         "MySQL",
+	"ntp":           "NTP",
+	"oracle-tns":    "OracleDB",
+	"pop3":          "POP3",
+	"pop3s":         "POP3S",
+	"postgresql":    "PostgreSQL",
+	"redis":         "Redis",
+	"smtp":          "SMTP",
+	"smtps":         "SMTPS",
+	"snmp":          "SNMP",
+	"ssh":           "SSH",
+	"ssl":           "TLS",
+	"ssl/http":      "HTTPS",
+	"ssl/imap":      "IMAPS",
+	"ssl/pop3":      "POP3S",
+	"ssl/smtp":      "SMTPS",
+	"telnet":        "Telnet",
+	"tftp":          "TFTP",
+	"upnp":          "SSDP",
+	"vnc":           "VNC",
+}
+
+func displayService(service string) string {
+	if name, ok := serviceNames[service]; ok {
+		return name
+	}
+	if inner, ok := strings.CutPrefix(service, "ssl/"); ok {
+		return displayService(inner) + "/TLS"
+	}
+	return service
+}
@@ -0,0 +1,185 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseServiceProbesNmapSyntax(t *testing.T) {
+	db, err := ParseServiceProbes([]byte(`# comment
+Exclude T:9100-9102,U:30000
+Probe TCP NULL q||
+match ssh m|^SSH-([\d.]+)-OpenSSH_([\w.]+)\r?\n| p/OpenSSH/ v/$SUBST(2,"_",".")/ i/protocol $1/ cpe:/a:openbsd:openssh:$2/a
+match broken m|^(a)\1|
+softmatch ftp m=^220 .*ftp=i
+
+Probe TCP Binary q|\x01\0\r\nhi|
+rarity 8
+ports 1234,2000-2002
+sslports 443
+totalwaitms 250
+fallback NULL
+match bin m|^\x01\xff(..)(.+)$|s p/$P(2)/ v/$I(1,">")/ o/$I(1,"<")/
+`))
+	if err != nil {
+		t.Fatalf("ParseServiceProbes() error = %v", err)
+	}
+	if db.Skipped != 1 {
+		t.Errorf("Skipped = %d, want the back-reference skipped", db.Skipped)
+	}
+	if !db.Exclude["TCP"][9101] || !db.Exclude["UDP"][30000] || db.Exclude["TCP"][30000] {
+		t.Errorf("Exclude = %v", db.Exclude)
+	}
+
+	bin := db.probe("TCP", "Binary")
+	if !bytes.Equal(bin.Payload, []byte("\x01\x00\r\nhi")) || bin.Rarity != 8 || !bin.Ports[2001] ||
+		!bin.SSLPorts[443] || bin.TotalWait != 250*time.Millisecond || len(bin.Fallback) != 1 {
+		t.Errorf("Binary probe = %+v", bin)
+	}
+	if got := db.ordered("TCP", 2001, 0, false); len(got) != 1 || got[0] != bin {
+		t.Errorf("ordered(2001) = %v, want the listing probe", got)
+	}
+	if got := db.ordered("TCP", 80, 7, false); len(got) != 0 {
+		t.Errorf("ordered(80) = %v, want rarity 8 left out at intensity 7", got)
+	}
+	if got := db.ordered("TCP", 9101, 9, false); got != nil {
+		t.Errorf("ordered(9101) = %v, want excluded port unprobed", got)
+	}
+
+	m := db.Match(bin, []byte("\x01\xff\x01\x02Pro\x00duct"))
+	if m == nil || m.Service != "bin" || m.Product != "Product" || m.Version != "258" || m.OS != "513" {
+		t.Errorf("Match(binary) = %+v", m)
+	}
+	// The fallback gives the banner-based matches a chance.
+	m = db.Match(bin, []byte("SSH-2.0-OpenSSH_9_6\r\n"))
+	if m == nil || m.Version != "9.6" || m.Info != "protocol 2.0" || m.Probe != "Binary" ||
+		len(m.CPE) != 1 || m.CPE[0] != "cpe:/a:openbsd:openssh:9_6" {
+		t.Errorf("Match(fallback) = %+v", m)
+	}
+	if m := db.Match(bin, []byte("220 Welcome to FTP")); m == nil || !m.Soft || m.DisplayService() != "FTP" {
+		t.Errorf("Match(softmatch) = %+v", m)
+	}
+
+	for _, bad := range []string{
+		"match x m|a|\n",
+		"Probe TCP X q|unterminated\n",
+		"Probe TCP X q||\nrarity 10\n",
+		"Probe TCP X q||\nmatch x m|a| z/what/\n",
+	} {
+		if _, err := ParseServiceProbes([]byte(bad)); err == nil {
+			t.Errorf("ParseServiceProbes(%q) error = nil", bad)
+		}
+	}
+}
+
+func TestDefaultServiceProbesIdentifyBanners(t *testing.T) {
+	tests := []struct {
+		banner, service, version string
+	}{
+		{"SSH-2.0-OpenSSH_9.6p1 Ubuntu-3ubuntu13\r\n", "SSH", "9.6p1"},
+		{"220 (vsFTPd 3.0.5)\r\n", "FTP", "3.0.5"},
+		{"220 mail.example.com ESMTP Exim 4.97 Mon, 01 Jan 2024\r\n", "SMTP", "4.97"},
+		{"J\x00\x00\x00\x0a8.0.36\x00\x08\x00\x00\x00", "MySQL", "8.0.36"},
+		{"HTTP/1.1 200 OK\r\nDate: now\r\nServer: nginx/1.26.0\r\n\r\n", "HTTP", "1.26.0"},
+		{"$3000\r\n# Server\r\nredis_version:7.2.4\r\nredis_git_sha1:0\r\n", "Redis", "7.2.4"},
+	}
+	for _, tt := range tests {
+		service, version := DetectService(0, tt.banner)
+		if service != tt.service || version != tt.version {
+			t.Errorf("DetectService(%q) = %s %q, want %s %q", tt.banner, service, version, tt.service, tt.version)
+		}
+	}
+	if service, _ := DetectService(22, "garbage"); service != "SSH" {
+		t.Errorf("DetectService(22, garbage) = %s, want the port's service", service)
+	}
+}
+
+// probeServer answers requests to whichever reply's prefix they start
+// with, and closes connections sending anything else.
+func probeServer(t *testing.T, replies map[string]string) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				buf := make([]byte, 1024)
+				conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+				n, _ := conn.Read(buf)
+				for prefix, reply := range replies {
+					if strings.HasPrefix(string(buf[:n]), prefix) {
+						conn.Write([]byte(reply))
+						time.Sleep(100 * time.Millisecond)
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestPortScanModuleProbesSilentServices(t *testing.T) {
+	redis := probeServer(t, map[string]string{
+		"*1\r\n$4\r\ninfo\r\n": "$2000\r\n# Server\r\nredis_version:7.2.4\r\nredis_mode:standalone\r\n",
+	})
+	web := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "Apache/2.4.58 (Ubuntu)")
+	}))
+	defer web.Close()
+	secure := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "nginx/1.26.0")
+	}))
+	defer secure.Close()
+	webPort := strconv.Itoa(web.Listener.Addr().(*net.TCPAddr).Port)
+	securePort := strconv.Itoa(secure.Listener.Addr().(*net.TCPAddr).Port)
+
+	opts := testOptions(map[string]interface{}{
+		"target":     "127.0.0.1",
+		"ports-list": strconv.Itoa(redis) + "," + webPort + "," + securePort,
+		"retry":      0,
+		"intensity":  9,
+	})
+	opts.Config.Scanner.PortTimeout = 500 * time.Millisecond
+	result, err := NewPortScanModule().Run(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	want := map[string]string{
+		strconv.Itoa(redis) + "/tcp": "Redis Redis key-value store 7.2.4",
+		webPort + "/tcp":             "HTTP Apache httpd 2.4.58",
+		securePort + "/tcp":          "HTTPS nginx 1.26.0",
+	}
+	if len(result.Findings) != len(want) {
+		t.Fatalf("findings = %#v, want %d", result.Findings, len(want))
+	}
+	for _, f := range result.Findings {
+		if f.Description != want[f.Value] {
+			t.Errorf("%s description = %q, want %q (%v)", f.Value, f.Description, want[f.Value], f.Metadata)
+		}
+	}
+	for _, f := range result.Findings {
+		if f.Value == webPort+"/tcp" && (f.Metadata["probe"] != "GetRequest" || f.Metadata["info"] != "Ubuntu") {
+			t.Errorf("web metadata = %v, want GetRequest match with info", f.Metadata)
+		}
+	}
+	if result.Metadata["probe_intensity"] != 9 {
+		t.Errorf("probe_intensity = %v", result.Metadata["probe_intensity"])
+	}
+}
@@ -0,0 +1,197 @@
+package scanner
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/replay"
+)
+
+// maxReply caps the bytes read from one probe's reply.
+const maxReply = 16 << 10
+
+var defaultServiceProbes = sync.OnceValue(DefaultServiceProbes)
+
+func (ps *PortScanner) serviceProbes() *ServiceProbes {
+	if ps.Probes == nil {
+		return defaultServiceProbes()
+	}
+	return ps.Probes
+}
+
+type probeDialer func(ctx context.Context) (net.Conn, error)
+
+// detectService identifies the service on an open TCP port from the
+// banner it sent unprompted, sending probes when that is not enough. It
+// returns the match, if any, and the reply that produced it.
+//
+// As in nmap, a connection closed before any data is tcpwrapped and not
+// probed further, and a port that answers the TLS probe is probed again
+// through a TLS tunnel to name the service inside.
+func (ps *PortScanner) detectService(ctx context.Context, address string, port int, banner []byte, bannerErr error, timeout time.Duration) (*ServiceMatch, []byte) {
+	// A replayed connection ends with EOF wherever the recording
+	// timed out, so only live connections can be tcpwrapped.
+	if len(banner) == 0 && errors.Is(bannerErr, io.EOF) && ps.Replay.Mode() != replay.ModeReplay {
+		return nil, nil
+	}
+	db := ps.serviceProbes()
+	dial := func(ctx context.Context) (net.Conn, error) {
+		return ps.dial(ctx, address, timeout)
+	}
+	match, reply := ps.identify(ctx, db, dial, port, banner, false, timeout)
+	if match == nil || match.Soft || match.Service != "ssl" || ctx.Err() != nil {
+		return match, reply
+	}
+
+	config := &tls.Config{InsecureSkipVerify: true, MinVersion: tls.VersionTLS10}
+	if host := hostOf(address); net.ParseIP(host) == nil {
+		config.ServerName = host
+	}
+	tlsDial := func(ctx context.Context) (net.Conn, error) {
+		conn, err := dial(ctx)
+		if err != nil {
+			return nil, err
+		}
+		conn.SetDeadline(time.Now().Add(timeout))
+		tlsConn := tls.Client(conn, config)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return tlsConn, nil
+	}
+	conn, err := tlsDial(ctx)
+	if err != nil {
+		return match, reply
+	}
+	tlsBanner := exchange(conn, nil, timeout, nil)
+	conn.Close()
+	if inner, innerReply := ps.identify(ctx, db, tlsDial, port, tlsBanner, true, timeout); inner != nil {
+		inner.Service = "ssl/" + inner.Service
+		return inner, innerReply
+	}
+	return match, reply
+}
+
+// identify matches banner against the NULL probe, then sends each probe
+// worth trying on port over a new connection until one hard-matches.
+// Once a softmatch names the service, only probes that can identify it
+// are sent.
+func (ps *PortScanner) identify(ctx context.Context, db *ServiceProbes, dial probeDialer, port int, banner []byte, ssl bool, timeout time.Duration) (*ServiceMatch, []byte) {
+	var soft *ServiceMatch
+	var softReply []byte
+	if null := db.probe("TCP", "NULL"); null != nil {
+		if m := db.Match(null, banner); m != nil {
+			if !m.Soft {
+				return m, banner
+			}
+			soft, softReply = m, banner
+		}
+	}
+
+	for _, probe := range db.ordered("TCP", port, ps.Intensity, ssl) {
+		if soft != nil && !probe.hasMatch(soft.Service) {
+			continue
+		}
+		conn, err := dial(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		wait := timeout
+		if probe.TotalWait > 0 && probe.TotalWait < wait {
+			wait = probe.TotalWait
+		}
+		reply := exchange(conn, probe.Payload, wait, func(reply []byte) bool {
+			m := db.Match(probe, reply)
+			return m != nil && !m.Soft
+		})
+		conn.Close()
+
+		if m := db.Match(probe, reply); m != nil {
+			if !m.Soft {
+				return m, reply
+			}
+			if soft == nil {
+				soft, softReply = m, reply
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return soft, softReply
+}
+
+// exchange writes payload, if any, and reads the reply until wait
+// passes, the server closes, or done accepts what has arrived.
+func exchange(conn net.Conn, payload []byte, wait time.Duration, done func([]byte) bool) []byte {
+	conn.SetDeadline(time.Now().Add(wait))
+	if len(payload) > 0 {
+		if _, err := conn.Write(payload); err != nil {
+			return nil
+		}
+	}
+	reply := make([]byte, 0, 4096)
+	buf := make([]byte, 4096)
+	for len(reply) < maxReply {
+		n, err := conn.Read(buf)
+		reply = append(reply, buf[:n]...)
+		if err != nil || (n > 0 && done != nil && done(reply)) {
+			break
+		}
+	}
+	return reply
+}
+
+// matchUDP identifies a reply to the probe sent to a UDP port against
+// the database's UDP probes for that port.
+func (db *ServiceProbes) matchUDP(port int, reply []byte) *ServiceMatch {
+	var soft *ServiceMatch
+	for _, probe := range db.ordered("UDP", port, 0, false) {
+		if m := db.Match(probe, reply); m != nil {
+			if !m.Soft {
+				return m
+			}
+			if soft == nil {
+				soft = m
+			}
+		}
+	}
+	return soft
+}
+
+// udpProbe returns the payload to send to a UDP port: the built-in one
+// for the protocol, else the database's probe listing the port, else an
+// empty datagram.
+func (ps *PortScanner) udpProbe(port int) *udpProbe {
+	if probe, ok := udpProbes[port]; ok {
+		return probe
+	}
+	if probes := ps.serviceProbes().ordered("UDP", port, 0, false); len(probes) > 0 {
+		payload := probes[0].Payload
+		return &udpProbe{name: probes[0].Name, payload: func() []byte { return payload }}
+	}
+	return emptyProbe
+}
+
+// describeService joins service, product and version for display,
+// leaving out a product that only repeats the service name.
+func describeService(service, product, version string) string {
+	description := service
+	if product != "" && !strings.EqualFold(product, service) {
+		description += " " + product
+	}
+	if version != "" {
+		description += " " + version
+	}
+	return description
+}
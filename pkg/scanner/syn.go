@@ -0,0 +1,475 @@
+package scanner
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/maphash"
+	"math/rand/v2"
+	"net"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/registry"
+)
+
+// ErrSYNUnavailable means raw sockets cannot be opened: the platform is
+// not Linux, or the process lacks CAP_NET_RAW.
+var ErrSYNUnavailable = errors.New("SYN scan unavailable")
+
+const (
+	tcpSYN = 0x02
+	tcpRST = 0x04
+	tcpACK = 0x10
+)
+
+// SYNScanner finds open TCP ports by sending SYN packets on a raw socket
+// and reading the SYN-ACK or RST answers, without completing handshakes.
+// The kernel, having no socket for the reply, resets half-open
+// connections itself.
+//
+// Probes go out port by port across hosts, paced to Rate, with up to
+// Outstanding awaiting an answer. Each host's timeout, retries and
+// liveness are tracked as in Engine.
+type SYNScanner struct {
+	// Rate caps packets per second, retransmissions included; zero is
+	// unlimited.
+	Rate int
+	// Outstanding caps probes awaiting an answer; zero means 1000.
+	Outstanding int
+	// Retries, Timeout, MinTimeout and DeadAfter are as for Engine.
+	Retries    int
+	Timeout    time.Duration
+	MinTimeout time.Duration
+	DeadAfter  int
+	// Progress counts one probe per host and port; nil disables it.
+	Progress registry.Progress
+
+	conn4, conn6 net.PacketConn
+	// source returns the local address packets to dst leave from.
+	source func(dst netip.Addr) (netip.Addr, error)
+	port   uint16
+	seed   maphash.Seed
+}
+
+// NewSYNScanner opens the raw sockets a SYN scan needs. Errors wrap
+// ErrSYNUnavailable. IPv6 is left out when only IPv4 is available.
+func NewSYNScanner() (*SYNScanner, error) {
+	conn4, err := listenRaw("ip4:tcp")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSYNUnavailable, err)
+	}
+	conn6, _ := listenRaw("ip6:tcp")
+	return newSYNScanner(conn4, conn6, routeSource), nil
+}
+
+func newSYNScanner(conn4, conn6 net.PacketConn, source func(netip.Addr) (netip.Addr, error)) *SYNScanner {
+	return &SYNScanner{
+		conn4:  conn4,
+		conn6:  conn6,
+		source: source,
+		port:   uint16(40000 + rand.IntN(20000)),
+		seed:   maphash.MakeSeed(),
+	}
+}
+
+// Close closes the raw sockets.
+func (s *SYNScanner) Close() error {
+	if s.conn6 != nil {
+		s.conn6.Close()
+	}
+	return s.conn4.Close()
+}
+
+// routeSource finds the local address the kernel routes dst from, by
+// connecting a UDP socket, which sends nothing.
+func routeSource(dst netip.Addr) (netip.Addr, error) {
+	conn, err := net.DialUDP("udp", nil, net.UDPAddrFromAddrPort(netip.AddrPortFrom(dst, 9)))
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).AddrPort().Addr().Unmap(), nil
+}
+
+// address returns the address to probe host at: host itself if it is an
+// IP address, or its first resolved address of a family a raw socket is
+// open for.
+func (s *SYNScanner) address(ctx context.Context, host string) (string, bool) {
+	var addrs []netip.Addr
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addrs = []netip.Addr{addr}
+	} else if addrs, err = net.DefaultResolver.LookupNetIP(ctx, "ip", host); err != nil {
+		return "", false
+	}
+	for _, addr := range addrs {
+		addr = addr.Unmap()
+		if addr.Is4() || s.conn6 != nil {
+			return addr.String(), true
+		}
+	}
+	return "", false
+}
+
+// synKey identifies a probe by where it went.
+type synKey struct {
+	addr netip.Addr
+	port uint16
+}
+
+type synProbe struct {
+	host     *hostState
+	src      netip.Addr
+	attempt  int
+	sent     time.Time
+	deadline time.Time
+}
+
+// synScan is the state of one Scan call.
+type synScan struct {
+	*SYNScanner
+	ctx      context.Context
+	pace     *pacer
+	progress registry.Progress
+	lo, hi   time.Duration
+
+	mu      sync.Mutex
+	probes  map[synKey]*synProbe
+	open    map[string][]int
+	slots   chan struct{}
+	pending sync.WaitGroup
+}
+
+// Scan probes ports on every host, which must be IP addresses, and
+// returns each host's open ports and statistics, in the order given.
+func (s *SYNScanner) Scan(ctx context.Context, hosts []string, ports []int) (map[string][]int, []HostStats, error) {
+	addrs := make([]netip.Addr, len(hosts))
+	for i, host := range hosts {
+		addr, err := netip.ParseAddr(host)
+		if err != nil {
+			return nil, nil, fmt.Errorf("SYN scan needs addresses, got %q", host)
+		}
+		addrs[i] = addr.Unmap()
+		if addrs[i].Is6() && s.conn6 == nil {
+			return nil, nil, fmt.Errorf("%w: no IPv6 raw socket for %s", ErrSYNUnavailable, host)
+		}
+	}
+	outstanding := s.Outstanding
+	if outstanding <= 0 {
+		outstanding = 1000
+	}
+	scan := &synScan{
+		SYNScanner: s,
+		ctx:        ctx,
+		pace:       newPacer(s.Rate),
+		progress:   s.Progress,
+		probes:     map[synKey]*synProbe{},
+		open:       map[string][]int{},
+		slots:      make(chan struct{}, outstanding),
+	}
+	if scan.progress == nil {
+		scan.progress = registry.NopProgress
+	}
+	scan.lo, scan.hi = (&Engine{Timeout: s.Timeout, MinTimeout: s.MinTimeout}).timeouts()
+	scan.progress.AddTotal(int64(len(hosts) * len(ports)))
+
+	states := make([]*hostState, len(hosts))
+	sources := make([]netip.Addr, len(hosts))
+	for i, host := range hosts {
+		states[i] = &hostState{stats: HostStats{Host: host}}
+		src, err := s.source(addrs[i])
+		if err != nil {
+			// No route: nothing will answer.
+			states[i].stats.Down = true
+		}
+		sources[i] = src
+	}
+
+	stop := make(chan struct{})
+	var readers sync.WaitGroup
+	for _, conn := range []net.PacketConn{s.conn4, s.conn6} {
+		if conn != nil {
+			readers.Add(1)
+			go func() {
+				defer readers.Done()
+				scan.receive(conn, stop)
+			}()
+		}
+	}
+	readers.Add(1)
+	go func() {
+		defer readers.Done()
+		scan.retransmit(stop)
+	}()
+
+feed:
+	for _, port := range discoveryFirst(ports) {
+		for i, h := range states {
+			if h.skip() {
+				scan.progress.Increment(1)
+				continue
+			}
+			select {
+			case scan.slots <- struct{}{}:
+			case <-ctx.Done():
+				break feed
+			}
+			if !scan.start(h, addrs[i], sources[i], uint16(port)) {
+				break feed
+			}
+		}
+	}
+
+	// Wait for every probe to be answered or given up on; cancellation
+	// gives them all up.
+	finished := make(chan struct{})
+	go func() {
+		scan.pending.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-ctx.Done():
+	}
+	close(stop)
+	readers.Wait()
+	scan.mu.Lock()
+	for key := range scan.probes {
+		// Cancelled: unanswered probes say nothing about their ports.
+		delete(scan.probes, key)
+		scan.pending.Done()
+	}
+	scan.mu.Unlock()
+
+	stats := make([]HostStats, len(states))
+	for i, h := range states {
+		stats[i] = h.stats
+		stats[i].RTTMs = float64(h.srtt.Microseconds()) / 1000
+		stats[i].TimeoutMs = float64(h.timeout(scan.lo, scan.hi).Microseconds()) / 1000
+		stats[i].Retries = h.retries(s.Retries)
+	}
+	scan.mu.Lock()
+	defer scan.mu.Unlock()
+	return scan.open, stats, ctx.Err()
+}
+
+// start sends a port's first probe, holding a slot. It reports false
+// when the scan was cancelled.
+func (sc *synScan) start(h *hostState, addr, src netip.Addr, port uint16) bool {
+	key := synKey{addr, port}
+	sc.mu.Lock()
+	if _, dup := sc.probes[key]; dup {
+		sc.mu.Unlock()
+		<-sc.slots
+		sc.progress.Increment(1)
+		return true
+	}
+	p := &synProbe{host: h, src: src}
+	sc.probes[key] = p
+	sc.pending.Add(1)
+	sc.mu.Unlock()
+	return sc.send(key, p)
+}
+
+// send paces and writes a probe's next attempt.
+func (sc *synScan) send(key synKey, p *synProbe) bool {
+	if err := sc.pace.wait(sc.ctx); err != nil {
+		sc.finish(key, p, false)
+		return false
+	}
+	packet := synPacket(p.src, key.addr, sc.port, key.port, sc.cookie(key))
+	conn := sc.conn4
+	if key.addr.Is6() {
+		conn = sc.conn6
+	}
+	sc.mu.Lock()
+	p.sent = time.Now()
+	p.deadline = p.sent.Add(min(p.host.timeout(sc.lo, sc.hi)<<p.attempt, sc.hi))
+	sc.mu.Unlock()
+	if _, err := conn.WriteTo(packet, &net.IPAddr{IP: key.addr.AsSlice()}); err != nil && unreachable(err) {
+		sc.finish(key, p, false)
+	}
+	return true
+}
+
+// finish resolves a probe once, as answered or not, freeing its slot.
+func (sc *synScan) finish(key synKey, p *synProbe, answered bool) bool {
+	sc.mu.Lock()
+	if sc.probes[key] != p {
+		sc.mu.Unlock()
+		return false
+	}
+	delete(sc.probes, key)
+	attempt := p.attempt
+	sc.mu.Unlock()
+	if !answered {
+		p.host.silent(attempt, sc.DeadAfter)
+	}
+	<-sc.slots
+	sc.progress.Increment(1)
+	sc.pending.Done()
+	return true
+}
+
+// receive matches SYN-ACK and RST answers to probes until stop closes.
+func (sc *synScan) receive(conn net.PacketConn, stop <-chan struct{}) {
+	buf := make([]byte, 1500)
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		conn.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			continue
+		}
+		ipAddr, ok := from.(*net.IPAddr)
+		if !ok {
+			continue
+		}
+		addr, ok := netip.AddrFromSlice(ipAddr.IP)
+		if !ok {
+			continue
+		}
+		reply, ok := parseTCP(buf[:n])
+		if !ok || reply.dstPort != sc.port || reply.flags&(tcpSYN|tcpRST) == 0 || reply.flags&tcpACK == 0 {
+			continue
+		}
+		key := synKey{addr.Unmap(), reply.srcPort}
+		if reply.ack != sc.cookie(key)+1 {
+			continue
+		}
+		sc.mu.Lock()
+		p := sc.probes[key]
+		var rtt time.Duration
+		var attempt int
+		if p != nil {
+			rtt, attempt = time.Since(p.sent), p.attempt
+		}
+		sc.mu.Unlock()
+		if p == nil || !sc.finish(key, p, true) {
+			continue
+		}
+		open := reply.flags&tcpSYN != 0
+		p.host.observe(rtt, attempt, open)
+		if open {
+			sc.mu.Lock()
+			sc.open[p.host.stats.Host] = append(sc.open[p.host.stats.Host], int(key.port))
+			sc.mu.Unlock()
+		}
+	}
+}
+
+// retransmit resends probes past their deadline, or gives up on them
+// once their host's retries are spent, until stop closes.
+func (sc *synScan) retransmit(stop <-chan struct{}) {
+	ticker := time.NewTicker(5 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		now := time.Now()
+		type expiry struct {
+			key synKey
+			p   *synProbe
+		}
+		var expired []expiry
+		sc.mu.Lock()
+		for key, p := range sc.probes {
+			if !p.sent.IsZero() && now.After(p.deadline) {
+				expired = append(expired, expiry{key, p})
+			}
+		}
+		sc.mu.Unlock()
+		for _, e := range expired {
+			if sc.ctx.Err() != nil || e.p.attempt >= e.p.host.retries(sc.Retries) || e.p.host.skip() {
+				sc.finish(e.key, e.p, false)
+				continue
+			}
+			sc.mu.Lock()
+			e.p.attempt++
+			sc.mu.Unlock()
+			sc.send(e.key, e.p)
+		}
+	}
+}
+
+// cookie is the sequence number of the probe to key, so that answers
+// are recognized without remembering what was sent.
+func (s *SYNScanner) cookie(key synKey) uint32 {
+	var b [18]byte
+	addr := key.addr.As16()
+	copy(b[:], addr[:])
+	binary.BigEndian.PutUint16(b[16:], key.port)
+	return uint32(maphash.Bytes(s.seed, b[:]))
+}
+
+// synPacket builds a TCP SYN segment with an MSS option, checksummed for
+// the src and dst addresses.
+func synPacket(src, dst netip.Addr, srcPort, dstPort uint16, seq uint32) []byte {
+	b := make([]byte, 24)
+	binary.BigEndian.PutUint16(b[0:], srcPort)
+	binary.BigEndian.PutUint16(b[2:], dstPort)
+	binary.BigEndian.PutUint32(b[4:], seq)
+	b[12] = 6 << 4 // data offset, in 32-bit words
+	b[13] = tcpSYN
+	binary.BigEndian.PutUint16(b[14:], 1024) // window
+	copy(b[20:], []byte{2, 4, 0x05, 0xb4})   // MSS 1460
+	binary.BigEndian.PutUint16(b[16:], tcpChecksum(src, dst, b))
+	return b
+}
+
+// tcpChecksum is the Internet checksum of segment and its pseudo-header.
+func tcpChecksum(src, dst netip.Addr, segment []byte) uint16 {
+	var pseudo []byte
+	pseudo = append(pseudo, src.AsSlice()...)
+	pseudo = append(pseudo, dst.AsSlice()...)
+	if src.Is4() {
+		pseudo = append(pseudo, 0, 6)
+		pseudo = binary.BigEndian.AppendUint16(pseudo, uint16(len(segment)))
+	} else {
+		pseudo = binary.BigEndian.AppendUint32(pseudo, uint32(len(segment)))
+		pseudo = append(pseudo, 0, 0, 0, 6)
+	}
+	var sum uint32
+	for _, data := range [][]byte{pseudo, segment} {
+		for i := 0; i+1 < len(data); i += 2 {
+			sum += uint32(binary.BigEndian.Uint16(data[i:]))
+		}
+		if len(data)%2 == 1 {
+			sum += uint32(data[len(data)-1]) << 8
+		}
+	}
+	for sum > 0xffff {
+		sum = sum>>16 + sum&0xffff
+	}
+	return ^uint16(sum)
+}
+
+type tcpHeader struct {
+	srcPort, dstPort uint16
+	seq, ack         uint32
+	flags            byte
+}
+
+// parseTCP reads the header of a TCP segment, as raw sockets deliver it
+// without the IP header.
+func parseTCP(b []byte) (tcpHeader, bool) {
+	if len(b) < 20 {
+		return tcpHeader{}, false
+	}
+	return tcpHeader{
+		srcPort: binary.BigEndian.Uint16(b[0:]),
+		dstPort: binary.BigEndian.Uint16(b[2:]),
+		seq:     binary.BigEndian.Uint32(b[4:]),
+		ack:     binary.BigEndian.Uint32(b[8:]),
+		flags:   b[13],
+	}, true
+}
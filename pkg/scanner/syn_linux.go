@@ -0,0 +1,9 @@
+package scanner
+
+import "net"
+
+// listenRaw opens a raw IP socket receiving every TCP segment for the
+// network, "ip4:tcp" or "ip6:tcp". It needs CAP_NET_RAW.
+func listenRaw(network string) (net.PacketConn, error) {
+	return net.ListenIP(network, nil)
+}
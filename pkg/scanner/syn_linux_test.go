@@ -0,0 +1,110 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+	"unsafe"
+)
+
+// netnsEnv marks the test binary re-run inside a network namespace.
+const netnsEnv = "GOSPYDER_SYN_NETNS"
+
+// TestSYNScanInNetworkNamespace runs TestSYNScanLoopback in a new user
+// and network namespace, where it may open raw sockets without root and
+// scan a loopback interface of its own.
+func TestSYNScanInNetworkNamespace(t *testing.T) {
+	if os.Getenv(netnsEnv) != "" {
+		t.Skip("already in the namespace")
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^TestSYNScanLoopback$", "-test.v")
+	cmd.Env = append(os.Environ(), netnsEnv+"=1")
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags:  syscall.CLONE_NEWUSER | syscall.CLONE_NEWNET,
+		UidMappings: []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getuid(), Size: 1}},
+		GidMappings: []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getgid(), Size: 1}},
+	}
+	out, err := cmd.CombinedOutput()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		t.Skipf("cannot create a network namespace: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("loopback SYN scan failed: %v\n%s", err, out)
+	}
+	t.Logf("%s", out)
+}
+
+func TestSYNScanLoopback(t *testing.T) {
+	if os.Getenv(netnsEnv) == "" {
+		t.Skip("runs inside TestSYNScanInNetworkNamespace")
+	}
+	if err := linkUp("lo"); err != nil {
+		t.Fatalf("bring lo up: %v", err)
+	}
+	listener, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write([]byte("SSH-2.0-OpenSSH_9.6\r\n"))
+			conn.Close()
+		}
+	}()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	result, err := NewPortScanModule().Run(context.Background(), testOptions(map[string]interface{}{
+		"target":     "127.0.0.1",
+		"ports-list": fmt.Sprintf("%d,%d", port, port+1),
+		"retry":      1,
+		"syn":        true,
+	}))
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Metadata["scan_type"] != "syn" {
+		t.Fatalf("scan_type = %v, want syn", result.Metadata["scan_type"])
+	}
+	if len(result.Findings) != 1 || result.Findings[0].Metadata["port"] != port || result.Findings[0].Metadata["service"] != "SSH" {
+		t.Fatalf("findings = %#v, want SSH on %d", result.Findings, port)
+	}
+	stats := result.Metadata["hosts"].([]HostStats)[0]
+	if stats.Open != 1 || stats.Closed != 1 || stats.Filtered != 0 {
+		t.Errorf("stats = %+v, want the other port answered by RST", stats)
+	}
+}
+
+// linkUp sets the IFF_UP flag of an interface, as "ip link set up" does.
+func linkUp(name string) error {
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_DGRAM, 0)
+	if err != nil {
+		return err
+	}
+	defer syscall.Close(fd)
+	var ifr struct {
+		name  [syscall.IFNAMSIZ]byte
+		flags uint16
+		_     [22]byte
+	}
+	copy(ifr.name[:], name)
+	for _, req := range []uintptr{syscall.SIOCGIFFLAGS, syscall.SIOCSIFFLAGS} {
+		if req == syscall.SIOCSIFFLAGS {
+			ifr.flags |= syscall.IFF_UP
+		}
+		if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), req, uintptr(unsafe.Pointer(&ifr))); errno != 0 {
+			return errno
+		}
+	}
+	return nil
+}
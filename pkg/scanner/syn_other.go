@@ -0,0 +1,11 @@
+//go:build !linux
+
+package scanner
+
+import "net"
+
+// listenRaw is only implemented on Linux, where raw sockets deliver TCP
+// segments; elsewhere ports are connect-scanned.
+func listenRaw(string) (net.PacketConn, error) {
+	return nil, ErrSYNUnavailable
+}
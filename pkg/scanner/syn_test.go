@@ -0,0 +1,194 @@
+package scanner
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/registry"
+)
+
+// fakeRaw is a raw socket whose network answers SYNs by address: "open"
+// with a SYN-ACK, "closed" with a RST and anything else not at all.
+type fakeRaw struct {
+	mu       sync.Mutex
+	states   map[string]string
+	sent     map[string]int
+	deadline time.Time
+	replies  chan fakeReply
+}
+
+type fakeReply struct {
+	from netip.Addr
+	data []byte
+}
+
+func newFakeRaw(states map[string]string) *fakeRaw {
+	return &fakeRaw{states: states, sent: map[string]int{}, replies: make(chan fakeReply, 1024)}
+}
+
+func (r *fakeRaw) WriteTo(b []byte, addr net.Addr) (int, error) {
+	syn, _ := parseTCP(b)
+	dst, _ := netip.AddrFromSlice(addr.(*net.IPAddr).IP)
+	address := net.JoinHostPort(dst.String(), strconv.Itoa(int(syn.dstPort)))
+	r.mu.Lock()
+	r.sent[address]++
+	state := r.states[address]
+	if state == "" {
+		state = r.states[dst.String()]
+	}
+	r.mu.Unlock()
+
+	flags := byte(tcpRST | tcpACK)
+	switch state {
+	case "open":
+		flags = tcpSYN | tcpACK
+	case "closed":
+	default:
+		return len(b), nil
+	}
+	reply := make([]byte, 20)
+	binary.BigEndian.PutUint16(reply[0:], syn.dstPort)
+	binary.BigEndian.PutUint16(reply[2:], syn.srcPort)
+	binary.BigEndian.PutUint32(reply[8:], syn.seq+1)
+	reply[13] = flags
+	r.replies <- fakeReply{dst, reply}
+	return len(b), nil
+}
+
+func (r *fakeRaw) ReadFrom(b []byte) (int, net.Addr, error) {
+	r.mu.Lock()
+	wait := time.Until(r.deadline)
+	r.mu.Unlock()
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case reply := <-r.replies:
+		return copy(b, reply.data), &net.IPAddr{IP: reply.from.AsSlice()}, nil
+	case <-timer.C:
+		return 0, nil, os.ErrDeadlineExceeded
+	}
+}
+
+func (r *fakeRaw) SetReadDeadline(t time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deadline = t
+	return nil
+}
+
+func (r *fakeRaw) Close() error                       { return nil }
+func (r *fakeRaw) LocalAddr() net.Addr                { return &net.IPAddr{} }
+func (r *fakeRaw) SetDeadline(t time.Time) error      { return r.SetReadDeadline(t) }
+func (r *fakeRaw) SetWriteDeadline(t time.Time) error { return nil }
+
+func fakeSource(netip.Addr) (netip.Addr, error) {
+	return netip.MustParseAddr("192.0.2.100"), nil
+}
+
+func TestSYNScannerFindsOpenPortsAndSkipsDeadHosts(t *testing.T) {
+	raw := newFakeRaw(map[string]string{"192.0.2.1": "closed", "192.0.2.1:1002": "open"})
+	syn := newSYNScanner(raw, nil, fakeSource)
+	syn.Retries, syn.Timeout, syn.DeadAfter, syn.Outstanding = 1, 20*time.Millisecond, 3, 2
+	ports := []int{1001, 1002, 1003, 1004, 1005, 1006, 1007, 1008}
+
+	open, stats, err := syn.Scan(context.Background(), []string{"192.0.2.1", "192.0.2.2"}, ports)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if fmt.Sprint(open) != "map[192.0.2.1:[1002]]" {
+		t.Errorf("open = %v", open)
+	}
+	alive, dead := stats[0], stats[1]
+	if alive.Open != 1 || alive.Closed != len(ports)-1 || alive.Down {
+		t.Errorf("alive host = %+v", alive)
+	}
+	if !dead.Down || dead.Filtered < 3 || dead.Filtered+dead.Skipped != len(ports) {
+		t.Errorf("dead host = %+v, want down after 3 ports", dead)
+	}
+	// Every unanswered probe was retransmitted once.
+	for _, port := range ports[:dead.Filtered] {
+		if n := raw.sent[net.JoinHostPort("192.0.2.2", strconv.Itoa(port))]; n != 2 {
+			t.Errorf("port %d sent %d times, want 2", port, n)
+		}
+	}
+}
+
+// totalProgress counts the expected work items.
+type totalProgress struct {
+	registry.Progress
+	total atomic.Int64
+}
+
+func (p *totalProgress) AddTotal(n int64) { p.total.Add(n) }
+
+func TestScanHostsKeepsSYNResultsWhenCancelled(t *testing.T) {
+	raw := newFakeRaw(map[string]string{"192.0.2.1:1001": "open"})
+	progress := &totalProgress{Progress: registry.NopProgress}
+	ps := &PortScanner{SYN: newSYNScanner(raw, nil, fakeSource), Progress: progress}
+	ports := []int{1001, 1002}
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	// 192.0.2.1:1002 never answers, so the scan runs into the deadline;
+	// ::1 has no raw socket and is connect-scanned.
+	results, stats := ps.ScanHosts(ctx, []string{"192.0.2.1", "::1"}, ports, 10, 0, time.Second)
+	if results["192.0.2.1"][1001] == nil {
+		t.Errorf("results = %v, want 1001 kept after the deadline", results)
+	}
+	if len(stats) != 2 || stats[0].Host != "192.0.2.1" || stats[0].Open != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if got := progress.total.Load(); got != int64(2*len(ports)) {
+		t.Errorf("progress total = %d, want %d", got, 2*len(ports))
+	}
+}
+
+func TestSYNScannerPacesProbes(t *testing.T) {
+	raw := newFakeRaw(map[string]string{"192.0.2.1": "closed"})
+	syn := newSYNScanner(raw, nil, fakeSource)
+	syn.Rate = 200
+
+	start := time.Now()
+	_, stats, err := syn.Scan(context.Background(), []string{"192.0.2.1"}, []int{1001, 1002, 1003, 1004, 1005, 1006, 1007, 1008, 1009, 1010, 1011})
+	if err != nil || stats[0].Closed != 11 {
+		t.Fatalf("Scan() = %+v, %v", stats, err)
+	}
+	// 11 packets at 200/s are 10 intervals of 5ms apart.
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("scan took %v, want at least 50ms", elapsed)
+	}
+}
+
+func TestSYNScannerRejectsIPv6WithoutSocket(t *testing.T) {
+	syn := newSYNScanner(newFakeRaw(nil), nil, fakeSource)
+	if _, _, err := syn.Scan(context.Background(), []string{"2001:db8::1"}, []int{80}); err == nil {
+		t.Error("Scan() error = nil, want no IPv6 raw socket")
+	}
+	if _, ok := syn.address(context.Background(), "2001:db8::1"); ok {
+		t.Error("address() accepted IPv6 without a socket")
+	}
+}
+
+func TestSYNPacketChecksums(t *testing.T) {
+	for _, pair := range [][2]string{{"192.0.2.1", "198.51.100.7"}, {"2001:db8::1", "2001:db8::2"}} {
+		src, dst := netip.MustParseAddr(pair[0]), netip.MustParseAddr(pair[1])
+		packet := synPacket(src, dst, 40000, 443, 0xdeadbeef)
+		hdr, ok := parseTCP(packet)
+		if !ok || hdr.srcPort != 40000 || hdr.dstPort != 443 || hdr.seq != 0xdeadbeef || hdr.flags != tcpSYN {
+			t.Errorf("header = %+v", hdr)
+		}
+		// A segment with a correct checksum sums to zero.
+		if sum := tcpChecksum(src, dst, packet); sum != 0 {
+			t.Errorf("%s: checksum over packet = %#x, want 0", pair[0], sum)
+		}
+	}
+}
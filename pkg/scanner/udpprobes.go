@@ -0,0 +1,262 @@
+package scanner
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// udpProbe is a protocol-correct request for a UDP service. Services
+// ignore datagrams they cannot parse, so an empty probe would leave most
+// of them looking open|filtered.
+type udpProbe struct {
+	name string
+	// payload builds the request; probes with IDs randomize them per port.
+	payload func() []byte
+	// parse turns a reply into a readable banner, or "" when the reply is
+	// not an answer to req. The service itself is matched on the raw reply.
+	parse func(req, resp []byte) string
+	// anyPort accepts replies from any source port of the target, for
+	// servers that answer from a new one.
+	anyPort bool
+}
+
+var emptyProbe = &udpProbe{name: "empty", payload: func() []byte { return nil }}
+
+var udpProbes = map[int]*udpProbe{
+	53:    {name: "dns-version", payload: dnsVersionQuery, parse: parseDNSVersion},
+	69:    {name: "tftp-read", payload: tftpReadRequest, parse: parseTFTP, anyPort: true},
+	123:   {name: "ntp-client", payload: ntpClientRequest, parse: parseNTP},
+	161:   {name: "snmp-sysdescr", payload: snmpSysDescrRequest, parse: parseSNMP},
+	500:   {name: "ike-main-mode", payload: ikeMainMode, parse: parseIKE},
+	1900:  {name: "ssdp-msearch", payload: ssdpSearch, parse: parseSSDP, anyPort: true},
+	5353:  {name: "mdns-services", payload: mdnsServicesQuery, parse: parseMDNS},
+	11211: {name: "memcached-version", payload: memcachedVersion, parse: parseMemcached},
+}
+
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	rand.Read(b)
+	return b
+}
+
+// dnsQuery builds a single-question query with a random ID.
+func dnsQuery(name string, qtype dnsmessage.Type, class dnsmessage.Class) []byte {
+	id := binary.BigEndian.Uint16(randomBytes(2))
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: id})
+	b.StartQuestions()
+	b.Question(dnsmessage.Question{Name: dnsmessage.MustNewName(name), Type: qtype, Class: class})
+	msg, _ := b.Finish()
+	return msg
+}
+
+// dnsReply parses resp if it answers req.
+func dnsReply(req, resp []byte) (*dnsmessage.Message, bool) {
+	var msg dnsmessage.Message
+	if msg.Unpack(resp) != nil || !msg.Header.Response || len(req) < 2 ||
+		msg.Header.ID != binary.BigEndian.Uint16(req) {
+		return nil, false
+	}
+	return &msg, true
+}
+
+// dnsVersionQuery asks for version.bind in the CHAOS class, which BIND,
+// Unbound, Knot and PowerDNS answer unless configured not to.
+func dnsVersionQuery() []byte {
+	return dnsQuery("version.bind.", dnsmessage.TypeTXT, dnsmessage.Class(3))
+}
+
+func parseDNSVersion(req, resp []byte) string {
+	msg, ok := dnsReply(req, resp)
+	if !ok {
+		return ""
+	}
+	for _, answer := range msg.Answers {
+		if txt, ok := answer.Body.(*dnsmessage.TXTResource); ok && len(txt.TXT) > 0 {
+			return "DNS version.bind: " + sanitizeBanner(strings.Join(txt.TXT, ""))
+		}
+	}
+	return "DNS response (" + strings.TrimPrefix(msg.Header.RCode.String(), "RCode") + ")"
+}
+
+// mdnsServicesQuery asks for the DNS-SD service list with the unicast
+// response bit set, so the responder answers the querying port.
+func mdnsServicesQuery() []byte {
+	return dnsQuery("_services._dns-sd._udp.local.", dnsmessage.TypePTR, dnsmessage.ClassINET|1<<15)
+}
+
+func parseMDNS(req, resp []byte) string {
+	var msg dnsmessage.Message
+	if msg.Unpack(resp) != nil || !msg.Header.Response {
+		return ""
+	}
+	var services []string
+	for _, answer := range msg.Answers {
+		if ptr, ok := answer.Body.(*dnsmessage.PTRResource); ok {
+			services = append(services, strings.TrimSuffix(ptr.PTR.String(), "."))
+		}
+	}
+	if len(services) == 0 {
+		return "mDNS response"
+	}
+	return "mDNS services: " + strings.Join(services, ", ")
+}
+
+// tftpReadRequest asks for a file that will not exist, so servers answer
+// with an error packet.
+func tftpReadRequest() []byte {
+	name := hex.EncodeToString(randomBytes(6)) + ".txt"
+	return append(append([]byte{0, 1}, name...), "\x00octet\x00"...)
+}
+
+func parseTFTP(_, resp []byte) string {
+	if len(resp) < 4 || resp[0] != 0 {
+		return ""
+	}
+	switch resp[1] {
+	case 3:
+		return fmt.Sprintf("TFTP data block %d", binary.BigEndian.Uint16(resp[2:]))
+	case 5:
+		msg := string(bytes.TrimRight(resp[4:], "\x00"))
+		return fmt.Sprintf("TFTP error %d: %s", binary.BigEndian.Uint16(resp[2:]), sanitizeBanner(msg))
+	}
+	return ""
+}
+
+// ntpClientRequest is an NTPv4 client (mode 3) packet.
+func ntpClientRequest() []byte {
+	req := make([]byte, 48)
+	req[0] = 0x23 // LI 0, version 4, mode 3
+	return req
+}
+
+func parseNTP(_, resp []byte) string {
+	if len(resp) < 48 || resp[0]&7 != 4 {
+		return ""
+	}
+	banner := fmt.Sprintf("NTP v%d server stratum %d", resp[0]>>3&7, resp[1])
+	if resp[1] == 1 {
+		// Primary servers name their reference clock, e.g. GPS.
+		if refid := strings.TrimRight(string(resp[12:16]), "\x00"); refid != "" {
+			banner += " refid " + sanitizeBanner(refid)
+		}
+	}
+	return banner
+}
+
+// sysDescrOID is 1.3.6.1.2.1.1.1.0 in BER.
+var sysDescrOID = []byte{0x06, 0x08, 0x2b, 0x06, 0x01, 0x02, 0x01, 0x01, 0x01, 0x00}
+
+// snmpSysDescrRequest is an SNMPv2c GetRequest for sysDescr.0 with the
+// community "public".
+func snmpSysDescrRequest() []byte {
+	req := []byte{
+		0x30, 0x29, 0x02, 0x01, 0x01, 0x04, 0x06, 'p', 'u', 'b', 'l', 'i', 'c',
+		0xa0, 0x1c, 0x02, 0x04, 0, 0, 0, 0, 0x02, 0x01, 0x00, 0x02, 0x01, 0x00,
+		0x30, 0x0e, 0x30, 0x0c,
+	}
+	copy(req[17:21], randomBytes(4))
+	req[17] &= 0x7f // keep the request ID positive
+	return append(append(req, sysDescrOID...), 0x05, 0x00)
+}
+
+func parseSNMP(_, resp []byte) string {
+	if len(resp) < 2 || resp[0] != 0x30 {
+		return ""
+	}
+	at := bytes.Index(resp, sysDescrOID)
+	if at < 0 {
+		return "SNMPv2c response"
+	}
+	value := resp[at+len(sysDescrOID):]
+	if len(value) < 2 || value[0] != 0x04 {
+		return "SNMPv2c response"
+	}
+	length, header := int(value[1]), 2
+	if length&0x80 != 0 {
+		n := length & 0x7f
+		if n == 0 || n > 2 || len(value) < 2+n {
+			return "SNMPv2c response"
+		}
+		length, header = 0, 2+n
+		for _, b := range value[2 : 2+n] {
+			length = length<<8 | int(b)
+		}
+	}
+	if len(value) < header+length {
+		return "SNMPv2c response"
+	}
+	return "SNMPv2c sysDescr: " + sanitizeBanner(string(value[header:header+length]))
+}
+
+// ikeMainMode is an IKEv1 Main Mode proposal of 3DES, SHA1, a pre-shared
+// key and MODP group 2, which most IKE daemons answer whether or not
+// they accept it.
+func ikeMainMode() []byte {
+	msg := append(randomBytes(8), make([]byte, 8)...) // initiator, responder cookies
+	msg = append(msg,
+		0x01, 0x10, 0x02, 0x00, // next payload SA, version 1.0, Main Mode, flags
+		0, 0, 0, 0, // message ID
+		0, 0, 0, 80, // length
+		// SA payload: DOI IPsec, situation identity only.
+		0x00, 0x00, 0x00, 52, 0, 0, 0, 1, 0, 0, 0, 1,
+		// Proposal 1: ISAKMP, no SPI, one transform.
+		0x00, 0x00, 0x00, 40, 0x01, 0x01, 0x00, 0x01,
+		// Transform 1: KEY_IKE with its attributes.
+		0x00, 0x00, 0x00, 32, 0x01, 0x01, 0x00, 0x00,
+		0x80, 0x01, 0x00, 0x05, // encryption 3DES-CBC
+		0x80, 0x02, 0x00, 0x02, // hash SHA1
+		0x80, 0x03, 0x00, 0x01, // pre-shared key
+		0x80, 0x04, 0x00, 0x02, // MODP group 2
+		0x80, 0x0b, 0x00, 0x01, // life in seconds
+		0x80, 0x0c, 0x70, 0x80, // 28800
+	)
+	return msg
+}
+
+func parseIKE(req, resp []byte) string {
+	if len(resp) < 28 || len(req) < 8 || !bytes.Equal(resp[:8], req[:8]) {
+		return ""
+	}
+	return fmt.Sprintf("ISAKMP IKEv%d responder, exchange type %d", resp[17]>>4, resp[18])
+}
+
+func ssdpSearch() []byte {
+	return []byte("M-SEARCH * HTTP/1.1\r\nHOST: 239.255.255.250:1900\r\nMAN: \"ssdp:discover\"\r\nMX: 1\r\nST: ssdp:all\r\n\r\n")
+}
+
+func parseSSDP(_, resp []byte) string {
+	if !bytes.HasPrefix(resp, []byte("HTTP/1.")) {
+		return ""
+	}
+	for _, line := range strings.Split(string(resp), "\r\n") {
+		name, value, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(name), "server") {
+			return "SSDP server: " + sanitizeBanner(value)
+		}
+	}
+	return "SSDP response"
+}
+
+// memcachedVersion is the "version" command behind the UDP frame header
+// (request ID, sequence number, datagram count, reserved). It asks for
+// far less than "stats", so the probe is no amplifier.
+func memcachedVersion() []byte {
+	return append(append(randomBytes(2), 0, 0, 0, 1, 0, 0), "version\r\n"...)
+}
+
+func parseMemcached(req, resp []byte) string {
+	if len(resp) < 8 || len(req) < 2 || !bytes.Equal(resp[:2], req[:2]) {
+		return ""
+	}
+	line := strings.TrimSpace(string(resp[8:]))
+	if v, ok := strings.CutPrefix(line, "VERSION "); ok {
+		return "memcached version " + sanitizeBanner(v)
+	}
+	return "memcached " + sanitizeBanner(line)
+}
@@ -0,0 +1,301 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/logger"
+	"github.com/NASHEDIxCODER/gospyder/internal/telemetry"
+)
+
+// UDP port states, named as nmap names them.
+const (
+	StateOpen         = "open"
+	StateOpenFiltered = "open|filtered"
+	StateClosed       = "closed"
+	StateFiltered     = "filtered"
+)
+
+// UDPResult is the outcome of probing one UDP port.
+type UDPResult struct {
+	Port  int
+	State string
+	// Probe names the payload sent, e.g. "dns-version" or "empty".
+	Probe   string
+	Banner  string
+	Service string
+	Version string
+	Product string
+	CPE     []string
+}
+
+// ScanUDP probes ports over UDP with up to threads ports in flight. Each
+// port gets its protocol's payload, or an empty datagram, sent up to
+// retries+1 times while no reply arrives. Sends across all ports are
+// paced to PortScanner.Rate packets per second.
+//
+// A reply means open. An ICMP port unreachable, reported by the kernel
+// on the connected socket, means closed, and other ICMP unreachables
+// mean filtered. Silence is open|filtered, as for nmap. Probes whose
+// servers answer from another port (TFTP, SSDP) use an unconnected
+// socket, which sees no ICMP errors.
+func (ps *PortScanner) ScanUDP(ctx context.Context, target string, ports []int, threads, retries int, timeout time.Duration) map[int]*UDPResult {
+	results := make(map[int]*UDPResult, len(ports))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	ctx, span := ps.Telemetry.StartSpan(ctx, "ports.scan_udp",
+		telemetry.String("host", target),
+		telemetry.Int("ports", len(ports)),
+	)
+	defer span.End()
+
+	if threads > len(ports) {
+		threads = len(ports)
+	}
+	if threads <= 0 {
+		threads = 1
+	}
+	pace := newPacer(ps.Rate)
+	sem := make(chan struct{}, threads)
+	progress := ps.progress()
+	progress.AddTotal(int64(len(ports)))
+
+	for _, port := range ports {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return results
+		}
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			defer progress.Increment(1)
+
+			r := ps.probeUDP(ctx, pace, target, p, retries, timeout)
+			if r == nil {
+				return
+			}
+			if r.State == StateOpen {
+				progress.AddFinding()
+				if ps.Logger.Enabled(logger.LevelDebug) {
+					ps.Logger.Debug("Port %d/udp open - %s (banner: %s)", p, r.Service, truncateBanner(r.Banner, 60))
+				}
+			}
+			mu.Lock()
+			results[p] = r
+			mu.Unlock()
+		}(port)
+	}
+	wg.Wait()
+
+	open := 0
+	for _, r := range results {
+		if r.State == StateOpen {
+			open++
+		}
+	}
+	span.SetAttributes(telemetry.Int("open_ports", open))
+	ps.Telemetry.AddOpenPorts("udp", open)
+	return results
+}
+
+// probeUDP sends port's probe until a reply, an ICMP error or the last
+// retry. It returns nil when the scan was cancelled or the socket could
+// not be opened.
+func (ps *PortScanner) probeUDP(ctx context.Context, pace *pacer, target string, port, retries int, timeout time.Duration) *UDPResult {
+	probe := ps.udpProbe(port)
+	result := &UDPResult{Port: port, State: StateOpenFiltered, Probe: probe.name}
+	address := net.JoinHostPort(target, fmt.Sprint(port))
+
+	dial := (&net.Dialer{Timeout: timeout}).DialContext
+	if probe.anyPort {
+		dial = dialAnyPort
+	}
+	conn, err := ps.Replay.DialContext(ctx, "udp", address, dial)
+	if err != nil {
+		if unreachable(err) {
+			result.State = StateFiltered
+			return result
+		}
+		return nil
+	}
+	defer conn.Close()
+
+	payload := probe.payload()
+	buf := make([]byte, 64<<10)
+	for attempt := 0; attempt <= retries; attempt++ {
+		if err := pace.wait(ctx); err != nil {
+			return nil
+		}
+		if _, err := conn.Write(payload); err != nil {
+			// An ICMP error for an earlier attempt surfaces on the next
+			// write as well as on reads.
+			if state := icmpState(err); state != "" {
+				result.State = state
+				return result
+			}
+			continue
+		}
+		conn.SetReadDeadline(time.Now().Add(timeout))
+		n, err := conn.Read(buf)
+		if n > 0 || err == nil {
+			result.State = StateOpen
+			if probe.parse != nil {
+				result.Banner = probe.parse(payload, buf[:n])
+			}
+			if result.Banner == "" {
+				result.Banner = sanitizeBanner(string(buf[:n]))
+			}
+			result.Service = serviceFromPort(port)
+			if m := ps.serviceProbes().matchUDP(port, buf[:n]); m != nil {
+				result.Service, result.Version = m.DisplayService(), m.Version
+				result.Product, result.CPE = m.Product, m.CPE
+			}
+			return result
+		}
+		if state := icmpState(err); state != "" {
+			result.State = state
+			return result
+		}
+		if errors.Is(err, io.EOF) {
+			// A replayed exchange with no recorded reply.
+			break
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+	result.Service = serviceFromPort(port)
+	return result
+}
+
+// icmpState maps the socket errors ICMP unreachables produce to a port
+// state, or "" for other errors such as timeouts.
+func icmpState(err error) string {
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return StateClosed
+	case unreachable(err):
+		return StateFiltered
+	}
+	return ""
+}
+
+func unreachable(err error) bool {
+	return errors.Is(err, syscall.EHOSTUNREACH) || errors.Is(err, syscall.ENETUNREACH)
+}
+
+// anyPortConn is an unconnected UDP socket that talks to one host and
+// accepts its replies from any port.
+type anyPortConn struct {
+	*net.UDPConn
+	remote *net.UDPAddr
+}
+
+func dialAnyPort(ctx context.Context, network, address string) (net.Conn, error) {
+	remote, err := (&net.Resolver{}).LookupNetIP(ctx, "ip", hostOf(address))
+	if err != nil || len(remote) == 0 {
+		return nil, fmt.Errorf("resolve %s: %w", address, err)
+	}
+	addr, err := net.ResolveUDPAddr(network, net.JoinHostPort(remote[0].Unmap().String(), portOf(address)))
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP(network, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &anyPortConn{UDPConn: conn, remote: addr}, nil
+}
+
+func (c *anyPortConn) Write(p []byte) (int, error) {
+	return c.WriteToUDP(p, c.remote)
+}
+
+func (c *anyPortConn) Read(p []byte) (int, error) {
+	for {
+		n, from, err := c.ReadFromUDP(p)
+		if err != nil || from.IP.Equal(c.remote.IP) {
+			return n, err
+		}
+	}
+}
+
+func (c *anyPortConn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+func hostOf(address string) string {
+	host, _, _ := net.SplitHostPort(address)
+	return host
+}
+
+func portOf(address string) string {
+	_, port, _ := net.SplitHostPort(address)
+	return port
+}
+
+// pacer spaces sends evenly to a rate in packets per second.
+type pacer struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// newPacer returns a pacer for rate packets per second; zero or less is
+// unlimited.
+func newPacer(rate int) *pacer {
+	p := &pacer{}
+	if rate > 0 {
+		p.interval = time.Second / time.Duration(rate)
+	}
+	return p
+}
+
+// wait blocks until the next send slot or ctx is done.
+func (p *pacer) wait(ctx context.Context) error {
+	if p.interval == 0 {
+		return ctx.Err()
+	}
+	p.mu.Lock()
+	now := time.Now()
+	if p.next.Before(now) {
+		p.next = now
+	}
+	slot := p.next
+	p.next = p.next.Add(p.interval)
+	p.mu.Unlock()
+
+	delay := time.Until(slot)
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sortedUDPResults returns the ports in results, in order.
+func sortedUDPResults(results map[int]*UDPResult) []int {
+	ports := make([]int, 0, len(results))
+	for port := range results {
+		ports = append(ports, port)
+	}
+	sort.Ints(ports)
+	return ports
+}
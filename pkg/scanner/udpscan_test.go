@@ -0,0 +1,173 @@
+package scanner
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// udpServer answers each datagram with reply's result; a nil result is
+// not sent.
+func udpServer(t *testing.T, reply func(req []byte) []byte) int {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	go func() {
+		buf := make([]byte, 2048)
+		for {
+			n, from, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if resp := reply(buf[:n]); resp != nil {
+				conn.WriteTo(resp, from)
+			}
+		}
+	}()
+	return conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+func TestPortScanModuleClassifiesUDPPorts(t *testing.T) {
+	dns := udpServer(t, func(req []byte) []byte {
+		var msg dnsmessage.Message
+		if msg.Unpack(req) != nil || len(msg.Questions) != 1 {
+			return nil
+		}
+		msg.Header.Response = true
+		msg.Answers = []dnsmessage.Resource{{
+			Header: dnsmessage.ResourceHeader{Name: msg.Questions[0].Name, Type: dnsmessage.TypeTXT, Class: msg.Questions[0].Class},
+			Body:   &dnsmessage.TXTResource{TXT: []string{"9.18.24-1-Debian"}},
+		}}
+		resp, _ := msg.Pack()
+		return resp
+	})
+	silent := udpServer(t, func([]byte) []byte { return nil })
+	// A port freed just now answers with ICMP port unreachable.
+	closedConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := closedConn.LocalAddr().(*net.UDPAddr).Port
+	closedConn.Close()
+
+	// Send the built-in DNS payload to the test port and match its reply
+	// with the database's DNS probe, extended to that port.
+	udpProbes[dns] = udpProbes[53]
+	defer delete(udpProbes, dns)
+	section := string(builtinServiceProbes)
+	section = section[strings.Index(section, "Probe UDP DNSVersionBindReq"):strings.Index(section, "Probe UDP TFTPReadReq")]
+	probes := filepath.Join(t.TempDir(), "probes.txt")
+	if err := os.WriteFile(probes, []byte(strings.Replace(section, "ports 53", "ports 53,"+strconv.Itoa(dns), 1)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := testOptions(map[string]interface{}{
+		"target":     "127.0.0.1",
+		"ports-list": strconv.Itoa(dns) + "," + strconv.Itoa(silent) + "," + strconv.Itoa(closed),
+		"udp":        true,
+		"retry":      1,
+		"probes":     probes,
+	})
+	opts.Config.Scanner.PortTimeout = 300 * time.Millisecond
+	result, err := NewPortScanModule().Run(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if len(result.Findings) != 1 {
+		t.Fatalf("findings = %#v, want one open UDP port", result.Findings)
+	}
+	f := result.Findings[0]
+	if f.Value != strconv.Itoa(dns)+"/udp" || f.Description != "DNS ISC BIND 9.18.24" || f.Metadata["probe"] != "dns-version" {
+		t.Errorf("finding = %s %q %v", f.Value, f.Description, f.Metadata)
+	}
+	if got, _ := result.Metadata["udp_open_filtered"].([]int); len(got) != 1 || got[0] != silent {
+		t.Errorf("udp_open_filtered = %v, want [%d]", result.Metadata["udp_open_filtered"], silent)
+	}
+	if result.Metadata["udp_closed"] != 1 {
+		t.Errorf("udp_closed = %v, want 1", result.Metadata["udp_closed"])
+	}
+	if _, ok := result.Metadata["ports_scanned"]; ok {
+		t.Error("-sU alone also ran a TCP scan")
+	}
+}
+
+func TestUDPProbeRepliesMatchServiceProbes(t *testing.T) {
+	ntp := make([]byte, 48)
+	ntp[0], ntp[1] = 0x24, 1 // version 4, mode 4 (server), stratum 1
+	copy(ntp[12:], "GPS")
+
+	snmpReq := snmpSysDescrRequest()
+	descr := "Cisco IOS Software, C2960 Software, Version 15.0(2)SE"
+	snmp := append([]byte{0x30, 0x50}, snmpReq[2:27]...)
+	snmp = append(append(snmp, sysDescrOID...), 0x04, byte(len(descr)))
+	snmp = append(snmp, descr...)
+
+	ike := ikeMainMode()
+	ikeReply := append(append([]byte{}, ike[:8]...), make([]byte, 20)...)
+	ikeReply[17], ikeReply[18] = 0x10, 2
+
+	memReq := memcachedVersion()
+	memReply := append(append([]byte{}, memReq[:2]...), 0, 0, 0, 1, 0, 0)
+	memReply = append(memReply, "VERSION 1.6.21\r\n"...)
+
+	tests := []struct {
+		port          int
+		req, resp     []byte
+		service, want string
+	}{
+		{123, ntpClientRequest(), ntp, "NTP", "v4"},
+		{161, snmpReq, snmp, "SNMP", "v2c"},
+		{500, ike, ikeReply, "IKE", "v1"},
+		{69, tftpReadRequest(), append([]byte{0, 5, 0, 1}, "File not found\x00"...), "TFTP", ""},
+		{1900, ssdpSearch(), []byte("HTTP/1.1 200 OK\r\nSERVER: Linux/5.4 UPnP/1.0 MiniUPnPd/2.2\r\n\r\n"), "SSDP", "2.2"},
+		{11211, memReq, memReply, "Memcached", "1.6.21"},
+	}
+	db := DefaultServiceProbes()
+	for _, tt := range tests {
+		if banner := udpProbes[tt.port].parse(tt.req, tt.resp); banner == "" {
+			t.Errorf("port %d: parse() rejected the reply", tt.port)
+		}
+		m := db.matchUDP(tt.port, tt.resp)
+		if m == nil || m.DisplayService() != tt.service || m.Version != tt.want {
+			t.Errorf("port %d: matchUDP() = %+v, want %s %q", tt.port, m, tt.service, tt.want)
+		}
+	}
+	if m := db.matchUDP(161, snmp); m == nil || m.Info != descr {
+		t.Errorf("matchUDP(snmp) info = %+v, want sysDescr", m)
+	}
+	if banner := parseSNMP(snmpReq, snmp); banner != "SNMPv2c sysDescr: "+descr {
+		t.Errorf("parseSNMP() = %q", banner)
+	}
+
+	// Replies to someone else's request are not parsed as ours.
+	stale := append([]byte{}, memReply...)
+	binary.BigEndian.PutUint16(stale, binary.BigEndian.Uint16(memReq)+1)
+	if banner := parseMemcached(memReq, stale); banner != "" {
+		t.Errorf("parseMemcached(stale) = %q, want empty", banner)
+	}
+}
+
+func TestPacerSpacesSends(t *testing.T) {
+	p := newPacer(50)
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := p.wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 70*time.Millisecond {
+		t.Errorf("5 sends at 50/s took %v, want at least 80ms", elapsed)
+	}
+}
@@ -7,9 +7,16 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
 )
 
-type WAFScanner struct{}
+type WAFScanner struct {
+	// Transport is used for probe requests; nil means http.DefaultTransport.
+	Transport http.RoundTripper
+	// Errors receives a failure when no probe request got a response.
+	Errors *errors.Collector
+}
 
 type WAFDetection struct {
 	Name       string
@@ -25,7 +32,8 @@ func (ws *WAFScanner) Detect(ctx context.Context, target string) string {
 
 func (ws *WAFScanner) DetectDetailed(ctx context.Context, target string) WAFDetection {
 	client := &http.Client{
-		Timeout: 5 * time.Second,
+		Timeout:   5 * time.Second,
+		Transport: ws.Transport,
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			return http.ErrUseLastResponse
 		},
@@ -118,6 +126,14 @@ func (ws *WAFScanner) DetectDetailed(ctx context.Context, target string) WAFDete
 		},
 	}
 
+	responded := false
+	var lastErr error
+	defer func() {
+		if !responded && lastErr != nil && ctx.Err() == nil {
+			ws.Errors.Add(errors.Wrap(fmt.Sprintf("WAF probes to %s got no response", target), lastErr))
+		}
+	}()
+
 	for _, payload := range payloads {
 		for _, baseURL := range wafBaseURLs(target) {
 			reqURL := baseURL + payload
@@ -126,8 +142,10 @@ func (ws *WAFScanner) DetectDetailed(ctx context.Context, target string) WAFDete
 
 			resp, err := client.Do(req)
 			if err != nil {
+				lastErr = err
 				continue
 			}
+			responded = true
 			// fmt.Println("URL:", reqURL)
 			// fmt.Println("STATUS:", resp.StatusCode)
 			// fmt.Println("SERVER:", resp.Header.Get("Server"))
@@ -1,70 +1,226 @@
 package sources
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
-	"fmt"
-	"strings"
+	"io"
+	"os"
+	"sync"
+	"time"
 
-	"github.com/gorilla/websocket"
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
 	"github.com/NASHEDIxCODER/gospyder/pkg/models"
+	"github.com/gorilla/websocket"
 )
 
+// CertStreamURL is the public CertStream websocket.
+const CertStreamURL = "wss://certstream.calidog.io"
+
+const (
+	certStreamMinBackoff = time.Second
+	certStreamMaxBackoff = time.Minute
+)
+
+// CertStreamClient watches a CertStream server, such as the public one or
+// a self-hosted certstream-server-go, and reconnects with exponential
+// backoff when the connection drops. It understands full certificate
+// updates and the domains-only stream.
 type CertStreamClient struct {
-	conn *websocket.Conn
+	// URL is the websocket endpoint; empty uses CertStreamURL.
+	URL string
+	// Replay reads messages from this file, one JSON message per line,
+	// instead of connecting.
+	Replay string
+	// Record receives every message read from the network as a line, in
+	// the format Replay reads.
+	Record io.Writer
+	// MinBackoff and MaxBackoff bound the reconnect delay; zero means 1s
+	// and 1m.
+	MinBackoff, MaxBackoff time.Duration
+	// OnDisconnect, when set, is told why the connection dropped and how
+	// long until the next attempt.
+	OnDisconnect func(err error, retry time.Duration)
+
+	recordMu sync.Mutex
 }
 
-func NewCertStream() (*CertStreamClient, error) {
-	conn, _, err := websocket.DefaultDialer.Dial("wss://certstream.calidog.io", nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to CertStream: %w", err)
-	}
-	return &CertStreamClient{conn: conn}, nil
+// NewCertStream returns a client for the websocket at url.
+func NewCertStream(url string) *CertStreamClient {
+	return &CertStreamClient{URL: url}
 }
 
+// Watch sends names under targetDomain from the stream to domains until
+// ctx ends, or until a replayed file is exhausted. Wildcard names are
+// reported without their "*." label.
 func (c *CertStreamClient) Watch(ctx context.Context, targetDomain string, domains chan<- models.Domain) error {
-	defer c.conn.Close()
+	e := newEmitter("certstream", targetDomain, domains)
+	if c.Replay != "" {
+		return c.replay(ctx, e)
+	}
 
+	minBackoff, maxBackoff := c.MinBackoff, c.MaxBackoff
+	if minBackoff <= 0 {
+		minBackoff = certStreamMinBackoff
+	}
+	if maxBackoff < minBackoff {
+		maxBackoff = max(certStreamMaxBackoff, minBackoff)
+	}
+	backoff := minBackoff
 	for {
+		received, err := c.stream(ctx, e)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if received {
+			backoff = minBackoff
+		}
+		if c.OnDisconnect != nil {
+			c.OnDisconnect(err, backoff)
+		}
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		default:
+		case <-time.After(backoff):
 		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
 
-		_, msg, err := c.conn.ReadMessage()
+// stream reads one connection until it fails and reports whether any
+// message arrived.
+func (c *CertStreamClient) stream(ctx context.Context, e *emitter) (bool, error) {
+	url := c.URL
+	if url == "" {
+		url = CertStreamURL
+	}
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, url, nil)
+	if err != nil {
+		return false, errors.NewNetworkError("connect to CertStream", err)
+	}
+	defer conn.Close()
+	// ReadMessage does not watch ctx; closing the connection unblocks it.
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	received := false
+	for {
+		_, msg, err := conn.ReadMessage()
 		if err != nil {
-			return fmt.Errorf("websocket read: %w", err)
+			return received, errors.NewNetworkError("CertStream read", err)
+		}
+		received = true
+		c.record(msg)
+		if err := emitCertStream(ctx, msg, e); err != nil {
+			return received, err
 		}
+	}
+}
 
-		var cert map[string]interface{}
-		if err := json.Unmarshal(msg, &cert); err != nil {
-			continue
+func (c *CertStreamClient) record(msg []byte) {
+	if c.Record == nil {
+		return
+	}
+	c.recordMu.Lock()
+	defer c.recordMu.Unlock()
+	c.Record.Write(append(append([]byte(nil), msg...), '\n'))
+}
+
+func (c *CertStreamClient) replay(ctx context.Context, e *emitter) error {
+	f, err := os.Open(c.Replay)
+	if err != nil {
+		return errors.NewIOError("open CertStream replay", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		if err := emitCertStream(ctx, scanner.Bytes(), e); err != nil {
+			return err
 		}
+	}
+	if err := scanner.Err(); err != nil {
+		return errors.NewIOError("read CertStream replay", err)
+	}
+	return nil
+}
+
+// certStreamMessage covers the certstream message shapes: certificate
+// updates carry a leaf certificate in data, the domains-only stream
+// carries a list of names, and heartbeats carry nothing useful.
+type certStreamMessage struct {
+	MessageType string          `json:"message_type"`
+	Data        json.RawMessage `json:"data"`
+	LeafCert    *certStreamLeaf `json:"leaf_cert"` // older servers
+}
 
-		for _, domain := range extractDomains(cert) {
-			if strings.HasSuffix(domain, "."+targetDomain) {
-				select {
-				case domains <- models.Domain{Name: domain, Source: "certstream"}:
-				case <-ctx.Done():
-					return ctx.Err()
-				}
-			}
+type certStreamLeaf struct {
+	AllDomains []string `json:"all_domains"`
+	Subject    struct {
+		CN string `json:"CN"`
+	} `json:"subject"`
+}
+
+// emitCertStream sends the matching names in one message. Malformed
+// messages are skipped.
+func emitCertStream(ctx context.Context, msg []byte, e *emitter) error {
+	for _, name := range certStreamDomains(msg) {
+		if err := e.emit(ctx, name); err != nil {
+			return err
 		}
 	}
+	return nil
 }
 
-func extractDomains(cert map[string]interface{}) []string {
-	var domains []string
-	
-	if leaf, ok := cert["leaf_cert"].(map[string]interface{}); ok {
-		if names, ok := leaf["all_domains"].([]interface{}); ok {
-			for _, n := range names {
-				if name, ok := n.(string); ok {
-					domains = append(domains, name)
-				}
-			}
+func certStreamDomains(msg []byte) []string {
+	var m certStreamMessage
+	if err := json.Unmarshal(msg, &m); err != nil {
+		return nil
+	}
+	leaf := m.LeafCert
+	switch m.MessageType {
+	case "heartbeat":
+		return nil
+	case "dns_entries":
+		var names []string
+		json.Unmarshal(m.Data, &names)
+		return names
+	case "certificate_update":
+		var data struct {
+			LeafCert *certStreamLeaf `json:"leaf_cert"`
 		}
+		if json.Unmarshal(m.Data, &data) == nil && data.LeafCert != nil {
+			leaf = data.LeafCert
+		}
+	}
+	if leaf == nil {
+		return nil
+	}
+	return append(leaf.AllDomains, leaf.Subject.CN)
+}
+
+// CertStream is the live Certificate Transparency feed as a Source. It
+// reports names from certificates issued while connected and returns only
+// when ctx ends, unless it replays a recorded file.
+type CertStream struct {
+	client *CertStreamClient
+}
+
+// NewCertStreamSource creates a CertStream source for opts.BaseURL. A set
+// opts.Input replays recorded messages instead of connecting.
+func NewCertStreamSource(opts Options) Source {
+	return &CertStream{client: &CertStreamClient{URL: opts.BaseURL, Replay: opts.Input}}
+}
+
+func (s *CertStream) Name() string {
+	return "certstream"
+}
+
+// Enumerate watches the stream for names under domain until ctx ends.
+func (s *CertStream) Enumerate(ctx context.Context, domain string, out chan<- models.Domain) error {
+	if err := s.client.Watch(ctx, domain, out); err != nil && ctx.Err() == nil {
+		return err
 	}
-	return domains
-}
\ No newline at end of file
+	return nil
+}
@@ -0,0 +1,90 @@
+package sources
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NASHEDIxCODER/gospyder/pkg/models"
+	"github.com/gorilla/websocket"
+)
+
+func TestCertStreamReconnectsAndReplaysRecording(t *testing.T) {
+	// The first connection drops after one certificate; the second serves
+	// the domains-only stream and stays open.
+	var connections atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		if connections.Add(1) == 1 {
+			conn.WriteMessage(websocket.TextMessage, []byte(`{"message_type":"certificate_update","data":{"leaf_cert":{"subject":{"CN":"www.example.com"},"all_domains":["*.dev.example.com","*.example.com","example.org"]}}}`))
+			return
+		}
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"message_type":"heartbeat","timestamp":1}`))
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"message_type":"dns_entries","data":["api.example.com","www.example.com"]}`))
+		conn.ReadMessage()
+	}))
+	defer server.Close()
+
+	var recorded bytes.Buffer
+	disconnects := 0
+	client := &CertStreamClient{
+		URL:          "ws" + strings.TrimPrefix(server.URL, "http"),
+		Record:       &recorded,
+		MinBackoff:   10 * time.Millisecond,
+		OnDisconnect: func(error, time.Duration) { disconnects++ },
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	got := watchNames(t, ctx, client, 3, cancel)
+	if got != "api.example.com dev.example.com www.example.com" {
+		t.Fatalf("live names = %q", got)
+	}
+	if disconnects != 1 || connections.Load() != 2 {
+		t.Fatalf("disconnects = %d, connections = %d; want 1 reconnect", disconnects, connections.Load())
+	}
+
+	path := filepath.Join(t.TempDir(), "certstream.jsonl")
+	if err := os.WriteFile(path, recorded.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	replay := &CertStreamClient{URL: "ws://127.0.0.1:1", Replay: path}
+	if got := watchNames(t, context.Background(), replay, -1, nil); got != "api.example.com dev.example.com www.example.com" {
+		t.Fatalf("replayed names = %q", got)
+	}
+}
+
+// watchNames runs client.Watch for example.com and returns the sorted
+// names. With want >= 0, stop is called once that many have arrived.
+func watchNames(t *testing.T, ctx context.Context, client *CertStreamClient, want int, stop func()) string {
+	t.Helper()
+	out := make(chan models.Domain, 10)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- client.Watch(ctx, "example.com", out)
+		close(out)
+	}()
+	var names []string
+	for d := range out {
+		names = append(names, d.Name)
+		if len(names) == want {
+			stop()
+		}
+	}
+	if err := <-errc; err != nil && (want < 0 || ctx.Err() != context.Canceled) {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	sort.Strings(names)
+	return strings.Join(names, " ")
+}
@@ -0,0 +1,82 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/url"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/pkg/models"
+)
+
+// commonCrawlIndexes is how many of the newest crawls are searched.
+const commonCrawlIndexes = 3
+
+// CommonCrawl lists the hosts of URLs in the newest Common Crawl indexes.
+type CommonCrawl struct {
+	httpSource
+}
+
+// NewCommonCrawl creates a Common Crawl index source.
+func NewCommonCrawl(opts Options) Source {
+	return &CommonCrawl{newHTTPSource("commoncrawl", "https://index.commoncrawl.org", opts)}
+}
+
+type commonCrawlIndex struct {
+	ID     string `json:"id"`
+	CDXAPI string `json:"cdx-api"`
+}
+
+// Enumerate sends the hosts of crawled URLs under domain.
+func (s *CommonCrawl) Enumerate(ctx context.Context, domain string, out chan<- models.Domain) error {
+	body, err := s.get(ctx, s.baseURL+"/collinfo.json", nil)
+	if err != nil {
+		return err
+	}
+	if body == nil {
+		return errors.NewNotFoundError("commoncrawl index list not found")
+	}
+	var indexes []commonCrawlIndex
+	err = json.NewDecoder(body).Decode(&indexes)
+	body.Close()
+	if err != nil {
+		return errors.Wrap("decode commoncrawl index list", err)
+	}
+	if len(indexes) > commonCrawlIndexes {
+		indexes = indexes[:commonCrawlIndexes]
+	}
+
+	e := newEmitter(s.name, domain, out)
+	query := url.Values{"url": {"*." + domain}, "output": {"json"}, "fl": {"url"}}
+	for _, index := range indexes {
+		if err := s.search(ctx, index.CDXAPI+"?"+query.Encode(), e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// search reads one index's newline-delimited JSON captures.
+func (s *CommonCrawl) search(ctx context.Context, rawURL string, e *emitter) error {
+	body, err := s.get(ctx, rawURL, nil)
+	if err != nil || body == nil {
+		return err
+	}
+	defer body.Close()
+
+	dec := json.NewDecoder(body)
+	for {
+		var capture struct {
+			URL string `json:"url"`
+		}
+		if err := dec.Decode(&capture); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return errors.Wrap("decode commoncrawl captures", err)
+		}
+		if err := e.emit(ctx, capture.URL); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/pkg/models"
+)
+
+// CrtSh searches the crt.sh Certificate Transparency log index.
+type CrtSh struct {
+	httpSource
+}
+
+// NewCrtSh creates a crt.sh source.
+func NewCrtSh(opts Options) Source {
+	return &CrtSh{newHTTPSource("crtsh", "https://crt.sh", opts)}
+}
+
+type crtshEntry struct {
+	CommonName string `json:"common_name"`
+	NameValue  string `json:"name_value"` // newline-separated SAN entries
+}
+
+// Enumerate sends the names on certificates issued for domain.
+func (s *CrtSh) Enumerate(ctx context.Context, domain string, out chan<- models.Domain) error {
+	body, err := s.get(ctx, s.baseURL+"/?output=json&q="+url.QueryEscape("%."+domain), nil)
+	if err != nil || body == nil {
+		return err
+	}
+	defer body.Close()
+
+	var entries []crtshEntry
+	if err := json.NewDecoder(body).Decode(&entries); err != nil {
+		return errors.Wrap("decode crt.sh response", err)
+	}
+	e := newEmitter(s.name, domain, out)
+	for _, entry := range entries {
+		for _, name := range append(strings.Split(entry.NameValue, "\n"), entry.CommonName) {
+			if err := e.emit(ctx, name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package sources
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"unicode"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/pkg/models"
+)
+
+// File imports subdomains from a file or stdin, such as the output of
+// other tools. It accepts one name per line, CSV with the names in any
+// column, a JSON array, or JSON objects one per line. Values that are not
+// subdomains of the target are ignored.
+type File struct {
+	path  string
+	stdin io.Reader
+}
+
+// NewFile creates a file import source reading opts.Input.
+func NewFile(opts Options) Source {
+	stdin := opts.Stdin
+	if stdin == nil {
+		stdin = os.Stdin
+	}
+	return &File{path: opts.Input, stdin: stdin}
+}
+
+func (s *File) Name() string {
+	return "file"
+}
+
+// Enumerate sends the subdomains of domain listed in the input.
+func (s *File) Enumerate(ctx context.Context, domain string, out chan<- models.Domain) error {
+	input := s.stdin
+	if s.path != "-" {
+		f, err := os.Open(s.path)
+		if err != nil {
+			return errors.NewIOError("open import file", err)
+		}
+		defer f.Close()
+		input = f
+	}
+
+	r := bufio.NewReader(input)
+	e := newEmitter(s.Name(), domain, out)
+	var err error
+	switch firstRune(r) {
+	case '[', '{':
+		err = readJSON(ctx, r, e)
+	default:
+		err = readCSV(ctx, r, e)
+	}
+	if err != nil && ctx.Err() == nil {
+		return errors.NewIOError("read import file", err)
+	}
+	return err
+}
+
+// firstRune returns the first non-space rune in r without consuming it.
+func firstRune(r *bufio.Reader) rune {
+	for {
+		c, _, err := r.ReadRune()
+		if err != nil {
+			return 0
+		}
+		if !unicode.IsSpace(c) {
+			r.UnreadRune()
+			return c
+		}
+	}
+}
+
+// readCSV treats every field as a candidate name; a plain list is CSV with
+// one column.
+func readCSV(ctx context.Context, r io.Reader, e *emitter) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	cr.Comment = '#'
+	cr.TrimLeadingSpace = true
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, field := range record {
+			if err := e.emit(ctx, field); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// readJSON reads one or more JSON values and treats every string in them
+// as a candidate name.
+func readJSON(ctx context.Context, r io.Reader, e *emitter) error {
+	dec := json.NewDecoder(r)
+	for {
+		var v interface{}
+		if err := dec.Decode(&v); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := emitStrings(ctx, v, e); err != nil {
+			return err
+		}
+	}
+}
+
+func emitStrings(ctx context.Context, v interface{}, e *emitter) error {
+	switch v := v.(type) {
+	case string:
+		return e.emit(ctx, v)
+	case []interface{}:
+		for _, item := range v {
+			if err := emitStrings(ctx, item, e); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := emitStrings(ctx, v[key], e); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
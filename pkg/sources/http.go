@@ -0,0 +1,162 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/pkg/models"
+)
+
+// httpSource holds what the HTTP API sources share: the service URL, the
+// client and the request rate limit.
+type httpSource struct {
+	name    string
+	baseURL string
+	apiKey  string
+	client  *http.Client
+	limiter *limiter
+}
+
+func newHTTPSource(name, defaultURL string, opts Options) httpSource {
+	base := opts.BaseURL
+	if base == "" {
+		base = defaultURL
+	}
+	client := opts.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return httpSource{
+		name:    name,
+		baseURL: strings.TrimSuffix(base, "/"),
+		apiKey:  opts.APIKey,
+		client:  client,
+		limiter: &limiter{interval: opts.RateLimit},
+	}
+}
+
+func (s *httpSource) Name() string {
+	return s.name
+}
+
+// get fetches rawURL once the rate limit allows. A 404 returns a nil body:
+// the services use it for "nothing found".
+func (s *httpSource) get(ctx context.Context, rawURL string, header http.Header) (io.ReadCloser, error) {
+	if err := s.limiter.wait(ctx); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, errors.NewNetworkError(s.name+" request failed", err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		resp.Body.Close()
+		return nil, nil
+	case resp.StatusCode == http.StatusTooManyRequests:
+		resp.Body.Close()
+		return nil, errors.NewNetworkError(s.name+" rate limited", fmt.Errorf("HTTP %d", resp.StatusCode))
+	case resp.StatusCode != http.StatusOK:
+		resp.Body.Close()
+		return nil, errors.NewNetworkError(s.name+" request failed", fmt.Errorf("HTTP %d", resp.StatusCode))
+	}
+	return resp.Body, nil
+}
+
+// limiter spaces requests at least interval apart.
+type limiter struct {
+	interval time.Duration
+	mu       sync.Mutex
+	next     time.Time
+}
+
+func (l *limiter) wait(ctx context.Context) error {
+	if l.interval <= 0 {
+		return ctx.Err()
+	}
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(time.Until(at)):
+		return nil
+	}
+}
+
+// emitter sends the distinct subdomains of domain found in raw values,
+// which may be names, wildcards or URLs, to out.
+type emitter struct {
+	source string
+	domain string
+	out    chan<- models.Domain
+	seen   map[string]bool
+}
+
+func newEmitter(source, domain string, out chan<- models.Domain) *emitter {
+	return &emitter{source: source, domain: strings.ToLower(domain), out: out, seen: map[string]bool{}}
+}
+
+func (e *emitter) emit(ctx context.Context, raw string) error {
+	name, ok := subdomainOf(raw, e.domain)
+	if !ok || e.seen[name] {
+		return nil
+	}
+	e.seen[name] = true
+	select {
+	case e.out <- models.Domain{Name: name, Source: e.source}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// subdomainOf extracts the host from raw and reports whether it is a
+// subdomain of domain.
+func subdomainOf(raw, domain string) (string, bool) {
+	name := strings.ToLower(strings.TrimSpace(raw))
+	if strings.Contains(name, "://") {
+		u, err := url.Parse(name)
+		if err != nil {
+			return "", false
+		}
+		name = u.Hostname()
+	} else {
+		name, _, _ = strings.Cut(name, "/")
+		if host, _, err := net.SplitHostPort(name); err == nil {
+			name = host
+		}
+	}
+	name = strings.TrimPrefix(strings.TrimSuffix(name, "."), "*.")
+	if !strings.HasSuffix(name, "."+domain) {
+		return "", false
+	}
+	for _, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '.' || c == '_') {
+			return "", false
+		}
+	}
+	return name, true
+}
@@ -0,0 +1,55 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/pkg/models"
+)
+
+// PassiveDNS reads historical resolutions from an AlienVault OTX style
+// passive DNS API. The API key is optional and raises the service's rate
+// limit.
+type PassiveDNS struct {
+	httpSource
+}
+
+// NewPassiveDNS creates a passive DNS source.
+func NewPassiveDNS(opts Options) Source {
+	return &PassiveDNS{newHTTPSource("passivedns", "https://otx.alienvault.com", opts)}
+}
+
+type passiveDNSResponse struct {
+	PassiveDNS []struct {
+		Hostname string `json:"hostname"`
+		Address  string `json:"address"`
+	} `json:"passive_dns"`
+}
+
+// Enumerate sends the hostnames observed under domain.
+func (s *PassiveDNS) Enumerate(ctx context.Context, domain string, out chan<- models.Domain) error {
+	header := http.Header{}
+	if s.apiKey != "" {
+		header.Set("X-OTX-API-KEY", s.apiKey)
+	}
+	body, err := s.get(ctx, s.baseURL+"/api/v1/indicators/domain/"+url.PathEscape(domain)+"/passive_dns", header)
+	if err != nil || body == nil {
+		return err
+	}
+	defer body.Close()
+
+	var resp passiveDNSResponse
+	if err := json.NewDecoder(body).Decode(&resp); err != nil {
+		return errors.Wrap("decode passive DNS response", err)
+	}
+	e := newEmitter(s.name, domain, out)
+	for _, record := range resp.PassiveDNS {
+		if err := e.emit(ctx, record.Hostname); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/config"
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/pkg/models"
+)
+
+// Source is a passive subdomain source.
+type Source interface {
+	// Name identifies the source in configuration and on results.
+	Name() string
+
+	// Enumerate sends the subdomains of domain it knows of to out, tagged
+	// with its name, and returns when done or when ctx ends.
+	Enumerate(ctx context.Context, domain string, out chan<- models.Domain) error
+}
+
+// Options configures a source instance.
+type Options struct {
+	BaseURL   string        // service URL; empty uses the public service
+	APIKey    string        // sent by sources whose service accepts one
+	RateLimit time.Duration // minimum delay between requests
+	Client    *http.Client  // nil uses http.DefaultClient
+	Input     string        // file source path ("-" reads Stdin), or CertStream replay file
+	Stdin     io.Reader     // nil uses os.Stdin
+}
+
+// Factory creates a source from its options.
+type Factory func(opts Options) Source
+
+// DefaultSources run when no sources are configured. CertStream is opt-in
+// since it only ends with the context.
+var DefaultSources = []string{"crtsh", "wayback", "commoncrawl", "passivedns"}
+
+// Registry maps source names to factories.
+type Registry struct {
+	factories map[string]Factory
+}
+
+// NewRegistry creates an empty source registry.
+func NewRegistry() *Registry {
+	return &Registry{factories: map[string]Factory{}}
+}
+
+// DefaultRegistry returns a registry holding every built-in source.
+func DefaultRegistry() *Registry {
+	r := NewRegistry()
+	r.Register("crtsh", NewCrtSh)
+	r.Register("wayback", NewWayback)
+	r.Register("commoncrawl", NewCommonCrawl)
+	r.Register("passivedns", NewPassiveDNS)
+	r.Register("file", NewFile)
+	r.Register("certstream", NewCertStreamSource)
+	return r
+}
+
+// Register adds a source factory under name.
+func (r *Registry) Register(name string, factory Factory) error {
+	if _, exists := r.factories[name]; exists {
+		return fmt.Errorf("source %s already registered", name)
+	}
+	r.factories[name] = factory
+	return nil
+}
+
+// Names returns the registered source names in sorted order.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New creates the source registered under name.
+func (r *Registry) New(name string, opts Options) (Source, error) {
+	factory, ok := r.factories[name]
+	if !ok {
+		return nil, errors.NewValidationError(fmt.Sprintf("unknown source %q (available: %s)", name, strings.Join(r.Names(), ", ")))
+	}
+	return factory(opts), nil
+}
+
+// FromConfig creates the sources enabled by cfg, or DefaultSources when
+// none are. The file source is added whenever cfg.Import is set.
+func (r *Registry) FromConfig(cfg config.SourcesConfig, client *http.Client) ([]Source, error) {
+	names := cfg.Enabled
+	if len(names) == 0 {
+		names = DefaultSources
+	}
+	if cfg.Import != "" && !contains(names, "file") {
+		names = append(append([]string(nil), names...), "file")
+	}
+
+	var sources []Source
+	for _, name := range names {
+		settings := cfg.Settings[name]
+		opts := Options{
+			BaseURL:   settings.BaseURL,
+			APIKey:    settings.APIKey,
+			RateLimit: settings.RateLimit,
+			Client:    client,
+			Input:     settings.Replay,
+		}
+		if opts.APIKey == "" {
+			opts.APIKey = os.Getenv("GOSPYDER_" + strings.ToUpper(name) + "_KEY")
+		}
+		if name == "file" {
+			if cfg.Import == "" {
+				return nil, errors.NewValidationError("file source needs an import path")
+			}
+			opts.Input = cfg.Import
+		}
+		source, err := r.New(name, opts)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
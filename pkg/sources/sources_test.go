@@ -0,0 +1,156 @@
+package sources
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/config"
+	"github.com/NASHEDIxCODER/gospyder/pkg/models"
+)
+
+// collect runs source against example.com and returns the sorted names.
+func collect(t *testing.T, source Source) []string {
+	t.Helper()
+	out := make(chan models.Domain, 100)
+	err := source.Enumerate(context.Background(), "example.com", out)
+	close(out)
+	if err != nil {
+		t.Fatalf("%s: Enumerate() error = %v", source.Name(), err)
+	}
+	var names []string
+	for d := range out {
+		if d.Source != source.Name() {
+			t.Errorf("%s: domain %s tagged %q", source.Name(), d.Name, d.Source)
+		}
+		names = append(names, d.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestHTTPSourcesParseServiceResponses(t *testing.T) {
+	mux := http.NewServeMux()
+	var server *httptest.Server
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("q") != "%.example.com" {
+			http.Error(w, "bad query", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`[{"common_name":"www.example.com","name_value":"*.api.example.com\nmail.example.com\nexample.com"},
+			{"common_name":"evil-example.com","name_value":"www.example.com"}]`))
+	})
+	mux.HandleFunc("/cdx/search/cdx", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("http://old.example.com:80/index.html\nhttps://www.example.com/a?b=c\nhttp://example.org/\n"))
+	})
+	mux.HandleFunc("/collinfo.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id":"CC-2","cdx-api":"` + server.URL + `/CC-2-index"},{"id":"CC-1","cdx-api":"` + server.URL + `/CC-1-index"}]`))
+	})
+	mux.HandleFunc("/CC-2-index", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{\"url\":\"https://shop.example.com/\"}\n{\"url\":\"https://cdn.example.com/x.js\"}\n"))
+	})
+	// CC-1 has no captures and answers 404 like the real index.
+	mux.HandleFunc("/CC-1-index", http.NotFound)
+	mux.HandleFunc("/api/v1/indicators/domain/example.com/passive_dns", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-OTX-API-KEY") != "secret" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"passive_dns":[{"hostname":"vpn.example.com","address":"192.0.2.1"},{"hostname":"NS1.Example.com.","address":"192.0.2.2"}]}`))
+	})
+	server = httptest.NewServer(mux)
+	defer server.Close()
+
+	opts := Options{BaseURL: server.URL, Client: server.Client()}
+	keyed := opts
+	keyed.APIKey = "secret"
+	tests := []struct {
+		source Source
+		want   string
+	}{
+		{NewCrtSh(opts), "api.example.com mail.example.com www.example.com"},
+		{NewWayback(opts), "old.example.com www.example.com"},
+		{NewCommonCrawl(opts), "cdn.example.com shop.example.com"},
+		{NewPassiveDNS(keyed), "ns1.example.com vpn.example.com"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(collect(t, tt.source), " "); got != tt.want {
+			t.Errorf("%s found %q, want %q", tt.source.Name(), got, tt.want)
+		}
+	}
+
+	out := make(chan models.Domain, 10)
+	if err := NewPassiveDNS(opts).Enumerate(context.Background(), "example.com", out); err == nil {
+		t.Error("passivedns without its API key succeeded against a 403")
+	}
+}
+
+func TestFileSourceReadsListsCSVAndJSON(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"list.txt":   "# from another tool\nwww.example.com\n\nmail.example.com.\nexample.org\n",
+		"hosts.csv":  "host,ip\napi.example.com,192.0.2.1\n\"dev.example.com\",192.0.2.2\n",
+		"array.json": `["a.example.com", {"host": "b.example.com", "ips": ["192.0.2.1"]}]`,
+		"lines.json": "{\"host\":\"c.example.com\",\"input\":\"example.com\"}\n{\"host\":\"d.example.com\"}\n",
+	}
+	want := map[string]string{
+		"list.txt":   "mail.example.com www.example.com",
+		"hosts.csv":  "api.example.com dev.example.com",
+		"array.json": "a.example.com b.example.com",
+		"lines.json": "c.example.com d.example.com",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(collect(t, NewFile(Options{Input: path})), " "); got != want[name] {
+			t.Errorf("%s: found %q, want %q", name, got, want[name])
+		}
+	}
+
+	stdin := NewFile(Options{Input: "-", Stdin: strings.NewReader("x.example.com\n")})
+	if got := collect(t, stdin); len(got) != 1 || got[0] != "x.example.com" {
+		t.Errorf("stdin: found %v", got)
+	}
+}
+
+func TestFromConfigAppliesSettingsAndRateLimit(t *testing.T) {
+	var requests []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, time.Now())
+		w.Write([]byte(`[{"name_value":"www.example.com"}]`))
+	}))
+	defer server.Close()
+
+	cfg := config.SourcesConfig{
+		Enabled: []string{"crtsh"},
+		Import:  filepath.Join(t.TempDir(), "missing.txt"),
+		Settings: map[string]config.SourceConfig{
+			"crtsh": {BaseURL: server.URL, RateLimit: 100 * time.Millisecond},
+		},
+	}
+	sources, err := DefaultRegistry().FromConfig(cfg, server.Client())
+	if err != nil {
+		t.Fatalf("FromConfig() error = %v", err)
+	}
+	if len(sources) != 2 || sources[0].Name() != "crtsh" || sources[1].Name() != "file" {
+		t.Fatalf("FromConfig() = %v, want crtsh and file", sources)
+	}
+	for i := 0; i < 2; i++ {
+		collect(t, sources[0])
+	}
+	if len(requests) != 2 || requests[1].Sub(requests[0]) < 90*time.Millisecond {
+		t.Fatalf("requests at %v, want 2 spaced by the rate limit", requests)
+	}
+
+	if _, err := DefaultRegistry().FromConfig(config.SourcesConfig{Enabled: []string{"shodan"}}, nil); err == nil {
+		t.Fatal("FromConfig() accepted an unknown source")
+	}
+}
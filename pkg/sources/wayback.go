@@ -0,0 +1,48 @@
+package sources
+
+import (
+	"bufio"
+	"context"
+	"net/url"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/pkg/models"
+)
+
+// Wayback lists the hosts of URLs archived by the Wayback Machine.
+type Wayback struct {
+	httpSource
+}
+
+// NewWayback creates a Wayback Machine CDX source.
+func NewWayback(opts Options) Source {
+	return &Wayback{newHTTPSource("wayback", "https://web.archive.org", opts)}
+}
+
+// Enumerate sends the hosts of archived URLs under domain.
+func (s *Wayback) Enumerate(ctx context.Context, domain string, out chan<- models.Domain) error {
+	query := url.Values{
+		"url":      {"*." + domain + "/*"},
+		"output":   {"txt"},
+		"fl":       {"original"},
+		"collapse": {"urlkey"},
+	}
+	body, err := s.get(ctx, s.baseURL+"/cdx/search/cdx?"+query.Encode(), nil)
+	if err != nil || body == nil {
+		return err
+	}
+	defer body.Close()
+
+	e := newEmitter(s.name, domain, out)
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if err := e.emit(ctx, scanner.Text()); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return errors.NewNetworkError("read wayback response", err)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package takeover
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+)
+
+//go:embed fingerprints.json
+var builtinFingerprints []byte
+
+// Fingerprint describes a service whose abandoned resources can be
+// claimed by whoever registers them again. A name is vulnerable when its
+// CNAME chain passes through one of CNAME and either the chain's target
+// does not resolve (NXDomain) or the HTTP response matches Status and
+// Body.
+type Fingerprint struct {
+	Service  string   `json:"service"`
+	CNAME    []string `json:"cname"`              // domains the chain must pass through
+	Body     []string `json:"body,omitempty"`     // any of these in the response body
+	Status   int      `json:"status,omitempty"`   // response status; zero matches any
+	NXDomain bool     `json:"nxdomain,omitempty"` // vulnerable when the target is NXDOMAIN
+}
+
+// matchesCNAME returns the first host in chain under one of f's domains.
+func (f Fingerprint) matchesCNAME(chain []string) (string, bool) {
+	for _, host := range chain {
+		host = "." + strings.ToLower(strings.TrimSuffix(host, ".")) + "."
+		for _, domain := range f.CNAME {
+			if strings.Contains(host, "."+strings.ToLower(domain)+".") {
+				return strings.Trim(host, "."), true
+			}
+		}
+	}
+	return "", false
+}
+
+// matchesHTTP reports whether a response with status and body matches,
+// and which body pattern it contained.
+func (f Fingerprint) matchesHTTP(status int, body string) (string, bool) {
+	if f.Status != 0 && status != f.Status {
+		return "", false
+	}
+	if len(f.Body) == 0 {
+		return "", f.Status != 0
+	}
+	for _, pattern := range f.Body {
+		if strings.Contains(body, pattern) {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+func (f Fingerprint) validate() error {
+	switch {
+	case f.Service == "":
+		return fmt.Errorf("fingerprint without a service name")
+	case len(f.CNAME) == 0:
+		return fmt.Errorf("fingerprint %s has no cname domains", f.Service)
+	case !f.NXDomain && len(f.Body) == 0 && f.Status == 0:
+		return fmt.Errorf("fingerprint %s needs nxdomain, body or status", f.Service)
+	}
+	return nil
+}
+
+// DefaultFingerprints returns the built-in fingerprints.
+func DefaultFingerprints() []Fingerprint {
+	fingerprints, err := parseFingerprints(builtinFingerprints)
+	if err != nil {
+		panic("takeover: built-in fingerprints: " + err.Error())
+	}
+	return fingerprints
+}
+
+// LoadFingerprints returns the built-in fingerprints extended by the JSON
+// file at path. Entries naming a built-in service replace it. An empty
+// path returns the built-ins.
+func LoadFingerprints(path string) ([]Fingerprint, error) {
+	fingerprints := DefaultFingerprints()
+	if path == "" {
+		return fingerprints, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.NewIOError("read takeover fingerprints", err)
+	}
+	extra, err := parseFingerprints(data)
+	if err != nil {
+		return nil, errors.NewValidationError(fmt.Sprintf("%s: %v", path, err))
+	}
+	index := map[string]int{}
+	for i, f := range fingerprints {
+		index[strings.ToLower(f.Service)] = i
+	}
+	for _, f := range extra {
+		if i, ok := index[strings.ToLower(f.Service)]; ok {
+			fingerprints[i] = f
+			continue
+		}
+		fingerprints = append(fingerprints, f)
+	}
+	return fingerprints, nil
+}
+
+func parseFingerprints(data []byte) ([]Fingerprint, error) {
+	var fingerprints []Fingerprint
+	if err := json.Unmarshal(data, &fingerprints); err != nil {
+		return nil, err
+	}
+	for _, f := range fingerprints {
+		if err := f.validate(); err != nil {
+			return nil, err
+		}
+	}
+	return fingerprints, nil
+}
@@ -0,0 +1,209 @@
+package takeover
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/internal/registry"
+	"github.com/NASHEDIxCODER/gospyder/pkg/resolver"
+)
+
+// ModuleAdapter checks the target and enum's subdomains for dangling
+// CNAMEs to claimable services.
+type ModuleAdapter struct{}
+
+// NewModule creates a new subdomain takeover module
+func NewModule() registry.Module {
+	return &ModuleAdapter{}
+}
+
+// Name returns the module name
+func (m *ModuleAdapter) Name() string {
+	return "takeover"
+}
+
+// Description returns the module description
+func (m *ModuleAdapter) Description() string {
+	return "Subdomain takeover detection from CNAME chains, NXDOMAIN targets and service fingerprints"
+}
+
+// Run checks the target, the subdomains of a prior enum run and any names
+// in the "list" file.
+func (m *ModuleAdapter) Run(ctx context.Context, opts registry.Options) (*registry.Result, error) {
+	target, ok := opts.Flags["target"].(string)
+	if !ok || target == "" {
+		return nil, fmt.Errorf("target flag required")
+	}
+	apex := strings.ToLower(strings.TrimSuffix(target, "."))
+
+	path, _ := opts.Flags["fingerprints"].(string)
+	fingerprints, err := LoadFingerprints(path)
+	if err != nil {
+		return nil, err
+	}
+	list, _ := opts.Flags["list"].(string)
+	names, err := checkNames(apex, list, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := resolver.NewPoolFromConfig(ctx, opts.Config.DNS, opts.Telemetry, opts.Replay)
+	if err != nil {
+		return nil, err
+	}
+	checker := &Checker{Pool: pool, Client: opts.HTTPClient, Fingerprints: fingerprints}
+
+	opts.Logger.Info("Checking %d name(s) against %d takeover fingerprints", len(names), len(fingerprints))
+	results := checkAll(ctx, checker, names, opts.Config.Threads, opts.ProgressReporter(), opts.Errors)
+
+	findings := make([]registry.Finding, 0, len(results))
+	for _, r := range results {
+		if finding, ok := resultFinding(r); ok {
+			opts.Logger.Warn("%s: %s", r.Name, finding.Description)
+			findings = append(findings, finding)
+		}
+	}
+
+	return &registry.Result{
+		Module:    m.Name(),
+		Timestamp: time.Now(),
+		Status:    "success",
+		Target:    target,
+		Findings:  findings,
+		Metadata: map[string]interface{}{
+			"names_checked": len(names),
+			"cnames":        len(results),
+			"fingerprints":  len(fingerprints),
+		},
+	}, nil
+}
+
+// checkNames returns the apex, enum's subdomains and the names in list,
+// sorted and without duplicates.
+func checkNames(apex, list string, opts registry.Options) ([]string, error) {
+	seen := map[string]bool{apex: true}
+	var names []string
+	add := func(name string) {
+		name = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	results, _ := opts.Flags["results"].(map[string]*registry.Result)
+	if enum := results["enum"]; enum != nil {
+		for _, finding := range enum.Findings {
+			add(finding.Value)
+		}
+	}
+	if list != "" {
+		file, err := os.Open(list)
+		if err != nil {
+			return nil, errors.NewIOError("open name list", err)
+		}
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			if line := scanner.Text(); !strings.HasPrefix(strings.TrimSpace(line), "#") {
+				add(line)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, errors.NewIOError("read name list", err)
+		}
+	}
+	sort.Strings(names)
+	return append([]string{apex}, names...), nil
+}
+
+// checkAll checks names concurrently and returns the results of those
+// with a CNAME, in name order.
+func checkAll(ctx context.Context, checker *Checker, names []string, threads int, progress registry.Progress, errs *errors.Collector) []*Result {
+	if threads <= 0 {
+		threads = 10
+	}
+	failures := errors.NewTally("takeover checks")
+	defer failures.FlushTo(errs)
+	progress.AddTotal(int64(len(names)))
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, threads)
+	results := map[string]*Result{}
+	for _, name := range names {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			defer progress.Increment(1)
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-sem }()
+
+			result, err := checker.Check(ctx, name)
+			if err != nil && ctx.Err() == nil {
+				progress.AddError()
+				failures.Add(err)
+			}
+			if result == nil {
+				return
+			}
+			if result.Vulnerable() || result.Dangling {
+				progress.AddFinding()
+			}
+			mu.Lock()
+			results[name] = result
+			mu.Unlock()
+		}(name)
+	}
+	wg.Wait()
+
+	ordered := make([]*Result, 0, len(results))
+	for _, name := range names {
+		if r := results[name]; r != nil {
+			ordered = append(ordered, r)
+		}
+	}
+	return ordered
+}
+
+// resultFinding reports a fingerprint match as high severity and a CNAME
+// to a missing target that no fingerprint covers as medium.
+func resultFinding(r *Result) (registry.Finding, bool) {
+	metadata := map[string]interface{}{
+		"cname_chain": r.CNAMEs,
+		"target":      r.CNAMEs[len(r.CNAMEs)-1],
+		"nxdomain":    r.Dangling,
+	}
+	switch {
+	case r.Vulnerable():
+		metadata["service"] = r.Service
+		return registry.Finding{
+			Type:        "takeover",
+			Value:       r.Name,
+			Description: "Possible subdomain takeover via " + r.Service,
+			Severity:    "high",
+			Evidence:    r.Evidence,
+			Metadata:    metadata,
+		}, true
+	case r.Dangling:
+		return registry.Finding{
+			Type:        "dangling_cname",
+			Value:       r.Name,
+			Description: "CNAME target " + r.CNAMEs[len(r.CNAMEs)-1] + " does not exist",
+			Severity:    "medium",
+			Evidence:    r.Evidence,
+			Metadata:    metadata,
+		}, true
+	}
+	return registry.Finding{}, false
+}
@@ -0,0 +1,161 @@
+package takeover
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/pkg/resolver"
+)
+
+const (
+	// maxChain bounds the CNAME hops followed per name.
+	maxChain = 10
+	// maxBody bounds the response body read for fingerprinting.
+	maxBody = 512 << 10
+)
+
+// Result is the outcome of checking one name.
+type Result struct {
+	Name     string
+	CNAMEs   []string // chain from Name to its final target
+	Dangling bool     // the final target does not exist
+	Service  string   // matched fingerprint's service; empty when none matched
+	Evidence []string
+}
+
+// Vulnerable reports whether a fingerprint matched.
+func (r *Result) Vulnerable() bool {
+	return r.Service != ""
+}
+
+// Checker matches the CNAME chains of names against fingerprints.
+type Checker struct {
+	Pool         *resolver.Pool
+	Client       *http.Client // nil uses http.DefaultClient
+	Fingerprints []Fingerprint
+}
+
+// Check follows the CNAME chain of name and tests it against each
+// fingerprint whose domains it passes through. Names without a CNAME
+// return a nil result.
+func (c *Checker) Check(ctx context.Context, name string) (*Result, error) {
+	chain, dangling, err := c.chain(ctx, name)
+	if err != nil || len(chain) == 0 {
+		return nil, err
+	}
+	result := &Result{Name: name, CNAMEs: chain, Dangling: dangling}
+	result.Evidence = append(result.Evidence, "CNAME chain: "+strings.Join(append([]string{name}, chain...), " -> "))
+	if dangling {
+		result.Evidence = append(result.Evidence, chain[len(chain)-1]+": NXDOMAIN")
+	}
+
+	var page *response
+	for _, f := range c.Fingerprints {
+		host, ok := f.matchesCNAME(chain)
+		if !ok {
+			continue
+		}
+		if f.NXDomain {
+			if dangling {
+				result.Service = f.Service
+				result.Evidence = append(result.Evidence, "CNAME points at "+f.Service+" ("+host+")")
+				return result, nil
+			}
+			continue
+		}
+		if dangling {
+			continue
+		}
+		if page == nil {
+			if page, err = c.fetch(ctx, name); err != nil {
+				return result, err
+			}
+		}
+		if pattern, ok := f.matchesHTTP(page.status, page.body); ok {
+			result.Service = f.Service
+			result.Evidence = append(result.Evidence, fmt.Sprintf("CNAME points at %s (%s)", f.Service, host), fmt.Sprintf("%s returned HTTP %d", page.url, page.status))
+			if pattern != "" {
+				result.Evidence = append(result.Evidence, fmt.Sprintf("Body contains %q", pattern))
+			}
+			return result, nil
+		}
+	}
+	return result, nil
+}
+
+// chain follows CNAME records from name. dangling reports that the last
+// target does not exist.
+func (c *Checker) chain(ctx context.Context, name string) (chain []string, dangling bool, err error) {
+	current := name
+	for len(chain) < maxChain {
+		records, err := c.Pool.Query(ctx, current, resolver.TypeCNAME)
+		if err != nil {
+			if errors.TypeOf(err) == errors.ErrorTypeNotFound {
+				return chain, len(chain) > 0, nil
+			}
+			return chain, false, err
+		}
+		next := ""
+		for _, r := range records {
+			if r.Type == resolver.TypeCNAME && strings.EqualFold(r.Name, current) {
+				next = strings.ToLower(r.Value)
+				break
+			}
+		}
+		if next == "" {
+			break
+		}
+		chain = append(chain, next)
+		current = next
+	}
+	if len(chain) == 0 {
+		return nil, false, nil
+	}
+	// The last hop may exist without a CNAME yet have no records at all.
+	if _, err := c.Pool.Query(ctx, current, resolver.TypeA); err != nil {
+		if errors.TypeOf(err) == errors.ErrorTypeNotFound {
+			return chain, true, nil
+		}
+		return chain, false, err
+	}
+	return chain, false, nil
+}
+
+type response struct {
+	url    string
+	status int
+	body   string
+}
+
+// fetch requests name over HTTP, then HTTPS when HTTP fails.
+func (c *Checker) fetch(ctx context.Context, name string) (*response, error) {
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	var lastErr error
+	for _, scheme := range []string{"http", "https"} {
+		url := scheme + "://" + name + "/"
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxBody))
+		resp.Body.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return &response{url: url, status: resp.StatusCode, body: string(body)}, nil
+	}
+	return nil, errors.NewNetworkError("fetch "+name, lastErr)
+}
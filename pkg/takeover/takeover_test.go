@@ -0,0 +1,119 @@
+package takeover
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/config"
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/internal/logger"
+	"github.com/NASHEDIxCODER/gospyder/internal/registry"
+	"github.com/NASHEDIxCODER/gospyder/tests/mocks"
+)
+
+func TestModuleReportsTakeoverCandidates(t *testing.T) {
+	dns := mocks.NewDNSServer(t,
+		"blog.example.com 300 CNAME example.github.io",
+		"example.github.io 300 A 127.0.0.1",
+		"www.example.com 300 CNAME live.github.io",
+		"live.github.io 300 A 127.0.0.1",
+		"old.example.com 300 CNAME gone.cloudapp.net",
+		"stale.example.com 300 CNAME missing.example.net",
+		"app.example.com 300 CNAME app.example.org",
+		"app.example.org 300 A 127.0.0.1",
+		"api.example.com 300 A 192.0.2.1",
+	)
+	// Every host is served here, as GitHub Pages and a custom service.
+	web := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Host {
+		case "blog.example.com":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("<h1>404</h1><p>There isn't a GitHub Pages site here.</p>"))
+		case "app.example.com":
+			w.Write([]byte("Unclaimed: this app is free"))
+		default:
+			w.Write([]byte("hello"))
+		}
+	}))
+	defer web.Close()
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, web.Listener.Addr().String())
+		},
+	}}
+
+	// A user fingerprint adds a service alongside the built-ins.
+	extra := filepath.Join(t.TempDir(), "fingerprints.json")
+	if err := os.WriteFile(extra, []byte(`[{"service":"Example Apps","cname":["example.org"],"body":["Unclaimed:"]}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	list := filepath.Join(t.TempDir(), "names.txt")
+	if err := os.WriteFile(list, []byte("www.example.com\nold.example.com\nstale.example.com\napp.example.com\napi.example.com\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Threads = 4
+	cfg.DNS.Resolvers = []string{dns.Addr}
+	opts := registry.Options{
+		Config:     cfg,
+		Logger:     logger.New(false),
+		HTTPClient: client,
+		Errors:     errors.NewCollector(),
+		Flags: map[string]interface{}{
+			"target":       "example.com",
+			"list":         list,
+			"fingerprints": extra,
+			"results": map[string]*registry.Result{"enum": {Findings: []registry.Finding{
+				{Type: "subdomain", Value: "blog.example.com"},
+			}}},
+		},
+	}
+	result, err := NewModule().Run(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	got := map[string]registry.Finding{}
+	for _, f := range result.Findings {
+		got[f.Value] = f
+	}
+	want := map[string]struct{ kind, service string }{
+		"blog.example.com":  {"takeover", "GitHub Pages"},
+		"old.example.com":   {"takeover", "Microsoft Azure"},
+		"app.example.com":   {"takeover", "Example Apps"},
+		"stale.example.com": {"dangling_cname", ""},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("findings = %+v, want %d", result.Findings, len(want))
+	}
+	for name, w := range want {
+		f, ok := got[name]
+		if !ok || f.Type != w.kind || (w.service != "" && f.Metadata["service"] != w.service) {
+			t.Errorf("%s: finding = %+v, want %s %s", name, f, w.kind, w.service)
+		}
+	}
+	blog := got["blog.example.com"]
+	if blog.Severity != "high" || !strings.Contains(strings.Join(blog.Evidence, "\n"), "blog.example.com -> example.github.io") {
+		t.Errorf("blog evidence = %v", blog.Evidence)
+	}
+	if n := result.Metadata["cnames"]; n != 5 {
+		t.Errorf("cnames = %v, want 5", n)
+	}
+}
+
+func TestLoadFingerprintsRejectsIncompleteEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fingerprints.json")
+	if err := os.WriteFile(path, []byte(`[{"service":"Vague","cname":["example.net"]}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFingerprints(path); err == nil {
+		t.Fatal("LoadFingerprints() accepted a fingerprint without a condition")
+	}
+}
@@ -0,0 +1,131 @@
+package tlsscan
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Issue is a weakness in a TLS service's setup.
+type Issue struct {
+	Kind        string // finding type, e.g. "tls_expired"
+	Severity    string
+	Description string
+}
+
+// Check lists the weaknesses of r as of now: an expired, not yet valid,
+// soon expiring, self-signed, untrusted or mismatched leaf certificate,
+// weak keys or signatures in the chain, deprecated protocol versions and
+// weak cipher suites. Certificates expiring within warn are reported.
+func Check(r *Report, now time.Time, warn time.Duration) []Issue {
+	var issues []Issue
+	add := func(kind, severity, format string, args ...interface{}) {
+		issues = append(issues, Issue{Kind: kind, Severity: severity, Description: fmt.Sprintf(format, args...)})
+	}
+
+	if len(r.Chain) > 0 {
+		leaf := r.Chain[0]
+		switch {
+		case now.After(leaf.NotAfter):
+			add("tls_expired", "high", "Certificate expired on %s", leaf.NotAfter.Format("2006-01-02"))
+		case now.Before(leaf.NotBefore):
+			add("tls_not_yet_valid", "medium", "Certificate is not valid until %s", leaf.NotBefore.Format("2006-01-02"))
+		case leaf.NotAfter.Sub(now) < warn:
+			add("tls_expiring", "low", "Certificate expires in %d days, on %s", int(leaf.NotAfter.Sub(now).Hours()/24), leaf.NotAfter.Format("2006-01-02"))
+		}
+		switch {
+		case leaf.SelfSigned:
+			add("tls_self_signed", "medium", "Self-signed certificate for %s", leaf.Subject)
+		case !r.Trusted:
+			add("tls_untrusted", "medium", "Certificate chain is not trusted: %s", r.TrustError)
+		}
+		if r.ServerName != "" && leaf.cert != nil {
+			if err := leaf.cert.VerifyHostname(r.ServerName); err != nil {
+				add("tls_mismatch", "medium", "Certificate is not valid for %s (covers %s)", r.ServerName, coverage(leaf))
+			}
+		}
+		for i, cert := range r.Chain {
+			if weak := keyWeakness(cert); weak != "" {
+				add("tls_weak_key", "medium", "%s certificate %s has a %s", position(i), cert.Subject, weak)
+			}
+			// A root's signature on itself is never checked.
+			if !cert.SelfSigned && weakSignature(cert) {
+				add("tls_weak_signature", "medium", "%s certificate %s is signed with %s", position(i), cert.Subject, cert.Signature)
+			}
+		}
+	}
+
+	for _, version := range r.Versions {
+		switch version {
+		case "SSLv3":
+			add("tls_weak_protocol", "high", "SSLv3 is enabled (POODLE)")
+		case "TLS 1.0", "TLS 1.1":
+			add("tls_weak_protocol", "medium", "%s is enabled (deprecated by RFC 8996)", version)
+		}
+	}
+
+	severity := ""
+	var weak []string
+	seen := map[string]bool{}
+	for _, version := range r.Versions {
+		for _, name := range r.Ciphers[version] {
+			reason := CipherWeakness(name)
+			if reason == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			weak = append(weak, name+" ("+reason+")")
+			if severity == "" {
+				severity = "medium"
+			}
+			if strings.Contains(name, "_NULL_") || strings.Contains(name, "EXPORT") || strings.Contains(name, "_anon_") {
+				severity = "high"
+			}
+		}
+	}
+	if len(weak) > 0 {
+		add("tls_weak_cipher", severity, "Weak cipher suites accepted: %s", strings.Join(weak, ", "))
+	}
+	return issues
+}
+
+// keyWeakness describes a key too short to trust, or returns "".
+func keyWeakness(c Certificate) string {
+	switch {
+	case c.KeyType == "RSA" && c.KeyBits < 2048:
+		return fmt.Sprintf("%d-bit RSA key", c.KeyBits)
+	case c.KeyType == "ECDSA" && c.KeyBits < 256:
+		return fmt.Sprintf("%d-bit ECDSA key", c.KeyBits)
+	case c.KeyType == "DSA":
+		return "DSA key"
+	}
+	return ""
+}
+
+// weakSignature reports an MD2, MD5 or SHA-1 signature.
+func weakSignature(c Certificate) bool {
+	for _, hash := range []string{"MD2", "MD5", "SHA1"} {
+		if strings.Contains(c.Signature, hash) {
+			return true
+		}
+	}
+	return false
+}
+
+// coverage lists the names a certificate is valid for.
+func coverage(c Certificate) string {
+	if len(c.SANs) > 0 {
+		return strings.Join(c.SANs, ", ")
+	}
+	if c.cert != nil && c.cert.Subject.CommonName != "" {
+		return c.cert.Subject.CommonName + ", by common name only"
+	}
+	return "no names"
+}
+
+func position(i int) string {
+	if i == 0 {
+		return "Leaf"
+	}
+	return "Chain"
+}
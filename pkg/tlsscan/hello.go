@@ -0,0 +1,392 @@
+package tlsscan
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// versionSSL30 is SSL 3.0, which crypto/tls no longer speaks but servers
+// still may.
+const versionSSL30 = 0x0300
+
+// probedVersions are the protocol versions enumerated, newest first.
+var probedVersions = []uint16{tls.VersionTLS13, tls.VersionTLS12, tls.VersionTLS11, tls.VersionTLS10, versionSSL30}
+
+const (
+	extServerName          = 0x0000
+	extSupportedGroups     = 0x000a
+	extECPointFormats      = 0x000b
+	extSignatureAlgorithms = 0x000d
+	extALPN                = 0x0010
+	extExtendedMaster      = 0x0017
+	extSupportedVersions   = 0x002b
+	extPSKModes            = 0x002d
+	extKeyShare            = 0x0033
+	extRenegotiationInfo   = 0xff01
+)
+
+// errAlert is returned when the server answers a hello with an alert,
+// its way of declining every version or cipher suite offered.
+var errAlert = errors.New("server sent a TLS alert")
+
+// errNotTLS is returned when the reply is not a TLS handshake.
+var errNotTLS = errors.New("reply is not a TLS handshake")
+
+// cipherSuite names a cipher suite by its IANA registry name.
+type cipherSuite struct {
+	id   uint16
+	name string
+}
+
+// cipherSuites lists every suite offered during enumeration: all those
+// JARM offers plus the NULL, export, anonymous and DES suites old
+// servers still accept.
+var cipherSuites = []cipherSuite{
+	{0x0001, "TLS_RSA_WITH_NULL_MD5"},
+	{0x0002, "TLS_RSA_WITH_NULL_SHA"},
+	{0x0003, "TLS_RSA_EXPORT_WITH_RC4_40_MD5"},
+	{0x0004, "TLS_RSA_WITH_RC4_128_MD5"},
+	{0x0005, "TLS_RSA_WITH_RC4_128_SHA"},
+	{0x0006, "TLS_RSA_EXPORT_WITH_RC2_CBC_40_MD5"},
+	{0x0007, "TLS_RSA_WITH_IDEA_CBC_SHA"},
+	{0x0008, "TLS_RSA_EXPORT_WITH_DES40_CBC_SHA"},
+	{0x0009, "TLS_RSA_WITH_DES_CBC_SHA"},
+	{0x000a, "TLS_RSA_WITH_3DES_EDE_CBC_SHA"},
+	{0x0014, "TLS_DHE_RSA_EXPORT_WITH_DES40_CBC_SHA"},
+	{0x0015, "TLS_DHE_RSA_WITH_DES_CBC_SHA"},
+	{0x0016, "TLS_DHE_RSA_WITH_3DES_EDE_CBC_SHA"},
+	{0x0018, "TLS_DH_anon_WITH_RC4_128_MD5"},
+	{0x001b, "TLS_DH_anon_WITH_3DES_EDE_CBC_SHA"},
+	{0x002f, "TLS_RSA_WITH_AES_128_CBC_SHA"},
+	{0x0033, "TLS_DHE_RSA_WITH_AES_128_CBC_SHA"},
+	{0x0034, "TLS_DH_anon_WITH_AES_128_CBC_SHA"},
+	{0x0035, "TLS_RSA_WITH_AES_256_CBC_SHA"},
+	{0x0039, "TLS_DHE_RSA_WITH_AES_256_CBC_SHA"},
+	{0x003a, "TLS_DH_anon_WITH_AES_256_CBC_SHA"},
+	{0x003b, "TLS_RSA_WITH_NULL_SHA256"},
+	{0x003c, "TLS_RSA_WITH_AES_128_CBC_SHA256"},
+	{0x003d, "TLS_RSA_WITH_AES_256_CBC_SHA256"},
+	{0x0041, "TLS_RSA_WITH_CAMELLIA_128_CBC_SHA"},
+	{0x0045, "TLS_DHE_RSA_WITH_CAMELLIA_128_CBC_SHA"},
+	{0x0067, "TLS_DHE_RSA_WITH_AES_128_CBC_SHA256"},
+	{0x006b, "TLS_DHE_RSA_WITH_AES_256_CBC_SHA256"},
+	{0x0084, "TLS_RSA_WITH_CAMELLIA_256_CBC_SHA"},
+	{0x0088, "TLS_DHE_RSA_WITH_CAMELLIA_256_CBC_SHA"},
+	{0x009a, "TLS_DHE_RSA_WITH_SEED_CBC_SHA"},
+	{0x009c, "TLS_RSA_WITH_AES_128_GCM_SHA256"},
+	{0x009d, "TLS_RSA_WITH_AES_256_GCM_SHA384"},
+	{0x009e, "TLS_DHE_RSA_WITH_AES_128_GCM_SHA256"},
+	{0x009f, "TLS_DHE_RSA_WITH_AES_256_GCM_SHA384"},
+	{0x00ba, "TLS_RSA_WITH_CAMELLIA_128_CBC_SHA256"},
+	{0x00be, "TLS_DHE_RSA_WITH_CAMELLIA_128_CBC_SHA256"},
+	{0x00c0, "TLS_RSA_WITH_CAMELLIA_256_CBC_SHA256"},
+	{0x00c4, "TLS_DHE_RSA_WITH_CAMELLIA_256_CBC_SHA256"},
+	{0x1301, "TLS_AES_128_GCM_SHA256"},
+	{0x1302, "TLS_AES_256_GCM_SHA384"},
+	{0x1303, "TLS_CHACHA20_POLY1305_SHA256"},
+	{0x1304, "TLS_AES_128_CCM_SHA256"},
+	{0x1305, "TLS_AES_128_CCM_8_SHA256"},
+	{0xc006, "TLS_ECDHE_ECDSA_WITH_NULL_SHA"},
+	{0xc007, "TLS_ECDHE_ECDSA_WITH_RC4_128_SHA"},
+	{0xc008, "TLS_ECDHE_ECDSA_WITH_3DES_EDE_CBC_SHA"},
+	{0xc009, "TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA"},
+	{0xc00a, "TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA"},
+	{0xc010, "TLS_ECDHE_RSA_WITH_NULL_SHA"},
+	{0xc011, "TLS_ECDHE_RSA_WITH_RC4_128_SHA"},
+	{0xc012, "TLS_ECDHE_RSA_WITH_3DES_EDE_CBC_SHA"},
+	{0xc013, "TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA"},
+	{0xc014, "TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA"},
+	{0xc016, "TLS_ECDH_anon_WITH_RC4_128_SHA"},
+	{0xc018, "TLS_ECDH_anon_WITH_AES_128_CBC_SHA"},
+	{0xc019, "TLS_ECDH_anon_WITH_AES_256_CBC_SHA"},
+	{0xc023, "TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA256"},
+	{0xc024, "TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA384"},
+	{0xc027, "TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256"},
+	{0xc028, "TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA384"},
+	{0xc02b, "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"},
+	{0xc02c, "TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384"},
+	{0xc02f, "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+	{0xc030, "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"},
+	{0xc060, "TLS_ECDHE_RSA_WITH_ARIA_128_GCM_SHA256"},
+	{0xc061, "TLS_ECDHE_RSA_WITH_ARIA_256_GCM_SHA384"},
+	{0xc072, "TLS_ECDHE_ECDSA_WITH_CAMELLIA_128_CBC_SHA256"},
+	{0xc073, "TLS_ECDHE_ECDSA_WITH_CAMELLIA_256_CBC_SHA384"},
+	{0xc076, "TLS_ECDHE_RSA_WITH_CAMELLIA_128_CBC_SHA256"},
+	{0xc077, "TLS_ECDHE_RSA_WITH_CAMELLIA_256_CBC_SHA384"},
+	{0xc09c, "TLS_RSA_WITH_AES_128_CCM"},
+	{0xc09d, "TLS_RSA_WITH_AES_256_CCM"},
+	{0xc09e, "TLS_DHE_RSA_WITH_AES_128_CCM"},
+	{0xc09f, "TLS_DHE_RSA_WITH_AES_256_CCM"},
+	{0xc0a0, "TLS_RSA_WITH_AES_128_CCM_8"},
+	{0xc0a1, "TLS_RSA_WITH_AES_256_CCM_8"},
+	{0xc0a2, "TLS_DHE_RSA_WITH_AES_128_CCM_8"},
+	{0xc0a3, "TLS_DHE_RSA_WITH_AES_256_CCM_8"},
+	{0xc0ac, "TLS_ECDHE_ECDSA_WITH_AES_128_CCM"},
+	{0xc0ad, "TLS_ECDHE_ECDSA_WITH_AES_256_CCM"},
+	{0xc0ae, "TLS_ECDHE_ECDSA_WITH_AES_128_CCM_8"},
+	{0xc0af, "TLS_ECDHE_ECDSA_WITH_AES_256_CCM_8"},
+	{0xcc13, "TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256_OLD"},
+	{0xcc14, "TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256_OLD"},
+	{0xcca8, "TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256"},
+	{0xcca9, "TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256"},
+}
+
+// CipherName returns the IANA name of a cipher suite, or its number in
+// hex when unknown.
+func CipherName(id uint16) string {
+	for _, suite := range cipherSuites {
+		if suite.id == id {
+			return suite.name
+		}
+	}
+	return fmt.Sprintf("0x%04X", id)
+}
+
+// VersionName returns the conventional name of a protocol version.
+func VersionName(version uint16) string {
+	if version == versionSSL30 {
+		return "SSLv3"
+	}
+	return tls.VersionName(version)
+}
+
+// CipherWeakness returns why a cipher suite is weak, or "" when it is
+// not: no encryption, export-grade keys, no authentication, or a broken
+// cipher or MAC.
+func CipherWeakness(name string) string {
+	switch {
+	case strings.Contains(name, "_NULL_"):
+		return "no encryption"
+	case strings.Contains(name, "EXPORT"):
+		return "export-grade key"
+	case strings.Contains(name, "_anon_"):
+		return "unauthenticated key exchange"
+	case strings.Contains(name, "_RC4_"):
+		return "RC4"
+	case strings.Contains(name, "_RC2_"):
+		return "RC2"
+	case strings.Contains(name, "_DES_"), strings.Contains(name, "_DES40_"):
+		return "DES"
+	case strings.Contains(name, "_3DES_"):
+		return "3DES (Sweet32)"
+	case strings.HasSuffix(name, "_MD5"):
+		return "MD5 MAC"
+	}
+	return ""
+}
+
+// suitesFor returns the cipher suites worth offering with version: the
+// TLS 1.3 suites for TLS 1.3 and all others below it.
+func suitesFor(version uint16) []uint16 {
+	var ids []uint16
+	for _, suite := range cipherSuites {
+		if (suite.id>>8 == 0x13) == (version == tls.VersionTLS13) {
+			ids = append(ids, suite.id)
+		}
+	}
+	return ids
+}
+
+// probeHello builds a ClientHello record offering only version and
+// ciphers, with the extensions a server needs to accept them.
+func probeHello(version uint16, ciphers []uint16, serverName string) []byte {
+	if version == versionSSL30 {
+		return clientHello(versionSSL30, versionSSL30, ciphers, nil)
+	}
+	var ext []byte
+	if serverName != "" {
+		ext = extension(ext, extServerName, sniBody(serverName))
+	}
+	ext = extension(ext, extSupportedGroups, vec16(nil, u16s(0x001d, 0x0017, 0x0018, 0x0019)))
+	ext = extension(ext, extECPointFormats, []byte{1, 0})
+	ext = extension(ext, extSignatureAlgorithms, vec16(nil, u16s(0x0403, 0x0804, 0x0401, 0x0503, 0x0805, 0x0501, 0x0806, 0x0601, 0x0201)))
+	ext = extension(ext, extRenegotiationInfo, []byte{0})
+	if version != tls.VersionTLS13 {
+		ext = extension(ext, extExtendedMaster, nil)
+		return clientHello(version, version, ciphers, ext)
+	}
+	ext = extension(ext, extSupportedVersions, vec8(nil, u16s(tls.VersionTLS13)))
+	ext = extension(ext, extKeyShare, vec16(nil, keyShareEntry(nil)))
+	ext = extension(ext, extPSKModes, []byte{1, 1})
+	return clientHello(tls.VersionTLS10, tls.VersionTLS12, ciphers, ext)
+}
+
+// clientHello wraps a ClientHello with a random nonce and session ID in a
+// handshake record. A nil ext sends no extensions block at all, as SSL
+// 3.0 clients did.
+func clientHello(recordVersion, helloVersion uint16, ciphers []uint16, ext []byte) []byte {
+	body := binary.BigEndian.AppendUint16(nil, helloVersion)
+	body = append(body, random(32)...)
+	body = vec8(body, random(32))
+	body = vec16(body, u16s(ciphers...))
+	body = append(body, 1, 0) // null compression only
+	if ext != nil {
+		body = vec16(body, ext)
+	}
+	handshake := append([]byte{1, byte(len(body) >> 16), byte(len(body) >> 8), byte(len(body))}, body...)
+	record := []byte{22}
+	record = binary.BigEndian.AppendUint16(record, recordVersion)
+	return vec16(record, handshake)
+}
+
+func sniBody(name string) []byte {
+	entry := vec16([]byte{0}, []byte(name))
+	return vec16(nil, entry)
+}
+
+// keyShareEntry appends an X25519 key share. Its key is random bytes: the
+// handshake never gets far enough to need the private half.
+func keyShareEntry(b []byte) []byte {
+	b = binary.BigEndian.AppendUint16(b, 0x001d)
+	return vec16(b, random(32))
+}
+
+func extension(b []byte, typ uint16, body []byte) []byte {
+	b = binary.BigEndian.AppendUint16(b, typ)
+	return vec16(b, body)
+}
+
+func vec16(b, body []byte) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(body)))
+	return append(b, body...)
+}
+
+func vec8(b, body []byte) []byte {
+	return append(append(b, byte(len(body))), body...)
+}
+
+func u16s(values ...uint16) []byte {
+	b := make([]byte, 0, 2*len(values))
+	for _, v := range values {
+		b = binary.BigEndian.AppendUint16(b, v)
+	}
+	return b
+}
+
+func random(n int) []byte {
+	b := make([]byte, n)
+	rand.Read(b)
+	return b
+}
+
+// serverHello holds the fields of a ServerHello that fingerprints and
+// enumeration use.
+type serverHello struct {
+	version    uint16 // legacy_version; TLS 1.3 puts the real one in an extension
+	cipher     uint16
+	extensions []helloExtension
+}
+
+type helloExtension struct {
+	typ  uint16
+	data []byte
+}
+
+// negotiated returns the protocol version the server chose.
+func (h *serverHello) negotiated() uint16 {
+	if data, ok := h.extension(extSupportedVersions); ok && len(data) == 2 {
+		return binary.BigEndian.Uint16(data)
+	}
+	return h.version
+}
+
+func (h *serverHello) extension(typ uint16) ([]byte, bool) {
+	for _, ext := range h.extensions {
+		if ext.typ == typ {
+			return ext.data, true
+		}
+	}
+	return nil, false
+}
+
+// alpn returns the protocol the server selected, if any.
+func (h *serverHello) alpn() string {
+	if data, ok := h.extension(extALPN); ok && len(data) > 3 {
+		return string(data[3:])
+	}
+	return ""
+}
+
+// maxRecord is the largest TLS record a server may send.
+const maxRecord = 1<<14 + 2048
+
+// readServerHello reads records from r until the ServerHello is complete.
+func readServerHello(r io.Reader) (*serverHello, error) {
+	var handshake []byte
+	header := make([]byte, 5)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil, err
+		}
+		length := int(binary.BigEndian.Uint16(header[3:]))
+		if header[1] != 3 || length > maxRecord {
+			return nil, errNotTLS
+		}
+		body := make([]byte, length)
+		if _, err := io.ReadFull(r, body); err != nil {
+			return nil, err
+		}
+		switch header[0] {
+		case 21:
+			return nil, errAlert
+		case 22:
+			handshake = append(handshake, body...)
+		default:
+			return nil, errNotTLS
+		}
+		if len(handshake) < 4 {
+			continue
+		}
+		if handshake[0] != 2 {
+			return nil, errNotTLS
+		}
+		size := int(handshake[1])<<16 | int(handshake[2])<<8 | int(handshake[3])
+		if len(handshake) >= 4+size {
+			return parseServerHello(handshake[4 : 4+size])
+		}
+	}
+}
+
+// parseServerHello parses the body of a ServerHello message.
+func parseServerHello(body []byte) (*serverHello, error) {
+	r := bytes.NewReader(body)
+	var fixed struct {
+		Version uint16
+		Random  [32]byte
+	}
+	if err := binary.Read(r, binary.BigEndian, &fixed); err != nil {
+		return nil, errNotTLS
+	}
+	sessionLen, err := r.ReadByte()
+	if err != nil || r.Len() < int(sessionLen)+3 {
+		return nil, errNotTLS
+	}
+	r.Seek(int64(sessionLen), io.SeekCurrent)
+	h := &serverHello{version: fixed.Version}
+	binary.Read(r, binary.BigEndian, &h.cipher)
+	r.ReadByte() // compression method
+
+	var extLen uint16
+	if binary.Read(r, binary.BigEndian, &extLen) != nil {
+		return h, nil // no extensions
+	}
+	if r.Len() < int(extLen) {
+		return nil, errNotTLS
+	}
+	for r.Len() >= 4 {
+		var ext struct{ Type, Len uint16 }
+		binary.Read(r, binary.BigEndian, &ext)
+		data := make([]byte, ext.Len)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, errNotTLS
+		}
+		h.extensions = append(h.extensions, helloExtension{typ: ext.Type, data: data})
+	}
+	return h, nil
+}
@@ -0,0 +1,299 @@
+package tlsscan
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/internal/replay"
+)
+
+// Inspector connects to TLS services and reports how they are set up.
+type Inspector struct {
+	Timeout time.Duration   // per connection; zero means 5s
+	Dial    replay.DialFunc // nil dials directly
+	// Ciphers enumerates every accepted cipher suite per version; without
+	// it only the versions are found, one handshake each.
+	Ciphers bool
+}
+
+// Report describes one TLS service.
+type Report struct {
+	Host       string              `json:"host"`
+	Port       int                 `json:"port"`
+	ServerName string              `json:"server_name,omitempty"` // SNI sent; empty for addresses
+	Version    string              `json:"version,omitempty"`     // negotiated by a default client
+	Cipher     string              `json:"cipher,omitempty"`
+	ALPN       string              `json:"alpn,omitempty"`
+	Chain      []Certificate       `json:"chain,omitempty"`
+	Trusted    bool                `json:"trusted"`
+	TrustError string              `json:"trust_error,omitempty"`
+	Versions   []string            `json:"versions"`          // newest first
+	Ciphers    map[string][]string `json:"ciphers,omitempty"` // by version, in the server's order of preference
+	JARM       string              `json:"jarm"`
+	JA3S       string              `json:"ja3s,omitempty"`
+	JA3SRaw    string              `json:"ja3s_raw,omitempty"`
+}
+
+// Certificate summarizes one certificate of the chain.
+type Certificate struct {
+	Subject    string    `json:"subject"`
+	Issuer     string    `json:"issuer"`
+	SANs       []string  `json:"sans,omitempty"`
+	NotBefore  time.Time `json:"not_before"`
+	NotAfter   time.Time `json:"not_after"`
+	KeyType    string    `json:"key_type"`
+	KeyBits    int       `json:"key_bits"`
+	Signature  string    `json:"signature"`
+	SelfSigned bool      `json:"self_signed"`
+	SHA256     string    `json:"sha256"`
+
+	cert *x509.Certificate
+}
+
+// Inspect handshakes with host:port and reports its certificate chain,
+// protocol versions, cipher suites and fingerprints. Names are sent as
+// SNI and checked against the certificate; addresses are not. It fails
+// only when nothing on the port speaks TLS.
+func (in *Inspector) Inspect(ctx context.Context, host string, port int) (*Report, error) {
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	report := &Report{Host: host, Port: port, Versions: []string{}}
+	if net.ParseIP(host) == nil {
+		report.ServerName = host
+	}
+
+	chainErr := in.chain(ctx, address, report)
+	versions, ciphers := in.enumerate(ctx, address, report.ServerName)
+	if chainErr != nil && len(versions) == 0 {
+		return nil, errors.NewNetworkError("TLS handshake with "+address, chainErr)
+	}
+	for _, v := range versions {
+		report.Versions = append(report.Versions, VersionName(v))
+	}
+	if in.Ciphers {
+		report.Ciphers = map[string][]string{}
+		for _, v := range versions {
+			for _, id := range ciphers[v] {
+				report.Ciphers[VersionName(v)] = append(report.Ciphers[VersionName(v)], CipherName(id))
+			}
+		}
+	}
+	report.JARM = in.jarm(ctx, address, host)
+	return report, nil
+}
+
+// chain handshakes as a default client would, filling in the negotiated
+// parameters, certificates and JA3S of report.
+func (in *Inspector) chain(ctx context.Context, address string, report *Report) error {
+	conn, err := in.dial(ctx, address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var suites []uint16
+	for _, suite := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		suites = append(suites, suite.ID)
+	}
+	recorder := &recordingConn{Conn: conn}
+	client := tls.Client(recorder, &tls.Config{
+		ServerName:         report.ServerName,
+		InsecureSkipVerify: true,
+		MinVersion:         tls.VersionTLS10,
+		CipherSuites:       suites,
+		NextProtos:         []string{"h2", "http/1.1"},
+	})
+	if err := client.HandshakeContext(ctx); err != nil {
+		return err
+	}
+	state := client.ConnectionState()
+	report.Version = VersionName(state.Version)
+	report.Cipher = CipherName(state.CipherSuite)
+	report.ALPN = state.NegotiatedProtocol
+	if hello, err := readServerHello(bytes.NewReader(recorder.read.Bytes())); err == nil {
+		report.JA3S, report.JA3SRaw = ja3s(hello)
+	}
+
+	for _, cert := range state.PeerCertificates {
+		report.Chain = append(report.Chain, describe(cert))
+	}
+	report.Trusted, report.TrustError = verify(state.PeerCertificates)
+	return nil
+}
+
+// verify checks the chain against the system roots, at a time the leaf
+// is valid so that expiry, reported on its own, does not hide the issuer.
+func verify(certs []*x509.Certificate) (bool, string) {
+	if len(certs) == 0 {
+		return false, "no certificate"
+	}
+	leaf := certs[0]
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	at := time.Now()
+	if at.After(leaf.NotAfter) || at.Before(leaf.NotBefore) {
+		at = leaf.NotAfter.Add(-time.Minute)
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{Intermediates: intermediates, CurrentTime: at}); err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
+
+// describe summarizes cert.
+func describe(cert *x509.Certificate) Certificate {
+	sum := sha256.Sum256(cert.Raw)
+	c := Certificate{
+		Subject:   cert.Subject.String(),
+		Issuer:    cert.Issuer.String(),
+		NotBefore: cert.NotBefore,
+		NotAfter:  cert.NotAfter,
+		Signature: cert.SignatureAlgorithm.String(),
+		SHA256:    hex.EncodeToString(sum[:]),
+		// CheckSignatureFrom would insist on a CA certificate.
+		SelfSigned: bytes.Equal(cert.RawIssuer, cert.RawSubject) &&
+			cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature) == nil,
+		cert: cert,
+	}
+	c.SANs = append(c.SANs, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		c.SANs = append(c.SANs, ip.String())
+	}
+	c.SANs = append(c.SANs, cert.EmailAddresses...)
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		c.KeyType, c.KeyBits = "RSA", key.N.BitLen()
+	case *ecdsa.PublicKey:
+		c.KeyType, c.KeyBits = "ECDSA", key.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		c.KeyType, c.KeyBits = "Ed25519", 256
+	default:
+		c.KeyType = cert.PublicKeyAlgorithm.String()
+	}
+	return c
+}
+
+// enumerate finds the versions the server accepts and, with Ciphers set,
+// the suites it accepts with each. Suites are found by offering all of
+// them and taking away the server's choice until it refuses the rest.
+func (in *Inspector) enumerate(ctx context.Context, address, serverName string) ([]uint16, map[uint16][]uint16) {
+	accepted := make([][]uint16, len(probedVersions))
+	var wg sync.WaitGroup
+	for i, version := range probedVersions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			offered := suitesFor(version)
+			for len(offered) > 0 && ctx.Err() == nil {
+				hello, err := in.exchange(ctx, address, probeHello(version, offered, serverName))
+				if err != nil || hello.negotiated() != version {
+					return
+				}
+				n := len(offered)
+				offered = remove(offered, hello.cipher)
+				if len(offered) == n {
+					return // chose a suite not offered
+				}
+				accepted[i] = append(accepted[i], hello.cipher)
+				if !in.Ciphers {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	var versions []uint16
+	ciphers := map[uint16][]uint16{}
+	for i, version := range probedVersions {
+		if len(accepted[i]) > 0 {
+			versions = append(versions, version)
+			ciphers[version] = accepted[i]
+		}
+	}
+	return versions, ciphers
+}
+
+// jarm sends the ten JARM probes and hashes the answers.
+func (in *Inspector) jarm(ctx context.Context, address, host string) string {
+	results := make([]string, len(jarmProbes))
+	var wg sync.WaitGroup
+	for i, probe := range jarmProbes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			hello, _ := in.exchange(ctx, address, probe.hello(host))
+			results[i] = jarmResult(hello)
+		}()
+	}
+	wg.Wait()
+	return jarmHash(results)
+}
+
+// exchange sends a ClientHello record on a new connection and reads the
+// ServerHello.
+func (in *Inspector) exchange(ctx context.Context, address string, hello []byte) (*serverHello, error) {
+	conn, err := in.dial(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if _, err := conn.Write(hello); err != nil {
+		return nil, err
+	}
+	return readServerHello(conn)
+}
+
+func (in *Inspector) dial(ctx context.Context, address string) (net.Conn, error) {
+	timeout := in.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	dial := in.Dial
+	if dial == nil {
+		dial = (&net.Dialer{Timeout: timeout}).DialContext
+	}
+	conn, err := dial(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+	return conn, nil
+}
+
+func remove(ids []uint16, id uint16) []uint16 {
+	out := make([]uint16, 0, len(ids))
+	for _, other := range ids {
+		if other != id {
+			out = append(out, other)
+		}
+	}
+	return out
+}
+
+// recordingConn keeps what the handshake reads, for the ServerHello.
+type recordingConn struct {
+	net.Conn
+	read bytes.Buffer
+}
+
+func (c *recordingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if c.read.Len() < maxRecord {
+		c.read.Write(p[:n])
+	}
+	return n, err
+}
@@ -0,0 +1,259 @@
+package tlsscan
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+)
+
+// order rearranges a JARM probe's cipher suites, ALPN protocols or
+// supported versions.
+type order int
+
+const (
+	forward order = iota
+	reverse
+	topHalf
+	bottomHalf
+	middleOut
+)
+
+// support is which supported_versions extension a JARM probe sends.
+type support int
+
+const (
+	supportNone support = iota
+	support12           // TLS 1.0 to 1.2
+	support13           // TLS 1.0 to 1.3
+)
+
+// jarmProbe is one of the ten ClientHellos JARM sends.
+type jarmProbe struct {
+	version  uint16
+	no13     bool // leave the TLS 1.3 suites out
+	ciphers  order
+	grease   bool
+	rareALPN bool // leave out http/1.1 and h2
+	support  support
+	extOrder order // order of the ALPN and supported_versions lists
+}
+
+// jarmProbes are the probes in the order the reference implementation
+// sends and hashes them.
+var jarmProbes = []jarmProbe{
+	{version: tls.VersionTLS12, ciphers: forward, support: support12, extOrder: reverse},
+	{version: tls.VersionTLS12, ciphers: reverse, support: support12, extOrder: forward},
+	{version: tls.VersionTLS12, ciphers: topHalf, extOrder: forward},
+	{version: tls.VersionTLS12, ciphers: bottomHalf, rareALPN: true, extOrder: forward},
+	{version: tls.VersionTLS12, ciphers: middleOut, grease: true, rareALPN: true, extOrder: reverse},
+	{version: tls.VersionTLS11, ciphers: forward, extOrder: forward},
+	{version: tls.VersionTLS13, ciphers: forward, support: support13, extOrder: reverse},
+	{version: tls.VersionTLS13, ciphers: reverse, support: support13, extOrder: forward},
+	{version: tls.VersionTLS13, no13: true, ciphers: forward, support: support13, extOrder: forward},
+	{version: tls.VersionTLS13, ciphers: middleOut, grease: true, support: support13, extOrder: reverse},
+}
+
+// jarmCiphers is the suite list JARM offers, in its order.
+var jarmCiphers = []uint16{
+	0x0016, 0x0033, 0x0067, 0xc09e, 0xc0a2, 0x009e, 0x0039, 0x006b, 0xc09f, 0xc0a3, 0x009f, 0x0045,
+	0x00be, 0x0088, 0x00c4, 0x009a, 0xc008, 0xc009, 0xc023, 0xc0ac, 0xc0ae, 0xc02b, 0xc00a, 0xc024,
+	0xc0ad, 0xc0af, 0xc02c, 0xc072, 0xc073, 0xcca9, 0x1302, 0x1301, 0xcc14, 0xc007, 0xc012, 0xc013,
+	0xc027, 0xc02f, 0xc014, 0xc028, 0xc030, 0xc060, 0xc061, 0xc076, 0xc077, 0xcca8, 0x1305, 0x1304,
+	0x1303, 0xcc13, 0xc011, 0x000a, 0x002f, 0x003c, 0xc09c, 0xc0a0, 0x009c, 0x0035, 0x003d, 0xc09d,
+	0xc0a1, 0x009d, 0x0041, 0x00ba, 0x0084, 0x00c0, 0x0007, 0x0004, 0x0005,
+}
+
+// jarmCipherIndex is the list the hash numbers selected suites by.
+var jarmCipherIndex = []uint16{
+	0x0004, 0x0005, 0x0007, 0x000a, 0x0016, 0x002f, 0x0033, 0x0035, 0x0039, 0x003c, 0x003d, 0x0041,
+	0x0045, 0x0067, 0x006b, 0x0084, 0x0088, 0x009a, 0x009c, 0x009d, 0x009e, 0x009f, 0x00ba, 0x00be,
+	0x00c0, 0x00c4, 0xc007, 0xc008, 0xc009, 0xc00a, 0xc011, 0xc012, 0xc013, 0xc014, 0xc023, 0xc024,
+	0xc027, 0xc028, 0xc02b, 0xc02c, 0xc02f, 0xc030, 0xc060, 0xc061, 0xc072, 0xc073, 0xc076, 0xc077,
+	0xc09c, 0xc09d, 0xc09e, 0xc09f, 0xc0a0, 0xc0a1, 0xc0a2, 0xc0a3, 0xc0ac, 0xc0ad, 0xc0ae, 0xc0af,
+	0xcc13, 0xcc14, 0xcca8, 0xcca9, 0x1301, 0x1302, 0x1303, 0x1304, 0x1305,
+}
+
+var (
+	jarmALPN     = []string{"http/0.9", "http/1.0", "http/1.1", "spdy/1", "spdy/2", "spdy/3", "h2", "h2c", "hq"}
+	jarmRareALPN = []string{"http/0.9", "http/1.0", "spdy/1", "spdy/2", "spdy/3", "h2c", "hq"}
+)
+
+// hello builds the probe's ClientHello record for serverName, which may
+// be an address: JARM sends whatever host it was given.
+func (p jarmProbe) hello(serverName string) []byte {
+	var ciphers []uint16
+	for _, id := range jarmCiphers {
+		if !p.no13 || id>>8 != 0x13 {
+			ciphers = append(ciphers, id)
+		}
+	}
+	ciphers = mung(ciphers, p.ciphers)
+	if p.grease {
+		ciphers = append([]uint16{grease()}, ciphers...)
+	}
+
+	var ext []byte
+	if p.grease {
+		ext = extension(ext, grease(), nil)
+	}
+	ext = extension(ext, extServerName, sniBody(serverName))
+	ext = extension(ext, extExtendedMaster, nil)
+	ext = extension(ext, 0x0001, []byte{1}) // max_fragment_length
+	ext = extension(ext, extRenegotiationInfo, []byte{0})
+	ext = extension(ext, extSupportedGroups, vec16(nil, u16s(0x001d, 0x0017, 0x0018, 0x0019)))
+	ext = extension(ext, extECPointFormats, []byte{1, 0})
+	ext = extension(ext, 0x0023, nil) // session_ticket
+
+	alpns := jarmALPN
+	if p.rareALPN {
+		alpns = jarmRareALPN
+	}
+	var alpn []byte
+	for _, proto := range mung(alpns, p.extOrder) {
+		alpn = vec8(alpn, []byte(proto))
+	}
+	ext = extension(ext, extALPN, vec16(nil, alpn))
+	ext = extension(ext, extSignatureAlgorithms, vec16(nil, u16s(0x0403, 0x0804, 0x0401, 0x0503, 0x0805, 0x0501, 0x0806, 0x0601, 0x0201)))
+
+	var share []byte
+	if p.grease {
+		share = append(binary.BigEndian.AppendUint16(nil, grease()), 0, 1, 0)
+	}
+	ext = extension(ext, extKeyShare, vec16(nil, keyShareEntry(share)))
+	ext = extension(ext, extPSKModes, []byte{1, 1})
+
+	if p.version == tls.VersionTLS13 || p.support == support12 {
+		versions := []uint16{tls.VersionTLS10, tls.VersionTLS11, tls.VersionTLS12}
+		if p.support != support12 {
+			versions = append(versions, tls.VersionTLS13)
+		}
+		versions = mung(versions, p.extOrder)
+		if p.grease {
+			versions = append([]uint16{grease()}, versions...)
+		}
+		ext = extension(ext, extSupportedVersions, vec8(nil, u16s(versions...)))
+	}
+
+	if p.version == tls.VersionTLS13 {
+		return clientHello(tls.VersionTLS10, tls.VersionTLS12, ciphers, ext)
+	}
+	return clientHello(p.version, p.version, ciphers, ext)
+}
+
+// mung reorders items as JARM does.
+func mung[T any](items []T, o order) []T {
+	n := len(items)
+	var out []T
+	switch o {
+	case forward:
+		out = append(out, items...)
+	case reverse:
+		for i := n - 1; i >= 0; i-- {
+			out = append(out, items[i])
+		}
+	case bottomHalf:
+		out = append(out, items[(n+1)/2:]...)
+	case topHalf:
+		// The middle item of an odd list goes to the top half.
+		if n%2 == 1 {
+			out = append(out, items[n/2])
+		}
+		out = append(out, mung(mung(items, reverse), bottomHalf)...)
+	case middleOut:
+		middle := n / 2
+		if n%2 == 1 {
+			out = append(out, items[middle])
+			for i := 1; i <= middle; i++ {
+				out = append(out, items[middle+i], items[middle-i])
+			}
+		} else {
+			for i := 1; i <= middle; i++ {
+				out = append(out, items[middle-1+i], items[middle-i])
+			}
+		}
+	}
+	return out
+}
+
+func grease() uint16 {
+	b := byte(rand.IntN(16))<<4 | 0x0a
+	return uint16(b)<<8 | uint16(b)
+}
+
+// jarmResult renders a probe's answer as "cipher|version|alpn|extensions",
+// or "|||" when there was none.
+func jarmResult(h *serverHello) string {
+	if h == nil {
+		return "|||"
+	}
+	types := make([]string, len(h.extensions))
+	for i, ext := range h.extensions {
+		types[i] = fmt.Sprintf("%04x", ext.typ)
+	}
+	return fmt.Sprintf("%04x|%04x|%s|%s", h.cipher, h.version, h.alpn(), strings.Join(types, "-"))
+}
+
+// jarmHash folds the ten probe results into the 62-character JARM
+// fingerprint: two characters for each suite chosen and one for each
+// version, followed by a truncated SHA-256 of the ALPN and extension
+// answers. A server answering nothing hashes to all zeros.
+func jarmHash(results []string) string {
+	if strings.Trim(strings.Join(results, ""), "|") == "" {
+		return strings.Repeat("0", 62)
+	}
+	var fuzzy, rest strings.Builder
+	for _, result := range results {
+		parts := strings.SplitN(result, "|", 4)
+		fuzzy.WriteString(jarmCipher(parts[0]))
+		fuzzy.WriteString(jarmVersion(parts[1]))
+		rest.WriteString(parts[2])
+		rest.WriteString(parts[3])
+	}
+	sum := sha256.Sum256([]byte(rest.String()))
+	return fuzzy.String() + hex.EncodeToString(sum[:])[:32]
+}
+
+func jarmCipher(cipher string) string {
+	if cipher == "" {
+		return "00"
+	}
+	id, _ := strconv.ParseUint(cipher, 16, 16)
+	count := len(jarmCipherIndex) + 1
+	for i, known := range jarmCipherIndex {
+		if uint16(id) == known {
+			count = i + 1
+			break
+		}
+	}
+	return fmt.Sprintf("%02x", count)
+}
+
+func jarmVersion(version string) string {
+	if len(version) != 4 {
+		return "0"
+	}
+	minor := int(version[3] - '0')
+	if minor < 0 || minor > 5 {
+		return "0"
+	}
+	return string("abcdef"[minor])
+}
+
+// ja3s computes the JA3S fingerprint of a ServerHello: the MD5 of its
+// version, cipher suite and extension types in decimal. It returns the
+// fingerprint and the string hashed.
+func ja3s(h *serverHello) (string, string) {
+	types := make([]string, len(h.extensions))
+	for i, ext := range h.extensions {
+		types[i] = strconv.Itoa(int(ext.typ))
+	}
+	raw := fmt.Sprintf("%d,%d,%s", h.version, h.cipher, strings.Join(types, "-"))
+	sum := md5.Sum([]byte(raw))
+	return hex.EncodeToString(sum[:]), raw
+}
@@ -0,0 +1,297 @@
+package tlsscan
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/internal/registry"
+	"github.com/NASHEDIxCODER/gospyder/pkg/scanner"
+)
+
+// ModuleAdapter inspects the TLS services of a host.
+type ModuleAdapter struct{}
+
+// NewModule creates a new TLS inspection module
+func NewModule() registry.Module {
+	return &ModuleAdapter{}
+}
+
+// Name returns the module name
+func (m *ModuleAdapter) Name() string {
+	return "tls"
+}
+
+// Description returns the module description
+func (m *ModuleAdapter) Description() string {
+	return "TLS inspection: certificate chains, protocol versions, cipher suites, JARM and JA3S"
+}
+
+// Run inspects the open ports of a prior ports scan that may speak TLS,
+// or else the "tls-ports" flag's ports or the configured ones, and
+// reports each TLS service and its weaknesses. The DNS names its
+// certificates cover are listed in the "san_names" metadata, for enum to
+// resolve as subdomain candidates.
+func (m *ModuleAdapter) Run(ctx context.Context, opts registry.Options) (*registry.Result, error) {
+	target, ok := opts.Flags["target"].(string)
+	if !ok || target == "" {
+		return nil, fmt.Errorf("target flag required")
+	}
+	host, targetPort := splitTarget(target)
+	ports, source, err := tlsPorts(opts, host, targetPort)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := opts.Config.TLS
+	if ciphers, ok := opts.Flags["ciphers"].(bool); ok {
+		cfg.Ciphers = ciphers
+	}
+	timeout := opts.Config.Scanner.PortTimeout
+	inspector := &Inspector{
+		Timeout: timeout,
+		Ciphers: cfg.Ciphers,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			dialer := &net.Dialer{Timeout: timeout}
+			return opts.Replay.DialContext(ctx, network, address, dialer.DialContext)
+		},
+	}
+
+	opts.Logger.Info("Inspecting TLS on %d port(s) of %s (from %s)", len(ports), host, source)
+	progress := opts.ProgressReporter()
+	progress.AddTotal(int64(len(ports)))
+	reports := make([]*Report, len(ports))
+	threads := opts.Config.Threads
+	if threads <= 0 {
+		threads = 10
+	}
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, threads)
+	for i, port := range ports {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			defer progress.Increment(1)
+			report, err := inspector.Inspect(ctx, host, port)
+			if err != nil {
+				// Most ports tried do not speak TLS; that is not a failure.
+				opts.Logger.Debug("No TLS on %s:%d: %v", host, port, err)
+				return
+			}
+			reports[i] = report
+		}()
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		opts.Errors.Add(errors.NewTimeoutError(fmt.Sprintf("TLS inspection of %s stopped before all %d ports were checked", host, len(ports))))
+	}
+
+	now := time.Now()
+	findings := []registry.Finding{}
+	tlsPorts, notTLS := []int{}, []int{}
+	for i, report := range reports {
+		if report == nil {
+			notTLS = append(notTLS, ports[i])
+			continue
+		}
+		tlsPorts = append(tlsPorts, report.Port)
+		findings = append(findings, serviceFinding(report))
+		for _, issue := range Check(report, now, cfg.ExpiryWarning) {
+			findings = append(findings, registry.Finding{
+				Type:        issue.Kind,
+				Value:       net.JoinHostPort(host, strconv.Itoa(report.Port)),
+				Description: issue.Description,
+				Severity:    issue.Severity,
+				Metadata:    map[string]interface{}{"host": host, "port": report.Port},
+			})
+		}
+	}
+	for range findings {
+		progress.AddFinding()
+	}
+
+	return &registry.Result{
+		Module:    m.Name(),
+		Timestamp: time.Now(),
+		Status:    "success",
+		Target:    target,
+		Findings:  findings,
+		Metadata: map[string]interface{}{
+			"host":          host,
+			"port_source":   source,
+			"ports_checked": len(ports),
+			"tls_ports":     tlsPorts,
+			"not_tls":       notTLS,
+			"san_names":     SANNames(reports),
+		},
+	}, nil
+}
+
+// serviceFinding describes a TLS service: how a default client connects
+// and whose certificate it gets.
+func serviceFinding(r *Report) registry.Finding {
+	description := r.Version + " " + r.Cipher
+	if r.Version == "" {
+		description = "Accepts " + strings.Join(r.Versions, ", ") + " but no handshake a default client completes"
+	}
+	if len(r.Chain) > 0 {
+		leaf := r.Chain[0]
+		description += fmt.Sprintf("; certificate for %s issued by %s, valid until %s",
+			commonName(leaf), issuerName(leaf), leaf.NotAfter.Format("2006-01-02"))
+	}
+	evidence := []string{"JARM " + r.JARM}
+	if r.JA3S != "" {
+		evidence = append(evidence, "JA3S "+r.JA3S+" ("+r.JA3SRaw+")")
+	}
+	metadata := map[string]interface{}{
+		"host":     r.Host,
+		"port":     r.Port,
+		"versions": r.Versions,
+		"trusted":  r.Trusted,
+		"jarm":     r.JARM,
+	}
+	for key, value := range map[string]string{
+		"version":     r.Version,
+		"cipher":      r.Cipher,
+		"alpn":        r.ALPN,
+		"server_name": r.ServerName,
+		"trust_error": r.TrustError,
+		"ja3s":        r.JA3S,
+		"ja3s_raw":    r.JA3SRaw,
+	} {
+		if value != "" {
+			metadata[key] = value
+		}
+	}
+	if r.Ciphers != nil {
+		metadata["ciphers"] = r.Ciphers
+	}
+	if len(r.Chain) > 0 {
+		metadata["chain"] = r.Chain
+		metadata["sans"] = r.Chain[0].SANs
+	}
+	return registry.Finding{
+		Type:        "tls_service",
+		Value:       net.JoinHostPort(r.Host, strconv.Itoa(r.Port)),
+		Description: description,
+		Severity:    "info",
+		Evidence:    evidence,
+		Metadata:    metadata,
+	}
+}
+
+// SANNames returns the DNS names the leaf certificates of reports cover,
+// lowercased, with wildcards reduced to their parent, sorted and
+// deduplicated.
+func SANNames(reports []*Report) []string {
+	seen := map[string]bool{}
+	names := []string{}
+	for _, r := range reports {
+		if r == nil || len(r.Chain) == 0 || r.Chain[0].cert == nil {
+			continue
+		}
+		for _, name := range r.Chain[0].cert.DNSNames {
+			name = strings.TrimSuffix(strings.ToLower(strings.TrimPrefix(name, "*.")), ".")
+			if name != "" && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func commonName(c Certificate) string {
+	if c.cert != nil && c.cert.Subject.CommonName != "" {
+		return c.cert.Subject.CommonName
+	}
+	if len(c.SANs) > 0 {
+		return c.SANs[0]
+	}
+	return c.Subject
+}
+
+func issuerName(c Certificate) string {
+	if c.SelfSigned {
+		return "itself"
+	}
+	if c.cert != nil && c.cert.Issuer.CommonName != "" {
+		return c.cert.Issuer.CommonName
+	}
+	return c.Issuer
+}
+
+// tlsPorts returns the ports to inspect and where they came from: the
+// target's own port, the "tls-ports" flag, the open TCP ports a prior
+// ports scan found on host that may speak TLS, or the configured ports.
+func tlsPorts(opts registry.Options, host string, targetPort int) ([]int, string, error) {
+	if targetPort > 0 {
+		return []int{targetPort}, "target", nil
+	}
+	if list, _ := opts.Flags["tls-ports"].(string); list != "" {
+		ports, err := scanner.ParsePorts(list)
+		return ports, "flag", err
+	}
+	results, _ := opts.Flags["results"].(map[string]*registry.Result)
+	if scan := results["ports"]; scan != nil {
+		ports := []int{}
+		for _, f := range scan.Findings {
+			port, _ := f.Metadata["port"].(int)
+			protocol, _ := f.Metadata["protocol"].(string)
+			service, _ := f.Metadata["service"].(string)
+			// A scan of several hosts names each finding's host.
+			if scanned, ok := f.Metadata["host"].(string); ok && !strings.EqualFold(scanned, host) {
+				continue
+			}
+			if port > 0 && protocol == "tcp" && mayBeTLS(service) {
+				ports = append(ports, port)
+			}
+		}
+		return ports, "ports scan", nil
+	}
+	return append([]int(nil), opts.Config.TLS.Ports...), "config", nil
+}
+
+// mayBeTLS reports whether a ports scan service name may be TLS: one the
+// service probes saw inside TLS, or one they could not name.
+func mayBeTLS(service string) bool {
+	switch service {
+	case "TLS", "HTTPS", "HTTPS-alt", "IMAPS", "POP3S", "SMTPS", "unknown", "":
+		return true
+	}
+	return strings.HasSuffix(service, "/TLS")
+}
+
+// splitTarget returns the host of a name, address, host:port or URL,
+// and the port it names, if any.
+func splitTarget(target string) (string, int) {
+	host, port := target, ""
+	if strings.Contains(target, "://") {
+		if u, err := url.Parse(target); err == nil {
+			host, port = u.Hostname(), u.Port()
+			if port == "" && u.Scheme == "https" {
+				port = "443"
+			}
+		}
+	} else if h, p, err := net.SplitHostPort(target); err == nil {
+		host, port = h, p
+	}
+	n, _ := strconv.Atoi(port)
+	return strings.TrimSuffix(strings.ToLower(strings.Trim(host, "[]")), "."), n
+}
@@ -0,0 +1,227 @@
+package tlsscan
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/config"
+	"github.com/NASHEDIxCODER/gospyder/internal/errors"
+	"github.com/NASHEDIxCODER/gospyder/internal/logger"
+	"github.com/NASHEDIxCODER/gospyder/internal/registry"
+)
+
+// weakServer serves TLS 1.1 and 1.2 with a 3DES suite and an expired,
+// self-signed certificate on a 1024-bit RSA key.
+func weakServer(t *testing.T) net.Listener {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "www.example.com"},
+		DNSNames:     []string{"www.example.com", "*.api.example.com"},
+		NotBefore:    time.Now().Add(-48 * time.Hour),
+		NotAfter:     time.Now().Add(-24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+		MinVersion:   tls.VersionTLS11,
+		MaxVersion:   tls.VersionTLS12,
+		CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_RSA_WITH_3DES_EDE_CBC_SHA},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				conn.SetDeadline(time.Now().Add(2 * time.Second))
+				conn.(*tls.Conn).Handshake()
+			}()
+		}
+	}()
+	return listener
+}
+
+func TestInspectReportsChainVersionsAndCiphers(t *testing.T) {
+	listener := weakServer(t)
+	inspector := &Inspector{
+		Timeout: 2 * time.Second,
+		Ciphers: true,
+		// Reach the server under a name it has no certificate for.
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, listener.Addr().String())
+		},
+	}
+	report, err := inspector.Inspect(context.Background(), "shop.example.com", 443)
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+
+	if report.Version != "TLS 1.2" || report.ServerName != "shop.example.com" {
+		t.Errorf("negotiated %s with SNI %q", report.Version, report.ServerName)
+	}
+	if fmt.Sprint(report.Versions) != "[TLS 1.2 TLS 1.1]" {
+		t.Errorf("Versions = %v", report.Versions)
+	}
+	if got := fmt.Sprint(report.Ciphers["TLS 1.2"]); got != "[TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256 TLS_RSA_WITH_3DES_EDE_CBC_SHA]" {
+		t.Errorf("TLS 1.2 ciphers = %s", got)
+	}
+	if got := fmt.Sprint(report.Ciphers["TLS 1.1"]); got != "[TLS_RSA_WITH_3DES_EDE_CBC_SHA]" {
+		t.Errorf("TLS 1.1 ciphers = %s", got)
+	}
+	if len(report.Chain) != 1 || !report.Chain[0].SelfSigned || report.Chain[0].KeyType != "RSA" || report.Chain[0].KeyBits != 1024 {
+		t.Errorf("Chain = %+v", report.Chain)
+	}
+	if len(report.JARM) != 62 || strings.Trim(report.JARM, "0") == "" {
+		t.Errorf("JARM = %q", report.JARM)
+	}
+	if len(report.JA3S) != 32 || !strings.HasPrefix(report.JA3SRaw, "771,49199,") {
+		t.Errorf("JA3S = %q (%s)", report.JA3S, report.JA3SRaw)
+	}
+
+	kinds := map[string]string{}
+	for _, issue := range Check(report, time.Now(), 30*24*time.Hour) {
+		kinds[issue.Kind] = issue.Severity
+	}
+	want := map[string]string{
+		"tls_expired":       "high",
+		"tls_self_signed":   "medium",
+		"tls_mismatch":      "medium",
+		"tls_weak_key":      "medium",
+		"tls_weak_protocol": "medium",
+		"tls_weak_cipher":   "medium",
+	}
+	if fmt.Sprint(kinds) != fmt.Sprint(want) {
+		t.Errorf("Check() = %v, want %v", kinds, want)
+	}
+	if names := SANNames([]*Report{report, nil}); fmt.Sprint(names) != "[api.example.com www.example.com]" {
+		t.Errorf("SANNames() = %v", names)
+	}
+}
+
+func TestJARMHelpers(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	for _, tt := range []struct {
+		order order
+		want  string
+	}{
+		{reverse, "[5 4 3 2 1]"},
+		{bottomHalf, "[4 5]"},
+		{topHalf, "[3 2 1]"},
+		{middleOut, "[3 4 2 5 1]"},
+	} {
+		if got := fmt.Sprint(mung(items, tt.order)); got != tt.want {
+			t.Errorf("mung(%d) = %s, want %s", tt.order, got, tt.want)
+		}
+	}
+	if got := fmt.Sprint(mung([]int{1, 2, 3, 4}, middleOut)); got != "[3 2 4 1]" {
+		t.Errorf("mung(even, middleOut) = %s", got)
+	}
+
+	empty := make([]string, len(jarmProbes))
+	for i := range empty {
+		empty[i] = "|||"
+	}
+	if got := jarmHash(empty); got != strings.Repeat("0", 62) {
+		t.Errorf("jarmHash(no answers) = %s", got)
+	}
+	answers := append([]string{"c02f|0303|h2|ff01-0000-0010"}, empty[1:]...)
+	if got := jarmHash(answers); !strings.HasPrefix(got, "29d"+strings.Repeat("000", 9)) {
+		t.Errorf("jarmHash() = %s, want suite 41 and version d first", got)
+	}
+
+	// Each probe is a well-formed ClientHello record.
+	for i, probe := range jarmProbes {
+		hello := probe.hello("example.com")
+		if hello[0] != 22 || int(hello[3])<<8|int(hello[4]) != len(hello)-5 {
+			t.Errorf("probe %d: malformed record", i)
+		}
+	}
+}
+
+func TestModuleInspectsTLSPortsFromPortsScan(t *testing.T) {
+	secure := weakServer(t).Addr().(*net.TCPAddr).Port
+	plain, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plain.Close()
+	go func() {
+		for {
+			conn, err := plain.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write([]byte("220 ready\r\n"))
+			conn.Close()
+		}
+	}()
+	plainPort := plain.Addr().(*net.TCPAddr).Port
+
+	scan := &registry.Result{Module: "ports", Findings: []registry.Finding{
+		{Metadata: map[string]interface{}{"port": secure, "protocol": "tcp", "service": "HTTPS"}},
+		{Metadata: map[string]interface{}{"port": plainPort, "protocol": "tcp", "service": "unknown"}},
+		{Metadata: map[string]interface{}{"port": 22, "protocol": "tcp", "service": "SSH"}},
+	}}
+	cfg := config.DefaultConfig()
+	cfg.Scanner.PortTimeout = time.Second
+	result, err := NewModule().Run(context.Background(), registry.Options{
+		Config: cfg,
+		Logger: logger.New(false),
+		Errors: errors.NewCollector(),
+		Flags: map[string]interface{}{
+			"target":  "127.0.0.1",
+			"ciphers": false,
+			"results": map[string]*registry.Result{"ports": scan},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if fmt.Sprint(result.Metadata["tls_ports"]) != fmt.Sprint([]int{secure}) || fmt.Sprint(result.Metadata["not_tls"]) != fmt.Sprint([]int{plainPort}) {
+		t.Errorf("tls_ports = %v, not_tls = %v", result.Metadata["tls_ports"], result.Metadata["not_tls"])
+	}
+	if result.Metadata["ports_checked"] != 2 || result.Metadata["port_source"] != "ports scan" {
+		t.Errorf("metadata = %v, want SSH left out", result.Metadata)
+	}
+	types := map[string]bool{}
+	for _, f := range result.Findings {
+		types[f.Type] = true
+		if f.Type == "tls_service" && !strings.HasPrefix(f.Description, "TLS 1.2 TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256; certificate for www.example.com issued by itself") {
+			t.Errorf("service description = %q", f.Description)
+		}
+	}
+	// Addresses are not checked against the certificate, and suites
+	// are not enumerated with -ciphers=false.
+	if !types["tls_service"] || !types["tls_expired"] || types["tls_mismatch"] || types["tls_weak_cipher"] {
+		t.Errorf("finding types = %v", types)
+	}
+	if fmt.Sprint(result.Metadata["san_names"]) != "[api.example.com www.example.com]" {
+		t.Errorf("san_names = %v", result.Metadata["san_names"])
+	}
+}
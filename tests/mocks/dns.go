@@ -0,0 +1,565 @@
+package mocks
+
+import (
+	"crypto/tls"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// DNSServer is an in-process authoritative DNS stand-in answering from
+// fixed records over UDP and TCP on the same loopback port. UDP answers
+// larger than 512 bytes are truncated so clients must retry over TCP.
+// Zone transfers are refused unless AllowTransfer is called. Queries with
+// the DNSSEC OK bit receive the zone's NSEC3 records with negative answers.
+// ServeTLS and ServeHTTPS add DNS over TLS and DNS over HTTPS listeners
+// answering from the same records.
+type DNSServer struct {
+	// Addr is the "127.0.0.1:port" address to query.
+	Addr string
+
+	records []dnsRecord
+	udp     net.PacketConn
+	tcp     net.Listener
+	cert    *Certificate
+
+	mu            sync.Mutex
+	queries       []string
+	allowTransfer bool
+	hijack        string
+	failing       map[string]bool
+}
+
+const (
+	typeRRSIG = dnsmessage.Type(46)
+	typeNSEC  = dnsmessage.Type(47)
+	typeNSEC3 = dnsmessage.Type(50)
+)
+
+// dnsTypes are the record types fixtures may use; RRSIG only appears in
+// NSEC type bitmaps.
+var dnsTypes = map[string]dnsmessage.Type{
+	"A": dnsmessage.TypeA, "AAAA": dnsmessage.TypeAAAA, "CNAME": dnsmessage.TypeCNAME,
+	"MX": dnsmessage.TypeMX, "NS": dnsmessage.TypeNS, "PTR": dnsmessage.TypePTR, "TXT": dnsmessage.TypeTXT, "SOA": dnsmessage.TypeSOA,
+	"RRSIG": typeRRSIG, "NSEC": typeNSEC, "NSEC3": typeNSEC3,
+}
+
+type dnsRecord struct {
+	name  string
+	rtype dnsmessage.Type
+	ttl   uint32
+	value string
+}
+
+// NewDNSServer serves records given as zone-file style lines
+// "name TTL TYPE value", e.g. "www.example.com 300 A 192.0.2.1". MX values
+// are "pref target"; SOA values are "ns mbox serial refresh retry expire min";
+// NSEC values are "next types..."; NSEC3 values are
+// "alg flags iterations salt next-hash types...".
+// The server stops when the test ends.
+func NewDNSServer(t testing.TB, records ...string) *DNSServer {
+	t.Helper()
+	s := &DNSServer{}
+	for _, line := range records {
+		r, err := parseDNSRecord(line)
+		if err != nil {
+			t.Fatalf("DNS fixture: %v", err)
+		}
+		s.records = append(s.records, r)
+	}
+
+	udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen udp: %v", err)
+	}
+	tcp, err := net.Listen("tcp", udp.LocalAddr().String())
+	if err != nil {
+		udp.Close()
+		t.Fatalf("listen tcp: %v", err)
+	}
+	s.udp, s.tcp, s.Addr = udp, tcp, udp.LocalAddr().String()
+	go s.serveUDP()
+	go s.serveStream(tcp)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Close stops the server.
+func (s *DNSServer) Close() {
+	s.udp.Close()
+	s.tcp.Close()
+}
+
+// AllowTransfer makes the server answer AXFR requests over TCP.
+func (s *DNSServer) AllowTransfer() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.allowTransfer = true
+}
+
+// HijackNXDOMAIN makes the server answer A queries for missing names with
+// ip, like resolvers that redirect typos to a search page.
+func (s *DNSServer) HijackNXDOMAIN(ip string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hijack = ip
+}
+
+// FailNames makes the server answer every query for the given names with
+// SERVFAIL, like a broken upstream.
+func (s *DNSServer) FailNames(names ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failing == nil {
+		s.failing = map[string]bool{}
+	}
+	for _, name := range names {
+		s.failing[strings.ToLower(name)] = true
+	}
+}
+
+// Queries returns the received questions as "name TYPE" strings.
+func (s *DNSServer) Queries() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.queries...)
+}
+
+func (s *DNSServer) serveUDP() {
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := s.udp.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		replies, err := s.answer(buf[:n], true)
+		if err == nil {
+			s.udp.WriteTo(replies[0], addr)
+		}
+	}
+}
+
+// ServeTLS starts a DNS over TLS listener and returns its
+// "127.0.0.1:port" address. Clients must trust TLSConfig.
+func (s *DNSServer) ServeTLS(t testing.TB) string {
+	t.Helper()
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", s.certificate(t).ServerConfig())
+	if err != nil {
+		t.Fatalf("listen tls: %v", err)
+	}
+	go s.serveStream(ln)
+	t.Cleanup(func() { ln.Close() })
+	return ln.Addr().String()
+}
+
+// ServeHTTPS starts a DNS over HTTPS listener and returns its
+// "https://127.0.0.1:port/dns-query" URL. It accepts RFC 8484 POST and GET
+// requests. Clients must trust TLSConfig.
+func (s *DNSServer) ServeHTTPS(t testing.TB) string {
+	t.Helper()
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(s.serveDoH))
+	srv.TLS = s.certificate(t).ServerConfig()
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return srv.URL + "/dns-query"
+}
+
+// TLSConfig returns a client configuration trusting the DoT and DoH
+// listeners.
+func (s *DNSServer) TLSConfig() *tls.Config {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cert == nil {
+		return nil
+	}
+	return s.cert.ClientConfig()
+}
+
+func (s *DNSServer) certificate(t testing.TB) *Certificate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cert == nil {
+		s.cert = NewCertificate(t, "127.0.0.1")
+	}
+	return s.cert
+}
+
+func (s *DNSServer) serveDoH(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/dns-query" {
+		http.NotFound(w, r)
+		return
+	}
+	var query []byte
+	var err error
+	switch r.Method {
+	case http.MethodPost:
+		if r.Header.Get("Content-Type") != "application/dns-message" {
+			http.Error(w, "unsupported media type", http.StatusUnsupportedMediaType)
+			return
+		}
+		query, err = io.ReadAll(r.Body)
+	case http.MethodGet:
+		query, err = base64.RawURLEncoding.DecodeString(r.URL.Query().Get("dns"))
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	replies, err := s.answer(query, false)
+	if err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/dns-message")
+	w.Write(replies[0])
+}
+
+// serveStream answers length-prefixed queries on ln, as DNS does over TCP
+// and TLS.
+func (s *DNSServer) serveStream(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go func(conn net.Conn) {
+			defer conn.Close()
+			for {
+				var length [2]byte
+				if _, err := io.ReadFull(conn, length[:]); err != nil {
+					return
+				}
+				query := make([]byte, binary.BigEndian.Uint16(length[:]))
+				if _, err := io.ReadFull(conn, query); err != nil {
+					return
+				}
+				replies, err := s.answer(query, false)
+				if err != nil {
+					return
+				}
+				for _, reply := range replies {
+					framed := make([]byte, 2+len(reply))
+					binary.BigEndian.PutUint16(framed, uint16(len(reply)))
+					copy(framed[2:], reply)
+					if _, err := conn.Write(framed); err != nil {
+						return
+					}
+				}
+			}
+		}(conn)
+	}
+}
+
+// answer returns the reply messages for query: one, or several for a zone
+// transfer.
+func (s *DNSServer) answer(query []byte, udp bool) ([][]byte, error) {
+	var msg dnsmessage.Message
+	if err := msg.Unpack(query); err != nil || len(msg.Questions) != 1 {
+		return nil, fmt.Errorf("bad query")
+	}
+	q := msg.Questions[0]
+	name := strings.ToLower(strings.TrimSuffix(q.Name.String(), "."))
+	s.mu.Lock()
+	s.queries = append(s.queries, name+" "+typeName(q.Type))
+	allowTransfer, hijack, failing := s.allowTransfer, s.hijack, s.failing[name]
+	s.mu.Unlock()
+
+	reply := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: msg.ID, Response: true, Authoritative: true, RecursionDesired: msg.RecursionDesired},
+		Questions: msg.Questions,
+	}
+	if failing {
+		reply.RCode = dnsmessage.RCodeServerFailure
+		packed, err := reply.Pack()
+		return [][]byte{packed}, err
+	}
+	if q.Type == dnsmessage.TypeAXFR {
+		if udp || !allowTransfer {
+			reply.RCode = dnsmessage.RCodeRefused
+			packed, err := reply.Pack()
+			return [][]byte{packed}, err
+		}
+		return s.transfer(reply, name)
+	}
+
+	answers, exists := s.lookup(name, q.Type)
+	if !exists && hijack != "" && q.Type == dnsmessage.TypeA {
+		answers, exists = []dnsRecord{{name: name, rtype: dnsmessage.TypeA, ttl: 60, value: hijack}}, true
+	}
+	for _, r := range answers {
+		reply.Answers = append(reply.Answers, r.resource())
+	}
+	if !exists {
+		reply.RCode = dnsmessage.RCodeNameError
+	}
+	if len(answers) == 0 {
+		if soa := s.zoneSOA(name); soa != nil {
+			reply.Authorities = append(reply.Authorities, soa.resource())
+			if dnssecOK(msg) {
+				for _, r := range s.records {
+					if r.rtype == typeNSEC3 && strings.HasSuffix(r.name, "."+soa.name) {
+						reply.Authorities = append(reply.Authorities, r.resource())
+					}
+				}
+			}
+		}
+	}
+
+	packed, err := reply.Pack()
+	if err != nil {
+		return nil, err
+	}
+	if udp && len(packed) > 512 {
+		reply.Truncated = true
+		reply.Answers, reply.Authorities = nil, nil
+		packed, err = reply.Pack()
+	}
+	return [][]byte{packed}, err
+}
+
+// transfer streams the zone's SOA, its other records and the SOA again,
+// split over two messages like larger real transfers.
+func (s *DNSServer) transfer(reply dnsmessage.Message, zone string) ([][]byte, error) {
+	var soa *dnsRecord
+	var body []dnsmessage.Resource
+	for i, r := range s.records {
+		if r.rtype == dnsmessage.TypeSOA && r.name == zone {
+			soa = &s.records[i]
+		} else if r.name == zone || strings.HasSuffix(r.name, "."+zone) {
+			body = append(body, r.resource())
+		}
+	}
+	if soa == nil {
+		reply.RCode = dnsmessage.RCodeRefused
+		packed, err := reply.Pack()
+		return [][]byte{packed}, err
+	}
+
+	half := len(body) / 2
+	first, second := reply, reply
+	first.Answers = append([]dnsmessage.Resource{soa.resource()}, body[:half]...)
+	second.Questions = nil
+	second.Answers = append(append([]dnsmessage.Resource(nil), body[half:]...), soa.resource())
+	var replies [][]byte
+	for _, m := range []dnsmessage.Message{first, second} {
+		packed, err := m.Pack()
+		if err != nil {
+			return nil, err
+		}
+		replies = append(replies, packed)
+	}
+	return replies, nil
+}
+
+func dnssecOK(msg dnsmessage.Message) bool {
+	for _, r := range msg.Additionals {
+		if r.Header.Type == dnsmessage.TypeOPT && r.Header.DNSSECAllowed() {
+			return true
+		}
+	}
+	return false
+}
+
+// lookup returns the records answering name and qtype, following CNAMEs
+// inside the fixture. exists is false when nothing is at or below name.
+func (s *DNSServer) lookup(name string, qtype dnsmessage.Type) ([]dnsRecord, bool) {
+	var answers []dnsRecord
+	current := name
+	for hops := 0; hops < 10; hops++ {
+		var cname *dnsRecord
+		matched := false
+		for i, r := range s.records {
+			if r.name != current {
+				continue
+			}
+			if r.rtype == qtype {
+				answers = append(answers, r)
+				matched = true
+			} else if r.rtype == dnsmessage.TypeCNAME {
+				cname = &s.records[i]
+			}
+		}
+		if matched || cname == nil || qtype == dnsmessage.TypeCNAME {
+			break
+		}
+		answers = append(answers, *cname)
+		current = cname.value
+	}
+	if len(answers) > 0 {
+		return answers, true
+	}
+	for _, r := range s.records {
+		if r.name == name || strings.HasSuffix(r.name, "."+name) {
+			return nil, true
+		}
+	}
+	return nil, false
+}
+
+// zoneSOA returns the SOA of the closest enclosing zone in the fixture.
+func (s *DNSServer) zoneSOA(name string) *dnsRecord {
+	for zone := name; zone != ""; {
+		for i, r := range s.records {
+			if r.rtype == dnsmessage.TypeSOA && r.name == zone {
+				return &s.records[i]
+			}
+		}
+		_, parent, ok := strings.Cut(zone, ".")
+		if !ok {
+			break
+		}
+		zone = parent
+	}
+	return nil
+}
+
+func (r dnsRecord) resource() dnsmessage.Resource {
+	header := dnsmessage.ResourceHeader{Name: dnsName(r.name), Type: r.rtype, Class: dnsmessage.ClassINET, TTL: r.ttl}
+	var body dnsmessage.ResourceBody
+	switch r.rtype {
+	case dnsmessage.TypeA:
+		var a [4]byte
+		copy(a[:], net.ParseIP(r.value).To4())
+		body = &dnsmessage.AResource{A: a}
+	case dnsmessage.TypeAAAA:
+		var aaaa [16]byte
+		copy(aaaa[:], net.ParseIP(r.value).To16())
+		body = &dnsmessage.AAAAResource{AAAA: aaaa}
+	case dnsmessage.TypeCNAME:
+		body = &dnsmessage.CNAMEResource{CNAME: dnsName(r.value)}
+	case dnsmessage.TypeNS:
+		body = &dnsmessage.NSResource{NS: dnsName(r.value)}
+	case dnsmessage.TypePTR:
+		body = &dnsmessage.PTRResource{PTR: dnsName(r.value)}
+	case dnsmessage.TypeMX:
+		pref, target, _ := strings.Cut(r.value, " ")
+		p, _ := strconv.ParseUint(pref, 10, 16)
+		body = &dnsmessage.MXResource{Pref: uint16(p), MX: dnsName(target)}
+	case dnsmessage.TypeTXT:
+		body = &dnsmessage.TXTResource{TXT: splitTXT(r.value)}
+	case dnsmessage.TypeSOA:
+		f := strings.Fields(r.value)
+		n := make([]uint32, 5)
+		for i := range n {
+			v, _ := strconv.ParseUint(f[2+i], 10, 32)
+			n[i] = uint32(v)
+		}
+		body = &dnsmessage.SOAResource{NS: dnsName(f[0]), MBox: dnsName(f[1]), Serial: n[0], Refresh: n[1], Retry: n[2], Expire: n[3], MinTTL: n[4]}
+	case typeNSEC:
+		f := strings.Fields(r.value)
+		body = &dnsmessage.UnknownResource{Type: typeNSEC, Data: append(wireName(f[0]), typeBitmap(f[1:])...)}
+	case typeNSEC3:
+		f := strings.Fields(r.value)
+		alg, _ := strconv.ParseUint(f[0], 10, 8)
+		flags, _ := strconv.ParseUint(f[1], 10, 8)
+		iterations, _ := strconv.ParseUint(f[2], 10, 16)
+		var salt []byte
+		if f[3] != "-" {
+			salt, _ = hex.DecodeString(f[3])
+		}
+		next, _ := base32.HexEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(f[4]))
+		data := []byte{byte(alg), byte(flags), byte(iterations >> 8), byte(iterations), byte(len(salt))}
+		data = append(append(data, salt...), byte(len(next)))
+		data = append(append(data, next...), typeBitmap(f[5:])...)
+		body = &dnsmessage.UnknownResource{Type: typeNSEC3, Data: data}
+	}
+	return dnsmessage.Resource{Header: header, Body: body}
+}
+
+// splitTXT splits a TXT value into 255-byte character strings.
+func splitTXT(value string) []string {
+	var parts []string
+	for len(value) > 255 {
+		parts = append(parts, value[:255])
+		value = value[255:]
+	}
+	return append(parts, value)
+}
+
+// wireName encodes name uncompressed, as NSEC rdata requires.
+func wireName(name string) []byte {
+	var b []byte
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		b = append(append(b, byte(len(label))), label...)
+	}
+	return append(b, 0)
+}
+
+// typeBitmap encodes type names as an NSEC type bitmap.
+func typeBitmap(names []string) []byte {
+	windows := map[int][]byte{}
+	for _, name := range names {
+		t, ok := dnsTypes[strings.ToUpper(name)]
+		if !ok {
+			continue
+		}
+		window, bit := int(t)>>8, int(t)&0xff
+		bits := windows[window]
+		for len(bits) <= bit/8 {
+			bits = append(bits, 0)
+		}
+		bits[bit/8] |= 0x80 >> (bit % 8)
+		windows[window] = bits
+	}
+	var b []byte
+	for window := 0; window < 256; window++ {
+		if bits, ok := windows[window]; ok {
+			b = append(append(b, byte(window), byte(len(bits))), bits...)
+		}
+	}
+	return b
+}
+
+func typeName(t dnsmessage.Type) string {
+	for name, v := range dnsTypes {
+		if v == t {
+			return name
+		}
+	}
+	return strings.TrimPrefix(t.String(), "Type")
+}
+
+func parseDNSRecord(line string) (dnsRecord, error) {
+	fields := strings.SplitN(line, " ", 4)
+	if len(fields) != 4 {
+		return dnsRecord{}, fmt.Errorf("invalid record %q", line)
+	}
+	ttl, err := strconv.ParseUint(fields[1], 10, 32)
+	if err != nil {
+		return dnsRecord{}, fmt.Errorf("invalid TTL in %q", line)
+	}
+	rtype, ok := dnsTypes[fields[2]]
+	if !ok || rtype == typeRRSIG {
+		return dnsRecord{}, fmt.Errorf("unsupported type in %q", line)
+	}
+	if rtype == dnsmessage.TypeSOA && len(strings.Fields(fields[3])) != 7 {
+		return dnsRecord{}, fmt.Errorf("invalid SOA in %q", line)
+	}
+	if rtype == typeNSEC3 && len(strings.Fields(fields[3])) < 5 {
+		return dnsRecord{}, fmt.Errorf("invalid NSEC3 in %q", line)
+	}
+	value := fields[3]
+	if rtype != dnsmessage.TypeTXT {
+		value = strings.ToLower(strings.TrimSuffix(value, "."))
+	}
+	return dnsRecord{name: strings.ToLower(strings.TrimSuffix(fields[0], ".")), rtype: rtype, ttl: uint32(ttl), value: value}, nil
+}
+
+func dnsName(name string) dnsmessage.Name {
+	return dnsmessage.MustNewName(strings.TrimSuffix(name, ".") + ".")
+}
@@ -0,0 +1,30 @@
+package mocks
+
+import (
+	"testing"
+
+	"github.com/NASHEDIxCODER/gospyder/internal/replay"
+)
+
+// ReplaySession loads a recorded fixture so a module can run offline.
+func ReplaySession(t testing.TB, path string) *replay.Session {
+	t.Helper()
+	session, err := replay.Load(path)
+	if err != nil {
+		t.Fatalf("load replay fixture: %v", err)
+	}
+	return session
+}
+
+// RecordSession records into path and saves the fixture when the test ends.
+// Use it once against a live target to capture a new regression fixture.
+func RecordSession(t testing.TB, path string) *replay.Session {
+	t.Helper()
+	session := replay.NewRecorder(path)
+	t.Cleanup(func() {
+		if err := session.Save(); err != nil {
+			t.Errorf("save replay fixture: %v", err)
+		}
+	})
+	return session
+}